@@ -1,16 +1,72 @@
 package cache
 
 import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+)
+
+const (
+	// tempDir holds files being written, it's emptied on Load. Mounts are
+	// stored in directories named by their ID, so it can't collide with one.
+	tempDir = ".dj-tmp"
+	// How often changed last used times are written to the metadata store
+	flushInterval = time.Minute
 )
 
 // CacheManager manages local file cache (LRU, pinning, checksums)
+//
+// File contents are stored under RootDir/<mount>/<path>. Entries are kept in
+// least recently used order, and whenever the cache grows over maxSize the
+// least recently used entries that aren't pinned are evicted. Dirty entries,
+// written locally and not uploaded yet, are never evicted or invalidated. The
+// index is persisted in the metadata store, so the cache survives restarts.
+//
+// Index changes are queued under mu and written by sync once mu is released,
+// so the metadata store is never written with the whole cache locked.
 type CacheManager struct {
 	RootDir string
 	mu      sync.Mutex
-	files   map[string]*CacheEntry // key: cache path
-	maxSize int64
+	store   *metadata.MetadataStore
+	files   map[string]*list.Element // key: cache path, value: *CacheEntry
+	lru     *list.List               // most recently used at the front
+	size    int64
+	maxSize int64                            // 0 means unlimited
+	touched map[string]bool                  // entries whose last used time isn't persisted yet
+	pending map[string]*metadata.CacheRecord // index changes to write, nil to delete
+	syncMu  sync.Mutex                       // keeps pending changes written in order
+	reads   map[uint32]*readCounts
+	stop    chan struct{}
+}
+
+// readCounts counts the reads of a mount that could be served from the cache
+type readCounts struct {
+	hits, misses uint64
+}
+
+// MountStats describes the cached contents of a mount. Hits and Misses count
+// reads since the cache manager was created.
+type MountStats struct {
+	MountID     uint32
+	Size        int64
+	Entries     int
+	PinnedBytes int64
+	DirtyBytes  int64
+	Hits        uint64
+	Misses      uint64
 }
 
 type CacheEntry struct {
@@ -19,36 +75,754 @@ type CacheEntry struct {
 	Pinned   bool
 	Checksum string
 	LastUsed time.Time
+	Version  string // Remote version the contents were read at, e.g. an ETag
+	Dirty    bool   // Written locally and not uploaded yet
+	Base     string // Remote version dirty contents are based on, if known
 }
 
-// NewCacheManager initializes the cache manager at the given root directory
-func NewCacheManager(root string, maxSize int64) *CacheManager {
+// NewCacheManager initializes the cache manager at the given root directory,
+// keeping its index in store
+func NewCacheManager(root string, maxSize int64, store *metadata.MetadataStore) *CacheManager {
 	return &CacheManager{
 		RootDir: root,
-		files:   make(map[string]*CacheEntry),
+		store:   store,
+		files:   make(map[string]*list.Element),
+		lru:     list.New(),
 		maxSize: maxSize,
+		touched: make(map[string]bool),
+		pending: make(map[string]*metadata.CacheRecord),
+		reads:   make(map[uint32]*readCounts),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Load creates the cache directory and loads the persisted index, reconciling
+// it with the files on disk: files without an index record (or whose size
+// doesn't match it) are removed, as are records whose file is missing, and
+// files left in the temp directory by an interrupted write. It then evicts
+// down to the maximum size and starts persisting last used times.
+func (c *CacheManager) Load() error {
+	if err := os.MkdirAll(c.RootDir, 0755); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(c.tempDir()); err != nil {
+		return err
+	}
+
+	records, err := c.store.GetCacheRecords()
+	if err != nil {
+		return err
+	}
+
+	var entries []*CacheEntry
+	var dirs []string
+	orphans := 0
+	err = filepath.WalkDir(c.RootDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == c.tempDir() {
+				return filepath.SkipDir
+			}
+			if p != c.RootDir {
+				dirs = append(dirs, p)
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(c.RootDir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		record, ok := records[key]
+		if !ok || !d.Type().IsRegular() || record.Size != info.Size() {
+			orphans++
+			return os.Remove(p)
+		}
+		delete(records, key)
+		entries = append(entries, &CacheEntry{
+			Path:     key,
+			Size:     record.Size,
+			Pinned:   record.Pinned,
+			Checksum: record.Checksum,
+			LastUsed: record.LastUsed,
+			Version:  record.Version,
+			Dirty:    record.Dirty,
+			Base:     record.Base,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Whatever is left has no file
+	missing := make([]string, 0, len(records))
+	for key := range records {
+		missing = append(missing, key)
+	}
+	if err := c.store.DeleteCacheRecords(missing...); err != nil {
+		return err
+	}
+
+	// Deepest first, so emptied parents can be removed too
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, dir := range dirs {
+		os.Remove(dir) // Fails for directories that aren't empty
+	}
+
+	if orphans > 0 || len(missing) > 0 {
+		fmt.Printf("[CacheManager] Removed %d orphaned files and %d missing entries\n", orphans, len(missing))
+	}
+
+	// Oldest first, so the most recently used end up at the front
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+
+	c.mu.Lock()
+	for _, entry := range entries {
+		c.insert(entry)
+	}
+	c.evict()
+	c.mu.Unlock()
+	c.sync()
+
+	go c.flushLoop()
+	return nil
+}
+
+// Close persists the last used times not yet written and stops the background flush.
+func (c *CacheManager) Close() {
+	close(c.stop)
+	c.Flush()
+}
+
+// Flush persists last used times that changed since the previous flush.
+func (c *CacheManager) Flush() {
+	c.mu.Lock()
+	for key := range c.touched {
+		if elem, ok := c.files[key]; ok {
+			c.persist(elem.Value.(*CacheEntry))
+		}
+	}
+	c.touched = make(map[string]bool)
+	c.mu.Unlock()
+	c.sync()
+}
+
+func (c *CacheManager) flushLoop() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.Flush()
+		}
+	}
+}
+
+// Put stores the contents of a file in the cache, replacing any previous
+// version, and evicts other entries if the cache grew too large. version
+// identifies the remote version of the contents. A file larger than the whole
+// cache isn't stored.
+func (c *CacheManager) Put(mountID uint32, filePath string, data []byte, version string) error {
+	return c.put(mountID, filePath, data, version, "", false, false)
+}
+
+// PutDirty stores contents written locally that still have to be uploaded.
+// They are kept regardless of the cache size until MarkClean is called. base
+// is the remote version the changes are based on; contents that are already
+// dirty keep the base of the first change.
+func (c *CacheManager) PutDirty(mountID uint32, filePath string, data []byte, base string) error {
+	return c.put(mountID, filePath, data, "", base, true, false)
+}
+
+// PutPinned stores the contents of a file and pins it, so it's kept
+// regardless of the cache size.
+func (c *CacheManager) PutPinned(mountID uint32, filePath string, data []byte, version string) error {
+	return c.put(mountID, filePath, data, version, "", false, true)
+}
+
+// put writes the contents to a temp file without holding c.mu, and only
+// moves it in place under c.mu, along with updating the entry, so the cached
+// file always matches the checksum of its entry.
+func (c *CacheManager) put(mountID uint32, filePath string, data []byte, version string, base string, dirty bool, pinned bool) error {
+	key := CachePath(mountID, filePath)
+	if c.tooLarge(key, data, dirty, pinned) {
+		c.Remove(mountID, filePath)
+		return nil
+	}
+
+	tmp, err := c.writeTemp(data)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp) // Only left over if it wasn't moved in place
+	sum := sha256.Sum256(data)
+
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.files[key]; ok {
+		existing := elem.Value.(*CacheEntry)
+		pinned = pinned || existing.Pinned
+		if dirty && existing.Dirty {
+			base = existing.Base
+		}
+	}
+	// The entry may have been pinned or made dirty meanwhile
+	if !pinned && !dirty && c.maxSize > 0 && int64(len(data)) > c.maxSize {
+		if elem, ok := c.files[key]; ok {
+			c.forget(c.remove(elem))
+		}
+		return nil
+	}
+
+	target := c.diskPath(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return err
 	}
+	entry := &CacheEntry{
+		Path:     key,
+		Size:     int64(len(data)),
+		Pinned:   pinned,
+		Checksum: hex.EncodeToString(sum[:]),
+		LastUsed: time.Now(),
+		Version:  version,
+		Dirty:    dirty,
+		Base:     base,
+	}
+	c.insert(entry)
+	c.persist(entry)
+	c.evict()
+	return nil
 }
 
-// AddFile adds a file to the cache (dummy implementation)
-func (c *CacheManager) AddFile(path string, size int64, pinned bool) {
+// tooLarge reports whether contents are larger than the whole cache and
+// wouldn't be kept regardless of the cache size.
+func (c *CacheManager) tooLarge(key string, data []byte, dirty bool, pinned bool) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.files[path] = &CacheEntry{Path: path, Size: size, Pinned: pinned, LastUsed: time.Now()}
+	if elem, ok := c.files[key]; ok {
+		pinned = pinned || elem.Value.(*CacheEntry).Pinned
+	}
+	return !pinned && !dirty && c.maxSize > 0 && int64(len(data)) > c.maxSize
+}
+
+// Get returns the cached contents of a file. Contents that no longer match
+// their checksum are removed and reported as a miss.
+func (c *CacheManager) Get(mountID uint32, filePath string) ([]byte, bool) {
+	key := CachePath(mountID, filePath)
+	for {
+		entry, ok := c.GetFile(key)
+		if !ok {
+			return nil, false
+		}
+
+		data, err := os.ReadFile(c.diskPath(key))
+		if err == nil {
+			sum := sha256.Sum256(data)
+			if hex.EncodeToString(sum[:]) == entry.Checksum {
+				return data, true
+			}
+			err = errors.New("checksum mismatch")
+		}
+		// A file replaced since the entry was looked up isn't corrupt, it's
+		// read again along with its new entry
+		if c.dropCorrupt(key, entry.Checksum, err) {
+			return nil, false
+		}
+	}
+}
+
+// dropCorrupt removes an entry whose file couldn't be read or didn't match
+// checksum, unless the entry changed meanwhile. It reports false if the
+// entry changed.
+func (c *CacheManager) dropCorrupt(key string, checksum string, err error) bool {
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.files[key]
+	if !ok {
+		return true
+	}
+	if elem.Value.(*CacheEntry).Checksum != checksum {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "[CacheManager] Dropping corrupt entry %s: %v\n", key, err)
+	c.forget(c.remove(elem))
+	return true
 }
 
 // EvictLRU evicts least recently used files until under maxSize
 func (c *CacheManager) EvictLRU() {
-	// TODO: Implement LRU eviction logic
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
 }
 
 // GetFile returns cache entry if present
 func (c *CacheManager) GetFile(path string) (*CacheEntry, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	entry, ok := c.files[path]
-	if ok {
-		entry.LastUsed = time.Now()
+	elem, ok := c.files[path]
+	if !ok {
+		return nil, false
 	}
-	return entry, ok
+	entry := elem.Value.(*CacheEntry)
+	entry.LastUsed = time.Now()
+	c.touched[path] = true
+	c.lru.MoveToFront(elem)
+	e := *entry
+	return &e, true
+}
+
+// Remove drops the cached contents of a single file
+func (c *CacheManager) Remove(mountID uint32, filePath string) {
+	key := CachePath(mountID, filePath)
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.files[key]; ok {
+		c.forget(c.remove(elem))
+	}
+}
+
+// SetPinned marks a cached file as pinned, so it's never evicted, or unpins
+// it. It reports whether the file is cached.
+func (c *CacheManager) SetPinned(mountID uint32, filePath string, pinned bool) bool {
+	key := CachePath(mountID, filePath)
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.files[key]
+	if !ok {
+		return false
+	}
+	elem.Value.(*CacheEntry).Pinned = pinned
+	c.persist(elem.Value.(*CacheEntry))
+	if !pinned {
+		c.evict()
+	}
+	return true
+}
+
+// MarkClean records that the contents with the given checksum were uploaded
+// as the given remote version, so the entry can be evicted again. It reports
+// false if the entry is gone or was written again since.
+func (c *CacheManager) MarkClean(mountID uint32, filePath string, checksum string, version string) bool {
+	key := CachePath(mountID, filePath)
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.files[key]
+	if !ok {
+		return false
+	}
+	entry := elem.Value.(*CacheEntry)
+	if entry.Checksum != checksum {
+		return false
+	}
+	entry.Dirty = false
+	entry.Version = version
+	entry.Base = ""
+	c.persist(entry)
+	c.evict()
+	return true
+}
+
+// SetBase changes the remote version the dirty contents of a file are based
+// on, e.g. once a conflict was resolved in favour of the local contents. It
+// reports false if the file has no dirty contents.
+func (c *CacheManager) SetBase(mountID uint32, filePath string, base string) bool {
+	key := CachePath(mountID, filePath)
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.files[key]
+	if !ok || !elem.Value.(*CacheEntry).Dirty {
+		return false
+	}
+	elem.Value.(*CacheEntry).Base = base
+	c.persist(elem.Value.(*CacheEntry))
+	return true
+}
+
+// Files returns the entries for a path in a mount and everything below it,
+// or for the path in every mount if mountID is 0
+func (c *CacheManager) Files(mountID uint32, filePath string) []CacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var entries []CacheEntry
+	for p, elem := range c.files {
+		if covers(p, mountID, filePath) {
+			entries = append(entries, *elem.Value.(*CacheEntry))
+		}
+	}
+	return entries
+}
+
+// Move moves the entries for a path in a mount and everything below it to
+// another path, keeping their state, e.g. after the file was renamed. Entries
+// already at the target are replaced.
+func (c *CacheManager) Move(mountID uint32, from string, to string) error {
+	fromKey, toKey := CachePath(mountID, from), CachePath(mountID, to)
+	prefix := strings.TrimSuffix(fromKey, "/") + "/"
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var moved []*CacheEntry
+	for p, elem := range c.files {
+		if p == fromKey || strings.HasPrefix(p, prefix) {
+			moved = append(moved, elem.Value.(*CacheEntry))
+		}
+	}
+	for _, entry := range moved {
+		target := toKey + strings.TrimPrefix(entry.Path, fromKey)
+		if elem, ok := c.files[target]; ok {
+			c.forget(c.remove(elem))
+		}
+		if err := os.MkdirAll(filepath.Dir(c.diskPath(target)), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(c.diskPath(entry.Path), c.diskPath(target)); err != nil {
+			return err
+		}
+		old := entry.Path
+		c.lru.Remove(c.files[old])
+		delete(c.files, old)
+		delete(c.touched, old)
+		c.size -= entry.Size
+		c.forget(old)
+
+		next := *entry
+		next.Path = target
+		c.insert(&next)
+		c.persist(&next)
+	}
+	return nil
+}
+
+// DirtyFiles returns the entries that haven't been uploaded yet
+func (c *CacheManager) DirtyFiles() []CacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var entries []CacheEntry
+	for _, elem := range c.files {
+		if entry := elem.Value.(*CacheEntry); entry.Dirty {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// Size returns the total size of the cached files
+func (c *CacheManager) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// MaxSize returns the size the cache is kept under, 0 if unlimited
+func (c *CacheManager) MaxSize() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.maxSize
+}
+
+// SetMaxSize changes the size the cache is kept under, evicting entries right
+// away if it's over the new limit. 0 means unlimited.
+func (c *CacheManager) SetMaxSize(maxSize int64) {
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxSize = maxSize
+	c.evict()
+}
+
+// CountRead records whether a read of a file in a mount was served from the
+// cache.
+func (c *CacheManager) CountRead(mountID uint32, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts, ok := c.reads[mountID]
+	if !ok {
+		counts = &readCounts{}
+		c.reads[mountID] = counts
+	}
+	if hit {
+		counts.hits++
+	} else {
+		counts.misses++
+	}
+}
+
+// Stats returns the statistics of every mount with cached contents or
+// counted reads, ordered by mount.
+func (c *CacheManager) Stats() []MountStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make(map[uint32]*MountStats)
+	get := func(mountID uint32) *MountStats {
+		if _, ok := stats[mountID]; !ok {
+			stats[mountID] = &MountStats{MountID: mountID}
+		}
+		return stats[mountID]
+	}
+	for key, elem := range c.files {
+		mountID, _, ok := SplitCachePath(key)
+		if !ok {
+			continue
+		}
+		entry := elem.Value.(*CacheEntry)
+		mount := get(mountID)
+		mount.Size += entry.Size
+		mount.Entries++
+		if entry.Pinned {
+			mount.PinnedBytes += entry.Size
+		}
+		if entry.Dirty {
+			mount.DirtyBytes += entry.Size
+		}
+	}
+	for mountID, counts := range c.reads {
+		mount := get(mountID)
+		mount.Hits, mount.Misses = counts.hits, counts.misses
+	}
+
+	result := make([]MountStats, 0, len(stats))
+	for _, mount := range stats {
+		result = append(result, *mount)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].MountID < result[j].MountID })
+	return result
+}
+
+// Purge removes the cached contents of a path in a mount and everything
+// below it, or of the path in every mount if mountID is 0. Contents that
+// haven't been uploaded yet are kept, and so are pinned ones unless pinned is
+// set. It returns how many entries and bytes were removed.
+func (c *CacheManager) Purge(mountID uint32, filePath string, pinned bool) (int, int64) {
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var removed []string
+	var bytes int64
+	for p, elem := range c.files {
+		entry := elem.Value.(*CacheEntry)
+		if entry.Dirty || (entry.Pinned && !pinned) {
+			continue
+		}
+		if covers(p, mountID, filePath) {
+			bytes += entry.Size
+			removed = append(removed, c.remove(elem))
+		}
+	}
+	c.forget(removed...)
+	return len(removed), bytes
+}
+
+// CachePath returns the cache path of a file in a mount
+func CachePath(mountID uint32, filePath string) string {
+	return fmt.Sprintf("%d%s", mountID, path.Clean("/"+filePath))
+}
+
+// SplitCachePath returns the mount and file path of a cache path
+func SplitCachePath(key string) (uint32, string, bool) {
+	id, filePath, ok := strings.Cut(key, "/")
+	if !ok {
+		return 0, "", false
+	}
+	mountID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, "", false
+	}
+	return uint32(mountID), "/" + filePath, true
+}
+
+// covers reports whether a cache path is a path in a mount or below it. A
+// mountID of 0 matches the path in every mount.
+func covers(key string, mountID uint32, filePath string) bool {
+	id, p, ok := SplitCachePath(key)
+	if !ok || (mountID != 0 && id != mountID) {
+		return false
+	}
+	dir := path.Clean("/" + filePath)
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// Invalidate drops cached entries for a path in a mount and everything below
+// it, except those that haven't been uploaded yet and pinned ones, which are
+// kept until they are refreshed
+func (c *CacheManager) Invalidate(mountID uint32, filePath string) {
+	c.drop(mountID, filePath, true)
+}
+
+// Discard drops cached entries for a path in a mount and everything below
+// it, including those that haven't been uploaded yet or are pinned
+func (c *CacheManager) Discard(mountID uint32, filePath string) {
+	c.drop(mountID, filePath, false)
+}
+
+func (c *CacheManager) drop(mountID uint32, filePath string, keep bool) {
+	key := CachePath(mountID, filePath)
+	prefix := strings.TrimSuffix(key, "/") + "/"
+	defer c.sync()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var removed []string
+	for p, elem := range c.files {
+		if entry := elem.Value.(*CacheEntry); keep && (entry.Dirty || entry.Pinned) {
+			continue
+		}
+		if p == key || strings.HasPrefix(p, prefix) {
+			removed = append(removed, c.remove(elem))
+		}
+	}
+	c.forget(removed...)
+}
+
+// insert adds or replaces an entry as the most recently used one.
+// c.mu must be held.
+func (c *CacheManager) insert(entry *CacheEntry) {
+	if elem, ok := c.files[entry.Path]; ok {
+		c.size -= elem.Value.(*CacheEntry).Size
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+	} else {
+		c.files[entry.Path] = c.lru.PushFront(entry)
+	}
+	c.size += entry.Size
+}
+
+// remove deletes an entry and its file, returning its key for forget.
+// c.mu must be held.
+func (c *CacheManager) remove(elem *list.Element) string {
+	entry := c.lru.Remove(elem).(*CacheEntry)
+	delete(c.files, entry.Path)
+	delete(c.touched, entry.Path)
+	c.size -= entry.Size
+	if err := os.Remove(c.diskPath(entry.Path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "[CacheManager] Failed to remove %s: %v\n", entry.Path, err)
+	}
+	return entry.Path
+}
+
+// persist queues writing the index record of an entry. c.mu must be held,
+// and sync called once it's released.
+func (c *CacheManager) persist(entry *CacheEntry) {
+	delete(c.touched, entry.Path)
+	record := cacheRecord(entry)
+	c.pending[entry.Path] = &record
+}
+
+// forget queues deleting the index records of removed entries. c.mu must be
+// held, and sync called once it's released.
+func (c *CacheManager) forget(keys ...string) {
+	for _, key := range keys {
+		c.pending[key] = nil
+	}
+}
+
+// sync writes the queued index changes to the metadata store in one
+// transaction. c.mu must not be held. Changes queued by concurrent calls are
+// written together, and never before changes queued earlier.
+func (c *CacheManager) sync() {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[string]*metadata.CacheRecord)
+	c.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	records := make(map[string]metadata.CacheRecord, len(pending))
+	var deleted []string
+	for key, record := range pending {
+		if record == nil {
+			deleted = append(deleted, key)
+		} else {
+			records[key] = *record
+		}
+	}
+	if err := c.store.UpdateCacheRecords(records, deleted); err != nil {
+		fmt.Fprintf(os.Stderr, "[CacheManager] Failed to persist cache index: %v\n", err)
+	}
+}
+
+func cacheRecord(entry *CacheEntry) metadata.CacheRecord {
+	return metadata.CacheRecord{
+		Size:     entry.Size,
+		Checksum: entry.Checksum,
+		Pinned:   entry.Pinned,
+		LastUsed: entry.LastUsed,
+		Version:  entry.Version,
+		Dirty:    entry.Dirty,
+		Base:     entry.Base,
+	}
+}
+
+// evict removes the least recently used entries that are neither pinned nor
+// dirty until the cache fits in maxSize. c.mu must be held.
+func (c *CacheManager) evict() {
+	if c.maxSize <= 0 {
+		return
+	}
+	var removed []string
+	for elem := c.lru.Back(); elem != nil && c.size > c.maxSize; {
+		prev := elem.Prev()
+		if entry := elem.Value.(*CacheEntry); !entry.Pinned && !entry.Dirty {
+			removed = append(removed, c.remove(elem))
+		}
+		elem = prev
+	}
+	c.forget(removed...)
+}
+
+// writeTemp writes contents to a new file in the temp directory and returns
+// its path.
+func (c *CacheManager) writeTemp(data []byte) (string, error) {
+	if err := os.MkdirAll(c.tempDir(), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(c.tempDir(), "put-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// diskPath returns where the contents of a cache entry are stored
+func (c *CacheManager) diskPath(key string) string {
+	return filepath.Join(c.RootDir, filepath.FromSlash(key))
+}
+
+// tempDir returns where files are written before they are moved in place
+func (c *CacheManager) tempDir() string {
+	return filepath.Join(c.RootDir, tempDir)
 }
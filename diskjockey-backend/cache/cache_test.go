@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+)
+
+func newTestStore(t *testing.T) *metadata.MetadataStore {
+	t.Helper()
+	store, err := metadata.OpenMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	if err != nil {
+		t.Fatalf("failed to open metadata store: %v", err)
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+func newTestCache(t *testing.T, root string, maxSize int64, store *metadata.MetadataStore) *CacheManager {
+	t.Helper()
+	c := NewCacheManager(root, maxSize, store)
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func cachedPaths(c *CacheManager) []string {
+	var paths []string
+	for _, entry := range c.Files(1, "/") {
+		paths = append(paths, entry.Path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestConcurrentPutDirty(t *testing.T) {
+	c := newTestCache(t, t.TempDir(), 0, newTestStore(t))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := c.PutDirty(1, "/a.txt", []byte(fmt.Sprintf("writer %d change %d", i, j)), ""); err != nil {
+					t.Errorf("PutDirty failed: %v", err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				c.Get(1, "/a.txt")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The file must still match its entry, so the dirty contents survive
+	if _, ok := c.Get(1, "/a.txt"); !ok {
+		t.Fatalf("dirty contents were dropped")
+	}
+	entry, _ := c.GetFile(CachePath(1, "/a.txt"))
+	if !entry.Dirty {
+		t.Fatalf("entry is no longer dirty")
+	}
+	if entries, _ := os.ReadDir(c.tempDir()); len(entries) != 0 {
+		t.Errorf("%d temp files left behind", len(entries))
+	}
+}
+
+func TestLoadReconcilesIndex(t *testing.T) {
+	root, store := t.TempDir(), newTestStore(t)
+	c := NewCacheManager(root, 0, store)
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	files := map[string]string{
+		"/a.txt":            "a",
+		"/dir/b.txt":        "b",
+		"/.dj-tmp-notes":    "named like a temp file",
+		"/dir/.dj-tmp-todo": "too",
+	}
+	for p, data := range files {
+		if err := c.Put(1, p, []byte(data), "v1"); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+	if err := c.PutDirty(1, "/dirty.txt", []byte("local"), "v1"); err != nil {
+		t.Fatalf("PutDirty failed: %v", err)
+	}
+	c.Remove(1, "/a.txt")
+	c.Close()
+
+	// An interrupted write, and a file without an index record
+	if err := os.WriteFile(filepath.Join(c.tempDir(), "put-1"), []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.diskPath(CachePath(1, "/orphan.txt")), []byte("orphan"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c = newTestCache(t, root, 0, store)
+	want := []string{"1/.dj-tmp-notes", "1/dir/.dj-tmp-todo", "1/dir/b.txt", "1/dirty.txt"}
+	if got := cachedPaths(c); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("reloaded cache holds %v, want %v", got, want)
+	}
+	if data, ok := c.Get(1, "/.dj-tmp-notes"); !ok || string(data) != "named like a temp file" {
+		t.Errorf("Get of a file named like a temp file = %q, %v", data, ok)
+	}
+	if entry, _ := c.GetFile(CachePath(1, "/dirty.txt")); !entry.Dirty || entry.Base != "v1" {
+		t.Errorf("dirty entry reloaded as %+v", entry)
+	}
+	if entries, _ := os.ReadDir(c.tempDir()); len(entries) != 0 {
+		t.Errorf("temp files survived Load")
+	}
+	if _, err := os.Stat(c.diskPath(CachePath(1, "/orphan.txt"))); !os.IsNotExist(err) {
+		t.Errorf("orphaned file survived Load")
+	}
+}
+
+func TestMove(t *testing.T) {
+	root, store := t.TempDir(), newTestStore(t)
+	c := NewCacheManager(root, 0, store)
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := c.PutDirty(1, "/dir/a.txt", []byte("a"), "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutPinned(1, "/dir/sub/b.txt", []byte("b"), "v2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(1, "/other/a.txt", []byte("replaced"), "v3"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Move(1, "/dir", "/other"); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	c.Close()
+
+	// The moved state must be persisted, not only held in memory
+	c = newTestCache(t, root, 0, store)
+	want := []string{"1/other/a.txt", "1/other/sub/b.txt"}
+	if got := cachedPaths(c); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("cache holds %v after Move, want %v", got, want)
+	}
+	if data, _ := c.Get(1, "/other/a.txt"); string(data) != "a" {
+		t.Errorf("moved file reads %q, want the moved contents", data)
+	}
+	if entry, _ := c.GetFile(CachePath(1, "/other/a.txt")); !entry.Dirty || entry.Base != "v1" {
+		t.Errorf("moved dirty entry is %+v", entry)
+	}
+	if entry, _ := c.GetFile(CachePath(1, "/other/sub/b.txt")); !entry.Pinned || entry.Version != "v2" {
+		t.Errorf("moved pinned entry is %+v", entry)
+	}
+}
+
+func TestEvictKeepsPinnedAndDirty(t *testing.T) {
+	c := newTestCache(t, t.TempDir(), 10, newTestStore(t))
+	if err := c.PutPinned(1, "/pinned", []byte("12345"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutDirty(1, "/dirty", []byte("12345"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(1, "/clean", []byte("12345"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(1, "/large", []byte("12345678901"), ""); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1/dirty", "1/pinned"}
+	if got := cachedPaths(c); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("cache holds %v, want %v", got, want)
+	}
+	if entries, _ := os.ReadDir(c.tempDir()); len(entries) != 0 {
+		t.Errorf("contents too large for the cache left a temp file")
+	}
+}
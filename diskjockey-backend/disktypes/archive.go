@@ -0,0 +1,694 @@
+package disktypes
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveDiskType implements DiskType for browsing a zip or tar archive as a
+// read-only filesystem. The archive is a local file, or a file on another
+// mount looked up in Mounts.
+//
+// The entries of the archive are indexed the first time the mount is used,
+// and again only when the archive changes. Zip archives are indexed from
+// their central directory and tar archives by seeking over the contents.
+// Compressed tar archives are decompressed to a temporary file once, so
+// their entries can be read directly too. An archive on another mount is
+// copied to a temporary file in chunks.
+
+type ArchiveDiskType struct {
+	Mounts types.MountRegistry
+}
+
+type ArchiveBackend struct {
+	mount  *models.Mount
+	mounts types.MountRegistry
+	source string // Name of the mount the archive is on, "" for a local file
+	path   string // Path of the archive on the source mount or local disk
+	format string // Configured format, "" to detect it
+
+	mu      sync.Mutex
+	index   *archiveIndex
+	checked time.Time // When the archive was last checked for changes
+}
+
+// How long an index is used before checking whether the archive changed
+const archiveCheckInterval = 10 * time.Second
+
+// How much of an archive on another mount is copied at a time
+const archiveCopyChunk = 4 << 20
+
+// Archive formats
+const (
+	archiveZip    = "zip"
+	archiveTar    = "tar"
+	archiveTarGz  = "tar.gz"
+	archiveTarZst = "tar.zst"
+)
+
+// archiveIndex holds the entries of an archive, and the open archive file
+// their contents are read from. The file stays open while the index is in
+// use, even after the backend replaced it with the index of a newer version.
+type archiveIndex struct {
+	format   string
+	version  string   // Of the archive the index was built from
+	file     *os.File // The archive, decompressed if it's a compressed tar
+	temp     bool     // file is a temporary copy to remove when closing
+	entries  map[string]*archiveEntry
+	children map[string][]string // Names of the entries in each directory
+
+	mu      sync.Mutex
+	users   int  // Callers that acquired the index and haven't released it
+	retired bool // The backend no longer uses the index
+}
+
+type archiveEntry struct {
+	info    types.FileInfo
+	zip     *zip.File
+	offset  int64 // Of the contents in a tar, -1 if they have to be read through the tar reader
+	ordinal int   // Position of the entry in a tar
+}
+
+func (a ArchiveDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &ArchiveBackend{mount: mount, mounts: a.Mounts}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (ArchiveDiskType) Name() string {
+	return "archive"
+}
+
+func (ArchiveDiskType) Description() string {
+	return "Read-only view of a zip, tar, tar.gz or tar.zst archive"
+}
+
+// ReadOnly implements types.ReadOnlyDiskType. Archives are only read.
+func (ArchiveDiskType) ReadOnly(mount *models.Mount) bool {
+	return true
+}
+
+func (ArchiveDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return types.DiskTypeConfigTemplate{
+		"path": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Path of the archive, on local disk or on source_mount",
+			Required:    true,
+		},
+		"source_mount": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Name of the mount the archive is stored on (default: the archive is a local file)",
+			Required:    false,
+		},
+		"format": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Archive format: auto, zip, tar, tar.gz or tar.zst (default auto)",
+			Required:    false,
+		},
+	}
+}
+
+func (b *ArchiveBackend) connect() error {
+	b.path = b.mount.Path
+	if b.path == "" {
+		return fmt.Errorf("archive: missing required config 'path'")
+	}
+
+	switch format := strings.ToLower(b.mount.Option("format")); format {
+	case "", "auto":
+		b.format = ""
+	case archiveZip, archiveTar, archiveTarGz, archiveTarZst:
+		b.format = format
+	case "tgz":
+		b.format = archiveTarGz
+	default:
+		return fmt.Errorf("archive: unsupported format %q", format)
+	}
+
+	b.source = b.mount.Option("source_mount")
+	if b.source == "" {
+		info, err := os.Stat(b.path)
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("archive: %s is a directory", b.path)
+		}
+		return nil
+	}
+	if b.source == b.mount.Name {
+		return fmt.Errorf("archive: source_mount can't be the archive mount itself")
+	}
+	if b.mounts == nil {
+		return fmt.Errorf("archive: archives on other mounts are not supported")
+	}
+	// The source mount may not be mounted yet while mounts are restored, so
+	// it's only looked up once the archive is used
+	return nil
+}
+
+// current returns the index of the archive, building it on first use and
+// again when the archive changed. The caller must release the index when
+// done with it.
+func (b *ArchiveBackend) current() (*archiveIndex, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.index != nil && time.Since(b.checked) < archiveCheckInterval {
+		return b.index.acquire(), nil
+	}
+	version, err := b.version()
+	if err != nil {
+		return nil, err
+	}
+	b.checked = time.Now()
+	if b.index != nil && b.index.version == version {
+		return b.index.acquire(), nil
+	}
+
+	start := time.Now()
+	index, err := b.load(version)
+	if err != nil {
+		return nil, err
+	}
+	b.retire()
+	b.index = index
+	fmt.Printf("[Archive] Indexed %d entries of %s archive %s in %v\n", len(index.entries)-1, index.format, b.path, time.Since(start).Round(time.Millisecond))
+	return index.acquire(), nil
+}
+
+// retire stops using the current index, which is closed once the reads in
+// progress finish. b.mu must be held.
+func (b *ArchiveBackend) retire() {
+	if b.index == nil {
+		return
+	}
+	b.index.retire()
+	b.index = nil
+}
+
+// sourceBackend returns the backend of the mount the archive is on.
+func (b *ArchiveBackend) sourceBackend() (types.Backend, error) {
+	mount, err := b.mounts.GetMountByName(b.source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", types.ErrOffline, err)
+	}
+	return mount.Backend, nil
+}
+
+// version returns a string that changes whenever the archive does.
+func (b *ArchiveBackend) version() (string, error) {
+	if b.source == "" {
+		info, err := os.Stat(b.path)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano()), nil
+	}
+
+	backend, err := b.sourceBackend()
+	if err != nil {
+		return "", err
+	}
+	info, err := types.Stat(backend, b.path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir {
+		return "", fmt.Errorf("archive: %s is a directory", b.path)
+	}
+	if info.ETag != "" {
+		return info.ETag, nil
+	}
+	return fmt.Sprintf("%d-%d", info.Size, info.ModTime.UnixNano()), nil
+}
+
+// load opens the archive and indexes its entries.
+func (b *ArchiveBackend) load(version string) (_ *archiveIndex, err error) {
+	index := &archiveIndex{
+		version:  version,
+		entries:  map[string]*archiveEntry{"/": {info: types.FileInfo{Name: "/", IsDir: true}, offset: -1}},
+		children: map[string][]string{},
+	}
+	if index.file, index.temp, err = b.open(version); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			index.close()
+		}
+	}()
+
+	index.format = b.format
+	if index.format == "" {
+		if index.format, err = detectArchiveFormat(index.file); err != nil {
+			return nil, err
+		}
+	}
+	if index.format == archiveTarGz || index.format == archiveTarZst {
+		if err := index.decompress(); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := index.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if index.format == archiveZip {
+		reader, err := zip.NewReader(index.file, info.Size())
+		if err != nil {
+			return nil, fmt.Errorf("archive: %w", err)
+		}
+		index.addZip(reader)
+	} else if err := index.addTar(io.NewSectionReader(index.file, 0, info.Size())); err != nil {
+		return nil, err
+	}
+
+	for _, names := range index.children {
+		sort.Strings(names)
+	}
+	return index, nil
+}
+
+// open opens the archive, copying it to a temporary file first when it's on
+// another mount.
+func (b *ArchiveBackend) open(version string) (*os.File, bool, error) {
+	if b.source == "" {
+		f, err := os.Open(b.path)
+		return f, false, err
+	}
+
+	backend, err := b.sourceBackend()
+	if err != nil {
+		return nil, false, err
+	}
+	f, err := os.CreateTemp("", "diskjockey-archive-*")
+	if err != nil {
+		return nil, false, err
+	}
+	err = copyArchive(f, backend, b.path)
+	if err == nil {
+		// A copy made while the archive changed mixes both versions
+		var current string
+		if current, err = b.version(); err == nil && current != version {
+			err = fmt.Errorf("archive: %s changed while it was copied", b.path)
+		}
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, false, err
+	}
+	return f, true, nil
+}
+
+// copyArchive copies an archive on another mount to f, a chunk at a time
+// when the source reads ranges so it's never held in memory in full.
+func copyArchive(f *os.File, backend types.Backend, p string) error {
+	if !readsRanges(backend) {
+		data, err := backend.Read(p)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	for offset := int64(0); ; {
+		chunk, err := types.ReadRange(backend, p, offset, archiveCopyChunk)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(chunk); err != nil {
+			return err
+		}
+		offset += int64(len(chunk))
+		if len(chunk) < archiveCopyChunk {
+			return nil
+		}
+	}
+}
+
+// readsRanges reports whether the disk type backend below the layers of a
+// mount reads ranges itself, instead of reading the whole file for each.
+func readsRanges(b types.Backend) bool {
+	for {
+		if wrapper, ok := b.(types.Wrapper); ok && wrapper.Unwrap() != nil {
+			b = wrapper.Unwrap()
+			continue
+		}
+		_, ok := b.(types.RangeReader)
+		return ok
+	}
+}
+
+// detectArchiveFormat recognizes an archive from its first bytes.
+func detectArchiveFormat(f *os.File) (string, error) {
+	magic := make([]byte, 4)
+	if _, err := f.ReadAt(magic, 0); err != nil && err != io.EOF {
+		return "", err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte("PK\x03\x04")), bytes.HasPrefix(magic, []byte("PK\x05\x06")):
+		return archiveZip, nil
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return archiveTarGz, nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return archiveTarZst, nil
+	default:
+		return archiveTar, nil
+	}
+}
+
+// acquire marks the index as in use until release is called.
+func (index *archiveIndex) acquire() *archiveIndex {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.users++
+	return index
+}
+
+func (index *archiveIndex) release() {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.users--
+	if index.retired && index.users == 0 {
+		index.close()
+	}
+}
+
+// retire closes the index as soon as it's no longer in use.
+func (index *archiveIndex) retire() {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.retired = true
+	if index.users == 0 {
+		index.close()
+	}
+}
+
+func (index *archiveIndex) close() {
+	index.file.Close()
+	if index.temp {
+		os.Remove(index.file.Name())
+	}
+}
+
+// decompress replaces the file of a compressed tar archive with a
+// temporary file holding the tar itself, so its entries can be read from
+// their offsets instead of decompressing the archive up to each of them.
+func (index *archiveIndex) decompress() error {
+	raw := io.NewSectionReader(index.file, 0, 1<<63-1)
+	var stream io.ReadCloser
+	switch index.format {
+	case archiveTarGz:
+		r, err := gzip.NewReader(raw)
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+		stream = r
+	case archiveTarZst:
+		r, err := zstd.NewReader(raw)
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+		stream = r.IOReadCloser()
+	}
+	defer stream.Close()
+
+	f, err := os.CreateTemp("", "diskjockey-archive-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, stream); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("archive: %w", err)
+	}
+	index.close()
+	index.file, index.temp = f, true
+	return nil
+}
+
+// add adds an entry by its name in the archive, along with the directories
+// it's in. Entries whose name escapes the root, or is below a file, are
+// skipped. A later entry for the same file replaces the earlier one.
+func (index *archiveIndex) add(name string, entry *archiveEntry) {
+	p, err := types.CleanPath(strings.ReplaceAll(name, "\\", "/"))
+	if err != nil || p == "/" {
+		return
+	}
+	entry.info.Name = path.Base(p)
+
+	if existing, ok := index.entries[p]; ok {
+		if existing.info.IsDir || entry.info.IsDir {
+			// Directories stay directories, an explicit entry only adds
+			// its modification time
+			if existing.info.IsDir && entry.info.IsDir {
+				existing.info.ModTime = entry.info.ModTime
+			}
+			return
+		}
+		index.entries[p] = entry
+		return
+	}
+
+	var missing []string
+	for dir := path.Dir(p); ; dir = path.Dir(dir) {
+		parent, ok := index.entries[dir]
+		if ok {
+			if !parent.info.IsDir {
+				return
+			}
+			break
+		}
+		missing = append(missing, dir)
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		dir := missing[i]
+		index.entries[dir] = &archiveEntry{info: types.FileInfo{Name: path.Base(dir), IsDir: true}, offset: -1}
+		index.children[path.Dir(dir)] = append(index.children[path.Dir(dir)], path.Base(dir))
+	}
+	index.entries[p] = entry
+	index.children[path.Dir(p)] = append(index.children[path.Dir(p)], entry.info.Name)
+}
+
+func (index *archiveIndex) addZip(reader *zip.Reader) {
+	for _, f := range reader.File {
+		if f.Mode()&fs.ModeSymlink != 0 {
+			continue
+		}
+		entry := &archiveEntry{
+			info: types.FileInfo{
+				IsDir:   strings.HasSuffix(f.Name, "/") || f.Mode().IsDir(),
+				ModTime: f.Modified,
+			},
+			zip:    f,
+			offset: -1,
+		}
+		if !entry.info.IsDir {
+			entry.info.Size = int64(f.UncompressedSize64)
+			entry.info.ETag = fmt.Sprintf("%08x", f.CRC32)
+		}
+		index.add(f.Name, entry)
+	}
+}
+
+// addTar indexes the entries of a tar archive, recording the offsets of
+// their contents as it seeks over them.
+func (index *archiveIndex) addTar(r io.ReadSeeker) error {
+	tr := tar.NewReader(r)
+	for ordinal := 0; ; ordinal++ {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+
+		entry := &archiveEntry{
+			info:    types.FileInfo{Size: hdr.Size, ModTime: hdr.ModTime},
+			offset:  -1,
+			ordinal: ordinal,
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			entry.info.IsDir = true
+			entry.info.Size = 0
+		case tar.TypeReg, tar.TypeGNUSparse:
+			if !isSparseTarEntry(hdr) {
+				if entry.offset, err = r.Seek(0, io.SeekCurrent); err != nil {
+					return err
+				}
+			}
+		case tar.TypeLink:
+			// A hard link shares the contents of an earlier entry
+			target, ok := index.entries[path.Clean("/"+hdr.Linkname)]
+			if !ok || target.info.IsDir {
+				continue
+			}
+			link := *target
+			entry = &link
+		default:
+			// Symlinks and special files
+			continue
+		}
+		index.add(hdr.Name, entry)
+	}
+}
+
+// isSparseTarEntry reports whether the contents of a tar entry are stored
+// sparsely, so they can't be read from its offset as they are.
+func isSparseTarEntry(hdr *tar.Header) bool {
+	if hdr.Typeflag == tar.TypeGNUSparse {
+		return true
+	}
+	for key := range hdr.PAXRecords {
+		if strings.HasPrefix(key, "GNU.sparse.") {
+			return true
+		}
+	}
+	return false
+}
+
+// read returns the contents of a file entry.
+func (index *archiveIndex) read(entry *archiveEntry) ([]byte, error) {
+	if entry.zip != nil {
+		r, err := entry.zip.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+
+	if entry.offset >= 0 {
+		// The size comes from the entry's header, which may claim more than
+		// the archive holds, so only what is there is read
+		data, err := io.ReadAll(io.NewSectionReader(index.file, entry.offset, entry.info.Size))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) != entry.info.Size {
+			return nil, fmt.Errorf("archive: %w", io.ErrUnexpectedEOF)
+		}
+		return data, nil
+	}
+
+	// Sparse entries are expanded by the tar reader
+	tr := tar.NewReader(io.NewSectionReader(index.file, 0, 1<<63-1))
+	for ordinal := 0; ; ordinal++ {
+		if _, err := tr.Next(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("archive: %w", err)
+		}
+		if ordinal == entry.ordinal {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// lookup returns the entry of a path, and the index it's in. The caller
+// must release the index when done with it.
+func (b *ArchiveBackend) lookup(op, p string) (*archiveIndex, *archiveEntry, error) {
+	index, err := b.current()
+	if err != nil {
+		return nil, nil, err
+	}
+	entry, ok := index.entries[joinRemote("", p)]
+	if !ok {
+		index.release()
+		return nil, nil, &fs.PathError{Op: op, Path: p, Err: fs.ErrNotExist}
+	}
+	return index, entry, nil
+}
+
+func (b *ArchiveBackend) List(p string) ([]types.FileInfo, error) {
+	index, entry, err := b.lookup("list", p)
+	if err != nil {
+		return nil, err
+	}
+	defer index.release()
+	if !entry.info.IsDir {
+		return nil, &fs.PathError{Op: "list", Path: p, Err: syscall.ENOTDIR}
+	}
+	var infos []types.FileInfo
+	for _, name := range index.children[p] {
+		infos = append(infos, index.entries[path.Join(p, name)].info)
+	}
+	return infos, nil
+}
+
+func (b *ArchiveBackend) Stat(p string) (types.FileInfo, error) {
+	index, entry, err := b.lookup("stat", p)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	index.release()
+	return entry.info, nil
+}
+
+func (b *ArchiveBackend) Read(p string) ([]byte, error) {
+	index, entry, err := b.lookup("read", p)
+	if err != nil {
+		return nil, err
+	}
+	defer index.release()
+	if entry.info.IsDir {
+		return nil, &fs.PathError{Op: "read", Path: p, Err: syscall.EISDIR}
+	}
+	return index.read(entry)
+}
+
+func (b *ArchiveBackend) Write(p string, data []byte) error {
+	return &fs.PathError{Op: "write", Path: p, Err: types.ErrReadOnly}
+}
+
+func (b *ArchiveBackend) Delete(p string) error {
+	return &fs.PathError{Op: "delete", Path: p, Err: types.ErrReadOnly}
+}
+
+func (b *ArchiveBackend) Rename(from, to string) error {
+	return &fs.PathError{Op: "rename", Path: from, Err: types.ErrReadOnly}
+}
+
+// Reconnect indexes the archive again.
+func (b *ArchiveBackend) Reconnect() error {
+	b.mu.Lock()
+	b.retire()
+	b.mu.Unlock()
+	index, err := b.current()
+	if err != nil {
+		return err
+	}
+	index.release()
+	return nil
+}
+
+func (b *ArchiveBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.retire()
+	return nil
+}
@@ -0,0 +1,368 @@
+package disktypes
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/klauspost/compress/zstd"
+)
+
+// rangeMemoryBackend is a memory backend reading ranges, counting the reads
+// of whole files and of ranges
+type rangeMemoryBackend struct {
+	*MemoryBackend
+	reads, ranges atomic.Int32
+}
+
+func (b *rangeMemoryBackend) Read(p string) ([]byte, error) {
+	b.reads.Add(1)
+	return b.MemoryBackend.Read(p)
+}
+
+func (b *rangeMemoryBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	b.ranges.Add(1)
+	data, err := b.MemoryBackend.Read(p)
+	if err != nil {
+		return nil, err
+	}
+	return types.SliceRange(data, offset, length), nil
+}
+
+type testMountRegistry map[string]types.Backend
+
+func (r testMountRegistry) GetMountByName(name string) (*types.Mount, error) {
+	b, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("mount %q is not mounted", name)
+	}
+	return &types.Mount{Name: name, Backend: b}, nil
+}
+
+func tarArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for name, data := range files {
+		if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveOnSourceMountIsCopiedInChunks(t *testing.T) {
+	large := make([]byte, 2*archiveCopyChunk+1234)
+	rand.New(rand.NewSource(1)).Read(large)
+	source := &rangeMemoryBackend{MemoryBackend: mustNew(t, MemoryDiskType{}, &models.Mount{}).(*MemoryBackend)}
+	if err := source.MemoryBackend.Write("/test.tar", tarArchive(t, map[string][]byte{"large.bin": large, "small.txt": []byte("small")})); err != nil {
+		t.Fatal(err)
+	}
+
+	mount := &models.Mount{Name: "archive", Path: "/test.tar", Options: map[string]string{"source_mount": "source"}}
+	b := mustNew(t, ArchiveDiskType{Mounts: testMountRegistry{"source": source}}, mount)
+	data, err := b.Read("/large.bin")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !bytes.Equal(data, large) {
+		t.Errorf("read %d bytes that don't match the archived file", len(data))
+	}
+	if data, err := b.Read("/small.txt"); err != nil || string(data) != "small" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+	if reads, ranges := source.reads.Load(), source.ranges.Load(); reads != 0 || ranges < 3 {
+		t.Errorf("archive copied with %d whole reads and %d ranges, want it in chunks", reads, ranges)
+	}
+}
+
+func TestArchiveIndexStaysOpenWhileInUse(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "test.tar")
+	if err := os.WriteFile(archive, tarArchive(t, map[string][]byte{"a.txt": []byte("first")}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := mustNew(t, ArchiveDiskType{}, &models.Mount{Path: archive}).(*ArchiveBackend)
+
+	// A read holding the index while the archive is replaced
+	old, entry, err := b.lookup("read", "/a.txt")
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	replacement := archive + ".new"
+	if err := os.WriteFile(replacement, tarArchive(t, map[string][]byte{"a.txt": []byte("second!")}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(replacement, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replacement, archive); err != nil {
+		t.Fatal(err)
+	}
+	b.mu.Lock()
+	b.checked = time.Time{}
+	b.mu.Unlock()
+	if data, err := b.Read("/a.txt"); err != nil || string(data) != "second!" {
+		t.Fatalf("Read after the archive changed = %q, %v", data, err)
+	}
+
+	if data, err := old.read(entry); err != nil || string(data) != "first" {
+		t.Errorf("read from the replaced index = %q, %v, want the old contents", data, err)
+	}
+	old.release()
+	if _, err := old.file.Stat(); err == nil {
+		t.Errorf("replaced index still open after its last reader finished")
+	}
+}
+
+// testArchive holds the files of the archives built by writeArchive, with a
+// nested directory
+var testArchive = map[string][]byte{
+	"docs/a.txt":     []byte("aaa"),
+	"docs/sub/b.txt": []byte("bbbb"),
+	"top.txt":        []byte("top"),
+}
+
+// writeArchive writes files as an archive of the given format, and returns
+// its path
+func writeArchive(t *testing.T, format string, files map[string][]byte) string {
+	t.Helper()
+	var data []byte
+	switch format {
+	case archiveZip:
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for name, contents := range files {
+			fw, err := w.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write(contents)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = buf.Bytes()
+	case archiveTar:
+		data = tarArchive(t, files)
+	case archiveTarGz:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(tarArchive(t, files))
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = buf.Bytes()
+	case archiveTarZst:
+		w, err := zstd.NewWriter(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = w.EncodeAll(tarArchive(t, files), nil)
+	}
+	name := filepath.Join(t.TempDir(), "test."+format)
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func archiveNames(t *testing.T, b types.Backend, dir string) string {
+	t.Helper()
+	infos, err := b.List(dir)
+	if err != nil {
+		t.Fatalf("List(%s) failed: %v", dir, err)
+	}
+	var names []string
+	for _, info := range infos {
+		if info.IsDir {
+			info.Name += "/"
+		}
+		names = append(names, info.Name)
+	}
+	return fmt.Sprint(names)
+}
+
+func TestArchiveFormats(t *testing.T) {
+	for _, test := range []struct{ format, option string }{
+		{archiveZip, ""},
+		{archiveZip, "zip"},
+		{archiveTar, ""},
+		{archiveTar, "tar"},
+		{archiveTarGz, ""},
+		{archiveTarGz, "tgz"},
+		{archiveTarZst, ""},
+		{archiveTarZst, "tar.zst"},
+	} {
+		t.Run(test.format+"/"+test.option, func(t *testing.T) {
+			mount := &models.Mount{Path: writeArchive(t, test.format, testArchive), Options: map[string]string{"format": test.option}}
+			b := mustNew(t, ArchiveDiskType{}, mount).(*ArchiveBackend)
+
+			if got, want := archiveNames(t, b, "/"), "[docs/ top.txt]"; got != want {
+				t.Errorf("List(/) = %v, want %v", got, want)
+			}
+			if got, want := archiveNames(t, b, "/docs"), "[a.txt sub/]"; got != want {
+				t.Errorf("List(/docs) = %v, want %v", got, want)
+			}
+			if got, want := archiveNames(t, b, "/docs/sub"), "[b.txt]"; got != want {
+				t.Errorf("List(/docs/sub) = %v, want %v", got, want)
+			}
+			for name, want := range testArchive {
+				data, err := b.Read("/" + name)
+				if err != nil || !bytes.Equal(data, want) {
+					t.Errorf("Read(%s) = %q, %v, want %q", name, data, err, want)
+				}
+				if info, err := b.Stat("/" + name); err != nil || info.Size != int64(len(want)) || info.IsDir {
+					t.Errorf("Stat(%s) = %+v, %v", name, info, err)
+				}
+			}
+			if info, err := b.Stat("/docs/sub"); err != nil || !info.IsDir {
+				t.Errorf("Stat of a directory = %+v, %v", info, err)
+			}
+			if _, err := b.Read("/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Read of a missing file = %v, want ErrNotExist", err)
+			}
+
+			// The entries of tars are read from their offsets, compressed or
+			// not, rather than by reading the archive up to them
+			index, entry, err := b.lookup("read", "/docs/sub/b.txt")
+			if err != nil {
+				t.Fatalf("lookup failed: %v", err)
+			}
+			defer index.release()
+			if index.format != test.format {
+				t.Errorf("archive read as %s", index.format)
+			}
+			if test.format != archiveZip && entry.offset < 0 {
+				t.Error("entry of a tar has no offset")
+			}
+		})
+	}
+
+	mount := &models.Mount{Path: writeArchive(t, archiveTar, testArchive), Options: map[string]string{"format": "rar"}}
+	if _, err := (ArchiveDiskType{}).New(mount); err == nil {
+		t.Error("mounted an archive of an unsupported format")
+	}
+}
+
+func TestArchiveLinks(t *testing.T) {
+	// A tar with a hard link to a file, and symlinks to a file and out of
+	// the archive
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	headers := []*tar.Header{
+		{Name: "dir/file.txt", Mode: 0o644, Size: 4, Typeflag: tar.TypeReg},
+		{Name: "hard.txt", Linkname: "dir/file.txt", Typeflag: tar.TypeLink},
+		{Name: "soft.txt", Linkname: "dir/file.txt", Typeflag: tar.TypeSymlink},
+		{Name: "escape", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink},
+		{Name: "dangling.txt", Linkname: "missing.txt", Typeflag: tar.TypeLink},
+	}
+	for _, hdr := range headers {
+		if err := w.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			w.Write([]byte("data"))
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "links.tar")
+	if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := mustNew(t, ArchiveDiskType{}, &models.Mount{Path: archive})
+
+	// Hard links share the contents of their target, and symlinks are left
+	// out as they could point anywhere
+	if got, want := archiveNames(t, b, "/"), "[dir/ hard.txt]"; got != want {
+		t.Errorf("List(/) = %v, want %v", got, want)
+	}
+	if data, err := b.Read("/hard.txt"); err != nil || string(data) != "data" {
+		t.Errorf("Read of a hard link = %q, %v", data, err)
+	}
+	for _, p := range []string{"/soft.txt", "/escape", "/dangling.txt"} {
+		if _, err := types.Stat(b, p); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%s) = %v, want ErrNotExist", p, err)
+		}
+	}
+
+	// Zip archives can hold symlinks too
+	buf.Reset()
+	zw := zip.NewWriter(&buf)
+	for name, mode := range map[string]fs.FileMode{"file.txt": 0o644, "soft.txt": fs.ModeSymlink | 0o777} {
+		hdr := &zip.FileHeader{Name: name}
+		hdr.SetMode(mode)
+		fw, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte("file.txt"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archive = filepath.Join(t.TempDir(), "links.zip")
+	if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b = mustNew(t, ArchiveDiskType{}, &models.Mount{Path: archive})
+	if got, want := archiveNames(t, b, "/"), "[file.txt]"; got != want {
+		t.Errorf("List(/) of a zip = %v, want %v", got, want)
+	}
+}
+
+func TestArchiveHostileHeader(t *testing.T) {
+	// A tar whose last entry claims a terabyte of contents but holds a few
+	// bytes is refused when it's indexed
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	w.WriteHeader(&tar.Header{Name: "a.txt", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg})
+	w.Write([]byte("a"))
+	w.WriteHeader(&tar.Header{Name: "huge.bin", Mode: 0o644, Size: 1 << 40, Typeflag: tar.TypeReg})
+	w.Flush()
+	buf.Write(make([]byte, 512))
+	archive := filepath.Join(t.TempDir(), "hostile.tar")
+	if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := mustNew(t, ArchiveDiskType{}, &models.Mount{Path: archive})
+	if _, err := b.Read("/huge.bin"); err == nil {
+		t.Error("read an entry larger than the archive")
+	}
+
+	// An entry whose size no longer matches the archive is read no further
+	// than the archive goes, without allocating what its header claims
+	archive = writeArchive(t, archiveTar, map[string][]byte{"a.txt": []byte("aaa")})
+	ab := mustNew(t, ArchiveDiskType{}, &models.Mount{Path: archive}).(*ArchiveBackend)
+	index, entry, err := ab.lookup("read", "/a.txt")
+	if err != nil {
+		t.Fatalf("lookup failed: %v", err)
+	}
+	defer index.release()
+	hostile := *entry
+	hostile.info.Size = 1 << 40
+	if _, err := index.read(&hostile); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("read of an entry past the end of the archive = %v, want ErrUnexpectedEOF", err)
+	}
+}
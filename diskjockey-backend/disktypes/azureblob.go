@@ -0,0 +1,640 @@
+package disktypes
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// AzureBlobDiskType implements DiskType for mounting a container of Azure
+// Blob Storage, or an emulator like Azurite. Blobs are files and the
+// prefixes of their names up to a "/" are directories.
+
+type AzureBlobDiskType struct{}
+
+type AzureBlobBackend struct {
+	mount     *models.Mount
+	client    *http.Client
+	account   string
+	key       []byte     // Shared key, nil if not used
+	sas       url.Values // Shared access signature, nil if not used
+	container string     // URL of the container
+	prefix    string     // Blob name prefix of the mount root, without slashes around it
+	blockSize int
+}
+
+const (
+	// Version of the Blob service REST API requests are made for
+	azureAPIVersion = "2021-08-06"
+	// Writes larger than the block size are uploaded in blocks
+	azureDefaultBlockSize = 8 << 20
+	// How long a copy made by a rename may take to complete
+	azureCopyTimeout = 5 * time.Minute
+)
+
+func (AzureBlobDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &AzureBlobBackend{mount: mount}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (AzureBlobDiskType) Name() string {
+	return "azureblob"
+}
+
+func (AzureBlobDiskType) Description() string {
+	return "Azure Blob Storage container"
+}
+
+func (AzureBlobDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return withCacheFields(types.DiskTypeConfigTemplate{
+		"account": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Name of the storage account",
+			Required:    true,
+		},
+		"container": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Name of the container to mount",
+			Required:    true,
+		},
+		"prefix": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Blob name prefix to mount instead of the whole container (e.g. assets/2024)",
+			Required:    false,
+		},
+		"account_key": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Shared key of the storage account",
+			Required:    false,
+		},
+		"sas_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Shared access signature for the account or container, used when no account key is set",
+			Required:    false,
+		},
+		"endpoint": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Blob service URL (default https://<account>.blob.core.windows.net, e.g. http://127.0.0.1:10000/devstoreaccount1 for Azurite)",
+			Required:    false,
+		},
+		"block_size": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Size in MiB of the blocks larger files are uploaded in (default 8)",
+			Required:    false,
+		},
+	})
+}
+
+func (b *AzureBlobBackend) connect() error {
+	b.account = b.mount.Option("account")
+	if b.account == "" {
+		return fmt.Errorf("azureblob: missing required config 'account'")
+	}
+	container := b.mount.Option("container")
+	if container == "" {
+		return fmt.Errorf("azureblob: missing required config 'container'")
+	}
+	b.prefix = strings.Trim(path.Clean("/"+b.mount.Option("prefix")), "/")
+
+	endpoint := strings.TrimSuffix(b.mount.Option("endpoint"), "/")
+	if endpoint == "" {
+		endpoint = "https://" + b.account + ".blob.core.windows.net"
+	}
+	if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("azureblob: invalid endpoint %q", endpoint)
+	}
+	b.container = endpoint + "/" + url.PathEscape(container)
+
+	b.key, b.sas = nil, nil
+	if key := b.mount.Option("account_key"); key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return fmt.Errorf("azureblob: account_key is not base64: %w", err)
+		}
+		b.key = decoded
+	} else if sas := strings.TrimPrefix(b.mount.Option("sas_token"), "?"); sas != "" {
+		values, err := url.ParseQuery(sas)
+		if err != nil {
+			return fmt.Errorf("azureblob: invalid sas_token: %w", err)
+		}
+		b.sas = values
+	}
+
+	b.blockSize = azureDefaultBlockSize
+	if raw := b.mount.Option("block_size"); raw != "" {
+		mib, err := strconv.Atoi(raw)
+		if err != nil || mib <= 0 || mib > 4000 {
+			return fmt.Errorf("azureblob: invalid block_size %q", raw)
+		}
+		b.blockSize = mib << 20
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	b.client = &http.Client{Transport: transport}
+
+	// Fail the mount early if the container is missing or access is denied
+	resp, err := b.send(http.MethodHead, "", url.Values{"restype": {"container"}}, nil, nil, "/")
+	if hasAzureStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("azureblob: container %q does not exist", container)
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// name returns the blob name of a mount path, "" for the root of the mount
+// when the whole container is mounted.
+func (b *AzureBlobBackend) name(p string) string {
+	return strings.TrimPrefix(joinRemote("/"+b.prefix, p), "/")
+}
+
+// dirPrefix returns the blob name prefix of the blobs in a directory.
+func (b *AzureBlobBackend) dirPrefix(p string) string {
+	if name := b.name(p); name != "" {
+		return name + "/"
+	}
+	return ""
+}
+
+// blobURL returns the URL of a blob, or of the container for "".
+func (b *AzureBlobBackend) blobURL(name string) string {
+	if name == "" {
+		return b.container
+	}
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return b.container + "/" + strings.Join(segments, "/")
+}
+
+// azureError is returned for a response that isn't a success. It wraps the
+// sentinel error matching the status, if there is one.
+type azureError struct {
+	status int
+	code   string
+}
+
+func (e *azureError) Error() string {
+	if e.code == "" {
+		return fmt.Sprintf("azureblob: server responded %d %s", e.status, http.StatusText(e.status))
+	}
+	return fmt.Sprintf("azureblob: %s (%d)", e.code, e.status)
+}
+
+func (e *azureError) Unwrap() error {
+	switch e.status {
+	case http.StatusNotFound:
+		return fs.ErrNotExist
+	case http.StatusPreconditionFailed:
+		return types.ErrConflict
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return types.ErrOffline
+	default:
+		return nil
+	}
+}
+
+// hasAzureStatus reports whether err is for a response with the status
+func hasAzureStatus(err error, status int) bool {
+	var azureErr *azureError
+	return errors.As(err, &azureErr) && azureErr.status == status
+}
+
+// send makes an authorized request for a blob, or the container if name is
+// "", and returns the response of a success.
+func (b *AzureBlobBackend) send(method, name string, query url.Values, header http.Header, body []byte, p string) (*http.Response, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	for key, values := range b.sas {
+		query[key] = values
+	}
+	u := b.blobURL(name)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	if b.key != nil {
+		req.Header.Set("Authorization", "SharedKey "+b.account+":"+b.sign(req, int64(len(body))))
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	return nil, &fs.PathError{Op: strings.ToLower(method), Path: p, Err: &azureError{status: resp.StatusCode, code: resp.Header.Get("x-ms-error-code")}}
+}
+
+// sign returns the Shared Key signature of a request.
+func (b *AzureBlobBackend) sign(req *http.Request, contentLength int64) string {
+	length := ""
+	if contentLength > 0 {
+		length = strconv.FormatInt(contentLength, 10)
+	}
+	lines := []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}
+
+	var msHeaders []string
+	for key := range req.Header {
+		if lower := strings.ToLower(key); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower+":"+strings.TrimSpace(req.Header.Get(key)))
+		}
+	}
+	sort.Strings(msHeaders)
+	lines = append(lines, msHeaders...)
+
+	resource := "/" + b.account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(key) + ":" + strings.Join(values, ",")
+	}
+	lines = append(lines, resource)
+
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// azureBlobList is a page of the List Blobs operation
+type azureBlobList struct {
+	Blobs struct {
+		Blob []struct {
+			Name       string `xml:"Name"`
+			Properties struct {
+				LastModified  string `xml:"Last-Modified"`
+				ContentLength int64  `xml:"Content-Length"`
+				Etag          string `xml:"Etag"`
+			} `xml:"Properties"`
+		} `xml:"Blob"`
+		BlobPrefix []struct {
+			Name string `xml:"Name"`
+		} `xml:"BlobPrefix"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// list returns a page of the blobs whose names start with prefix. With the
+// delimiter, blobs below the next "/" are grouped into prefixes.
+func (b *AzureBlobBackend) list(prefix, delimiter, marker string, max int, p string) (*azureBlobList, error) {
+	query := url.Values{"restype": {"container"}, "comp": {"list"}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	if marker != "" {
+		query.Set("marker", marker)
+	}
+	if max > 0 {
+		query.Set("maxresults", strconv.Itoa(max))
+	}
+	resp, err := b.send(http.MethodGet, "", query, nil, nil, p)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var page azureBlobList
+	if err := xml.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("azureblob: invalid listing: %w", err)
+	}
+	return &page, nil
+}
+
+func (b *AzureBlobBackend) List(p string) ([]types.FileInfo, error) {
+	prefix := b.dirPrefix(p)
+
+	var out []types.FileInfo
+	marker, placeholder := "", false
+	for {
+		page, err := b.list(prefix, "/", marker, 0, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range page.Blobs.BlobPrefix {
+			out = append(out, types.FileInfo{Name: strings.TrimSuffix(strings.TrimPrefix(dir.Name, prefix), "/"), IsDir: true})
+		}
+		for _, blob := range page.Blobs.Blob {
+			name := strings.TrimPrefix(blob.Name, prefix)
+			if name == "" {
+				// The empty blob some tools create to keep a directory
+				placeholder = true
+				continue
+			}
+			modTime, _ := time.Parse(http.TimeFormat, blob.Properties.LastModified)
+			out = append(out, types.FileInfo{
+				Name:    name,
+				Size:    blob.Properties.ContentLength,
+				ModTime: modTime,
+				ETag:    blob.Properties.Etag,
+			})
+		}
+		if marker = page.NextMarker; marker == "" {
+			break
+		}
+	}
+
+	// Directories only exist while there are blobs below them
+	if len(out) == 0 && !placeholder && b.name(p) != b.prefix {
+		return nil, &fs.PathError{Op: "list", Path: p, Err: fs.ErrNotExist}
+	}
+	return out, nil
+}
+
+func (b *AzureBlobBackend) Stat(p string) (types.FileInfo, error) {
+	name := b.name(p)
+	if name == b.prefix {
+		return types.FileInfo{Name: "/", IsDir: true}, nil
+	}
+
+	resp, err := b.send(http.MethodHead, name, nil, nil, nil, p)
+	if err == nil {
+		resp.Body.Close()
+		modTime, _ := time.Parse(http.TimeFormat, resp.Header.Get("Last-Modified"))
+		return types.FileInfo{
+			Name:    path.Base(name),
+			Size:    resp.ContentLength,
+			ModTime: modTime,
+			ETag:    resp.Header.Get("ETag"),
+		}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return types.FileInfo{}, err
+	}
+
+	// Not a blob, but it's a directory if any blob name starts with it
+	page, err := b.list(name+"/", "", "", 1, p)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	if len(page.Blobs.Blob) > 0 {
+		return types.FileInfo{Name: path.Base(name), IsDir: true}, nil
+	}
+	return types.FileInfo{}, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+}
+
+func (b *AzureBlobBackend) Read(p string) ([]byte, error) {
+	resp, err := b.send(http.MethodGet, b.name(p), nil, nil, nil, p)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// ReadRange implements types.RangeReader with a ranged GET.
+func (b *AzureBlobBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	if length == 0 {
+		return []byte{}, nil
+	}
+	header := http.Header{"x-ms-range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}}
+	resp, err := b.send(http.MethodGet, b.name(p), nil, header, nil, p)
+	if hasAzureStatus(err, http.StatusRequestedRangeNotSatisfiable) {
+		// The range starts past the end of the blob
+		return []byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, length))
+}
+
+func (b *AzureBlobBackend) Write(p string, data []byte) error {
+	return b.upload(p, data, "")
+}
+
+// WriteIfMatch writes the blob only if its current ETag is etag.
+func (b *AzureBlobBackend) WriteIfMatch(p string, data []byte, etag string) error {
+	return b.upload(p, data, etag)
+}
+
+// upload writes data in a single request, or as blocks of blockSize that
+// are committed together when it's larger than that.
+func (b *AzureBlobBackend) upload(p string, data []byte, etag string) error {
+	name := b.name(p)
+	if name == b.prefix {
+		return fmt.Errorf("cannot write to root directory")
+	}
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+
+	if len(data) <= b.blockSize {
+		header.Set("x-ms-blob-type", "BlockBlob")
+		resp, err := b.send(http.MethodPut, name, nil, header, data, p)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	var blockList bytes.Buffer
+	blockList.WriteString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+	for offset, n := 0, 0; offset < len(data); offset, n = offset+b.blockSize, n+1 {
+		// Block IDs of a blob must all have the same length
+		id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("block-%08d", n)))
+		chunk := data[offset:min(offset+b.blockSize, len(data))]
+		resp, err := b.send(http.MethodPut, name, url.Values{"comp": {"block"}, "blockid": {id}}, nil, chunk, p)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		blockList.WriteString("<Latest>" + id + "</Latest>")
+	}
+	blockList.WriteString("</BlockList>")
+
+	// Committing the list replaces the blob with the blocks
+	header.Set("Content-Type", "application/xml")
+	resp, err := b.send(http.MethodPut, name, url.Values{"comp": {"blocklist"}}, header, blockList.Bytes(), p)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Delete removes a blob, or every blob below a directory.
+func (b *AzureBlobBackend) Delete(p string) error {
+	info, err := b.Stat(p)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir {
+		return b.remove(b.name(p), p)
+	}
+	if b.name(p) == b.prefix {
+		return fmt.Errorf("cannot delete root directory")
+	}
+
+	names, err := b.namesBelow(b.dirPrefix(p), p)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := b.remove(name, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *AzureBlobBackend) remove(name, p string) error {
+	resp, err := b.send(http.MethodDelete, name, nil, nil, nil, p)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// namesBelow returns the names of every blob whose name starts with prefix.
+func (b *AzureBlobBackend) namesBelow(prefix, p string) ([]string, error) {
+	var names []string
+	marker := ""
+	for {
+		page, err := b.list(prefix, "", marker, 0, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, blob := range page.Blobs.Blob {
+			names = append(names, blob.Name)
+		}
+		if marker = page.NextMarker; marker == "" {
+			return names, nil
+		}
+	}
+}
+
+// Rename copies blobs to their new names on the server and removes the
+// originals. Blob Storage has no rename, so moving a directory copies every
+// blob below it.
+func (b *AzureBlobBackend) Rename(from, to string) error {
+	info, err := b.Stat(from)
+	if err != nil {
+		return err
+	}
+	if b.name(from) == b.prefix || b.name(to) == b.prefix {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	if !info.IsDir {
+		return b.move(b.name(from), b.name(to), from)
+	}
+
+	fromPrefix, toPrefix := b.dirPrefix(from), b.dirPrefix(to)
+	if strings.HasPrefix(toPrefix, fromPrefix) {
+		return fmt.Errorf("cannot move %s into itself", from)
+	}
+	names, err := b.namesBelow(fromPrefix, from)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := b.move(name, toPrefix+strings.TrimPrefix(name, fromPrefix), from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// move copies a blob to another name, waits for the copy to complete and
+// removes the original.
+func (b *AzureBlobBackend) move(fromName, toName, p string) error {
+	source := b.blobURL(fromName)
+	if b.sas != nil {
+		// The source is read with the same signature
+		source += "?" + b.sas.Encode()
+	}
+	resp, err := b.send(http.MethodPut, toName, nil, http.Header{"x-ms-copy-source": {source}}, nil, p)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	// Copies within an account usually complete before the response
+	status := resp.Header.Get("x-ms-copy-status")
+	deadline := time.Now().Add(azureCopyTimeout)
+	for status == "pending" {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("azureblob: copy of %s to %s did not complete", fromName, toName)
+		}
+		time.Sleep(time.Second)
+		if resp, err = b.send(http.MethodHead, toName, nil, nil, nil, p); err != nil {
+			return err
+		}
+		resp.Body.Close()
+		status = resp.Header.Get("x-ms-copy-status")
+	}
+	if status != "" && status != "success" {
+		return fmt.Errorf("azureblob: copy of %s to %s %s: %s", fromName, toName, status, resp.Header.Get("x-ms-copy-status-description"))
+	}
+	return b.remove(fromName, p)
+}
+
+func (b *AzureBlobBackend) Reconnect() error {
+	return b.connect()
+}
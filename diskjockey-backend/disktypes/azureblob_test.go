@@ -0,0 +1,569 @@
+package disktypes
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// fakeAzure is an in-memory Blob service, like Azurite, holding the "test"
+// container of its account. Requests must be signed with the account key,
+// or carry the shared access signature when sas is set.
+type fakeAzure struct {
+	t        *testing.T
+	server   *httptest.Server
+	key      []byte
+	sas      url.Values // Shared access signature required instead of the key
+	pageSize int        // Blobs and prefixes per page of a listing
+
+	mu     sync.Mutex
+	blobs  map[string]*fakeAzureBlob
+	blocks map[string]map[string][]byte // Blob name -> block ID -> staged block
+	staged int                          // Blocks staged
+	nextID int
+}
+
+type fakeAzureBlob struct {
+	data     []byte
+	etag     string
+	modified time.Time
+}
+
+const (
+	fakeAzureAccount   = "devstoreaccount1"
+	fakeAzureContainer = "test"
+)
+
+func newFakeAzure(t *testing.T) *fakeAzure {
+	t.Helper()
+	f := &fakeAzure{
+		t:        t,
+		key:      []byte("fake account key"),
+		pageSize: 100,
+		blobs:    map[string]*fakeAzureBlob{},
+		blocks:   map[string]map[string][]byte{},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// options returns the options mounting the container below prefix with the
+// account key
+func (f *fakeAzure) options(prefix string) map[string]string {
+	return map[string]string{
+		"account":     fakeAzureAccount,
+		"container":   fakeAzureContainer,
+		"prefix":      prefix,
+		"account_key": base64.StdEncoding.EncodeToString(f.key),
+		"endpoint":    f.server.URL + "/" + fakeAzureAccount,
+	}
+}
+
+func (f *fakeAzure) mount(prefix string, opts map[string]string) types.Backend {
+	f.t.Helper()
+	options := f.options(prefix)
+	for key, value := range opts {
+		options[key] = value
+	}
+	return mustNew(f.t, AzureBlobDiskType{}, &models.Mount{Options: options})
+}
+
+func (f *fakeAzure) put(name string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.putLocked(name, data)
+}
+
+func (f *fakeAzure) putLocked(name string, data []byte) {
+	f.nextID++
+	f.blobs[name] = &fakeAzureBlob{
+		data:     append([]byte(nil), data...),
+		etag:     fmt.Sprintf("\"0x%X\"", f.nextID),
+		modified: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func (f *fakeAzure) get(name string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	blob, ok := f.blobs[name]
+	if !ok {
+		return nil, false
+	}
+	return blob.data, true
+}
+
+func (f *fakeAzure) names() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(f.blobs))
+	for name := range f.blobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *fakeAzure) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("x-ms-version") == "" {
+		f.azureError(w, http.StatusBadRequest, "MissingRequiredHeader")
+		return
+	}
+	if !f.authorized(r) {
+		f.azureError(w, http.StatusForbidden, "AuthenticationFailed")
+		return
+	}
+	container, name, ok := f.target(r.URL.EscapedPath())
+	if !ok {
+		f.azureError(w, http.StatusBadRequest, "InvalidUri")
+		return
+	}
+	if container != fakeAzureContainer {
+		f.azureError(w, http.StatusNotFound, "ContainerNotFound")
+		return
+	}
+
+	query := r.URL.Query()
+	switch {
+	case name == "" && r.Method == http.MethodHead && query.Get("restype") == "container":
+		w.WriteHeader(http.StatusOK)
+	case name == "" && r.Method == http.MethodGet && query.Get("restype") == "container" && query.Get("comp") == "list":
+		f.list(w, query)
+	case name == "":
+		f.azureError(w, http.StatusBadRequest, "InvalidQueryParameterValue")
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		f.read(w, r, name)
+	case r.Method == http.MethodPut && query.Get("comp") == "block":
+		id := query.Get("blockid")
+		if id == "" {
+			f.azureError(w, http.StatusBadRequest, "InvalidQueryParameterValue")
+			return
+		}
+		if f.blocks[name] == nil {
+			f.blocks[name] = map[string][]byte{}
+		}
+		f.blocks[name][id], _ = io.ReadAll(r.Body)
+		f.staged++
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+		f.commitBlocks(w, r, name)
+	case r.Method == http.MethodPut && r.Header.Get("x-ms-copy-source") != "":
+		f.copyBlob(w, r, name)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			f.azureError(w, http.StatusBadRequest, "MissingRequiredHeader")
+			return
+		}
+		if !f.matches(w, r, name) {
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.putLocked(name, data)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete:
+		if _, ok := f.blobs[name]; !ok {
+			f.azureError(w, http.StatusNotFound, "BlobNotFound")
+			return
+		}
+		delete(f.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		f.azureError(w, http.StatusMethodNotAllowed, "UnsupportedHttpVerb")
+	}
+}
+
+// target splits an escaped request path into the container and blob name
+func (f *fakeAzure) target(escaped string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(escaped, "/"+fakeAzureAccount+"/")
+	if !ok {
+		return "", "", false
+	}
+	container, name, _ := strings.Cut(rest, "/")
+	var err error
+	if container, err = url.PathUnescape(container); err != nil {
+		return "", "", false
+	}
+	if name, err = url.PathUnescape(name); err != nil {
+		return "", "", false
+	}
+	return container, name, true
+}
+
+// authorized checks the Shared Key signature of a request, or its shared
+// access signature
+func (f *fakeAzure) authorized(r *http.Request) bool {
+	if f.sas != nil {
+		return r.URL.Query().Get("sig") == f.sas.Get("sig")
+	}
+	length := ""
+	if r.ContentLength > 0 {
+		length = strconv.FormatInt(r.ContentLength, 10)
+	}
+	var headers []string
+	for key := range r.Header {
+		if lower := strings.ToLower(key); strings.HasPrefix(lower, "x-ms-") {
+			headers = append(headers, lower+":"+strings.TrimSpace(r.Header.Get(key)))
+		}
+	}
+	sort.Strings(headers)
+	resource := "/" + fakeAzureAccount + r.URL.EscapedPath()
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(key) + ":" + strings.Join(values, ",")
+	}
+	toSign := strings.Join([]string{
+		r.Method,
+		r.Header.Get("Content-Encoding"),
+		r.Header.Get("Content-Language"),
+		length,
+		r.Header.Get("Content-MD5"),
+		r.Header.Get("Content-Type"),
+		r.Header.Get("Date"),
+		r.Header.Get("If-Modified-Since"),
+		r.Header.Get("If-Match"),
+		r.Header.Get("If-None-Match"),
+		r.Header.Get("If-Unmodified-Since"),
+		r.Header.Get("Range"),
+	}, "\n") + "\n" + strings.Join(append(headers, resource), "\n")
+	mac := hmac.New(sha256.New, f.key)
+	mac.Write([]byte(toSign))
+	want := "SharedKey " + fakeAzureAccount + ":" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(r.Header.Get("Authorization")), []byte(want))
+}
+
+// matches checks the If-Match condition of a write, and reports whether it
+// can go ahead
+func (f *fakeAzure) matches(w http.ResponseWriter, r *http.Request, name string) bool {
+	match := r.Header.Get("If-Match")
+	if blob, ok := f.blobs[name]; match != "" && (!ok || blob.etag != match) {
+		f.azureError(w, http.StatusPreconditionFailed, "ConditionNotMet")
+		return false
+	}
+	return true
+}
+
+// fakeAzureList is the response of List Blobs
+type fakeAzureList struct {
+	XMLName    xml.Name            `xml:"EnumerationResults"`
+	Blob       []fakeAzureListBlob `xml:"Blobs>Blob"`
+	BlobPrefix []fakeAzurePrefix   `xml:"Blobs>BlobPrefix"`
+	NextMarker string              `xml:"NextMarker"`
+}
+
+type fakeAzureListBlob struct {
+	Name          string `xml:"Name"`
+	LastModified  string `xml:"Properties>Last-Modified"`
+	ContentLength int    `xml:"Properties>Content-Length"`
+	Etag          string `xml:"Properties>Etag"`
+}
+
+type fakeAzurePrefix struct {
+	Name string `xml:"Name"`
+}
+
+// list returns a page of the blobs with a prefix, starting at the blob name
+// of the marker. With the delimiter, blobs below the next "/" are grouped
+// into a prefix.
+func (f *fakeAzure) list(w http.ResponseWriter, query url.Values) {
+	prefix, delimiter, marker := query.Get("prefix"), query.Get("delimiter"), query.Get("marker")
+	limit := f.pageSize
+	if max, err := strconv.Atoi(query.Get("maxresults")); err == nil && max < limit {
+		limit = max
+	}
+
+	var names []string
+	for name := range f.blobs {
+		if strings.HasPrefix(name, prefix) && name >= marker {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var page fakeAzureList
+	count, lastPrefix := 0, ""
+	for _, name := range names {
+		grouped := ""
+		if i := strings.Index(name[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+			grouped = name[:len(prefix)+i+len(delimiter)]
+			if grouped == lastPrefix {
+				continue
+			}
+		}
+		if count == limit {
+			page.NextMarker = name
+			break
+		}
+		count++
+		if grouped != "" {
+			lastPrefix = grouped
+			page.BlobPrefix = append(page.BlobPrefix, fakeAzurePrefix{Name: grouped})
+			continue
+		}
+		blob := f.blobs[name]
+		page.Blob = append(page.Blob, fakeAzureListBlob{
+			Name:          name,
+			LastModified:  blob.modified.Format(http.TimeFormat),
+			ContentLength: len(blob.data),
+			Etag:          blob.etag,
+		})
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(page)
+}
+
+func (f *fakeAzure) read(w http.ResponseWriter, r *http.Request, name string) {
+	blob, ok := f.blobs[name]
+	if !ok {
+		f.azureError(w, http.StatusNotFound, "BlobNotFound")
+		return
+	}
+	data, status := blob.data, http.StatusOK
+	if ranged := r.Header.Get("x-ms-range"); ranged != "" {
+		var start, end int
+		if _, err := fmt.Sscanf(ranged, "bytes=%d-%d", &start, &end); err != nil || start > end {
+			f.azureError(w, http.StatusBadRequest, "InvalidHeaderValue")
+			return
+		}
+		if start >= len(data) {
+			f.azureError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+			return
+		}
+		data, status = data[start:min(end+1, len(data))], http.StatusPartialContent
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Last-Modified", blob.modified.Format(http.TimeFormat))
+	w.Header().Set("ETag", blob.etag)
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// commitBlocks replaces a blob with the staged blocks of a block list
+func (f *fakeAzure) commitBlocks(w http.ResponseWriter, r *http.Request, name string) {
+	if !f.matches(w, r, name) {
+		return
+	}
+	var list struct {
+		Latest []string `xml:"Latest"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&list); err != nil {
+		f.azureError(w, http.StatusBadRequest, "InvalidXmlDocument")
+		return
+	}
+	var data []byte
+	for _, id := range list.Latest {
+		block, ok := f.blocks[name][id]
+		if !ok {
+			f.azureError(w, http.StatusBadRequest, "InvalidBlockList")
+			return
+		}
+		data = append(data, block...)
+	}
+	delete(f.blocks, name)
+	f.putLocked(name, data)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// copyBlob copies a blob of the container, which completes at once
+func (f *fakeAzure) copyBlob(w http.ResponseWriter, r *http.Request, name string) {
+	source, err := url.Parse(r.Header.Get("x-ms-copy-source"))
+	if err != nil || source.Host != r.Host {
+		f.azureError(w, http.StatusBadRequest, "InvalidHeaderValue")
+		return
+	}
+	// The source is read with the signature it carries, if any
+	if f.sas != nil && source.Query().Get("sig") != f.sas.Get("sig") {
+		f.azureError(w, http.StatusForbidden, "CannotVerifyCopySource")
+		return
+	}
+	container, from, ok := f.target(source.EscapedPath())
+	blob, exists := f.blobs[from]
+	if !ok || container != fakeAzureContainer || !exists {
+		f.azureError(w, http.StatusNotFound, "CannotVerifyCopySource")
+		return
+	}
+	f.putLocked(name, blob.data)
+	w.Header().Set("x-ms-copy-status", "success")
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (f *fakeAzure) azureError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("x-ms-error-code", code)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s<Error><Code>%s</Code><Message>%s</Message></Error>", xml.Header, code, http.StatusText(status))
+}
+
+func TestAzureBlobFilesAndDirectories(t *testing.T) {
+	fake := newFakeAzure(t)
+	fake.pageSize = 2
+	fake.put("mount/dir/", nil)
+	b := fake.mount("mount", nil)
+
+	files := map[string]string{
+		"/a b.txt":       "0123456789",
+		"/docs/x.txt":    "x",
+		"/docs/y.txt":    "y",
+		"/docs/sub/z.md": "z",
+	}
+	for p, content := range files {
+		if err := b.Write(p, []byte(content)); err != nil {
+			t.Fatalf("Write(%s) failed: %v", p, err)
+		}
+	}
+	if data, ok := fake.get("mount/a b.txt"); !ok || string(data) != "0123456789" {
+		t.Errorf("blob holds %q", data)
+	}
+
+	infos, err := b.List("/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, fmt.Sprintf("%s:%v", info.Name, info.IsDir))
+	}
+	sort.Strings(names)
+	if want := "[a b.txt:false dir:true docs:true]"; fmt.Sprint(names) != want {
+		t.Errorf("List = %v, want %v", names, want)
+	}
+	// The placeholder blob keeps the directory without showing in it
+	if infos, err := b.List("/dir"); err != nil || len(infos) != 0 {
+		t.Errorf("List of a placeholder directory = %v, %v", infos, err)
+	}
+	if _, err := b.List("/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("List of a missing directory = %v, want ErrNotExist", err)
+	}
+
+	stater := b.(types.Stater)
+	if info, err := stater.Stat("/a b.txt"); err != nil || info.Size != 10 || info.ETag == "" || info.ModTime.IsZero() {
+		t.Errorf("Stat = %+v, %v", info, err)
+	}
+	if info, err := stater.Stat("/docs"); err != nil || !info.IsDir {
+		t.Errorf("Stat of a directory = %+v, %v", info, err)
+	}
+	ranger := b.(types.RangeReader)
+	if data, err := ranger.ReadRange("/a b.txt", 2, 3); err != nil || string(data) != "234" {
+		t.Errorf("ReadRange = %q, %v", data, err)
+	}
+	if data, err := ranger.ReadRange("/a b.txt", 20, 3); err != nil || len(data) != 0 {
+		t.Errorf("ReadRange past the end = %q, %v", data, err)
+	}
+
+	if err := b.(types.Renamer).Rename("/docs", "/moved"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if data, err := b.Read("/moved/sub/z.md"); err != nil || string(data) != "z" {
+		t.Errorf("Read after Rename = %q, %v", data, err)
+	}
+	if err := b.Delete("/moved"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if want := "[mount/a b.txt mount/dir/]"; fmt.Sprint(fake.names()) != want {
+		t.Errorf("container holds %v, want %v", fake.names(), want)
+	}
+}
+
+func TestAzureBlobBlockUpload(t *testing.T) {
+	fake := newFakeAzure(t)
+	b := fake.mount("", map[string]string{"block_size": "1"})
+	data := make([]byte, 2<<20+1<<19)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	if err := b.Write("/large.bin", data); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if stored, ok := fake.get("large.bin"); !ok || !bytes.Equal(stored, data) {
+		t.Fatalf("stored %d bytes that don't match the %d written", len(stored), len(data))
+	}
+	fake.mu.Lock()
+	staged, left := fake.staged, len(fake.blocks)
+	fake.mu.Unlock()
+	if staged != 3 || left != 0 {
+		t.Errorf("staged %d blocks with %d blobs left uncommitted, want 3 and none", staged, left)
+	}
+}
+
+func TestAzureBlobWriteIfMatch(t *testing.T) {
+	fake := newFakeAzure(t)
+	fake.put("a.txt", []byte("base"))
+	b := fake.mount("", nil)
+	info, err := b.(types.Stater).Stat("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.put("a.txt", []byte("remote"))
+	writer := b.(types.ConditionalWriter)
+	if err := writer.WriteIfMatch("/a.txt", []byte("local"), info.ETag); !errors.Is(err, types.ErrConflict) {
+		t.Errorf("WriteIfMatch over a changed blob = %v, want ErrConflict", err)
+	}
+	if data, _ := fake.get("a.txt"); string(data) != "remote" {
+		t.Errorf("blob holds %q after the conflict", data)
+	}
+}
+
+func TestAzureBlobAuthorization(t *testing.T) {
+	fake := newFakeAzure(t)
+	options := fake.options("")
+	options["account_key"] = base64.StdEncoding.EncodeToString([]byte("wrong key"))
+	if _, err := (AzureBlobDiskType{}).New(&models.Mount{Options: options}); err == nil {
+		t.Error("mounted with the wrong account key")
+	}
+
+	// A shared access signature has to reach the source of copies too
+	fake.sas = url.Values{"sv": {azureAPIVersion}, "sp": {"racwdl"}, "sig": {"signature"}}
+	fake.put("a.txt", []byte("a"))
+	options = fake.options("")
+	delete(options, "account_key")
+	options["sas_token"] = "?" + fake.sas.Encode()
+	b := mustNew(t, AzureBlobDiskType{}, &models.Mount{Options: options})
+	if err := b.(types.Renamer).Rename("/a.txt", "/b.txt"); err != nil {
+		t.Fatalf("Rename with a shared access signature failed: %v", err)
+	}
+	if data, ok := fake.get("b.txt"); !ok || string(data) != "a" {
+		t.Errorf("renamed blob holds %q", data)
+	}
+}
+
+func TestAzureBlobMissingContainer(t *testing.T) {
+	fake := newFakeAzure(t)
+	options := fake.options("")
+	options["container"] = "missing"
+	_, err := AzureBlobDiskType{}.New(&models.Mount{Options: options})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("mounting a missing container = %v, want it reported as missing", err)
+	}
+}
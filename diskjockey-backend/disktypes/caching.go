@@ -0,0 +1,36 @@
+package disktypes
+
+import "github.com/christhomas/diskjockey/diskjockey-backend/types"
+
+// withCacheFields adds the options controlling the local cache and uploads to
+// the config template of a remote disk type.
+func withCacheFields(template types.DiskTypeConfigTemplate) types.DiskTypeConfigTemplate {
+	template = withReadCacheFields(template)
+	template["write_back"] = types.DiskTypeConfigField{
+		Type:        "bool",
+		Description: "Save writes locally and upload them in the background (default false)",
+		Required:    false,
+	}
+	template["conflict_policy"] = types.DiskTypeConfigField{
+		Type:        "string",
+		Description: "What to do when a file changed remotely since it was read: keep_both, local_wins, remote_wins or ask. Conflicts are only detected if this or write_back is set (default keep_both)",
+		Required:    false,
+	}
+	return template
+}
+
+// withReadCacheFields adds only the options controlling the local cache, for
+// read-only disk types that have no changes to upload.
+func withReadCacheFields(template types.DiskTypeConfigTemplate) types.DiskTypeConfigTemplate {
+	template["cache"] = types.DiskTypeConfigField{
+		Type:        "bool",
+		Description: "Keep a local copy of files that were read (default false)",
+		Required:    false,
+	}
+	template["cache_list_ttl"] = types.DiskTypeConfigField{
+		Type:        "integer",
+		Description: "Seconds a directory listing is reused, 0 to disable (default 10)",
+		Required:    false,
+	}
+	return template
+}
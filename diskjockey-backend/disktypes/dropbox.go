@@ -1,28 +1,43 @@
 package disktypes
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net/http"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/christhomas/diskjockey/diskjockey-backend/models"
 	"github.com/christhomas/diskjockey/diskjockey-backend/types"
 	dropbox "github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
 	files "github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+	"golang.org/x/oauth2"
 )
 
 // DropboxDiskType implements DiskType for Dropbox
 
-type DropboxDiskType struct{}
+type DropboxDiskType struct {
+	Tokens types.TokenStore // Saves access tokens refreshed while mounted
+}
 
 type DropboxBackend struct {
 	mount  *models.Mount
+	tokens types.TokenStore
 	client files.Client
+	notify dropbox.Config // config for the unauthenticated list_folder/longpoll client
 }
 
-func (DropboxDiskType) New(mount *models.Mount) (types.Backend, error) {
+const dropboxDefaultAPIURL = "https://api.dropboxapi.com"
+
+func (t DropboxDiskType) New(mount *models.Mount) (types.Backend, error) {
 	b := &DropboxBackend{
-		mount: mount,
+		mount:  mount,
+		tokens: t.Tokens,
 	}
 
 	if err := b.connect(); err != nil {
@@ -41,102 +56,438 @@ func (DropboxDiskType) Description() string {
 }
 
 func (DropboxDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
-	return types.DiskTypeConfigTemplate{
+	return withCacheFields(types.DiskTypeConfigTemplate{
+		"app_key": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Dropbox app key, used to authorize the mount and refresh its access token",
+			Required:    false,
+		},
 		"access_token": types.DiskTypeConfigField{
 			Type:        "string",
-			Description: "Dropbox API OAuth2 access token",
-			Required:    true,
+			Description: "Dropbox API OAuth2 access token (set by authorization, or a long-lived token)",
+			Required:    false,
+		},
+		"refresh_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Dropbox OAuth2 refresh token (set by authorization)",
+			Required:    false,
+		},
+		"redirect_port": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Fixed loopback port for the authorization redirect, if the app only allows registered redirect URIs",
+			Required:    false,
+		},
+		"api_url": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Dropbox API endpoint, used for every API host and the token endpoint (default " + dropboxDefaultAPIURL + ")",
+			Required:    false,
 		},
+	})
+}
+
+// OAuthConfig implements types.OAuthDiskType using the PKCE flow, which needs
+// no app secret. Offline access is requested to receive a refresh token.
+func (DropboxDiskType) OAuthConfig(mount *models.Mount) (*oauth2.Config, []oauth2.AuthCodeOption, error) {
+	appKey := mount.Option("app_key")
+	if appKey == "" {
+		return nil, nil, fmt.Errorf("missing required dropbox config field: app_key")
 	}
+	return dropboxOAuthConfig(appKey, mount), []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("token_access_type", "offline")}, nil
 }
 
-func (b *DropboxBackend) connect() error {
-	token := b.mount.AccessToken
-	if token == "" {
-		return fmt.Errorf("missing required dropbox config field: access_token")
+func dropboxOAuthConfig(appKey string, mount *models.Mount) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID: appKey,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   "https://www.dropbox.com/oauth2/authorize",
+			TokenURL:  dropboxAPIURL(mount) + "/oauth2/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
 	}
+}
+
+func (b *DropboxBackend) connect() error {
 	config := dropbox.Config{
-		Token:    token,
 		LogLevel: dropbox.LogInfo, // Or dropbox.LogOff
 	}
+
+	if b.mount.Option("refresh_token") != "" {
+		appKey := b.mount.Option("app_key")
+		if appKey == "" {
+			return fmt.Errorf("missing required dropbox config field: app_key")
+		}
+		tokens := newMountTokenSource(dropboxOAuthConfig(appKey, b.mount), b.mount, b.tokens)
+		// Fail the mount early if the refresh token has been revoked
+		if _, err := tokens.Token(); err != nil {
+			return err
+		}
+		config.Client = &http.Client{Transport: &oauth2.Transport{Source: tokens}}
+	} else {
+		token := b.mount.AccessToken
+		if token == "" {
+			return fmt.Errorf("%w: missing required dropbox config field: access_token (authorize the mount)", types.ErrReauthRequired)
+		}
+		config.Token = token
+	}
+
+	notifyConfig := dropbox.Config{LogLevel: config.LogLevel}
+	if b.mount.Option("api_url") != "" {
+		config.URLGenerator = dropboxURLGenerator(dropboxAPIURL(b.mount))
+		notifyConfig.URLGenerator = config.URLGenerator
+	}
+
 	b.client = files.New(config)
+	// The longpoll endpoint rejects requests that carry an access token
+	b.notify = notifyConfig
 	return nil
 }
 
-func (b *DropboxBackend) List(path string) ([]types.FileInfo, error) {
-	if path == "" {
-		path = "" // Dropbox root is ""
+// notifyClient returns a client for list_folder/longpoll whose requests are
+// cancelled with ctx. A longpoll blocks for up to its timeout, and the SDK
+// takes no context of its own.
+func (b *DropboxBackend) notifyClient(ctx context.Context) files.Client {
+	config := b.notify
+	config.Client = &http.Client{Transport: contextTransport{ctx: ctx, base: http.DefaultTransport}}
+	return files.New(config)
+}
+
+// contextTransport sends every request with ctx
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t contextTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(r.WithContext(t.ctx))
+}
+
+func dropboxAPIURL(mount *models.Mount) string {
+	if api := strings.TrimSuffix(mount.Option("api_url"), "/"); api != "" {
+		return api
 	}
-	arg := files.NewListFolderArg(path)
+	return dropboxDefaultAPIURL
+}
+
+// dropboxURLGenerator sends the requests for every Dropbox host (api, content
+// and notify) to one endpoint
+func dropboxURLGenerator(api string) func(hostType, namespace, route string) string {
+	return func(hostType, namespace, route string) string {
+		return api + "/2/" + namespace + "/" + route
+	}
+}
+
+// apiError adds context to errors returned by the Dropbox API
+func (b *DropboxBackend) apiError(err error) error {
+	errStr := err.Error()
+	if strings.Contains(errStr, "missing_scope") {
+		return fmt.Errorf("Dropbox API error: missing required permission scope. Please check your app's permissions and access token. (error: %s)", errStr)
+	}
+	if strings.Contains(errStr, "expired_access_token") || strings.Contains(errStr, "invalid_access_token") {
+		return fmt.Errorf("%w: Dropbox rejected the access token (error: %s)", types.ErrReauthRequired, errStr)
+	}
+	return err
+}
+
+// dropboxPath normalises a mount relative path for the Dropbox API, which
+// wants "" for the root and "/a/b" (no trailing slash) for everything else.
+func dropboxPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if cleaned == "/" {
+		return ""
+	}
+	return cleaned
+}
+
+func (b *DropboxBackend) List(path string) ([]types.FileInfo, error) {
+	arg := files.NewListFolderArg(dropboxPath(path))
 	res, err := b.client.ListFolder(arg)
 	if err != nil {
-		errStr := err.Error()
-		if strings.Contains(errStr, "missing_scope") {
-			return nil, fmt.Errorf("Dropbox API error: missing required permission scope. Please check your app's permissions and access token. (error: %s)", errStr)
-		}
-		return nil, err
+		return nil, b.apiError(err)
 	}
+
 	var out []types.FileInfo
-	for _, entry := range res.Entries {
-		switch f := entry.(type) {
-		case *files.FileMetadata:
-			out = append(out, types.FileInfo{
-				Name:  f.Name,
-				IsDir: false,
-				Size:  int64(f.Size),
-			})
-		case *files.FolderMetadata:
-			out = append(out, types.FileInfo{
-				Name:  f.Name,
-				IsDir: true,
-				Size:  0,
-			})
+	for {
+		for _, entry := range res.Entries {
+			if info, ok := dropboxFileInfo(entry); ok {
+				out = append(out, info)
+			}
+		}
+
+		// Large folders are returned in pages
+		if !res.HasMore {
+			break
+		}
+		res, err = b.client.ListFolderContinue(files.NewListFolderContinueArg(res.Cursor))
+		if err != nil {
+			return nil, b.apiError(err)
 		}
 	}
+
 	return out, nil
 }
 
+// dropboxFileInfo converts file and folder metadata, other entries (like
+// deleted ones) are skipped.
+func dropboxFileInfo(entry files.IsMetadata) (types.FileInfo, bool) {
+	switch f := entry.(type) {
+	case *files.FileMetadata:
+		return types.FileInfo{
+			Name:    f.Name,
+			IsDir:   false,
+			Size:    int64(f.Size),
+			ModTime: f.ServerModified,
+			ETag:    f.Rev,
+		}, true
+	case *files.FolderMetadata:
+		return types.FileInfo{
+			Name:  f.Name,
+			IsDir: true,
+			Size:  0,
+		}, true
+	}
+	return types.FileInfo{}, false
+}
+
+func (b *DropboxBackend) Stat(path string) (types.FileInfo, error) {
+	dbPath := dropboxPath(path)
+	if dbPath == "" {
+		// The API has no metadata for the root
+		return types.FileInfo{Name: "/", IsDir: true}, nil
+	}
+	entry, err := b.client.GetMetadata(files.NewGetMetadataArg(dbPath))
+	if err != nil {
+		if strings.Contains(err.Error(), "not_found") {
+			return types.FileInfo{}, &fs.PathError{Op: "stat", Path: dbPath, Err: fs.ErrNotExist}
+		}
+		return types.FileInfo{}, b.apiError(err)
+	}
+	info, ok := dropboxFileInfo(entry)
+	if !ok {
+		return types.FileInfo{}, &fs.PathError{Op: "stat", Path: dbPath, Err: fs.ErrNotExist}
+	}
+	return info, nil
+}
+
 func (b *DropboxBackend) Read(path string) ([]byte, error) {
-	arg := files.NewDownloadArg(path)
+	arg := files.NewDownloadArg(dropboxPath(path))
 	_, content, err := b.client.Download(arg)
 	if err != nil {
-		errStr := err.Error()
-		if strings.Contains(errStr, "missing_scope") {
-			return nil, fmt.Errorf("Dropbox API error: missing required permission scope. Please check your app's permissions and access token. (error: %s)", errStr)
-		}
-		return nil, err
+		return nil, b.apiError(err)
 	}
 	defer content.Close()
 	return io.ReadAll(content)
 }
 
+// dropboxUploadChunkSize is the largest file sent with a single upload call,
+// and the chunk size for upload sessions used for anything bigger. The API
+// rejects single uploads over 150 MB.
+const dropboxUploadChunkSize = 8 << 20
+
 func (b *DropboxBackend) Write(path string, data []byte) error {
-	arg := files.NewUploadArg(path)
-	arg.Mode.Tag = "overwrite"
-	_, err := b.client.Upload(arg, strings.NewReader(string(data)))
+	return b.upload(path, data, files.WriteMode{Tagged: dropbox.Tagged{Tag: files.WriteModeOverwrite}})
+}
+
+// WriteIfMatch writes the file only if its current rev is etag, letting
+// Dropbox detect a conflicting change instead of overwriting it.
+func (b *DropboxBackend) WriteIfMatch(path string, data []byte, etag string) error {
+	return b.upload(path, data, files.WriteMode{Tagged: dropbox.Tagged{Tag: files.WriteModeUpdate}, Update: etag})
+}
+
+func (b *DropboxBackend) upload(path string, data []byte, mode files.WriteMode) error {
+	dbPath := dropboxPath(path)
+	if dbPath == "" {
+		return fmt.Errorf("cannot write to root directory")
+	}
+
+	if len(data) > dropboxUploadChunkSize {
+		return b.uploadSession(dbPath, data, mode)
+	}
+
+	arg := files.NewUploadArg(dbPath)
+	arg.Mode = &mode
+	_, err := b.client.Upload(arg, bytes.NewReader(data))
 	if err != nil {
-		errStr := err.Error()
-		if strings.Contains(errStr, "missing_scope") {
-			return fmt.Errorf("Dropbox API error: missing required permission scope. Please check your app's permissions and access token. (error: %s)", errStr)
+		return b.uploadError(err)
+	}
+	return nil
+}
+
+// uploadError reports a rejected rev as a conflict.
+func (b *DropboxBackend) uploadError(err error) error {
+	if strings.Contains(err.Error(), "/conflict") {
+		return fmt.Errorf("%w: %v", types.ErrConflict, err)
+	}
+	return b.apiError(err)
+}
+
+// uploadSession uploads data in dropboxUploadChunkSize chunks: the first chunk
+// starts the session, middle chunks are appended and the last chunk commits
+// the file.
+func (b *DropboxBackend) uploadSession(dbPath string, data []byte, mode files.WriteMode) error {
+	start, err := b.client.UploadSessionStart(files.NewUploadSessionStartArg(), bytes.NewReader(data[:dropboxUploadChunkSize]))
+	if err != nil {
+		return b.apiError(err)
+	}
+
+	offset := dropboxUploadChunkSize
+	for len(data)-offset > dropboxUploadChunkSize {
+		cursor := files.NewUploadSessionCursor(start.SessionId, uint64(offset))
+		chunk := data[offset : offset+dropboxUploadChunkSize]
+		if err := b.client.UploadSessionAppendV2(files.NewUploadSessionAppendArg(cursor), bytes.NewReader(chunk)); err != nil {
+			return b.apiError(err)
 		}
-		return err
+		offset += dropboxUploadChunkSize
+	}
+
+	commit := files.NewCommitInfo(dbPath)
+	commit.Mode = &mode
+	cursor := files.NewUploadSessionCursor(start.SessionId, uint64(offset))
+	if _, err := b.client.UploadSessionFinish(files.NewUploadSessionFinishArg(cursor, commit), bytes.NewReader(data[offset:])); err != nil {
+		return b.uploadError(err)
 	}
 	return nil
 }
 
 func (b *DropboxBackend) Delete(path string) error {
-	arg := files.NewDeleteArg(path)
+	dbPath := dropboxPath(path)
+	if dbPath == "" {
+		return fmt.Errorf("cannot delete root directory")
+	}
+
+	arg := files.NewDeleteArg(dbPath)
 	_, err := b.client.DeleteV2(arg)
 	if err != nil {
-		errStr := err.Error()
-		if strings.Contains(errStr, "missing_scope") {
-			return fmt.Errorf("Dropbox API error: missing required permission scope. Please check your app's permissions and access token. (error: %s)", errStr)
-		}
+		return b.apiError(err)
+	}
+	return nil
+}
+
+func (b *DropboxBackend) Rename(from, to string) error {
+	fromPath, toPath := dropboxPath(from), dropboxPath(to)
+	if fromPath == "" || toPath == "" {
+		return fmt.Errorf("cannot rename root directory")
+	}
+
+	_, err := b.client.MoveV2(files.NewRelocationArg(fromPath, toPath))
+	if err != nil {
+		return b.apiError(err)
+	}
+	return nil
+}
+
+// Watch implements types.Watcher. It keeps a recursive list_folder cursor for
+// the whole Dropbox in the watch state, waits for changes with
+// list_folder/longpoll and reports every changed entry.
+//
+// Dropbox returns the current metadata of changed entries without saying
+// whether they are new, so changed files are reported as modified and
+// changed folders as created.
+func (b *DropboxBackend) Watch(ctx context.Context, state types.WatchState, emit func(types.ChangeEvent)) error {
+	cursor, err := state.Cursor()
+	if err != nil {
 		return err
 	}
+
+	notify := b.notifyClient(ctx)
+	for ctx.Err() == nil {
+		if cursor == "" {
+			arg := files.NewListFolderArg("")
+			arg.Recursive = true
+			res, err := b.client.ListFolderGetLatestCursor(arg)
+			if err != nil {
+				return b.apiError(err)
+			}
+			cursor = res.Cursor
+			if err := state.SetCursor(cursor); err != nil {
+				return err
+			}
+		}
+
+		poll, err := notify.ListFolderLongpoll(files.NewListFolderLongpollArg(cursor))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if isDropboxCursorReset(err) {
+				cursor = b.resetCursor(state, emit)
+				continue
+			}
+			return b.apiError(err)
+		}
+
+		if poll.Changes {
+			cursor, err = b.readChanges(cursor, state, emit)
+			if err != nil {
+				if isDropboxCursorReset(err) {
+					cursor = b.resetCursor(state, emit)
+					continue
+				}
+				return b.apiError(err)
+			}
+		}
+
+		if poll.Backoff > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(poll.Backoff) * time.Second):
+			}
+		}
+	}
+
 	return nil
 }
 
+// readChanges pages through the changes after cursor, emitting an event for
+// each entry and storing the cursor after every page. It returns the latest cursor.
+func (b *DropboxBackend) readChanges(cursor string, state types.WatchState, emit func(types.ChangeEvent)) (string, error) {
+	for {
+		res, err := b.client.ListFolderContinue(files.NewListFolderContinueArg(cursor))
+		if err != nil {
+			return cursor, err
+		}
+
+		for _, entry := range res.Entries {
+			switch f := entry.(type) {
+			case *files.FileMetadata:
+				emit(types.ChangeEvent{Path: f.PathDisplay, Kind: types.ChangeModified})
+			case *files.FolderMetadata:
+				emit(types.ChangeEvent{Path: f.PathDisplay, Kind: types.ChangeCreated, IsDir: true})
+			case *files.DeletedMetadata:
+				emit(types.ChangeEvent{Path: f.PathDisplay, Kind: types.ChangeDeleted})
+			}
+		}
+
+		cursor = res.Cursor
+		if err := state.SetCursor(cursor); err != nil {
+			return cursor, err
+		}
+		if !res.HasMore {
+			return cursor, nil
+		}
+	}
+}
+
+// resetCursor forgets an expired cursor. Changes since it was issued are lost,
+// so the root is reported as modified for clients to list everything again.
+func (b *DropboxBackend) resetCursor(state types.WatchState, emit func(types.ChangeEvent)) string {
+	state.SetCursor("")
+	emit(types.ChangeEvent{Path: "/", Kind: types.ChangeModified, IsDir: true})
+	return ""
+}
+
+// isDropboxCursorReset reports whether the API rejected a cursor as expired
+func isDropboxCursorReset(err error) bool {
+	var longpollErr files.ListFolderLongpollAPIError
+	if errors.As(err, &longpollErr) {
+		return longpollErr.EndpointError != nil && longpollErr.EndpointError.Tag == files.ListFolderLongpollErrorReset
+	}
+	var continueErr files.ListFolderContinueAPIError
+	if errors.As(err, &continueErr) {
+		return continueErr.EndpointError != nil && continueErr.EndpointError.Tag == files.ListFolderContinueErrorReset
+	}
+	return false
+}
+
 func (b *DropboxBackend) Reconnect() error {
 	return b.connect()
 }
@@ -0,0 +1,630 @@
+package disktypes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	files "github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+)
+
+// fakeDropbox is an in-memory Dropbox API server implementing the routes
+// DropboxBackend uses. Like the real API it rejects "/" and trailing slashes
+// in paths.
+type fakeDropbox struct {
+	t        *testing.T
+	server   *httptest.Server
+	pageSize int // Entries per list_folder page
+
+	mu       sync.Mutex
+	entries  map[string]*fakeDropboxEntry // Path -> entry, without the root
+	sessions map[string][]byte            // Upload session ID -> data so far
+	nextRev  int
+	calls    map[string]int // Route -> number of calls
+	// longpolls, when set, is sent the cursor of every longpoll, which then
+	// waits until the client gives up instead of answering
+	longpolls chan string
+}
+
+type fakeDropboxEntry struct {
+	data     []byte
+	dir      bool
+	rev      string
+	modified time.Time
+}
+
+type fakeDropboxWriteMode struct {
+	Tag    string `json:".tag"`
+	Update string `json:"update"`
+}
+
+func newFakeDropbox(t *testing.T) *fakeDropbox {
+	t.Helper()
+	f := &fakeDropbox{
+		t:        t,
+		pageSize: 100,
+		entries:  map[string]*fakeDropboxEntry{},
+		sessions: map[string][]byte{},
+		calls:    map[string]int{},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// backend mounts the fake with a long-lived access token
+func (f *fakeDropbox) backend() *DropboxBackend {
+	f.t.Helper()
+	b, err := DropboxDiskType{}.New(&models.Mount{
+		AccessToken: "test-token",
+		Options:     map[string]string{"api_url": f.server.URL},
+	})
+	if err != nil {
+		f.t.Fatalf("failed to mount fake dropbox: %v", err)
+	}
+	return b.(*DropboxBackend)
+}
+
+func (f *fakeDropbox) put(p string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.putLocked(p, data)
+}
+
+func (f *fakeDropbox) putLocked(p string, data []byte) *fakeDropboxEntry {
+	for dir := path.Dir(p); dir != "/"; dir = path.Dir(dir) {
+		if _, ok := f.entries[dir]; !ok {
+			f.entries[dir] = &fakeDropboxEntry{dir: true}
+		}
+	}
+	f.nextRev++
+	entry := &fakeDropboxEntry{
+		data:     append([]byte(nil), data...),
+		rev:      fmt.Sprintf("%09x", f.nextRev),
+		modified: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	f.entries[p] = entry
+	return entry
+}
+
+func (f *fakeDropbox) callCount(route string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[route]
+}
+
+func (f *fakeDropbox) handle(w http.ResponseWriter, r *http.Request) {
+	route := strings.TrimPrefix(r.URL.Path, "/2/")
+	// The longpoll route is the only one called without a token
+	if got := r.Header.Get("Authorization"); got != "Bearer test-token" && route != "files/list_folder/longpoll" {
+		http.Error(w, "invalid_access_token", http.StatusUnauthorized)
+		return
+	}
+
+	// RPC routes take their argument as the body, upload and download
+	// routes in a header
+	var arg []byte
+	var body []byte
+	if header := r.Header.Get("Dropbox-API-Arg"); header != "" {
+		arg = []byte(header)
+		body, _ = io.ReadAll(r.Body)
+	} else {
+		arg, _ = io.ReadAll(r.Body)
+	}
+
+	f.mu.Lock()
+	longpolls := f.longpolls
+	f.mu.Unlock()
+	if route == "files/list_folder/longpoll" && longpolls != nil {
+		var req struct {
+			Cursor string `json:"cursor"`
+		}
+		json.Unmarshal(arg, &req)
+		longpolls <- req.Cursor
+		<-r.Context().Done()
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[route]++
+
+	var req struct {
+		Path     string                `json:"path"`
+		FromPath string                `json:"from_path"`
+		ToPath   string                `json:"to_path"`
+		Cursor   json.RawMessage       `json:"cursor"`
+		Mode     *fakeDropboxWriteMode `json:"mode"`
+		Commit   *struct {
+			Path string                `json:"path"`
+			Mode *fakeDropboxWriteMode `json:"mode"`
+		} `json:"commit"`
+	}
+	if len(arg) > 0 {
+		if err := json.Unmarshal(arg, &req); err != nil {
+			http.Error(w, "invalid argument: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, p := range []string{req.Path, req.FromPath, req.ToPath} {
+		if p == "/" || (len(p) > 1 && strings.HasSuffix(p, "/")) {
+			http.Error(w, fmt.Sprintf("Error in call to API function %q: invalid path %q", route, p), http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch route {
+	case "files/list_folder":
+		if req.Path != "" {
+			if entry, ok := f.entries[req.Path]; !ok || !entry.dir {
+				f.apiError(w, "path/not_found/")
+				return
+			}
+		}
+		f.listPage(w, req.Path, 0)
+
+	case "files/list_folder/continue":
+		var cursor string
+		json.Unmarshal(req.Cursor, &cursor)
+		dir, offset, ok := strings.Cut(cursor, "|")
+		n, err := strconv.Atoi(offset)
+		if !ok || err != nil {
+			f.apiError(w, "reset/")
+			return
+		}
+		f.listPage(w, dir, n)
+
+	case "files/list_folder/get_latest_cursor":
+		writeJSON(w, map[string]string{"cursor": req.Path + "|0"})
+
+	case "files/list_folder/longpoll":
+		var cursor string
+		json.Unmarshal(req.Cursor, &cursor)
+		if _, _, ok := strings.Cut(cursor, "|"); !ok {
+			f.apiError(w, "reset/")
+			return
+		}
+		writeJSON(w, map[string]interface{}{"changes": false})
+
+	case "files/get_metadata":
+		entry, ok := f.entries[req.Path]
+		if !ok {
+			f.apiError(w, "path/not_found/")
+			return
+		}
+		writeJSON(w, f.metadata(req.Path, entry))
+
+	case "files/download":
+		entry, ok := f.entries[req.Path]
+		if !ok || entry.dir {
+			f.apiError(w, "path/not_found/")
+			return
+		}
+		result, _ := json.Marshal(f.metadata(req.Path, entry))
+		w.Header().Set("Dropbox-API-Result", string(result))
+		w.Write(entry.data)
+
+	case "files/upload":
+		f.commit(w, req.Path, req.Mode, body)
+
+	case "files/upload_session/start":
+		id := fmt.Sprintf("session-%d", len(f.sessions)+1)
+		f.sessions[id] = body
+		writeJSON(w, map[string]string{"session_id": id})
+
+	case "files/upload_session/append_v2", "files/upload_session/finish":
+		var cursor struct {
+			SessionID string `json:"session_id"`
+			Offset    int    `json:"offset"`
+		}
+		json.Unmarshal(req.Cursor, &cursor)
+		data, ok := f.sessions[cursor.SessionID]
+		if !ok {
+			f.apiError(w, "lookup_failed/not_found/")
+			return
+		}
+		if cursor.Offset != len(data) {
+			f.apiError(w, "lookup_failed/incorrect_offset/")
+			return
+		}
+		data = append(data, body...)
+		f.sessions[cursor.SessionID] = data
+		if route == "files/upload_session/append_v2" {
+			writeJSON(w, nil)
+			return
+		}
+		delete(f.sessions, cursor.SessionID)
+		f.commit(w, req.Commit.Path, req.Commit.Mode, data)
+
+	case "files/delete_v2":
+		entry, ok := f.entries[req.Path]
+		if !ok {
+			f.apiError(w, "path_lookup/not_found/")
+			return
+		}
+		for p := range f.entries {
+			if p == req.Path || strings.HasPrefix(p, req.Path+"/") {
+				delete(f.entries, p)
+			}
+		}
+		writeJSON(w, map[string]interface{}{"metadata": f.metadata(req.Path, entry)})
+
+	case "files/move_v2":
+		entry, ok := f.entries[req.FromPath]
+		if !ok {
+			f.apiError(w, "from_lookup/not_found/")
+			return
+		}
+		if _, exists := f.entries[req.ToPath]; exists {
+			f.apiError(w, "to/conflict/file/")
+			return
+		}
+		for p, e := range f.entries {
+			if p == req.FromPath || strings.HasPrefix(p, req.FromPath+"/") {
+				delete(f.entries, p)
+				f.entries[req.ToPath+strings.TrimPrefix(p, req.FromPath)] = e
+			}
+		}
+		writeJSON(w, map[string]interface{}{"metadata": f.metadata(req.ToPath, entry)})
+
+	default:
+		http.Error(w, "unknown route "+route, http.StatusNotFound)
+	}
+}
+
+// commit stores an uploaded file, checking the rev for update mode
+func (f *fakeDropbox) commit(w http.ResponseWriter, p string, mode *fakeDropboxWriteMode, data []byte) {
+	if mode != nil && mode.Tag == "update" {
+		if existing, ok := f.entries[p]; !ok || existing.rev != mode.Update {
+			f.apiError(w, "path/conflict/file/")
+			return
+		}
+	}
+	writeJSON(w, f.metadata(p, f.putLocked(p, data)))
+}
+
+// listPage returns the children of dir from offset, with a cursor for the
+// next page
+func (f *fakeDropbox) listPage(w http.ResponseWriter, dir string, offset int) {
+	parent := dir
+	if parent == "" {
+		parent = "/"
+	}
+	var names []string
+	for p := range f.entries {
+		if path.Dir(p) == parent {
+			names = append(names, p)
+		}
+	}
+	sort.Strings(names)
+	if offset > len(names) {
+		offset = len(names)
+	}
+	end := offset + f.pageSize
+	if end > len(names) {
+		end = len(names)
+	}
+
+	entries := []interface{}{}
+	for _, p := range names[offset:end] {
+		entries = append(entries, f.metadata(p, f.entries[p]))
+	}
+	writeJSON(w, map[string]interface{}{
+		"entries":  entries,
+		"cursor":   dir + "|" + strconv.Itoa(end),
+		"has_more": end < len(names),
+	})
+}
+
+func (f *fakeDropbox) metadata(p string, entry *fakeDropboxEntry) map[string]interface{} {
+	m := map[string]interface{}{
+		"name":         path.Base(p),
+		"path_display": p,
+		"path_lower":   strings.ToLower(p),
+		"id":           "id:" + p,
+	}
+	if entry.dir {
+		m[".tag"] = "folder"
+		return m
+	}
+	m[".tag"] = "file"
+	m["size"] = len(entry.data)
+	m["rev"] = entry.rev
+	m["client_modified"] = entry.modified.Format(time.RFC3339)
+	m["server_modified"] = entry.modified.Format(time.RFC3339)
+	return m
+}
+
+// apiError answers with an endpoint error, which the API sends as a 409
+func (f *fakeDropbox) apiError(w http.ResponseWriter, summary string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error_summary": summary,
+		"error":         map[string]interface{}{".tag": strings.Split(summary, "/")[0]},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestDropboxPath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"/", ""},
+		{".", ""},
+		{"//", ""},
+		{"a", "/a"},
+		{"/a/", "/a"},
+		{"/a//b/", "/a/b"},
+		{"/a/./b", "/a/b"},
+		{"/a/../b", "/b"},
+		{"/../a", "/a"},
+	}
+	for _, tt := range tests {
+		if got := dropboxPath(tt.in); got != tt.want {
+			t.Errorf("dropboxPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDropboxListPaginates(t *testing.T) {
+	fake := newFakeDropbox(t)
+	fake.pageSize = 2
+	for i := 0; i < 5; i++ {
+		fake.put(fmt.Sprintf("/file%d.txt", i), []byte("data"))
+	}
+	fake.put("/dir/nested.txt", []byte("nested"))
+	b := fake.backend()
+
+	files, err := b.List("/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(files) != 6 {
+		t.Fatalf("List returned %d entries, want 6: %+v", len(files), files)
+	}
+	if files[0].Name != "dir" || !files[0].IsDir {
+		t.Errorf("first entry = %+v, want the dir folder", files[0])
+	}
+	if got := fake.callCount("files/list_folder/continue"); got != 2 {
+		t.Errorf("list_folder/continue called %d times, want 2", got)
+	}
+
+	files, err = b.List("/dir/")
+	if err != nil {
+		t.Fatalf("List of a subfolder failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "nested.txt" || files[0].Size != 6 {
+		t.Fatalf("List of a subfolder = %+v, want nested.txt", files)
+	}
+}
+
+func TestDropboxReadWrite(t *testing.T) {
+	fake := newFakeDropbox(t)
+	b := fake.backend()
+
+	if err := b.Write("/docs/a.txt", []byte("hello")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, err := b.Read("docs/a.txt")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("Read = %q, want hello", data)
+	}
+
+	info, err := b.Stat("/docs/a.txt")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Name != "a.txt" || info.Size != 5 || info.IsDir || info.ETag == "" || info.ModTime.IsZero() {
+		t.Errorf("Stat = %+v", info)
+	}
+	if info, err := b.Stat("/"); err != nil || !info.IsDir {
+		t.Errorf("Stat of the root = %+v, %v, want a directory", info, err)
+	}
+	if _, err := b.Stat("/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a missing file error = %v, want fs.ErrNotExist", err)
+	}
+
+	if err := b.Rename("/docs/a.txt", "/docs/b.txt"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if _, err := b.Read("/docs/b.txt"); err != nil {
+		t.Fatalf("Read of the renamed file failed: %v", err)
+	}
+	if err := b.Delete("/docs/b.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := b.Stat("/docs/b.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat after Delete error = %v, want fs.ErrNotExist", err)
+	}
+
+	if err := b.Write("/", []byte("x")); err == nil {
+		t.Errorf("Write to the root succeeded")
+	}
+	if err := b.Delete("/"); err == nil {
+		t.Errorf("Delete of the root succeeded")
+	}
+}
+
+func TestDropboxLargeUpload(t *testing.T) {
+	fake := newFakeDropbox(t)
+	b := fake.backend()
+
+	data := make([]byte, 2*dropboxUploadChunkSize+123)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	if err := b.Write("/big.bin", data); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := fake.callCount("files/upload"); got != 0 {
+		t.Errorf("single upload called %d times, want an upload session", got)
+	}
+	if got := fake.callCount("files/upload_session/append_v2"); got != 1 {
+		t.Errorf("upload_session/append_v2 called %d times, want 1", got)
+	}
+	if got := fake.callCount("files/upload_session/finish"); got != 1 {
+		t.Errorf("upload_session/finish called %d times, want 1", got)
+	}
+
+	read, err := b.Read("/big.bin")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Fatalf("uploaded file differs, got %d bytes want %d", len(read), len(data))
+	}
+
+	// A file of exactly one chunk still fits a single upload
+	if err := b.Write("/chunk.bin", data[:dropboxUploadChunkSize]); err != nil {
+		t.Fatalf("Write of one chunk failed: %v", err)
+	}
+	if got := fake.callCount("files/upload"); got != 1 {
+		t.Errorf("single upload called %d times, want 1", got)
+	}
+}
+
+func TestDropboxWriteIfMatch(t *testing.T) {
+	fake := newFakeDropbox(t)
+	b := fake.backend()
+
+	if err := b.Write("/a.txt", []byte("v1")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	info, err := b.Stat("/a.txt")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if err := b.WriteIfMatch("/a.txt", []byte("v2"), info.ETag); err != nil {
+		t.Fatalf("WriteIfMatch with the current rev failed: %v", err)
+	}
+	if err := b.WriteIfMatch("/a.txt", []byte("v3"), info.ETag); !errors.Is(err, types.ErrConflict) {
+		t.Fatalf("WriteIfMatch with an old rev error = %v, want ErrConflict", err)
+	}
+	if data, _ := b.Read("/a.txt"); string(data) != "v2" {
+		t.Fatalf("file = %q after a conflict, want v2", data)
+	}
+}
+
+// fakeWatchState keeps the watch cursor in memory
+type fakeWatchState struct {
+	mu     sync.Mutex
+	cursor string
+}
+
+func (s *fakeWatchState) Cursor() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor, nil
+}
+
+func (s *fakeWatchState) SetCursor(cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor = cursor
+	return nil
+}
+
+func TestIsDropboxCursorReset(t *testing.T) {
+	fake := newFakeDropbox(t)
+	b := fake.backend()
+
+	_, err := b.client.ListFolderContinue(files.NewListFolderContinueArg("expired"))
+	if err == nil || !isDropboxCursorReset(err) {
+		t.Errorf("list_folder/continue error %v is not a cursor reset", err)
+	}
+	_, err = b.notifyClient(context.Background()).ListFolderLongpoll(files.NewListFolderLongpollArg("expired"))
+	if err == nil || !isDropboxCursorReset(err) {
+		t.Errorf("list_folder/longpoll error %v is not a cursor reset", err)
+	}
+
+	// Other errors keep the cursor, even when their text mentions a reset
+	_, err = b.client.ListFolder(files.NewListFolderArg("/missing"))
+	if err == nil || isDropboxCursorReset(err) {
+		t.Errorf("list_folder error %v is a cursor reset", err)
+	}
+	if err := errors.New("read tcp: connection reset by peer"); isDropboxCursorReset(err) {
+		t.Errorf("network error %v is a cursor reset", err)
+	}
+}
+
+func TestDropboxWatchResetsExpiredCursor(t *testing.T) {
+	fake := newFakeDropbox(t)
+	b := fake.backend()
+	state := &fakeWatchState{cursor: "expired"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var events []types.ChangeEvent
+	err := b.Watch(ctx, state, func(event types.ChangeEvent) {
+		events = append(events, event)
+		cancel()
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Path != "/" || events[0].Kind != types.ChangeModified {
+		t.Fatalf("events = %+v, want the root reported as modified", events)
+	}
+	if cursor, _ := state.Cursor(); cursor != "" {
+		t.Errorf("cursor = %q after a reset, want it cleared", cursor)
+	}
+}
+
+func TestDropboxWatchStopsDuringLongpoll(t *testing.T) {
+	fake := newFakeDropbox(t)
+	fake.longpolls = make(chan string, 1)
+	// Don't let a longpoll that is never cancelled hold up closing the server
+	t.Cleanup(fake.server.CloseClientConnections)
+	b := fake.backend()
+	state := &fakeWatchState{cursor: "|0"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Watch(ctx, state, func(types.ChangeEvent) {})
+	}()
+
+	select {
+	case cursor := <-fake.longpolls:
+		if cursor != "|0" {
+			t.Errorf("longpoll cursor = %q, want the stored one", cursor)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch never started a longpoll")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Watch returned %v after being cancelled, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch didn't return while the longpoll was waiting")
+	}
+}
@@ -42,7 +42,7 @@ func (FTPDiskType) Description() string {
 }
 
 func (FTPDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
-	return types.DiskTypeConfigTemplate{
+	return withCacheFields(withPollingFields(types.DiskTypeConfigTemplate{
 		"host": types.DiskTypeConfigField{
 			Type:        "string",
 			Description: "Remote FTP server hostname",
@@ -73,7 +73,7 @@ func (FTPDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
 			Description: "Enable FTPS (TLS) connection",
 			Required:    false,
 		},
-	}
+	}))
 }
 
 func (b *FTPBackend) connect() error {
@@ -133,7 +133,7 @@ func (b *FTPBackend) withReconnect(op func() error) error {
 func (b *FTPBackend) List(path string) ([]types.FileInfo, error) {
 	var result []types.FileInfo
 	err := b.withReconnect(func() error {
-		absPath := b.path + path
+		absPath := joinRemote(b.path, path)
 		entries, err := b.client.List(absPath)
 		if err != nil {
 			return err
@@ -144,9 +144,10 @@ func (b *FTPBackend) List(path string) ([]types.FileInfo, error) {
 				continue
 			}
 			out = append(out, types.FileInfo{
-				Name:  e.Name,
-				IsDir: e.Type == ftp.EntryTypeFolder,
-				Size:  int64(e.Size),
+				Name:    e.Name,
+				IsDir:   e.Type == ftp.EntryTypeFolder,
+				Size:    int64(e.Size),
+				ModTime: e.Time,
 			})
 		}
 		result = out
@@ -158,7 +159,7 @@ func (b *FTPBackend) List(path string) ([]types.FileInfo, error) {
 func (b *FTPBackend) Read(path string) ([]byte, error) {
 	var data []byte
 	err := b.withReconnect(func() error {
-		absPath := b.path + path
+		absPath := joinRemote(b.path, path)
 		r, err := b.client.Retr(absPath)
 		if err != nil {
 			return err
@@ -172,18 +173,24 @@ func (b *FTPBackend) Read(path string) ([]byte, error) {
 
 func (b *FTPBackend) Write(path string, data []byte) error {
 	return b.withReconnect(func() error {
-		absPath := b.path + path
+		absPath := joinRemote(b.path, path)
 		return b.client.Stor(absPath, strings.NewReader(string(data)))
 	})
 }
 
 func (b *FTPBackend) Delete(path string) error {
 	return b.withReconnect(func() error {
-		absPath := b.path + path
+		absPath := joinRemote(b.path, path)
 		return b.client.Delete(absPath)
 	})
 }
 
+func (b *FTPBackend) Rename(from, to string) error {
+	return b.withReconnect(func() error {
+		return b.client.Rename(joinRemote(b.path, from), joinRemote(b.path, to))
+	})
+}
+
 func (b *FTPBackend) Reconnect() error {
 	return b.connect()
 }
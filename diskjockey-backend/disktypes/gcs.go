@@ -0,0 +1,635 @@
+package disktypes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+// GCSDiskType implements DiskType for mounting a Google Cloud Storage
+// bucket, or an emulator like fake-gcs-server, with the JSON API. Objects
+// are files and the prefixes of their names up to a "/" are directories.
+
+type GCSDiskType struct{}
+
+type GCSBackend struct {
+	mount     *models.Mount
+	client    *http.Client
+	bucket    string
+	api       string // URL of the bucket in the JSON API
+	upload    string // URL of the bucket in the upload API
+	prefix    string // Object name prefix of the mount root, without slashes around it
+	chunkSize int
+}
+
+const (
+	gcsDefaultEndpoint = "https://storage.googleapis.com"
+	gcsScope           = "https://www.googleapis.com/auth/devstorage.read_write"
+	// Writes larger than the chunk size use a resumable upload, sent in
+	// chunks of that size, which must be a multiple of 256 KiB
+	gcsDefaultChunkSize = 16 << 20
+	// Times an upload chunk is retried
+	gcsMaxRetries = 3
+)
+
+func (GCSDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &GCSBackend{mount: mount}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (GCSDiskType) Name() string {
+	return "gcs"
+}
+
+func (GCSDiskType) Description() string {
+	return "Google Cloud Storage bucket"
+}
+
+func (GCSDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return withCacheFields(types.DiskTypeConfigTemplate{
+		"bucket": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Name of the bucket to mount",
+			Required:    true,
+		},
+		"prefix": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Object name prefix to mount instead of the whole bucket (e.g. assets/2024)",
+			Required:    false,
+		},
+		"service_account": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Path to the JSON key file of a service account, leave empty for anonymous access",
+			Required:    false,
+		},
+		"access_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "OAuth2 access token, used when no service account is set",
+			Required:    false,
+		},
+		"endpoint": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Storage endpoint (default " + gcsDefaultEndpoint + ", e.g. http://localhost:4443 for fake-gcs-server)",
+			Required:    false,
+		},
+		"chunk_size": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Size in MiB of the chunks larger files are uploaded in (default 16)",
+			Required:    false,
+		},
+	})
+}
+
+func (b *GCSBackend) connect() error {
+	b.bucket = b.mount.Option("bucket")
+	if b.bucket == "" {
+		return fmt.Errorf("gcs: missing required config 'bucket'")
+	}
+	b.prefix = strings.Trim(path.Clean("/"+b.mount.Option("prefix")), "/")
+
+	endpoint := strings.TrimSuffix(b.mount.Option("endpoint"), "/")
+	if endpoint == "" {
+		endpoint = gcsDefaultEndpoint
+	}
+	if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("gcs: invalid endpoint %q", endpoint)
+	}
+	b.api = endpoint + "/storage/v1/b/" + url.PathEscape(b.bucket)
+	b.upload = endpoint + "/upload/storage/v1/b/" + url.PathEscape(b.bucket)
+
+	b.chunkSize = gcsDefaultChunkSize
+	if raw := b.mount.Option("chunk_size"); raw != "" {
+		mib, err := strconv.Atoi(raw)
+		if err != nil || mib <= 0 {
+			return fmt.Errorf("gcs: invalid chunk_size %q", raw)
+		}
+		b.chunkSize = mib << 20
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	var client http.RoundTripper = transport
+	if keyFile := b.mount.Option("service_account"); keyFile != "" {
+		tokens, err := gcsServiceAccount(keyFile)
+		if err != nil {
+			return err
+		}
+		client = &oauth2.Transport{Source: tokens, Base: transport}
+	} else if token := b.mount.AccessToken; token != "" {
+		client = &oauth2.Transport{Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), Base: transport}
+	}
+	b.client = &http.Client{Transport: client}
+
+	// Fail the mount early if the bucket is missing or access is denied
+	err := b.do(http.MethodGet, b.api+"?fields=name", "/", nil, nil)
+	if hasGCSStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("gcs: bucket %q does not exist", b.bucket)
+	}
+	return err
+}
+
+// gcsServiceAccount returns a token source signing in as the service account
+// of a JSON key file.
+func gcsServiceAccount(keyFile string) (oauth2.TokenSource, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("gcs: failed to read service_account: %w", err)
+	}
+	var key struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("gcs: invalid service_account key file: %w", err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("gcs: %s is not a service account key file", keyFile)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	config := &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       []string{gcsScope},
+		TokenURL:     key.TokenURI,
+	}
+	return config.TokenSource(context.Background()), nil
+}
+
+// name returns the object name of a mount path, "" for the root of the mount
+// when the whole bucket is mounted.
+func (b *GCSBackend) name(p string) string {
+	return strings.TrimPrefix(joinRemote("/"+b.prefix, p), "/")
+}
+
+// dirPrefix returns the object name prefix of the objects in a directory.
+func (b *GCSBackend) dirPrefix(p string) string {
+	if name := b.name(p); name != "" {
+		return name + "/"
+	}
+	return ""
+}
+
+// objectURL returns the JSON API URL of an object, whose name is a single
+// path segment there.
+func (b *GCSBackend) objectURL(name string) string {
+	return b.api + "/o/" + url.PathEscape(name)
+}
+
+// gcsError is returned for a response that isn't a success. It wraps the
+// sentinel error matching the status, if there is one.
+type gcsError struct {
+	status  int
+	message string
+}
+
+func (e *gcsError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("gcs: server responded %d %s", e.status, http.StatusText(e.status))
+	}
+	return fmt.Sprintf("gcs: %s (%d)", e.message, e.status)
+}
+
+func (e *gcsError) Unwrap() error {
+	switch e.status {
+	case http.StatusNotFound:
+		return fs.ErrNotExist
+	case http.StatusPreconditionFailed:
+		return types.ErrConflict
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return types.ErrOffline
+	default:
+		return nil
+	}
+}
+
+// hasGCSStatus reports whether err is for a response with one of the statuses
+func hasGCSStatus(err error, statuses ...int) bool {
+	var gcsErr *gcsError
+	if !errors.As(err, &gcsErr) {
+		return false
+	}
+	for _, status := range statuses {
+		if gcsErr.status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// send makes a request and returns the response of a success.
+func (b *GCSBackend) send(method, u, p string, header http.Header, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+
+	statusErr := &gcsError{status: resp.StatusCode}
+	var errBody struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&errBody) == nil {
+		statusErr.message = errBody.Error.Message
+	}
+	resp.Body.Close()
+	return nil, &fs.PathError{Op: strings.ToLower(method), Path: p, Err: statusErr}
+}
+
+// do makes a JSON API request and decodes the response into out, if it
+// isn't nil.
+func (b *GCSBackend) do(method, u, p string, in, out any) error {
+	var header http.Header
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+		header = http.Header{"Content-Type": {"application/json"}}
+	}
+	resp, err := b.send(method, u, p, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// gcsObject is the part of the metadata of an object the backend uses
+type gcsObject struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size,string"`
+	Updated    time.Time `json:"updated"`
+	Generation string    `json:"generation"`
+}
+
+// info describes an object. The generation, which changes with every write,
+// is its ETag, so conditional writes can use it.
+func (o *gcsObject) info(name string) types.FileInfo {
+	return types.FileInfo{
+		Name:    name,
+		Size:    o.Size,
+		ModTime: o.Updated,
+		ETag:    o.Generation,
+	}
+}
+
+// gcsObjectList is a page of the objects.list operation
+type gcsObjectList struct {
+	Items         []gcsObject `json:"items"`
+	Prefixes      []string    `json:"prefixes"`
+	NextPageToken string      `json:"nextPageToken"`
+}
+
+// list returns a page of the objects whose names start with prefix. With the
+// delimiter, objects below the next "/" are grouped into prefixes.
+func (b *GCSBackend) list(prefix, delimiter, pageToken string, max int, p string) (*gcsObjectList, error) {
+	query := url.Values{"fields": {"items(name,size,updated,generation),prefixes,nextPageToken"}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	if pageToken != "" {
+		query.Set("pageToken", pageToken)
+	}
+	if max > 0 {
+		query.Set("maxResults", strconv.Itoa(max))
+	}
+	var page gcsObjectList
+	if err := b.do(http.MethodGet, b.api+"/o?"+query.Encode(), p, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (b *GCSBackend) List(p string) ([]types.FileInfo, error) {
+	prefix := b.dirPrefix(p)
+
+	var out []types.FileInfo
+	pageToken, placeholder := "", false
+	for {
+		page, err := b.list(prefix, "/", pageToken, 0, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range page.Prefixes {
+			out = append(out, types.FileInfo{Name: strings.TrimSuffix(strings.TrimPrefix(dir, prefix), "/"), IsDir: true})
+		}
+		for i := range page.Items {
+			name := strings.TrimPrefix(page.Items[i].Name, prefix)
+			if name == "" {
+				// The empty object some tools create to keep a directory
+				placeholder = true
+				continue
+			}
+			out = append(out, page.Items[i].info(name))
+		}
+		if pageToken = page.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	// Directories only exist while there are objects below them
+	if len(out) == 0 && !placeholder && b.name(p) != b.prefix {
+		return nil, &fs.PathError{Op: "list", Path: p, Err: fs.ErrNotExist}
+	}
+	return out, nil
+}
+
+func (b *GCSBackend) Stat(p string) (types.FileInfo, error) {
+	name := b.name(p)
+	if name == b.prefix {
+		return types.FileInfo{Name: "/", IsDir: true}, nil
+	}
+
+	var obj gcsObject
+	err := b.do(http.MethodGet, b.objectURL(name)+"?fields=name,size,updated,generation", p, nil, &obj)
+	if err == nil {
+		return obj.info(path.Base(name)), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return types.FileInfo{}, err
+	}
+
+	// Not an object, but it's a directory if any object name starts with it
+	page, err := b.list(name+"/", "", "", 1, p)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	if len(page.Items) > 0 {
+		return types.FileInfo{Name: path.Base(name), IsDir: true}, nil
+	}
+	return types.FileInfo{}, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+}
+
+func (b *GCSBackend) Read(p string) ([]byte, error) {
+	resp, err := b.send(http.MethodGet, b.objectURL(b.name(p))+"?alt=media", p, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// ReadRange implements types.RangeReader with a ranged download.
+func (b *GCSBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	if length == 0 {
+		return []byte{}, nil
+	}
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}}
+	resp, err := b.send(http.MethodGet, b.objectURL(b.name(p))+"?alt=media", p, header, nil)
+	if hasGCSStatus(err, http.StatusRequestedRangeNotSatisfiable) {
+		// The range starts past the end of the object
+		return []byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPartialContent {
+		return io.ReadAll(io.LimitReader(resp.Body, length))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return types.SliceRange(data, offset, length), nil
+}
+
+func (b *GCSBackend) Write(p string, data []byte) error {
+	return b.write(p, data, "")
+}
+
+// WriteIfMatch writes the object only if its current generation is etag.
+func (b *GCSBackend) WriteIfMatch(p string, data []byte, etag string) error {
+	return b.write(p, data, etag)
+}
+
+// write uploads data in a single request, or with a resumable upload in
+// chunks of chunkSize when it's larger than that.
+func (b *GCSBackend) write(p string, data []byte, generation string) error {
+	name := b.name(p)
+	if name == b.prefix {
+		return fmt.Errorf("cannot write to root directory")
+	}
+	query := url.Values{"name": {name}, "fields": {"generation"}}
+	if generation != "" {
+		query.Set("ifGenerationMatch", generation)
+	}
+
+	if len(data) <= b.chunkSize {
+		query.Set("uploadType", "media")
+		header := http.Header{"Content-Type": {"application/octet-stream"}}
+		resp, err := b.send(http.MethodPost, b.upload+"/o?"+query.Encode(), p, header, data)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	query.Set("uploadType", "resumable")
+	total := int64(len(data))
+	header := http.Header{
+		"Content-Type":            {"application/json; charset=UTF-8"},
+		"X-Upload-Content-Length": {strconv.FormatInt(total, 10)},
+	}
+	resp, err := b.send(http.MethodPost, b.upload+"/o?"+query.Encode(), p, header, []byte("{}"))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	session := resp.Header.Get("Location")
+	if session == "" {
+		return fmt.Errorf("gcs: upload of %s returned no session", p)
+	}
+	return b.resumableUpload(session, p, data)
+}
+
+// resumableUpload sends data to an upload session. When a chunk fails, the
+// session is asked which bytes it has and the upload resumes from there.
+func (b *GCSBackend) resumableUpload(session, p string, data []byte) error {
+	total := int64(len(data))
+	offset, failures := int64(0), 0
+	for {
+		end := min(offset+int64(b.chunkSize), total)
+		header := http.Header{"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", offset, end-1, total)}}
+		resp, err := b.send(http.MethodPut, session, p, header, data[offset:end])
+		if err != nil {
+			var gcsErr *gcsError
+			if (errors.As(err, &gcsErr) && gcsErr.status < http.StatusInternalServerError) || failures >= gcsMaxRetries {
+				// The session expired or the upload was refused, or it keeps failing
+				return err
+			}
+			failures++
+			fmt.Fprintf(os.Stderr, "[GCSBackend] Upload of %s failed at byte %d, resuming: %v\n", p, offset, err)
+			header := http.Header{"Content-Range": {fmt.Sprintf("bytes */%d", total)}}
+			if resp, err = b.send(http.MethodPut, session, p, header, nil); err != nil {
+				return err
+			}
+		} else {
+			failures = 0
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusPermanentRedirect {
+			// The upload is complete
+			return nil
+		}
+		// 308 Resume Incomplete says which bytes the session has
+		offset = 0
+		if received := resp.Header.Get("Range"); received != "" {
+			_, last, _ := strings.Cut(received, "-")
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil {
+				return fmt.Errorf("gcs: upload of %s returned range %q", p, received)
+			}
+			offset = n + 1
+		}
+	}
+}
+
+// Delete removes an object, or every object below a directory.
+func (b *GCSBackend) Delete(p string) error {
+	info, err := b.Stat(p)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir {
+		return b.do(http.MethodDelete, b.objectURL(b.name(p)), p, nil, nil)
+	}
+	if b.name(p) == b.prefix {
+		return fmt.Errorf("cannot delete root directory")
+	}
+
+	names, err := b.namesBelow(b.dirPrefix(p), p)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := b.do(http.MethodDelete, b.objectURL(name), p, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namesBelow returns the names of every object whose name starts with prefix.
+func (b *GCSBackend) namesBelow(prefix, p string) ([]string, error) {
+	var names []string
+	pageToken := ""
+	for {
+		page, err := b.list(prefix, "", pageToken, 0, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Items {
+			names = append(names, obj.Name)
+		}
+		if pageToken = page.NextPageToken; pageToken == "" {
+			return names, nil
+		}
+	}
+}
+
+// Rename copies objects to their new names on the server and removes the
+// originals. Cloud Storage has no rename, so moving a directory copies every
+// object below it.
+func (b *GCSBackend) Rename(from, to string) error {
+	info, err := b.Stat(from)
+	if err != nil {
+		return err
+	}
+	if b.name(from) == b.prefix || b.name(to) == b.prefix {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	if !info.IsDir {
+		return b.move(b.name(from), b.name(to), from)
+	}
+
+	fromPrefix, toPrefix := b.dirPrefix(from), b.dirPrefix(to)
+	if strings.HasPrefix(toPrefix, fromPrefix) {
+		return fmt.Errorf("cannot move %s into itself", from)
+	}
+	names, err := b.namesBelow(fromPrefix, from)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := b.move(name, toPrefix+strings.TrimPrefix(name, fromPrefix), from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// move rewrites an object to another name and removes the original. Large
+// objects are rewritten over several calls.
+func (b *GCSBackend) move(fromName, toName, p string) error {
+	u := b.objectURL(fromName) + "/rewriteTo/b/" + url.PathEscape(b.bucket) + "/o/" + url.PathEscape(toName)
+	query := url.Values{"fields": {"done,rewriteToken"}}
+	for {
+		var rewrite struct {
+			Done         bool   `json:"done"`
+			RewriteToken string `json:"rewriteToken"`
+		}
+		if err := b.do(http.MethodPost, u+"?"+query.Encode(), p, nil, &rewrite); err != nil {
+			return err
+		}
+		if rewrite.Done {
+			break
+		}
+		query.Set("rewriteToken", rewrite.RewriteToken)
+	}
+	return b.do(http.MethodDelete, b.objectURL(fromName), p, nil, nil)
+}
+
+func (b *GCSBackend) Reconnect() error {
+	return b.connect()
+}
@@ -0,0 +1,491 @@
+package disktypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// fakeGCS is an in-memory Cloud Storage JSON API server, like
+// fake-gcs-server, holding the "test" bucket. Requests must carry the
+// access token "test-token".
+type fakeGCS struct {
+	t        *testing.T
+	server   *httptest.Server
+	pageSize int // Objects and prefixes per page of a listing
+
+	mu         sync.Mutex
+	objects    map[string]*fakeGCSObject
+	generation int64
+	sessions   map[string]*fakeGCSSession // Resumable upload session ID -> upload
+	failChunk  bool                       // Fail the next upload chunk once
+	chunks     int                        // Upload chunks received
+	rewrites   int                        // Rewrite calls each object takes
+}
+
+type fakeGCSObject struct {
+	data       []byte
+	generation int64
+	updated    time.Time
+}
+
+type fakeGCSSession struct {
+	name       string
+	generation string // Generation the object must have, if any
+	total      int
+	data       []byte
+}
+
+const fakeGCSBucket = "test"
+
+func newFakeGCS(t *testing.T) *fakeGCS {
+	t.Helper()
+	f := &fakeGCS{
+		t:        t,
+		pageSize: 100,
+		objects:  map[string]*fakeGCSObject{},
+		sessions: map[string]*fakeGCSSession{},
+		rewrites: 1,
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// mount mounts the bucket below prefix with the access token and the
+// options given
+func (f *fakeGCS) mount(prefix string, opts map[string]string) types.Backend {
+	f.t.Helper()
+	options := map[string]string{"bucket": fakeGCSBucket, "prefix": prefix, "endpoint": f.server.URL}
+	for key, value := range opts {
+		options[key] = value
+	}
+	return mustNew(f.t, GCSDiskType{}, &models.Mount{AccessToken: "test-token", Options: options})
+}
+
+func (f *fakeGCS) put(name string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.putLocked(name, data)
+}
+
+func (f *fakeGCS) putLocked(name string, data []byte) *fakeGCSObject {
+	f.generation++
+	obj := &fakeGCSObject{
+		data:       append([]byte(nil), data...),
+		generation: f.generation,
+		updated:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	f.objects[name] = obj
+	return obj
+}
+
+func (f *fakeGCS) get(name string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, ok := f.objects[name]
+	if !ok {
+		return nil, false
+	}
+	return obj.data, true
+}
+
+func (f *fakeGCS) names() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(f.objects))
+	for name := range f.objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *fakeGCS) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id, ok := strings.CutPrefix(r.URL.Path, "/upload/session/"); ok {
+		f.uploadChunk(w, r, id)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		f.gcsError(w, http.StatusUnauthorized, "Anonymous caller does not have storage.objects.list access")
+		return
+	}
+
+	// Object names are a single path segment, escaped
+	segments := strings.Split(r.URL.EscapedPath(), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			f.gcsError(w, http.StatusBadRequest, "Invalid path")
+			return
+		}
+		segments[i] = unescaped
+	}
+	upload := len(segments) > 1 && segments[1] == "upload"
+	if upload {
+		segments = append(segments[:1], segments[2:]...)
+	}
+	if len(segments) < 5 || segments[1] != "storage" || segments[2] != "v1" || segments[3] != "b" {
+		f.gcsError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	if segments[4] != fakeGCSBucket {
+		f.gcsError(w, http.StatusNotFound, "The specified bucket does not exist.")
+		return
+	}
+	route := segments[5:]
+	query := r.URL.Query()
+
+	switch {
+	case upload && r.Method == http.MethodPost && len(route) == 1 && route[0] == "o":
+		f.upload(w, r)
+	case len(route) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, map[string]any{"name": fakeGCSBucket})
+	case len(route) == 1 && route[0] == "o" && r.Method == http.MethodGet:
+		f.list(w, query)
+	case len(route) == 2 && route[0] == "o":
+		f.object(w, r, route[1])
+	case len(route) == 7 && route[0] == "o" && route[2] == "rewriteTo" && route[3] == "b" && route[5] == "o" && r.Method == http.MethodPost:
+		if route[4] != fakeGCSBucket {
+			f.gcsError(w, http.StatusNotFound, "The specified bucket does not exist.")
+			return
+		}
+		f.rewrite(w, route[1], route[6], query.Get("rewriteToken"))
+	default:
+		f.gcsError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// list returns a page of the objects with a prefix. With the delimiter,
+// objects below the next "/" are grouped into prefixes.
+func (f *fakeGCS) list(w http.ResponseWriter, query url.Values) {
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	limit := f.pageSize
+	if max, err := strconv.Atoi(query.Get("maxResults")); err == nil && max < limit {
+		limit = max
+	}
+
+	var names []string
+	for name := range f.objects {
+		if strings.HasPrefix(name, prefix) && name >= query.Get("pageToken") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	items, prefixes := []any{}, []string{}
+	page := map[string]any{}
+	count := 0
+	for _, name := range names {
+		grouped := ""
+		if i := strings.Index(name[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+			grouped = name[:len(prefix)+i+len(delimiter)]
+			if len(prefixes) > 0 && prefixes[len(prefixes)-1] == grouped {
+				continue
+			}
+		}
+		if count == limit {
+			page["nextPageToken"] = name
+			break
+		}
+		count++
+		if grouped != "" {
+			prefixes = append(prefixes, grouped)
+		} else {
+			items = append(items, f.objects[name].json(name))
+		}
+	}
+	page["items"] = items
+	page["prefixes"] = prefixes
+	writeJSON(w, page)
+}
+
+func (f *fakeGCS) object(w http.ResponseWriter, r *http.Request, name string) {
+	obj, ok := f.objects[name]
+	if !ok {
+		f.gcsError(w, http.StatusNotFound, "No such object: "+fakeGCSBucket+"/"+name)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("alt") == "media":
+		http.ServeContent(w, r, "", obj.updated, bytes.NewReader(obj.data))
+	case r.Method == http.MethodGet:
+		writeJSON(w, obj.json(name))
+	case r.Method == http.MethodDelete:
+		delete(f.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.gcsError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// rewrite copies an object over rewrites calls, like large objects are
+func (f *fakeGCS) rewrite(w http.ResponseWriter, from, to, token string) {
+	obj, ok := f.objects[from]
+	if !ok {
+		f.gcsError(w, http.StatusNotFound, "No such object: "+fakeGCSBucket+"/"+from)
+		return
+	}
+	call := 1
+	if token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil {
+			f.gcsError(w, http.StatusBadRequest, "Invalid rewrite token")
+			return
+		}
+		call = n + 1
+	}
+	if call < f.rewrites {
+		writeJSON(w, map[string]any{"done": false, "rewriteToken": strconv.Itoa(call)})
+		return
+	}
+	f.putLocked(to, obj.data)
+	writeJSON(w, map[string]any{"done": true})
+}
+
+// checkGeneration checks the ifGenerationMatch condition of a write, and
+// reports whether it can go ahead
+func (f *fakeGCS) checkGeneration(w http.ResponseWriter, name, generation string) bool {
+	if generation == "" {
+		return true
+	}
+	if obj, ok := f.objects[name]; !ok || strconv.FormatInt(obj.generation, 10) != generation {
+		f.gcsError(w, http.StatusPreconditionFailed, "At least one of the pre-conditions you specified did not hold.")
+		return false
+	}
+	return true
+}
+
+// upload handles media uploads and starts resumable ones
+func (f *fakeGCS) upload(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name, generation := query.Get("name"), query.Get("ifGenerationMatch")
+	if name == "" {
+		f.gcsError(w, http.StatusBadRequest, "Required object name")
+		return
+	}
+	if !f.checkGeneration(w, name, generation) {
+		return
+	}
+	switch query.Get("uploadType") {
+	case "media":
+		data, _ := io.ReadAll(r.Body)
+		writeJSON(w, f.putLocked(name, data).json(name))
+	case "resumable":
+		total, err := strconv.Atoi(r.Header.Get("X-Upload-Content-Length"))
+		if err != nil {
+			f.gcsError(w, http.StatusBadRequest, "Invalid upload length")
+			return
+		}
+		f.generation++
+		id := fmt.Sprintf("session%d", f.generation)
+		f.sessions[id] = &fakeGCSSession{name: name, generation: generation, total: total}
+		w.Header().Set("Location", f.server.URL+"/upload/session/"+id)
+	default:
+		f.gcsError(w, http.StatusBadRequest, "Invalid upload type")
+	}
+}
+
+// uploadChunk receives a chunk of a resumable upload, or reports the bytes
+// received for a Content-Range of bytes */total. Session URLs need no token.
+func (f *fakeGCS) uploadChunk(w http.ResponseWriter, r *http.Request, id string) {
+	session, ok := f.sessions[id]
+	if !ok || r.Method != http.MethodPut {
+		f.gcsError(w, http.StatusNotFound, "No such upload session")
+		return
+	}
+	data, _ := io.ReadAll(r.Body)
+	contentRange := r.Header.Get("Content-Range")
+	if contentRange != fmt.Sprintf("bytes */%d", session.total) {
+		f.chunks++
+		if f.failChunk {
+			// The chunk arrived in part before the connection broke
+			f.failChunk = false
+			session.data = append(session.data, data[:len(data)/2]...)
+			f.gcsError(w, http.StatusServiceUnavailable, "Backend Error")
+			return
+		}
+		var start, end, total int
+		if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil || start != len(session.data) || end-start+1 != len(data) || total != session.total {
+			f.gcsError(w, http.StatusBadRequest, "Invalid Content-Range")
+			return
+		}
+		session.data = append(session.data, data...)
+	}
+	if len(session.data) < session.total {
+		if len(session.data) > 0 {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(session.data)-1))
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+		return
+	}
+	delete(f.sessions, id)
+	if !f.checkGeneration(w, session.name, session.generation) {
+		return
+	}
+	writeJSON(w, f.putLocked(session.name, session.data).json(session.name))
+}
+
+func (obj *fakeGCSObject) json(name string) map[string]any {
+	return map[string]any{
+		"name":       name,
+		"size":       strconv.Itoa(len(obj.data)),
+		"updated":    obj.updated.Format(time.RFC3339),
+		"generation": strconv.FormatInt(obj.generation, 10),
+	}
+}
+
+func (f *fakeGCS) gcsError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": status, "message": message}})
+}
+
+func TestGCSFilesAndDirectories(t *testing.T) {
+	fake := newFakeGCS(t)
+	fake.pageSize = 2
+	fake.rewrites = 3
+	fake.put("mount/dir/", nil)
+	b := fake.mount("mount", nil)
+
+	files := map[string]string{
+		"/a b.txt":       "0123456789",
+		"/docs/x.txt":    "x",
+		"/docs/y.txt":    "y",
+		"/docs/sub/z.md": "z",
+	}
+	for p, content := range files {
+		if err := b.Write(p, []byte(content)); err != nil {
+			t.Fatalf("Write(%s) failed: %v", p, err)
+		}
+	}
+	if data, ok := fake.get("mount/a b.txt"); !ok || string(data) != "0123456789" {
+		t.Errorf("object holds %q", data)
+	}
+
+	infos, err := b.List("/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, fmt.Sprintf("%s:%v", info.Name, info.IsDir))
+	}
+	sort.Strings(names)
+	if want := "[a b.txt:false dir:true docs:true]"; fmt.Sprint(names) != want {
+		t.Errorf("List = %v, want %v", names, want)
+	}
+	// The placeholder object keeps the directory without showing in it
+	if infos, err := b.List("/dir"); err != nil || len(infos) != 0 {
+		t.Errorf("List of a placeholder directory = %v, %v", infos, err)
+	}
+	if _, err := b.List("/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("List of a missing directory = %v, want ErrNotExist", err)
+	}
+
+	stater := b.(types.Stater)
+	if info, err := stater.Stat("/a b.txt"); err != nil || info.Size != 10 || info.ETag == "" || info.ModTime.IsZero() {
+		t.Errorf("Stat = %+v, %v", info, err)
+	}
+	if info, err := stater.Stat("/docs"); err != nil || !info.IsDir {
+		t.Errorf("Stat of a directory = %+v, %v", info, err)
+	}
+	ranger := b.(types.RangeReader)
+	if data, err := ranger.ReadRange("/a b.txt", 2, 3); err != nil || string(data) != "234" {
+		t.Errorf("ReadRange = %q, %v", data, err)
+	}
+	if data, err := ranger.ReadRange("/a b.txt", 20, 3); err != nil || len(data) != 0 {
+		t.Errorf("ReadRange past the end = %q, %v", data, err)
+	}
+
+	// Each object is rewritten over several calls
+	if err := b.(types.Renamer).Rename("/docs", "/moved"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if data, err := b.Read("/moved/sub/z.md"); err != nil || string(data) != "z" {
+		t.Errorf("Read after Rename = %q, %v", data, err)
+	}
+	if err := b.Delete("/moved"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if want := "[mount/a b.txt mount/dir/]"; fmt.Sprint(fake.names()) != want {
+		t.Errorf("bucket holds %v, want %v", fake.names(), want)
+	}
+}
+
+func TestGCSResumableUpload(t *testing.T) {
+	fake := newFakeGCS(t)
+	b := fake.mount("", map[string]string{"chunk_size": "1"})
+	data := make([]byte, 2<<20+1<<19)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	fake.mu.Lock()
+	fake.failChunk = true
+	fake.mu.Unlock()
+	if err := b.Write("/large.bin", data); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if stored, ok := fake.get("large.bin"); !ok || !bytes.Equal(stored, data) {
+		t.Fatalf("stored %d bytes that don't match the %d written", len(stored), len(data))
+	}
+	fake.mu.Lock()
+	chunks := fake.chunks
+	fake.mu.Unlock()
+	// The failed chunk, then two from where it broke off instead of the start
+	if chunks != 3 {
+		t.Errorf("uploaded in %d chunks, want 3", chunks)
+	}
+}
+
+func TestGCSWriteIfMatch(t *testing.T) {
+	fake := newFakeGCS(t)
+	fake.put("a.txt", []byte("base"))
+	b := fake.mount("", nil)
+	info, err := b.(types.Stater).Stat("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.put("a.txt", []byte("remote"))
+	writer := b.(types.ConditionalWriter)
+	if err := writer.WriteIfMatch("/a.txt", []byte("local"), info.ETag); !errors.Is(err, types.ErrConflict) {
+		t.Errorf("WriteIfMatch over a changed object = %v, want ErrConflict", err)
+	}
+	if data, _ := fake.get("a.txt"); string(data) != "remote" {
+		t.Errorf("object holds %q after the conflict", data)
+	}
+	info, _ = b.(types.Stater).Stat("/a.txt")
+	if err := writer.WriteIfMatch("/a.txt", []byte("local"), info.ETag); err != nil {
+		t.Errorf("WriteIfMatch over the current generation failed: %v", err)
+	}
+}
+
+func TestGCSMountErrors(t *testing.T) {
+	fake := newFakeGCS(t)
+	_, err := GCSDiskType{}.New(&models.Mount{AccessToken: "test-token", Options: map[string]string{"bucket": "missing", "endpoint": fake.server.URL}})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("mounting a missing bucket = %v, want it reported as missing", err)
+	}
+	if _, err := (GCSDiskType{}).New(&models.Mount{Options: map[string]string{"bucket": fakeGCSBucket, "endpoint": fake.server.URL}}); err == nil {
+		t.Error("mounted a private bucket without a token")
+	}
+}
@@ -0,0 +1,395 @@
+package disktypes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// GitDiskType implements DiskType for browsing the tree of a Git repository
+// at a branch, tag or commit, without a checkout. Every commit in the history
+// of the ref is also a directory, /.history/<sha>, holding its tree. Mounts
+// are read-only.
+
+type GitDiskType struct{}
+
+type GitBackend struct {
+	mount        *models.Mount
+	repo         *git.Repository
+	ref          string // Revision the mount shows
+	historyLimit int
+}
+
+const (
+	// Directory the history of the ref is shown in
+	gitHistoryDir = "/.history"
+	// Commits shown in the history unless history_limit is set
+	gitDefaultHistoryLimit = 100
+	// How often the ref is checked for new commits
+	gitWatchInterval = 30 * time.Second
+)
+
+func (GitDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &GitBackend{mount: mount}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (GitDiskType) Name() string {
+	return "git"
+}
+
+func (GitDiskType) Description() string {
+	return "Tree and history of a Git repository at a branch, tag or commit"
+}
+
+// ReadOnly implements types.ReadOnlyDiskType. Repositories are only read.
+func (GitDiskType) ReadOnly(mount *models.Mount) bool {
+	return true
+}
+
+func (GitDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return types.DiskTypeConfigTemplate{
+		"repo": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Path of the repository: a bare repository, or a working copy containing .git",
+			Required:    true,
+		},
+		"ref": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Branch, tag or commit to show (default HEAD)",
+			Required:    false,
+		},
+		"history_limit": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Number of commits listed in /.history, 0 for all (default 100)",
+			Required:    false,
+		},
+	}
+}
+
+func (b *GitBackend) connect() error {
+	repoPath := b.mount.Option("repo")
+	if repoPath == "" {
+		return fmt.Errorf("git: missing required config 'repo'")
+	}
+	// Bare repositories have no .git to detect, so they're opened as they are
+	repo, err := git.PlainOpen(repoPath)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repo, err = git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+	}
+	if err != nil {
+		return fmt.Errorf("git: failed to open %s: %w", repoPath, err)
+	}
+
+	b.ref = b.mount.Option("ref")
+	if b.ref == "" {
+		b.ref = "HEAD"
+	}
+	if _, err := repo.ResolveRevision(plumbing.Revision(b.ref)); err != nil {
+		return fmt.Errorf("git: failed to resolve ref %q: %w", b.ref, err)
+	}
+
+	b.historyLimit = gitDefaultHistoryLimit
+	if raw := b.mount.Option("history_limit"); raw != "" {
+		if b.historyLimit, err = strconv.Atoi(raw); err != nil || b.historyLimit < 0 {
+			return fmt.Errorf("git: invalid history_limit %q", raw)
+		}
+	}
+
+	b.repo = repo
+	return nil
+}
+
+// head returns the commit the ref currently points to.
+func (b *GitBackend) head() (*object.Commit, error) {
+	hash, err := b.repo.ResolveRevision(plumbing.Revision(b.ref))
+	if err != nil {
+		return nil, fmt.Errorf("git: failed to resolve ref %q: %w", b.ref, err)
+	}
+	return b.repo.CommitObject(*hash)
+}
+
+// locate returns the commit a mount path is in and the path of it in the
+// commit's tree, "" for the root of the tree. The commit is nil for the
+// history directory itself.
+func (b *GitBackend) locate(p string) (*object.Commit, string, error) {
+	p = joinRemote("", p)
+	if p == gitHistoryDir {
+		return nil, "", nil
+	}
+	rest, ok := strings.CutPrefix(p, gitHistoryDir+"/")
+	if !ok {
+		commit, err := b.head()
+		return commit, strings.TrimPrefix(p, "/"), err
+	}
+
+	sha, treePath, _ := strings.Cut(rest, "/")
+	if !plumbing.IsHash(sha) {
+		return nil, "", &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	commit, err := b.repo.CommitObject(plumbing.NewHash(sha))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, "", &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	return commit, treePath, err
+}
+
+// entry returns the tree entry of a path in a commit.
+func (b *GitBackend) entry(commit *object.Commit, treePath, p string) (*object.Tree, *object.TreeEntry, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, nil, err
+	}
+	if treePath == "" {
+		return tree, &object.TreeEntry{Name: "/", Mode: filemode.Dir, Hash: tree.Hash}, nil
+	}
+	entry, err := tree.FindEntry(treePath)
+	// A path below a file is looked up as a tree with the hash of the file,
+	// which isn't found
+	notFound := errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) || errors.Is(err, plumbing.ErrObjectNotFound)
+	if notFound || (err == nil && entry.Mode == filemode.Submodule) {
+		return nil, nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	return tree, entry, err
+}
+
+// info describes a tree entry of a commit. Files have their blob hash as
+// ETag, and everything the time of the commit.
+func (b *GitBackend) info(commit *object.Commit, entry *object.TreeEntry) (types.FileInfo, error) {
+	info := types.FileInfo{
+		Name:    entry.Name,
+		IsDir:   entry.Mode == filemode.Dir,
+		ModTime: commit.Committer.When,
+	}
+	if !info.IsDir {
+		size, err := b.repo.Storer.EncodedObjectSize(entry.Hash)
+		if err != nil {
+			return types.FileInfo{}, err
+		}
+		info.Size = size
+		info.ETag = entry.Hash.String()
+	}
+	return info, nil
+}
+
+func (b *GitBackend) List(p string) ([]types.FileInfo, error) {
+	commit, treePath, err := b.locate(p)
+	if err != nil {
+		return nil, err
+	}
+	if commit == nil {
+		return b.listHistory()
+	}
+	_, entry, err := b.entry(commit, treePath, p)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Mode != filemode.Dir {
+		return nil, &fs.PathError{Op: "list", Path: p, Err: syscall.ENOTDIR}
+	}
+	tree, err := b.repo.TreeObject(entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []types.FileInfo
+	if p == "/" {
+		infos = append(infos, types.FileInfo{Name: path.Base(gitHistoryDir), IsDir: true, ModTime: commit.Committer.When})
+	}
+	for i := range tree.Entries {
+		entry := &tree.Entries[i]
+		if entry.Mode == filemode.Submodule || (p == "/" && "/"+entry.Name == gitHistoryDir) {
+			continue
+		}
+		info, err := b.info(commit, entry)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// listHistory lists the commits of the ref, newest first.
+func (b *GitBackend) listHistory() ([]types.FileInfo, error) {
+	head, err := b.head()
+	if err != nil {
+		return nil, err
+	}
+	commits, err := b.repo.Log(&git.LogOptions{From: head.Hash, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	defer commits.Close()
+
+	var infos []types.FileInfo
+	for b.historyLimit == 0 || len(infos) < b.historyLimit {
+		commit, err := commits.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, types.FileInfo{Name: commit.Hash.String(), IsDir: true, ModTime: commit.Committer.When})
+	}
+	return infos, nil
+}
+
+func (b *GitBackend) Stat(p string) (types.FileInfo, error) {
+	commit, treePath, err := b.locate(p)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	if commit == nil {
+		return types.FileInfo{Name: path.Base(gitHistoryDir), IsDir: true}, nil
+	}
+	_, entry, err := b.entry(commit, treePath, p)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	info, err := b.info(commit, entry)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	info.Name = path.Base(p)
+	return info, nil
+}
+
+func (b *GitBackend) Read(p string) ([]byte, error) {
+	commit, treePath, err := b.locate(p)
+	if err != nil {
+		return nil, err
+	}
+	if commit == nil {
+		return nil, &fs.PathError{Op: "read", Path: p, Err: syscall.EISDIR}
+	}
+	_, entry, err := b.entry(commit, treePath, p)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Mode == filemode.Dir {
+		return nil, &fs.PathError{Op: "read", Path: p, Err: syscall.EISDIR}
+	}
+	blob, err := b.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (b *GitBackend) Write(p string, data []byte) error {
+	return &fs.PathError{Op: "write", Path: p, Err: types.ErrReadOnly}
+}
+
+func (b *GitBackend) Delete(p string) error {
+	return &fs.PathError{Op: "delete", Path: p, Err: types.ErrReadOnly}
+}
+
+func (b *GitBackend) Rename(from, to string) error {
+	return &fs.PathError{Op: "rename", Path: from, Err: types.ErrReadOnly}
+}
+
+// Watch implements types.Watcher. It checks the ref for new commits, keeping
+// the last commit seen in the watch state, and reports the paths that
+// differ between the trees of the two commits.
+func (b *GitBackend) Watch(ctx context.Context, state types.WatchState, emit func(types.ChangeEvent)) error {
+	last, err := state.Cursor()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(gitWatchInterval)
+	defer ticker.Stop()
+	for {
+		head, err := b.head()
+		if err != nil {
+			return err
+		}
+		if current := head.Hash.String(); current != last {
+			if last != "" {
+				b.emitChanges(last, head, emit)
+			}
+			if err := state.SetCursor(current); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// emitChanges reports the differences between the tree of the commit last
+// seen and the current one. If the last commit no longer exists, e.g. after
+// a force push and garbage collection, the root is reported as modified.
+func (b *GitBackend) emitChanges(last string, head *object.Commit, emit func(types.ChangeEvent)) {
+	emit(types.ChangeEvent{Path: gitHistoryDir, Kind: types.ChangeModified, IsDir: true})
+
+	changes, err := b.diff(plumbing.NewHash(last), head)
+	if err != nil {
+		emit(types.ChangeEvent{Path: "/", Kind: types.ChangeModified, IsDir: true})
+		return
+	}
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			continue
+		}
+		switch action {
+		case merkletrie.Insert:
+			emit(types.ChangeEvent{Path: "/" + change.To.Name, Kind: types.ChangeCreated})
+		case merkletrie.Delete:
+			emit(types.ChangeEvent{Path: "/" + change.From.Name, Kind: types.ChangeDeleted})
+		case merkletrie.Modify:
+			emit(types.ChangeEvent{Path: "/" + change.To.Name, Kind: types.ChangeModified})
+		}
+	}
+}
+
+func (b *GitBackend) diff(from plumbing.Hash, to *object.Commit) (object.Changes, error) {
+	previous, err := b.repo.CommitObject(from)
+	if err != nil {
+		return nil, err
+	}
+	fromTree, err := previous.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return object.DiffTree(fromTree, toTree)
+}
+
+func (b *GitBackend) Reconnect() error {
+	return b.connect()
+}
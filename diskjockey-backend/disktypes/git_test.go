@@ -0,0 +1,275 @@
+package disktypes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// gitTestRepo is a working copy in a temporary directory
+type gitTestRepo struct {
+	dir  string
+	repo *git.Repository
+	when time.Time // Of the next commit
+}
+
+func newGitTestRepo(t *testing.T) *gitTestRepo {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gitTestRepo{dir: dir, repo: repo, when: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// commit writes files, removing those without contents, and commits them,
+// returning the hash of the commit
+func (r *gitTestRepo) commit(t *testing.T, files map[string]string) string {
+	t.Helper()
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		p := filepath.Join(r.dir, filepath.FromSlash(name))
+		if data == "" {
+			if _, err := wt.Remove(name); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.when = r.when.Add(time.Hour)
+	hash, err := wt.Commit(fmt.Sprintf("commit at %v", r.when), &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@localhost", When: r.when},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func (r *gitTestRepo) mount(t *testing.T, options map[string]string) *GitBackend {
+	t.Helper()
+	all := map[string]string{"repo": r.dir}
+	for key, value := range options {
+		all[key] = value
+	}
+	return mustNew(t, GitDiskType{}, &models.Mount{Options: all}).(*GitBackend)
+}
+
+func gitNames(t *testing.T, b *GitBackend, dir string) string {
+	t.Helper()
+	infos, err := b.List(dir)
+	if err != nil {
+		t.Fatalf("List(%s) failed: %v", dir, err)
+	}
+	var names []string
+	for _, info := range infos {
+		if info.IsDir {
+			info.Name += "/"
+		}
+		names = append(names, info.Name)
+	}
+	return fmt.Sprint(names)
+}
+
+func TestGitRefs(t *testing.T) {
+	r := newGitTestRepo(t)
+	first := r.commit(t, map[string]string{"a.txt": "a1", "docs/b.txt": "b1"})
+	if _, err := r.repo.CreateTag("v1", plumbing.NewHash(first), nil); err != nil {
+		t.Fatal(err)
+	}
+	second := r.commit(t, map[string]string{"a.txt": "a2", "c.txt": "c"})
+	branch := plumbing.NewBranchReferenceName("old")
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(branch, plumbing.NewHash(first))); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		ref, a, root string
+	}{
+		{"", "a2", "[.history/ a.txt c.txt docs/]"},
+		{"master", "a2", "[.history/ a.txt c.txt docs/]"},
+		{"old", "a1", "[.history/ a.txt docs/]"},
+		{"v1", "a1", "[.history/ a.txt docs/]"},
+		{first, "a1", "[.history/ a.txt docs/]"},
+		{second[:10], "a2", "[.history/ a.txt c.txt docs/]"},
+	} {
+		b := r.mount(t, map[string]string{"ref": test.ref})
+		if got := gitNames(t, b, "/"); got != test.root {
+			t.Errorf("List(/) at %q = %v, want %v", test.ref, got, test.root)
+		}
+		if data, err := b.Read("/a.txt"); err != nil || string(data) != test.a {
+			t.Errorf("Read(/a.txt) at %q = %q, %v, want %q", test.ref, data, err, test.a)
+		}
+	}
+
+	for _, ref := range []string{"missing", "refs/heads/missing", "0123456789012345678901234567890123456789"} {
+		mount := &models.Mount{Options: map[string]string{"repo": r.dir, "ref": ref}}
+		if _, err := (GitDiskType{}).New(mount); err == nil {
+			t.Errorf("mounted the missing ref %q", ref)
+		}
+	}
+	if _, err := (GitDiskType{}).New(&models.Mount{Options: map[string]string{"repo": t.TempDir()}}); err == nil {
+		t.Error("mounted a directory that isn't a repository")
+	}
+}
+
+func TestGitReadAndStat(t *testing.T) {
+	r := newGitTestRepo(t)
+	r.commit(t, map[string]string{"a.txt": "hello", "docs/sub/b.txt": "bb"})
+	b := r.mount(t, nil)
+
+	info, err := b.Stat("/docs/sub/b.txt")
+	if err != nil || info.Name != "b.txt" || info.IsDir || info.Size != 2 || info.ETag == "" || !info.ModTime.Equal(r.when) {
+		t.Errorf("Stat of a blob = %+v, %v", info, err)
+	}
+	if info, err := b.Stat("/docs"); err != nil || !info.IsDir {
+		t.Errorf("Stat of a tree = %+v, %v", info, err)
+	}
+	if got, want := gitNames(t, b, "/docs"), "[sub/]"; got != want {
+		t.Errorf("List(/docs) = %v, want %v", got, want)
+	}
+	if data, err := b.Read("/docs/sub/b.txt"); err != nil || string(data) != "bb" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+
+	if _, err := b.Read("/docs"); !errors.Is(err, syscall.EISDIR) {
+		t.Errorf("Read of a directory = %v, want EISDIR", err)
+	}
+	if _, err := b.List("/a.txt"); !errors.Is(err, syscall.ENOTDIR) {
+		t.Errorf("List of a file = %v, want ENOTDIR", err)
+	}
+	for _, p := range []string{"/missing.txt", "/docs/missing/b.txt", "/a.txt/b"} {
+		if _, err := b.Stat(p); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%s) = %v, want ErrNotExist", p, err)
+		}
+	}
+
+	if !(GitDiskType{}).ReadOnly(&models.Mount{}) {
+		t.Error("git mounts aren't read-only")
+	}
+	for op, err := range map[string]error{
+		"Write":  b.Write("/a.txt", []byte("x")),
+		"Delete": b.Delete("/a.txt"),
+		"Rename": b.Rename("/a.txt", "/b.txt"),
+	} {
+		if !errors.Is(err, types.ErrReadOnly) {
+			t.Errorf("%s = %v, want ErrReadOnly", op, err)
+		}
+	}
+}
+
+func TestGitHistory(t *testing.T) {
+	r := newGitTestRepo(t)
+	first := r.commit(t, map[string]string{"a.txt": "a1"})
+	second := r.commit(t, map[string]string{"a.txt": "a2", "b.txt": "b"})
+	third := r.commit(t, map[string]string{"b.txt": ""})
+	b := r.mount(t, nil)
+
+	// Newest first, with every commit holding its own tree
+	if got, want := gitNames(t, b, "/.history"), fmt.Sprint([]string{third + "/", second + "/", first + "/"}); got != want {
+		t.Errorf("List(/.history) = %v, want %v", got, want)
+	}
+	if got, want := gitNames(t, b, "/.history/"+second), "[a.txt b.txt]"; got != want {
+		t.Errorf("List of the second commit = %v, want %v", got, want)
+	}
+	if data, err := b.Read("/.history/" + first + "/a.txt"); err != nil || string(data) != "a1" {
+		t.Errorf("Read in the first commit = %q, %v", data, err)
+	}
+	if info, err := b.Stat("/.history/" + second + "/b.txt"); err != nil || info.Size != 1 {
+		t.Errorf("Stat in the second commit = %+v, %v", info, err)
+	}
+	if info, err := b.Stat("/.history"); err != nil || !info.IsDir {
+		t.Errorf("Stat(/.history) = %+v, %v", info, err)
+	}
+
+	limited := r.mount(t, map[string]string{"history_limit": "2"})
+	if got, want := gitNames(t, limited, "/.history"), fmt.Sprint([]string{third + "/", second + "/"}); got != want {
+		t.Errorf("List(/.history) with history_limit 2 = %v, want %v", got, want)
+	}
+	if _, err := (GitDiskType{}).New(&models.Mount{Options: map[string]string{"repo": r.dir, "history_limit": "-1"}}); err == nil {
+		t.Error("accepted a negative history_limit")
+	}
+
+	for _, p := range []string{"/.history/nothex", "/.history/0123456789012345678901234567890123456789", "/.history/" + third + "/b.txt"} {
+		if _, err := b.Stat(p); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%s) = %v, want ErrNotExist", p, err)
+		}
+	}
+}
+
+// gitChanges runs one check of Watch from the commit in the cursor, and
+// returns the changes it reports as "kind path" along with the new cursor.
+func gitChanges(t *testing.T, b *GitBackend, cursor string) ([]string, string) {
+	t.Helper()
+	kinds := map[types.ChangeKind]string{
+		types.ChangeCreated:  "created",
+		types.ChangeModified: "modified",
+		types.ChangeDeleted:  "deleted",
+	}
+	state := &fakeWatchState{cursor: cursor}
+	var got []string
+	// With the context already done, Watch checks the ref once and returns
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := b.Watch(ctx, state, func(event types.ChangeEvent) {
+		got = append(got, kinds[event.Kind]+" "+event.Path)
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	sort.Strings(got)
+	return got, state.cursor
+}
+
+func TestGitWatch(t *testing.T) {
+	r := newGitTestRepo(t)
+	first := r.commit(t, map[string]string{"a.txt": "a1", "docs/b.txt": "b"})
+	b := r.mount(t, nil)
+
+	// The first check only records where the ref is
+	if got, cursor := gitChanges(t, b, ""); len(got) != 0 || cursor != first {
+		t.Errorf("first check reported %q and kept %s, want nothing and %s", got, cursor, first)
+	}
+	if got, _ := gitChanges(t, b, first); len(got) != 0 {
+		t.Errorf("check without new commits reported %q", got)
+	}
+
+	second := r.commit(t, map[string]string{"a.txt": "a2", "docs/b.txt": "", "docs/c.txt": "c"})
+	got, cursor := gitChanges(t, b, first)
+	want := []string{"created /docs/c.txt", "deleted /docs/b.txt", "modified /.history", "modified /a.txt"}
+	if fmt.Sprint(got) != fmt.Sprint(want) || cursor != second {
+		t.Errorf("check after a commit reported %q and kept %s, want %q and %s", got, cursor, want, second)
+	}
+
+	// A commit that is gone, as after a force push, changes everything
+	got, _ = gitChanges(t, b, "0123456789012345678901234567890123456789")
+	if want := []string{"modified /", "modified /.history"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("check from a missing commit reported %q, want %q", got, want)
+	}
+}
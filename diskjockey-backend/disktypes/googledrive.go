@@ -0,0 +1,1042 @@
+package disktypes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"golang.org/x/oauth2"
+)
+
+// GoogleDriveDiskType implements DiskType for Google Drive, using the Drive
+// v3 API.
+//
+// Drive identifies files by ID and lets a folder hold several files with the
+// same name, so paths are resolved by listing folders. The oldest of files
+// sharing a name keeps it, the others have their ID added to it, e.g.
+// "report [1a2B3c].pdf". Google Docs, Sheets, Slides and Drawings are read
+// as exports, with the extension of the export format added to their name.
+
+type GoogleDriveDiskType struct {
+	Tokens types.TokenStore // Saves access tokens refreshed while mounted
+}
+
+type GoogleDriveBackend struct {
+	mount  *models.Mount
+	tokens types.TokenStore
+	client *http.Client
+	api    string // URL of the Drive API
+	upload string // URL of the Drive upload API
+	root   string // ID of the folder mounted
+	drive  string // ID of the shared drive mounted, "" for My Drive
+
+	exports map[string]gdriveExport // Google Docs type -> export format
+
+	mu    sync.Mutex
+	dirs  map[string]string          // Path -> folder ID, of folders resolved before
+	known map[string]gdriveKnownFile // File ID -> where it was last seen, for changes
+}
+
+// gdriveExport is the format a Google Docs type is read as
+type gdriveExport struct {
+	mimeType  string
+	extension string
+}
+
+// gdriveKnownFile is where a file was last seen
+type gdriveKnownFile struct {
+	path   string
+	name   string
+	parent string
+}
+
+const (
+	gdriveDefaultAPIURL = "https://www.googleapis.com"
+	gdriveFolderType    = "application/vnd.google-apps.folder"
+	gdriveDocsPrefix    = "application/vnd.google-apps."
+	// Largest file uploaded with a single request, bigger ones use a
+	// resumable upload
+	gdriveSimpleUploadLimit = 5 << 20
+	// Size of resumable upload chunks, which must be a multiple of 256 KiB
+	gdriveUploadChunkSize = 8 << 20
+	// Times a request is retried when rate limited, or an upload chunk failed
+	gdriveMaxRetries = 3
+	// How often the changes API is checked
+	gdriveChangesInterval = 30 * time.Second
+	// Metadata of files requested from the API
+	gdriveFileFields = "id,name,mimeType,size,modifiedTime,createdTime,version,parents,trashed"
+)
+
+var gdriveExportFormats = map[string]map[string]gdriveExport{
+	"office": {
+		"application/vnd.google-apps.document":     {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"},
+		"application/vnd.google-apps.spreadsheet":  {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx"},
+		"application/vnd.google-apps.presentation": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", ".pptx"},
+		"application/vnd.google-apps.drawing":      {"application/pdf", ".pdf"},
+	},
+	"pdf": {
+		"application/vnd.google-apps.document":     {"application/pdf", ".pdf"},
+		"application/vnd.google-apps.spreadsheet":  {"application/pdf", ".pdf"},
+		"application/vnd.google-apps.presentation": {"application/pdf", ".pdf"},
+		"application/vnd.google-apps.drawing":      {"application/pdf", ".pdf"},
+	},
+}
+
+func (t GoogleDriveDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &GoogleDriveBackend{
+		mount:  mount,
+		tokens: t.Tokens,
+		dirs:   map[string]string{},
+		known:  map[string]gdriveKnownFile{},
+	}
+
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (GoogleDriveDiskType) Name() string {
+	return "googledrive"
+}
+
+func (GoogleDriveDiskType) Description() string {
+	return "Google Drive cloud storage"
+}
+
+func (GoogleDriveDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return withCacheFields(types.DiskTypeConfigTemplate{
+		"client_id": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "OAuth client ID of a desktop app, used to authorize the mount and refresh its access token",
+			Required:    false,
+		},
+		"client_secret": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "OAuth client secret of the desktop app, which Google requires even though it isn't secret",
+			Required:    false,
+		},
+		"access_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Google OAuth2 access token (set by authorization)",
+			Required:    false,
+		},
+		"refresh_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Google OAuth2 refresh token (set by authorization)",
+			Required:    false,
+		},
+		"redirect_port": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Fixed loopback port for the authorization redirect, if the app only allows registered redirect URIs",
+			Required:    false,
+		},
+		"folder_id": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "ID of the folder to mount (default the root of My Drive or the shared drive)",
+			Required:    false,
+		},
+		"drive_id": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "ID of the shared drive to mount (default My Drive)",
+			Required:    false,
+		},
+		"export_format": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Format Google Docs, Sheets and Slides are read as: office or pdf (default office)",
+			Required:    false,
+		},
+		"api_url": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Google APIs endpoint (default " + gdriveDefaultAPIURL + ")",
+			Required:    false,
+		},
+	})
+}
+
+// OAuthConfig implements types.OAuthDiskType. Google only returns a refresh
+// token for offline access, and only on the first consent unless asked to
+// prompt again.
+func (GoogleDriveDiskType) OAuthConfig(mount *models.Mount) (*oauth2.Config, []oauth2.AuthCodeOption, error) {
+	config, err := gdriveOAuthConfig(mount)
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, []oauth2.AuthCodeOption{oauth2.AccessTypeOffline, oauth2.SetAuthURLParam("prompt", "consent")}, nil
+}
+
+func gdriveOAuthConfig(mount *models.Mount) (*oauth2.Config, error) {
+	clientID := mount.Option("client_id")
+	if clientID == "" {
+		return nil, fmt.Errorf("missing required googledrive config field: client_id")
+	}
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: mount.Option("client_secret"),
+		Scopes:       []string{"https://www.googleapis.com/auth/drive"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   "https://accounts.google.com/o/oauth2/v2/auth",
+			TokenURL:  "https://oauth2.googleapis.com/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}, nil
+}
+
+func (b *GoogleDriveBackend) connect() error {
+	var tokens oauth2.TokenSource
+	if b.mount.Option("refresh_token") != "" {
+		config, err := gdriveOAuthConfig(b.mount)
+		if err != nil {
+			return err
+		}
+		refreshing := newMountTokenSource(config, b.mount, b.tokens)
+		// Fail the mount early if the refresh token has been revoked
+		if _, err := refreshing.Token(); err != nil {
+			return err
+		}
+		tokens = refreshing
+	} else {
+		token := b.mount.AccessToken
+		if token == "" {
+			return fmt.Errorf("%w: missing required googledrive config field: access_token (authorize the mount)", types.ErrReauthRequired)
+		}
+		tokens = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	}
+
+	format := b.mount.Option("export_format")
+	if format == "" {
+		format = "office"
+	}
+	exports, ok := gdriveExportFormats[format]
+	if !ok {
+		return fmt.Errorf("googledrive: unknown export_format %q, expected office or pdf", format)
+	}
+
+	api := strings.TrimSuffix(b.mount.Option("api_url"), "/")
+	if api == "" {
+		api = gdriveDefaultAPIURL
+	}
+	b.api = api + "/drive/v3"
+	b.upload = api + "/upload/drive/v3"
+	b.drive = b.mount.Option("drive_id")
+	b.root = b.mount.Option("folder_id")
+	if b.root == "" {
+		b.root = "root"
+		if b.drive != "" {
+			b.root = b.drive
+		}
+	}
+	b.exports = exports
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	b.client = &http.Client{Transport: &oauth2.Transport{Source: tokens, Base: transport}}
+
+	b.mu.Lock()
+	b.dirs = map[string]string{}
+	b.mu.Unlock()
+	return nil
+}
+
+// gdriveFile is the part of the metadata of a Drive file the backend uses
+type gdriveFile struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	MimeType     string    `json:"mimeType"`
+	Size         int64     `json:"size,string"`
+	ModifiedTime time.Time `json:"modifiedTime"`
+	CreatedTime  time.Time `json:"createdTime"`
+	Version      string    `json:"version"`
+	Parents      []string  `json:"parents"`
+	Trashed      bool      `json:"trashed"`
+}
+
+// gdriveEntry is a file listed in a folder, with the name it has in the mount
+type gdriveEntry struct {
+	gdriveFile
+	name string
+}
+
+func (f *gdriveFile) isDir() bool {
+	return f.MimeType == gdriveFolderType
+}
+
+// parent returns the ID of the folder holding the file
+func (f *gdriveFile) parent() string {
+	if len(f.Parents) == 0 {
+		return ""
+	}
+	return f.Parents[0]
+}
+
+func (e *gdriveEntry) info() types.FileInfo {
+	info := types.FileInfo{
+		Name:    e.name,
+		IsDir:   e.isDir(),
+		ModTime: e.ModifiedTime,
+	}
+	if !info.IsDir {
+		// Exports have no size until they are made
+		info.Size = e.Size
+		info.ETag = e.Version
+	}
+	return info
+}
+
+// displayName returns the name of a file in the mount before duplicates are
+// told apart, and false for Google files that can't be read. Slashes, which
+// Drive allows in names, are shown as full width slashes.
+func (b *GoogleDriveBackend) displayName(f *gdriveFile) (string, bool) {
+	name := strings.ReplaceAll(f.Name, "/", "／")
+	if f.isDir() || !strings.HasPrefix(f.MimeType, gdriveDocsPrefix) {
+		return name, true
+	}
+	export, ok := b.exports[f.MimeType]
+	return name + export.extension, ok
+}
+
+// driveName returns the Drive name of a file created at a mount name
+func driveName(name string) string {
+	return strings.ReplaceAll(name, "／", "/")
+}
+
+// gdriveError is returned for a Drive response that isn't a success. It
+// wraps the sentinel error matching the status, if there is one.
+type gdriveError struct {
+	status  int
+	reason  string
+	message string
+}
+
+func (e *gdriveError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("googledrive: server responded %d %s", e.status, http.StatusText(e.status))
+	}
+	return fmt.Sprintf("googledrive: %s (%d %s)", e.message, e.status, e.reason)
+}
+
+func (e *gdriveError) Unwrap() error {
+	switch e.status {
+	case http.StatusNotFound:
+		return fs.ErrNotExist
+	case http.StatusUnauthorized:
+		return types.ErrReauthRequired
+	case http.StatusPreconditionFailed:
+		return types.ErrConflict
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return types.ErrOffline
+	default:
+		return nil
+	}
+}
+
+// hasGDriveStatus reports whether err is for a response with one of the statuses
+func hasGDriveStatus(err error, statuses ...int) bool {
+	var driveErr *gdriveError
+	if !errors.As(err, &driveErr) {
+		return false
+	}
+	for _, status := range statuses {
+		if driveErr.status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// query returns the common query parameters of Drive requests, which have to
+// opt in to shared drives, and the extra ones given as key value pairs.
+func (b *GoogleDriveBackend) query(pairs ...string) url.Values {
+	values := url.Values{"supportsAllDrives": {"true"}}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		values.Set(pairs[i], pairs[i+1])
+	}
+	return values
+}
+
+// send makes a request and returns the response of a success. Rate limited
+// requests are retried with a growing delay.
+func (b *GoogleDriveBackend) send(method, u, p string, header http.Header, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, u, reader)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := b.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		statusErr := &gdriveError{status: resp.StatusCode}
+		var errBody struct {
+			Error struct {
+				Message string `json:"message"`
+				Errors  []struct {
+					Reason string `json:"reason"`
+				} `json:"errors"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&errBody) == nil {
+			statusErr.message = errBody.Error.Message
+			if len(errBody.Error.Errors) > 0 {
+				statusErr.reason = errBody.Error.Errors[0].Reason
+			}
+		}
+		resp.Body.Close()
+
+		rateLimited := resp.StatusCode == http.StatusTooManyRequests ||
+			(resp.StatusCode == http.StatusForbidden && strings.HasSuffix(statusErr.reason, "RateLimitExceeded"))
+		if rateLimited && attempt < gdriveMaxRetries {
+			time.Sleep(time.Duration(1<<attempt) * time.Second)
+			continue
+		}
+		return nil, &fs.PathError{Op: strings.ToLower(method), Path: p, Err: statusErr}
+	}
+}
+
+// do makes a Drive API request and decodes the JSON response into out, if
+// it isn't nil.
+func (b *GoogleDriveBackend) do(method, u, p string, in, out any) error {
+	var header http.Header
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+		header = http.Header{"Content-Type": {"application/json"}}
+	}
+	resp, err := b.send(method, u, p, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// children lists the files in a folder with their names in the mount, and
+// remembers where they were seen.
+func (b *GoogleDriveBackend) children(folderID, dir string) ([]gdriveEntry, error) {
+	query := b.query(
+		"q", fmt.Sprintf("'%s' in parents and trashed = false", gdriveEscape(folderID)),
+		"fields", "nextPageToken,files("+gdriveFileFields+")",
+		"pageSize", "1000",
+		"includeItemsFromAllDrives", "true",
+	)
+	if b.drive != "" {
+		query.Set("corpora", "drive")
+		query.Set("driveId", b.drive)
+	}
+
+	var files []gdriveFile
+	for {
+		var page struct {
+			Files         []gdriveFile `json:"files"`
+			NextPageToken string       `json:"nextPageToken"`
+		}
+		if err := b.do(http.MethodGet, b.api+"/files?"+query.Encode(), dir, nil, &page); err != nil {
+			return nil, err
+		}
+		files = append(files, page.Files...)
+		if page.NextPageToken == "" {
+			break
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+
+	// The oldest file keeps a name shared by several
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].CreatedTime.Equal(files[j].CreatedTime) {
+			return files[i].CreatedTime.Before(files[j].CreatedTime)
+		}
+		return files[i].ID < files[j].ID
+	})
+	entries := make([]gdriveEntry, 0, len(files))
+	seen := map[string]bool{}
+	for _, file := range files {
+		name, ok := b.displayName(&file)
+		if !ok {
+			continue
+		}
+		if seen[name] {
+			ext := path.Ext(name)
+			name = strings.TrimSuffix(name, ext) + " [" + file.ID + "]" + ext
+		}
+		seen[name] = true
+		entries = append(entries, gdriveEntry{gdriveFile: file, name: name})
+	}
+
+	b.mu.Lock()
+	for _, entry := range entries {
+		b.known[entry.ID] = gdriveKnownFile{path: path.Join(dir, entry.name), name: entry.Name, parent: folderID}
+	}
+	b.mu.Unlock()
+	return entries, nil
+}
+
+// gdriveEscape escapes a value for a string literal of a Drive query
+func gdriveEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// resolve returns the file at a clean path
+func (b *GoogleDriveBackend) resolve(p string) (*gdriveEntry, error) {
+	if p == "/" {
+		return &gdriveEntry{gdriveFile: gdriveFile{ID: b.root, MimeType: gdriveFolderType}, name: "/"}, nil
+	}
+	dir := path.Dir(p)
+	folderID, err := b.resolveDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := b.children(folderID, dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].name == path.Base(p) {
+			return &entries[i], nil
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+}
+
+// resolveDir returns the ID of the folder at a clean path
+func (b *GoogleDriveBackend) resolveDir(p string) (string, error) {
+	if p == "/" {
+		return b.root, nil
+	}
+	b.mu.Lock()
+	id, ok := b.dirs[p]
+	b.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	entry, err := b.resolve(p)
+	if err != nil {
+		return "", err
+	}
+	if !entry.isDir() {
+		return "", &fs.PathError{Op: "open", Path: p, Err: syscall.ENOTDIR}
+	}
+	b.mu.Lock()
+	b.dirs[p] = entry.ID
+	b.mu.Unlock()
+	return entry.ID, nil
+}
+
+// ensureDir returns the ID of the folder at a path, creating it and the
+// folders above it if needed.
+func (b *GoogleDriveBackend) ensureDir(p string) (string, error) {
+	id, err := b.resolveDir(p)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return id, err
+	}
+	parentID, err := b.ensureDir(path.Dir(p))
+	if err != nil {
+		return "", err
+	}
+
+	var folder gdriveFile
+	metadata := map[string]any{"name": driveName(path.Base(p)), "mimeType": gdriveFolderType, "parents": []string{parentID}}
+	if err := b.do(http.MethodPost, b.api+"/files?"+b.query("fields", "id").Encode(), p, metadata, &folder); err != nil {
+		return "", err
+	}
+	b.mu.Lock()
+	b.dirs[p] = folder.ID
+	b.mu.Unlock()
+	return folder.ID, nil
+}
+
+// forget drops the folders cached at or below a path, after it was moved or
+// deleted.
+func (b *GoogleDriveBackend) forget(p string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for dir := range b.dirs {
+		if dir == p || strings.HasPrefix(dir, p+"/") {
+			delete(b.dirs, dir)
+		}
+	}
+}
+
+func (b *GoogleDriveBackend) List(p string) ([]types.FileInfo, error) {
+	p = joinRemote("", p)
+	folderID, err := b.resolveDir(p)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := b.children(folderID, p)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.FileInfo, 0, len(entries))
+	for i := range entries {
+		out = append(out, entries[i].info())
+	}
+	return out, nil
+}
+
+func (b *GoogleDriveBackend) Stat(p string) (types.FileInfo, error) {
+	entry, err := b.resolve(joinRemote("", p))
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return entry.info(), nil
+}
+
+func (b *GoogleDriveBackend) Read(p string) ([]byte, error) {
+	resp, err := b.download(p, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// ReadRange implements types.RangeReader with a ranged download. Exports
+// can't be ranged, so they are cut down to the range.
+func (b *GoogleDriveBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	if length == 0 {
+		return []byte{}, nil
+	}
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}}
+	resp, err := b.download(p, header)
+	if hasGDriveStatus(err, http.StatusRequestedRangeNotSatisfiable) {
+		// The range starts past the end of the file
+		return []byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPartialContent {
+		return io.ReadAll(io.LimitReader(resp.Body, length))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return types.SliceRange(data, offset, length), nil
+}
+
+// download fetches the content of a file, or the export of a Google file.
+func (b *GoogleDriveBackend) download(p string, header http.Header) (*http.Response, error) {
+	p = joinRemote("", p)
+	entry, err := b.resolve(p)
+	if err != nil {
+		return nil, err
+	}
+	if entry.isDir() {
+		return nil, &fs.PathError{Op: "read", Path: p, Err: syscall.EISDIR}
+	}
+	if export, ok := b.exports[entry.MimeType]; ok {
+		u := b.api + "/files/" + url.PathEscape(entry.ID) + "/export?" + url.Values{"mimeType": {export.mimeType}}.Encode()
+		return b.send(http.MethodGet, u, p, nil, nil)
+	}
+	u := b.api + "/files/" + url.PathEscape(entry.ID) + "?" + b.query("alt", "media").Encode()
+	return b.send(http.MethodGet, u, p, header, nil)
+}
+
+// Write replaces the content of the file at a path, or creates it along
+// with the folders above it.
+func (b *GoogleDriveBackend) Write(p string, data []byte) error {
+	p = joinRemote("", p)
+	if p == "/" {
+		return fmt.Errorf("cannot write to root directory")
+	}
+
+	entry, err := b.resolve(p)
+	switch {
+	case err == nil && entry.isDir():
+		return &fs.PathError{Op: "write", Path: p, Err: syscall.EISDIR}
+	case err == nil && strings.HasPrefix(entry.MimeType, gdriveDocsPrefix):
+		// Exports can't be converted back
+		return &fs.PathError{Op: "write", Path: p, Err: types.ErrReadOnly}
+	case err == nil:
+		return b.uploadFile(http.MethodPatch, "/files/"+url.PathEscape(entry.ID), p, map[string]any{}, data)
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	parentID, err := b.ensureDir(path.Dir(p))
+	if err != nil {
+		return err
+	}
+	metadata := map[string]any{"name": driveName(path.Base(p)), "parents": []string{parentID}}
+	return b.uploadFile(http.MethodPost, "/files", p, metadata, data)
+}
+
+// uploadFile sends the metadata and content of a file to the upload API,
+// with a single multipart request for small files and a resumable upload
+// for bigger ones.
+func (b *GoogleDriveBackend) uploadFile(method, resource, p string, metadata map[string]any, data []byte) error {
+	if len(data) > gdriveSimpleUploadLimit {
+		return b.resumableUpload(method, resource, p, metadata, data)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{{"application/json; charset=UTF-8", encoded}, {"application/octet-stream", data}} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(part.content); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	header := http.Header{"Content-Type": {"multipart/related; boundary=" + writer.Boundary()}}
+	u := b.upload + resource + "?" + b.query("uploadType", "multipart", "fields", "id").Encode()
+	resp, err := b.send(method, u, p, header, body.Bytes())
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// resumableUpload starts a resumable upload session and sends data to it in
+// gdriveUploadChunkSize chunks. When a chunk fails, the session is asked
+// which bytes it has and the upload resumes from there.
+func (b *GoogleDriveBackend) resumableUpload(method, resource, p string, metadata map[string]any, data []byte) error {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	total := int64(len(data))
+	header := http.Header{
+		"Content-Type":            {"application/json; charset=UTF-8"},
+		"X-Upload-Content-Length": {strconv.FormatInt(total, 10)},
+	}
+	u := b.upload + resource + "?" + b.query("uploadType", "resumable", "fields", "id").Encode()
+	resp, err := b.send(method, u, p, header, encoded)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	session := resp.Header.Get("Location")
+	if session == "" {
+		return fmt.Errorf("googledrive: upload of %s returned no session", p)
+	}
+
+	offset, failures := int64(0), 0
+	for {
+		end := min(offset+gdriveUploadChunkSize, total)
+		header := http.Header{"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", offset, end-1, total)}}
+		resp, err := b.send(http.MethodPut, session, p, header, data[offset:end])
+		if err != nil {
+			if hasGDriveStatus(err, http.StatusNotFound, http.StatusGone) || failures >= gdriveMaxRetries {
+				// The session expired, or the upload keeps failing
+				return err
+			}
+			failures++
+			fmt.Fprintf(os.Stderr, "[GoogleDriveBackend] Upload of %s failed at byte %d, resuming: %v\n", p, offset, err)
+			header := http.Header{"Content-Range": {fmt.Sprintf("bytes */%d", total)}}
+			if resp, err = b.send(http.MethodPut, session, p, header, nil); err != nil {
+				return err
+			}
+		} else {
+			failures = 0
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusPermanentRedirect {
+			// The upload is complete
+			return nil
+		}
+		// 308 Resume Incomplete says which bytes the session has
+		offset = 0
+		if received := resp.Header.Get("Range"); received != "" {
+			_, last, _ := strings.Cut(received, "-")
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil {
+				return fmt.Errorf("googledrive: upload of %s returned range %q", p, received)
+			}
+			offset = n + 1
+		}
+	}
+}
+
+// Delete moves a file or folder to the trash.
+func (b *GoogleDriveBackend) Delete(p string) error {
+	p = joinRemote("", p)
+	if p == "/" {
+		return fmt.Errorf("cannot delete root directory")
+	}
+	entry, err := b.resolve(p)
+	if err != nil {
+		return err
+	}
+	if err := b.trash(entry.ID, p); err != nil {
+		return err
+	}
+	b.forget(p)
+	return nil
+}
+
+func (b *GoogleDriveBackend) trash(id, p string) error {
+	u := b.api + "/files/" + url.PathEscape(id) + "?" + b.query("fields", "id").Encode()
+	return b.do(http.MethodPatch, u, p, map[string]any{"trashed": true}, nil)
+}
+
+// Rename moves a file or folder by changing its name and parent. A file
+// already at the destination is moved to the trash.
+func (b *GoogleDriveBackend) Rename(from, to string) error {
+	from, to = joinRemote("", from), joinRemote("", to)
+	if from == "/" || to == "/" {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	entry, err := b.resolve(from)
+	if err != nil {
+		return err
+	}
+	existing, err := b.resolve(to)
+	if err == nil && existing.ID != entry.ID {
+		if err := b.trash(existing.ID, to); err != nil {
+			return err
+		}
+		b.forget(to)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	parentID, err := b.ensureDir(path.Dir(to))
+	if err != nil {
+		return err
+	}
+
+	query := b.query("fields", "id")
+	if parentID != entry.parent() {
+		query.Set("addParents", parentID)
+		query.Set("removeParents", entry.parent())
+	}
+	name := path.Base(to)
+	if export, ok := b.exports[entry.MimeType]; ok {
+		name = strings.TrimSuffix(name, export.extension)
+	}
+	u := b.api + "/files/" + url.PathEscape(entry.ID) + "?" + query.Encode()
+	if err := b.do(http.MethodPatch, u, from, map[string]any{"name": driveName(name)}, nil); err != nil {
+		return err
+	}
+	b.forget(from)
+	return nil
+}
+
+// gdriveChange is the part of an entry of the changes API the backend uses
+type gdriveChange struct {
+	FileID  string      `json:"fileId"`
+	Removed bool        `json:"removed"`
+	File    *gdriveFile `json:"file"`
+}
+
+// Watch implements types.Watcher. It keeps a page token of the changes API in
+// the watch state and checks it for changes every gdriveChangesInterval.
+//
+// Drive returns the current metadata of changed files without saying
+// whether they are new, so changed files are reported as modified and
+// changed folders as created.
+func (b *GoogleDriveBackend) Watch(ctx context.Context, state types.WatchState, emit func(types.ChangeEvent)) error {
+	token, err := state.Cursor()
+	if err != nil {
+		return err
+	}
+	// Changes name the parent by its ID, which for the root of My Drive
+	// isn't "root"
+	var root gdriveFile
+	if err := b.do(http.MethodGet, b.api+"/files/"+url.PathEscape(b.root)+"?"+b.query("fields", "id").Encode(), "/", nil, &root); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.known[root.ID] = gdriveKnownFile{path: "/"}
+	b.mu.Unlock()
+
+	for ctx.Err() == nil {
+		if token == "" {
+			var start struct {
+				StartPageToken string `json:"startPageToken"`
+			}
+			query := b.query()
+			if b.drive != "" {
+				query.Set("driveId", b.drive)
+			}
+			if err := b.do(http.MethodGet, b.api+"/changes/startPageToken?"+query.Encode(), "/", nil, &start); err != nil {
+				return err
+			}
+			token = start.StartPageToken
+			if err := state.SetCursor(token); err != nil {
+				return err
+			}
+		}
+
+		token, err = b.readChanges(token, state, emit)
+		if hasGDriveStatus(err, http.StatusNotFound, http.StatusGone) {
+			token = b.resetCursor(state, emit)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(gdriveChangesInterval):
+		}
+	}
+
+	return nil
+}
+
+// readChanges pages through the changes after a page token, emitting an
+// event for each and storing the token of the next page after every page.
+// It returns the token to check for new changes with.
+func (b *GoogleDriveBackend) readChanges(token string, state types.WatchState, emit func(types.ChangeEvent)) (string, error) {
+	query := b.query(
+		"fields", "nextPageToken,newStartPageToken,changes(fileId,removed,file("+gdriveFileFields+"))",
+		"pageSize", "1000",
+		"includeRemoved", "true",
+		"includeItemsFromAllDrives", "true",
+	)
+	if b.drive != "" {
+		query.Set("driveId", b.drive)
+	}
+
+	for {
+		query.Set("pageToken", token)
+		var page struct {
+			Changes           []gdriveChange `json:"changes"`
+			NextPageToken     string         `json:"nextPageToken"`
+			NewStartPageToken string         `json:"newStartPageToken"`
+		}
+		if err := b.do(http.MethodGet, b.api+"/changes?"+query.Encode(), "/", nil, &page); err != nil {
+			return token, err
+		}
+
+		if len(page.Changes) > 0 {
+			// Folders may have been renamed or moved
+			b.mu.Lock()
+			b.dirs = map[string]string{}
+			b.mu.Unlock()
+		}
+		rootChanged := false
+		for i := range page.Changes {
+			if !b.emitChange(&page.Changes[i], emit) {
+				rootChanged = true
+			}
+		}
+		if rootChanged {
+			emit(types.ChangeEvent{Path: "/", Kind: types.ChangeModified, IsDir: true})
+		}
+
+		token = page.NextPageToken
+		if token == "" {
+			token = page.NewStartPageToken
+		}
+		if token == "" {
+			return "", fmt.Errorf("googledrive: changes response has no page token")
+		}
+		if err := state.SetCursor(token); err != nil {
+			return token, err
+		}
+		if page.NextPageToken == "" {
+			return token, nil
+		}
+	}
+}
+
+// emitChange reports a changed file, and returns false if its path can't be
+// worked out from where it or its folder was seen before. A file moved or
+// renamed is reported as deleted at its old path.
+func (b *GoogleDriveBackend) emitChange(change *gdriveChange, emit func(types.ChangeEvent)) bool {
+	b.mu.Lock()
+	old, seen := b.known[change.FileID]
+	var current gdriveKnownFile
+	located := false
+	if file := change.File; file != nil && !change.Removed && !file.Trashed {
+		current = gdriveKnownFile{name: file.Name, parent: file.parent()}
+		if seen && old.name == current.name && old.parent == current.parent {
+			// Unchanged names keep the name told apart from duplicates
+			current.path, located = old.path, true
+		} else if folder, ok := b.known[current.parent]; ok {
+			if name, ok := b.displayName(file); ok {
+				current.path, located = path.Join(folder.path, name), true
+			}
+		}
+	}
+	if located {
+		b.known[change.FileID] = current
+	} else {
+		delete(b.known, change.FileID)
+	}
+	b.mu.Unlock()
+
+	isDir := change.File != nil && change.File.isDir()
+	if seen && (!located || old.path != current.path) {
+		emit(types.ChangeEvent{Path: old.path, Kind: types.ChangeDeleted, IsDir: isDir})
+	}
+	if located {
+		kind := types.ChangeModified
+		if isDir {
+			kind = types.ChangeCreated
+		}
+		emit(types.ChangeEvent{Path: current.path, Kind: kind, IsDir: isDir})
+	}
+	return seen || located
+}
+
+// resetCursor forgets a page token Drive no longer accepts. Changes since it
+// was issued are lost, so the root is reported as modified for clients to
+// list everything again.
+func (b *GoogleDriveBackend) resetCursor(state types.WatchState, emit func(types.ChangeEvent)) string {
+	state.SetCursor("")
+	emit(types.ChangeEvent{Path: "/", Kind: types.ChangeModified, IsDir: true})
+	return ""
+}
+
+func (b *GoogleDriveBackend) Reconnect() error {
+	return b.connect()
+}
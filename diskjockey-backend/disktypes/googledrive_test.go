@@ -0,0 +1,788 @@
+package disktypes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// fakeDrive is an in-memory Drive v3 server implementing the routes
+// GoogleDriveBackend uses. Like Drive, it identifies files by ID, lets a
+// folder hold several files with the same name and keeps trashed files.
+type fakeDrive struct {
+	t        *testing.T
+	server   *httptest.Server
+	pageSize int // Files per page of a listing
+
+	mu          sync.Mutex
+	files       map[string]*fakeDriveFile // ID -> file
+	nextID      int
+	clock       time.Time
+	changes     []string                     // IDs of the files changed, in order
+	sessions    map[string]*fakeDriveSession // Resumable upload session ID -> upload
+	failChunk   bool                         // Fail the next upload chunk once
+	chunks      int                          // Upload chunks received
+	rateLimited int                          // Requests left to rate limit
+}
+
+type fakeDriveFile struct {
+	id       string
+	name     string
+	mimeType string
+	parent   string
+	data     []byte
+	version  int
+	created  time.Time
+	modified time.Time
+	trashed  bool
+}
+
+type fakeDriveSession struct {
+	id       string // File updated, "" for a new file
+	metadata fakeDriveMetadata
+	total    int
+	data     []byte
+}
+
+// fakeDriveMetadata is the metadata of files created or updated
+type fakeDriveMetadata struct {
+	Name     string   `json:"name"`
+	MimeType string   `json:"mimeType"`
+	Parents  []string `json:"parents"`
+	Trashed  *bool    `json:"trashed"`
+}
+
+const fakeDriveRoot = "0AfakeRoot"
+
+// fakeDriveQuery matches the only query of file listings the backend makes
+var fakeDriveQuery = regexp.MustCompile(`^'((?:[^'\\]|\\.)*)' in parents and trashed = false$`)
+
+func newFakeDrive(t *testing.T) *fakeDrive {
+	t.Helper()
+	f := &fakeDrive{
+		t:        t,
+		pageSize: 100,
+		files:    map[string]*fakeDriveFile{fakeDriveRoot: {id: fakeDriveRoot, name: "My Drive", mimeType: gdriveFolderType}},
+		clock:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		sessions: map[string]*fakeDriveSession{},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// backend mounts the fake with a long-lived access token and the options given
+func (f *fakeDrive) backend(options map[string]string) *GoogleDriveBackend {
+	f.t.Helper()
+	mountOptions := map[string]string{"api_url": f.server.URL}
+	for key, value := range options {
+		mountOptions[key] = value
+	}
+	b, err := GoogleDriveDiskType{}.New(&models.Mount{AccessToken: "test-token", Options: mountOptions})
+	if err != nil {
+		f.t.Fatalf("failed to mount fake drive: %v", err)
+	}
+	return b.(*GoogleDriveBackend)
+}
+
+// add creates a file in a folder, even next to one with the same name, and
+// returns its ID
+func (f *fakeDrive) add(parent, name, mimeType string, data []byte) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.createLocked(parent, name, mimeType, data).id
+}
+
+func (f *fakeDrive) createLocked(parent, name, mimeType string, data []byte) *fakeDriveFile {
+	f.nextID++
+	f.clock = f.clock.Add(time.Second)
+	file := &fakeDriveFile{
+		id:       fmt.Sprintf("file%d", f.nextID),
+		name:     name,
+		mimeType: mimeType,
+		parent:   parent,
+		data:     append([]byte(nil), data...),
+		version:  1,
+		created:  f.clock,
+		modified: f.clock,
+	}
+	f.files[file.id] = file
+	f.changes = append(f.changes, file.id)
+	return file
+}
+
+// put creates or replaces the file at a path, creating its folders
+func (f *fakeDrive) put(p string, data []byte) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parent := fakeDriveRoot
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	for _, name := range segments[:len(segments)-1] {
+		folder := f.childLocked(parent, name)
+		if folder == nil {
+			folder = f.createLocked(parent, name, gdriveFolderType, nil)
+		}
+		parent = folder.id
+	}
+	file := f.childLocked(parent, segments[len(segments)-1])
+	if file == nil {
+		return f.createLocked(parent, segments[len(segments)-1], "text/plain", data).id
+	}
+	f.updateLocked(file, data)
+	return file.id
+}
+
+func (f *fakeDrive) updateLocked(file *fakeDriveFile, data []byte) {
+	file.data = append([]byte(nil), data...)
+	file.version++
+	f.clock = f.clock.Add(time.Second)
+	file.modified = f.clock
+	f.changes = append(f.changes, file.id)
+}
+
+// childLocked returns the oldest file in a folder with a name, that isn't
+// trashed
+func (f *fakeDrive) childLocked(parent, name string) *fakeDriveFile {
+	var found *fakeDriveFile
+	for _, file := range f.files {
+		if file.parent == parent && file.name == name && !file.trashed && (found == nil || file.created.Before(found.created)) {
+			found = file
+		}
+	}
+	return found
+}
+
+// get returns the content of the file at a path
+func (f *fakeDrive) get(p string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file := &fakeDriveFile{id: fakeDriveRoot}
+	for _, name := range strings.Split(strings.Trim(p, "/"), "/") {
+		if file = f.childLocked(file.id, name); file == nil {
+			return nil, false
+		}
+	}
+	return file.data, file.mimeType != gdriveFolderType
+}
+
+func (f *fakeDrive) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		f.driveError(w, http.StatusUnauthorized, "authError")
+		return
+	}
+	if f.rateLimited > 0 {
+		f.rateLimited--
+		f.driveError(w, http.StatusForbidden, "userRateLimitExceeded")
+		return
+	}
+
+	route := r.URL.Path
+	switch {
+	case strings.HasPrefix(route, "/upload/session/"):
+		f.uploadChunk(w, r, strings.TrimPrefix(route, "/upload/session/"))
+	case strings.HasPrefix(route, "/upload/drive/v3/files"):
+		f.upload(w, r, strings.TrimPrefix(strings.TrimPrefix(route, "/upload/drive/v3/files"), "/"))
+	case route == "/drive/v3/changes/startPageToken" && r.Method == http.MethodGet:
+		writeJSON(w, map[string]any{"startPageToken": strconv.Itoa(len(f.changes))})
+	case route == "/drive/v3/changes" && r.Method == http.MethodGet:
+		f.listChanges(w, r.URL.Query().Get("pageToken"))
+	case route == "/drive/v3/files" && r.Method == http.MethodGet:
+		f.list(w, r)
+	case route == "/drive/v3/files" && r.Method == http.MethodPost:
+		var metadata fakeDriveMetadata
+		json.NewDecoder(r.Body).Decode(&metadata)
+		if metadata.MimeType != gdriveFolderType {
+			f.driveError(w, http.StatusBadRequest, "badRequest")
+			return
+		}
+		f.create(w, metadata, nil)
+	case strings.HasPrefix(route, "/drive/v3/files/"):
+		id, action, _ := strings.Cut(strings.TrimPrefix(route, "/drive/v3/files/"), "/")
+		f.file(w, r, id, action)
+	default:
+		f.driveError(w, http.StatusNotFound, "notFound")
+	}
+}
+
+// lookupLocked returns the file with an ID, which may be the "root" alias
+func (f *fakeDrive) lookupLocked(id string) *fakeDriveFile {
+	if id == "root" {
+		id = fakeDriveRoot
+	}
+	return f.files[id]
+}
+
+func (f *fakeDrive) list(w http.ResponseWriter, r *http.Request) {
+	match := fakeDriveQuery.FindStringSubmatch(r.URL.Query().Get("q"))
+	if match == nil {
+		f.driveError(w, http.StatusBadRequest, "invalidQuery")
+		return
+	}
+	folder := f.lookupLocked(strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(match[1]))
+	if folder == nil {
+		f.driveError(w, http.StatusNotFound, "notFound")
+		return
+	}
+	var children []*fakeDriveFile
+	for _, file := range f.files {
+		if file.parent == folder.id && !file.trashed {
+			children = append(children, file)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].id < children[j].id })
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+	start = min(start, len(children))
+	end := min(start+f.pageSize, len(children))
+	files := []any{}
+	for _, file := range children[start:end] {
+		files = append(files, file.json())
+	}
+	page := map[string]any{"files": files}
+	if end < len(children) {
+		page["nextPageToken"] = strconv.Itoa(end)
+	}
+	writeJSON(w, page)
+}
+
+// create adds a file from the metadata of a request
+func (f *fakeDrive) create(w http.ResponseWriter, metadata fakeDriveMetadata, data []byte) {
+	if len(metadata.Parents) != 1 || metadata.Name == "" {
+		f.driveError(w, http.StatusBadRequest, "badRequest")
+		return
+	}
+	parent := f.lookupLocked(metadata.Parents[0])
+	if parent == nil || parent.mimeType != gdriveFolderType {
+		f.driveError(w, http.StatusNotFound, "notFound")
+		return
+	}
+	mimeType := metadata.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	writeJSON(w, f.createLocked(parent.id, metadata.Name, mimeType, data).json())
+}
+
+func (f *fakeDrive) file(w http.ResponseWriter, r *http.Request, id, action string) {
+	file := f.lookupLocked(id)
+	if file == nil {
+		f.driveError(w, http.StatusNotFound, "notFound")
+		return
+	}
+	isGoogleFile := strings.HasPrefix(file.mimeType, gdriveDocsPrefix)
+
+	switch {
+	case r.Method == http.MethodGet && action == "export":
+		if !isGoogleFile || file.mimeType == gdriveFolderType {
+			f.driveError(w, http.StatusForbidden, "fileNotExportable")
+			return
+		}
+		fmt.Fprintf(w, "%s as %s", file.name, r.URL.Query().Get("mimeType"))
+	case r.Method == http.MethodGet && action == "" && r.URL.Query().Get("alt") == "media":
+		if isGoogleFile {
+			f.driveError(w, http.StatusForbidden, "fileNotDownloadable")
+			return
+		}
+		http.ServeContent(w, r, "", file.modified, bytes.NewReader(file.data))
+	case r.Method == http.MethodGet && action == "":
+		writeJSON(w, file.json())
+	case r.Method == http.MethodPatch && action == "":
+		if file.id == fakeDriveRoot {
+			f.driveError(w, http.StatusForbidden, "insufficientFilePermissions")
+			return
+		}
+		var metadata fakeDriveMetadata
+		json.NewDecoder(r.Body).Decode(&metadata)
+		if !f.patch(w, file, metadata, r.URL.Query().Get("addParents"), r.URL.Query().Get("removeParents")) {
+			return
+		}
+		writeJSON(w, file.json())
+	default:
+		f.driveError(w, http.StatusMethodNotAllowed, "badRequest")
+	}
+}
+
+// patch updates the metadata of a file, and reports whether it did
+func (f *fakeDrive) patch(w http.ResponseWriter, file *fakeDriveFile, metadata fakeDriveMetadata, addParent, removeParent string) bool {
+	if addParent != "" || removeParent != "" {
+		parent := f.lookupLocked(addParent)
+		if removeParent != file.parent || parent == nil || parent.mimeType != gdriveFolderType {
+			f.driveError(w, http.StatusBadRequest, "badRequest")
+			return false
+		}
+		// A folder can't be moved into itself
+		for ancestor := parent; ancestor != nil; ancestor = f.files[ancestor.parent] {
+			if ancestor.id == file.id {
+				f.driveError(w, http.StatusBadRequest, "badRequest")
+				return false
+			}
+		}
+		file.parent = parent.id
+	}
+	if metadata.Name != "" {
+		file.name = metadata.Name
+	}
+	if metadata.Trashed != nil {
+		file.trashed = *metadata.Trashed
+	}
+	file.version++
+	f.changes = append(f.changes, file.id)
+	return true
+}
+
+// upload handles multipart and resumable uploads creating a file, or
+// updating the file with an ID
+func (f *fakeDrive) upload(w http.ResponseWriter, r *http.Request, id string) {
+	if (id == "") != (r.Method == http.MethodPost) || (id != "" && r.Method != http.MethodPatch) {
+		f.driveError(w, http.StatusMethodNotAllowed, "badRequest")
+		return
+	}
+	if id != "" && f.lookupLocked(id) == nil {
+		f.driveError(w, http.StatusNotFound, "notFound")
+		return
+	}
+
+	var metadata fakeDriveMetadata
+	switch r.URL.Query().Get("uploadType") {
+	case "multipart":
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			f.driveError(w, http.StatusBadRequest, "badContent")
+			return
+		}
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		part, err := reader.NextPart()
+		if err != nil || json.NewDecoder(part).Decode(&metadata) != nil {
+			f.driveError(w, http.StatusBadRequest, "badContent")
+			return
+		}
+		part, err = reader.NextPart()
+		if err != nil {
+			f.driveError(w, http.StatusBadRequest, "badContent")
+			return
+		}
+		data, _ := io.ReadAll(part)
+		f.finishUpload(w, id, metadata, data)
+	case "resumable":
+		total, err := strconv.Atoi(r.Header.Get("X-Upload-Content-Length"))
+		if err != nil || json.NewDecoder(r.Body).Decode(&metadata) != nil {
+			f.driveError(w, http.StatusBadRequest, "badContent")
+			return
+		}
+		f.nextID++
+		session := fmt.Sprintf("session%d", f.nextID)
+		f.sessions[session] = &fakeDriveSession{id: id, metadata: metadata, total: total}
+		w.Header().Set("Location", f.server.URL+"/upload/session/"+session)
+	default:
+		f.driveError(w, http.StatusBadRequest, "badRequest")
+	}
+}
+
+func (f *fakeDrive) finishUpload(w http.ResponseWriter, id string, metadata fakeDriveMetadata, data []byte) {
+	if id == "" {
+		f.create(w, metadata, data)
+		return
+	}
+	file := f.lookupLocked(id)
+	f.updateLocked(file, data)
+	writeJSON(w, file.json())
+}
+
+// uploadChunk receives a chunk of a resumable upload, or reports the bytes
+// received for a Content-Range of bytes */total
+func (f *fakeDrive) uploadChunk(w http.ResponseWriter, r *http.Request, id string) {
+	session, ok := f.sessions[id]
+	if !ok || r.Method != http.MethodPut {
+		f.driveError(w, http.StatusNotFound, "notFound")
+		return
+	}
+	data, _ := io.ReadAll(r.Body)
+	contentRange := r.Header.Get("Content-Range")
+	if contentRange != fmt.Sprintf("bytes */%d", session.total) {
+		f.chunks++
+		if f.failChunk {
+			// The chunk arrived in part before the connection broke
+			f.failChunk = false
+			session.data = append(session.data, data[:len(data)/2]...)
+			f.driveError(w, http.StatusInternalServerError, "backendError")
+			return
+		}
+		var start, end, total int
+		if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil || start != len(session.data) || end-start+1 != len(data) || total != session.total {
+			f.driveError(w, http.StatusBadRequest, "badContentRange")
+			return
+		}
+		session.data = append(session.data, data...)
+	}
+	if len(session.data) < session.total {
+		if len(session.data) > 0 {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(session.data)-1))
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+		return
+	}
+	delete(f.sessions, id)
+	f.finishUpload(w, session.id, session.metadata, session.data)
+}
+
+// listChanges returns the files changed after the change a page token
+// numbers, with their current metadata
+func (f *fakeDrive) listChanges(w http.ResponseWriter, token string) {
+	n, err := strconv.Atoi(token)
+	if err != nil || n < 0 || n > len(f.changes) {
+		f.driveError(w, http.StatusNotFound, "notFound")
+		return
+	}
+	changes := []any{}
+	for _, id := range f.changes[n:] {
+		changes = append(changes, map[string]any{"fileId": id, "removed": false, "file": f.files[id].json()})
+	}
+	writeJSON(w, map[string]any{"changes": changes, "newStartPageToken": strconv.Itoa(len(f.changes))})
+}
+
+func (file *fakeDriveFile) json() map[string]any {
+	m := map[string]any{
+		"id":           file.id,
+		"name":         file.name,
+		"mimeType":     file.mimeType,
+		"modifiedTime": file.modified.Format(time.RFC3339),
+		"createdTime":  file.created.Format(time.RFC3339),
+		"version":      strconv.Itoa(file.version),
+		"trashed":      file.trashed,
+	}
+	if file.parent != "" {
+		m["parents"] = []string{file.parent}
+	}
+	if !strings.HasPrefix(file.mimeType, gdriveDocsPrefix) {
+		m["size"] = strconv.Itoa(len(file.data))
+	}
+	return m
+}
+
+func (f *fakeDrive) driveError(w http.ResponseWriter, status int, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{
+		"code":    status,
+		"message": http.StatusText(status),
+		"errors":  []any{map[string]any{"reason": reason}},
+	}})
+}
+
+func TestGoogleDriveListNames(t *testing.T) {
+	fake := newFakeDrive(t)
+	fake.pageSize = 2
+	folder := fake.add(fakeDriveRoot, "dir", gdriveFolderType, nil)
+	fake.add(folder, "report.pdf", "application/pdf", []byte("first"))
+	fake.add(folder, "a.txt", "text/plain", []byte("a"))
+	second := fake.add(folder, "report.pdf", "application/pdf", []byte("second"))
+	fake.add(folder, "Notes", "application/vnd.google-apps.document", nil)
+	fake.add(folder, "Survey", "application/vnd.google-apps.form", nil)
+	fake.add(folder, "a/b", "text/plain", []byte("slash"))
+	fake.add(folder, "sub", gdriveFolderType, nil)
+
+	b := fake.backend(nil)
+	infos, err := b.List("/dir")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, fmt.Sprintf("%s:%v", info.Name, info.IsDir))
+	}
+	sort.Strings(names)
+	want := []string{
+		"Notes.docx:false",
+		"a.txt:false",
+		"a／b:false",
+		"report [" + second + "].pdf:false",
+		"report.pdf:false",
+		"sub:true",
+	}
+	if fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("List = %v, want %v", names, want)
+	}
+
+	// The oldest file keeps the name
+	if data, err := b.Read("/dir/report.pdf"); err != nil || string(data) != "first" {
+		t.Errorf("Read of the shared name = %q, %v, want the oldest file", data, err)
+	}
+	if data, err := b.Read("/dir/report [" + second + "].pdf"); err != nil || string(data) != "second" {
+		t.Errorf("Read of the told apart name = %q, %v", data, err)
+	}
+	if data, err := b.Read("/dir/a／b"); err != nil || string(data) != "slash" {
+		t.Errorf("Read of a name with a slash = %q, %v", data, err)
+	}
+}
+
+func TestGoogleDriveReadWrite(t *testing.T) {
+	fake := newFakeDrive(t)
+	b := fake.backend(nil)
+
+	if err := b.Write("/dir/sub/a b.txt", []byte("0123456789")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if data, err := b.Read("/dir/sub/a b.txt"); err != nil || string(data) != "0123456789" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+	if data, err := b.ReadRange("/dir/sub/a b.txt", 2, 3); err != nil || string(data) != "234" {
+		t.Errorf("ReadRange = %q, %v", data, err)
+	}
+	if data, err := b.ReadRange("/dir/sub/a b.txt", 20, 3); err != nil || len(data) != 0 {
+		t.Errorf("ReadRange past the end = %q, %v", data, err)
+	}
+	info, err := b.Stat("/dir/sub/a b.txt")
+	if err != nil || info.IsDir || info.Size != 10 || info.ETag == "" {
+		t.Errorf("Stat = %+v, %v", info, err)
+	}
+	if _, err := b.Read("/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read of a missing file = %v, want ErrNotExist", err)
+	}
+
+	// Writing over a file updates it instead of adding one with the same name
+	if err := b.Write("/dir/sub/a b.txt", []byte("updated")); err != nil {
+		t.Fatalf("Write over a file failed: %v", err)
+	}
+	if names := fakeDriveNames(t, b, "/dir/sub"); fmt.Sprint(names) != "[a b.txt]" {
+		t.Errorf("folder holds %v after writing over the file", names)
+	}
+	if updated, _ := b.Stat("/dir/sub/a b.txt"); updated.ETag == info.ETag {
+		t.Errorf("version %q didn't change with the content", updated.ETag)
+	}
+
+	// A file at the destination of a rename is moved to the trash
+	if err := b.Write("/other/b.txt", []byte("replaced")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Rename("/dir/sub/a b.txt", "/other/b.txt"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if data, ok := fake.get("/other/b.txt"); !ok || string(data) != "updated" {
+		t.Errorf("renamed file holds %q", data)
+	}
+	if names := fakeDriveNames(t, b, "/other"); fmt.Sprint(names) != "[b.txt]" {
+		t.Errorf("destination folder holds %v after the rename", names)
+	}
+	if err := b.Delete("/other"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := b.Stat("/other/b.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat after Delete = %v, want ErrNotExist", err)
+	}
+}
+
+func fakeDriveNames(t *testing.T, b types.Backend, dir string) []string {
+	t.Helper()
+	infos, err := b.List(dir)
+	if err != nil {
+		t.Fatalf("List(%s) failed: %v", dir, err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestGoogleDriveExports(t *testing.T) {
+	fake := newFakeDrive(t)
+	fake.add(fakeDriveRoot, "Notes", "application/vnd.google-apps.document", nil)
+	b := fake.backend(nil)
+
+	want := "Notes as application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	if data, err := b.Read("/Notes.docx"); err != nil || string(data) != want {
+		t.Errorf("Read of a document = %q, %v, want the export", data, err)
+	}
+	if data, err := b.ReadRange("/Notes.docx", 6, 2); err != nil || string(data) != "as" {
+		t.Errorf("ReadRange of a document = %q, %v", data, err)
+	}
+	if err := b.Write("/Notes.docx", []byte("edited")); !errors.Is(err, types.ErrReadOnly) {
+		t.Errorf("Write over a document = %v, want ErrReadOnly", err)
+	}
+	// The extension of the export isn't part of the name
+	if err := b.Rename("/Notes.docx", "/Plan.docx"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	fake.mu.Lock()
+	renamed := fake.childLocked(fakeDriveRoot, "Plan")
+	fake.mu.Unlock()
+	if renamed == nil {
+		t.Error("renamed document isn't named Plan")
+	}
+
+	pdf := fake.backend(map[string]string{"export_format": "pdf"})
+	if names := fakeDriveNames(t, pdf, "/"); fmt.Sprint(names) != "[Plan.pdf]" {
+		t.Errorf("pdf exports are listed as %v", names)
+	}
+}
+
+func TestGoogleDriveFolderID(t *testing.T) {
+	fake := newFakeDrive(t)
+	fake.put("/secret.txt", []byte(hostileSecret))
+	fake.put("/mount/inside.txt", []byte(hostileInside))
+	fake.mu.Lock()
+	folder := fake.childLocked(fakeDriveRoot, "mount").id
+	fake.mu.Unlock()
+
+	b := fake.backend(map[string]string{"folder_id": folder})
+	if names := fakeDriveNames(t, b, "/"); fmt.Sprint(names) != "[inside.txt]" {
+		t.Errorf("mounted folder lists %v", names)
+	}
+	if err := b.Write("/new.txt", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := fake.get("/mount/new.txt"); !ok || string(data) != "new" {
+		t.Errorf("file written to the mounted folder holds %q", data)
+	}
+}
+
+func TestGoogleDriveLargeUploadResumes(t *testing.T) {
+	fake := newFakeDrive(t)
+	b := fake.backend(nil)
+	data := make([]byte, 2*gdriveUploadChunkSize+gdriveSimpleUploadLimit/5)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	for _, p := range []string{"/large.bin", "/large.bin"} {
+		fake.mu.Lock()
+		fake.failChunk = true
+		fake.chunks = 0
+		fake.mu.Unlock()
+		// Once for a new file, then for the file written
+		if err := b.Write(p, data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		stored, ok := fake.get(p)
+		if !ok || !bytes.Equal(stored, data) {
+			t.Fatalf("stored %d bytes that don't match the %d written", len(stored), len(data))
+		}
+		fake.mu.Lock()
+		chunks := fake.chunks
+		fake.mu.Unlock()
+		// The failed chunk, then two from where it broke off instead of the start
+		if chunks != 3 {
+			t.Errorf("uploaded in %d chunks, want 3", chunks)
+		}
+	}
+	if names := fakeDriveNames(t, b, "/"); fmt.Sprint(names) != "[large.bin]" {
+		t.Errorf("root holds %v after writing the file twice", names)
+	}
+}
+
+func TestGoogleDriveRetriesRateLimitedRequests(t *testing.T) {
+	fake := newFakeDrive(t)
+	fake.put("/a.txt", []byte("a"))
+	b := fake.backend(nil)
+	fake.mu.Lock()
+	fake.rateLimited = 1
+	fake.mu.Unlock()
+	if data, err := b.Read("/a.txt"); err != nil || string(data) != "a" {
+		t.Errorf("Read after a rate limited request = %q, %v", data, err)
+	}
+}
+
+func TestGoogleDriveUnauthorized(t *testing.T) {
+	fake := newFakeDrive(t)
+	b, err := GoogleDriveDiskType{}.New(&models.Mount{
+		AccessToken: "revoked",
+		Options:     map[string]string{"api_url": fake.server.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.List("/"); !errors.Is(err, types.ErrReauthRequired) {
+		t.Errorf("List with a revoked token = %v, want ErrReauthRequired", err)
+	}
+}
+
+func TestGoogleDriveWatch(t *testing.T) {
+	fake := newFakeDrive(t)
+	b := fake.backend(nil)
+	fake.put("/dir/old.txt", []byte("old"))
+	fake.put("/dir/moved.txt", []byte("moved"))
+	// Changes are placed by where files and folders were seen
+	fakeDriveNames(t, b, "/dir")
+	fake.mu.Lock()
+	state := &fakeWatchState{cursor: strconv.Itoa(len(fake.changes))}
+	fake.mu.Unlock()
+	fake.put("/dir/new.txt", []byte("new"))
+	if err := b.Delete("/dir/old.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Rename("/dir/moved.txt", "/dir/renamed.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var events []string
+	err := b.Watch(ctx, state, func(event types.ChangeEvent) {
+		events = append(events, fmt.Sprintf("%s %v", event.Path, event.Kind))
+		if len(events) == 4 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	want := []string{
+		fmt.Sprintf("/dir/new.txt %v", types.ChangeModified),
+		fmt.Sprintf("/dir/old.txt %v", types.ChangeDeleted),
+		fmt.Sprintf("/dir/moved.txt %v", types.ChangeDeleted),
+		fmt.Sprintf("/dir/renamed.txt %v", types.ChangeModified),
+	}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	fake.mu.Lock()
+	latest := strconv.Itoa(len(fake.changes))
+	fake.mu.Unlock()
+	if cursor, _ := state.Cursor(); cursor != latest {
+		t.Errorf("cursor = %q, want the latest page token %q", cursor, latest)
+	}
+}
+
+func TestGoogleDriveWatchResetsExpiredCursor(t *testing.T) {
+	fake := newFakeDrive(t)
+	b := fake.backend(nil)
+	state := &fakeWatchState{cursor: "expired"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var events []types.ChangeEvent
+	err := b.Watch(ctx, state, func(event types.ChangeEvent) {
+		events = append(events, event)
+		cancel()
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Path != "/" || events[0].Kind != types.ChangeModified {
+		t.Fatalf("events = %+v, want the root reported as modified", events)
+	}
+	if cursor, _ := state.Cursor(); cursor != "" {
+		t.Errorf("cursor = %q, want the expired one forgotten", cursor)
+	}
+}
@@ -0,0 +1,574 @@
+package disktypes
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/webdav"
+)
+
+// The hostile path suite mounts every disk type that can run in the test on
+// the "mount" directory of its remote, next to a secret.txt outside the
+// mount. Requests for hostile paths sent straight to the backend, without
+// the checks of the mount service, must fail or stay inside the mount.
+
+const (
+	hostileInside = "inside"
+	hostileSecret = "secret"
+)
+
+// hostileTarget is a disk type backend set up for the suite. outside reports
+// anything that changed outside the mount, nil for remotes without an
+// outside.
+type hostileTarget struct {
+	name     string
+	readOnly bool
+	setup    func(t *testing.T) (backend types.Backend, outside func() error)
+}
+
+// hostileRemote creates a remote directory holding the mount directory with
+// inside.txt, and secret.txt outside of it. It returns both directories and
+// a check that nothing outside the mount changed.
+func hostileRemote(t *testing.T) (string, string, func() error) {
+	t.Helper()
+	remote := t.TempDir()
+	mount := filepath.Join(remote, "mount")
+	if err := os.Mkdir(mount, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mount, "inside.txt"), []byte(hostileInside), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(remote, "secret.txt"), []byte(hostileSecret), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return remote, mount, func() error { return checkOutside(remote, "mount") }
+}
+
+// checkOutside reports any entry of remote other than secret.txt and the
+// skipped directories, and any change to secret.txt.
+func checkOutside(remote string, skip ...string) error {
+	entries, err := os.ReadDir(remote)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == "secret.txt" {
+			continue
+		}
+		skipped := false
+		for _, s := range skip {
+			skipped = skipped || entry.Name() == s
+		}
+		if !skipped {
+			return fmt.Errorf("%s was created outside the mount", entry.Name())
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(remote, "secret.txt"))
+	if err != nil {
+		return fmt.Errorf("secret.txt outside the mount is gone: %w", err)
+	}
+	if string(data) != hostileSecret {
+		return fmt.Errorf("secret.txt outside the mount was overwritten with %q", data)
+	}
+	return nil
+}
+
+// checkOutsideNames reports any object of a bucket mounted at the "mount/"
+// prefix that is outside of it, other than secret.txt, and any change to
+// secret.txt.
+func checkOutsideNames(names []string, secret []byte, found bool) error {
+	for _, name := range names {
+		if name != "secret.txt" && !strings.HasPrefix(name, "mount/") {
+			return fmt.Errorf("%s was created outside the mount", name)
+		}
+	}
+	if !found || string(secret) != hostileSecret {
+		return fmt.Errorf("secret.txt outside the mount was changed: %q, found %v", secret, found)
+	}
+	return nil
+}
+
+func mustNew(t *testing.T, dt types.DiskType, mount *models.Mount) types.Backend {
+	t.Helper()
+	b, err := dt.New(mount)
+	if err != nil {
+		t.Fatalf("failed to mount %s: %v", dt.Name(), err)
+	}
+	if c, ok := b.(io.Closer); ok {
+		t.Cleanup(func() { c.Close() })
+	}
+	return b
+}
+
+var hostileTargets = []hostileTarget{
+	{name: "localdirectory", setup: func(t *testing.T) (types.Backend, func() error) {
+		_, mount, outside := hostileRemote(t)
+		return mustNew(t, LocalDirectoryDiskType{}, &models.Mount{Path: mount}), outside
+	}},
+	{name: "memory", setup: func(t *testing.T) (types.Backend, func() error) {
+		b := mustNew(t, MemoryDiskType{}, &models.Mount{})
+		if err := b.Write("/inside.txt", []byte(hostileInside)); err != nil {
+			t.Fatal(err)
+		}
+		return b, func() error { return nil }
+	}},
+	{name: "dropbox", setup: func(t *testing.T) (types.Backend, func() error) {
+		fake := newFakeDropbox(t)
+		fake.put("/inside.txt", []byte(hostileInside))
+		return fake.backend(), func() error { return nil }
+	}},
+	{name: "onedrive", setup: func(t *testing.T) (types.Backend, func() error) {
+		fake := newFakeGraph(t)
+		fake.put("/inside.txt", []byte(hostileInside))
+		return fake.backend(), func() error { return nil }
+	}},
+	{name: "googledrive", setup: func(t *testing.T) (types.Backend, func() error) {
+		fake := newFakeDrive(t)
+		secret := fake.put("/secret.txt", []byte(hostileSecret))
+		fake.put("/mount/inside.txt", []byte(hostileInside))
+		fake.mu.Lock()
+		mount := fake.childLocked(fakeDriveRoot, "mount").id
+		fake.mu.Unlock()
+		return fake.backend(map[string]string{"folder_id": mount}), func() error {
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			for _, file := range fake.files {
+				if file.parent == fakeDriveRoot && !file.trashed && file.id != mount && file.id != secret {
+					return fmt.Errorf("%s was created outside the mount", file.name)
+				}
+			}
+			if file := fake.files[mount]; file.trashed || file.parent != fakeDriveRoot || file.name != "mount" {
+				return fmt.Errorf("the mounted folder was changed")
+			}
+			if file := fake.files[secret]; file.trashed || file.parent != fakeDriveRoot || string(file.data) != hostileSecret {
+				return fmt.Errorf("secret.txt outside the mount was changed")
+			}
+			return nil
+		}
+	}},
+	{name: "webdav", setup: func(t *testing.T) (types.Backend, func() error) {
+		remote, _, outside := hostileRemote(t)
+		server := httptest.NewServer(&webdav.Handler{FileSystem: webdav.Dir(remote), LockSystem: webdav.NewMemLS()})
+		t.Cleanup(server.Close)
+		return mustNew(t, WebDAVDiskType{}, &models.Mount{Path: "/mount", Options: map[string]string{"url": server.URL, "auth": "none"}}), outside
+	}},
+	{name: "s3", setup: func(t *testing.T) (types.Backend, func() error) {
+		f := newFakeS3(t)
+		f.put("mount/inside.txt", []byte(hostileInside))
+		f.put("secret.txt", []byte(hostileSecret))
+		return f.mount("mount", nil), func() error {
+			secret, err := f.get("secret.txt")
+			return checkOutsideNames(f.keys(), secret, err == nil)
+		}
+	}},
+	{name: "azureblob", setup: func(t *testing.T) (types.Backend, func() error) {
+		f := newFakeAzure(t)
+		f.put("mount/inside.txt", []byte(hostileInside))
+		f.put("secret.txt", []byte(hostileSecret))
+		return f.mount("mount", nil), func() error {
+			secret, ok := f.get("secret.txt")
+			return checkOutsideNames(f.names(), secret, ok)
+		}
+	}},
+	{name: "gcs", setup: func(t *testing.T) (types.Backend, func() error) {
+		f := newFakeGCS(t)
+		f.put("mount/inside.txt", []byte(hostileInside))
+		f.put("secret.txt", []byte(hostileSecret))
+		return f.mount("mount", nil), func() error {
+			secret, ok := f.get("secret.txt")
+			return checkOutsideNames(f.names(), secret, ok)
+		}
+	}},
+	{name: "sftp", setup: func(t *testing.T) (types.Backend, func() error) {
+		_, mount, outside := hostileRemote(t)
+		port := newSFTPServer(t)
+		return mustNew(t, SFTPDiskType{}, &models.Mount{Host: "127.0.0.1", Port: port, Username: "test", Password: "test", Path: mount}), outside
+	}},
+	{name: "nfs", setup: func(t *testing.T) (types.Backend, func() error) {
+		_, mount, outside := hostileRemote(t)
+		return newNFSBackend(t, mount), outside
+	}},
+	{name: "http", readOnly: true, setup: func(t *testing.T) (types.Backend, func() error) {
+		remote, _, outside := hostileRemote(t)
+		server := httptest.NewServer(http.FileServer(http.Dir(remote)))
+		t.Cleanup(server.Close)
+		return mustNew(t, HTTPDiskType{}, &models.Mount{Options: map[string]string{"url": server.URL + "/mount/"}}), outside
+	}},
+	{name: "archive", readOnly: true, setup: func(t *testing.T) (types.Backend, func() error) {
+		// Entries escaping the archive root must be ignored, not served
+		archive := filepath.Join(t.TempDir(), "test.zip")
+		writeZip(t, archive, map[string]string{
+			"inside.txt":         hostileInside,
+			"../secret.txt":      hostileSecret,
+			"a/../../secret.txt": hostileSecret,
+			`..\..\secret.txt`:   hostileSecret,
+			"nul\x00/secret.txt": hostileSecret,
+		})
+		return mustNew(t, ArchiveDiskType{}, &models.Mount{Path: archive}), func() error { return nil }
+	}},
+	{name: "git", readOnly: true, setup: func(t *testing.T) (types.Backend, func() error) {
+		// Writable mounts need a bare repository, cloned from a work tree
+		remote := t.TempDir()
+		work := filepath.Join(remote, "work")
+		repo, err := git.PlainInit(work, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(work, "inside.txt"), []byte(hostileInside), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		worktree, err := repo.Worktree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add("inside.txt"); err != nil {
+			t.Fatal(err)
+		}
+		signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+		if _, err := worktree.Commit("initial", &git.CommitOptions{Author: signature}); err != nil {
+			t.Fatal(err)
+		}
+		bare := filepath.Join(remote, "repo.git")
+		if _, err := git.PlainClone(bare, true, &git.CloneOptions{URL: work}); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(remote, "secret.txt"), []byte(hostileSecret), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		b := mustNew(t, GitDiskType{}, &models.Mount{Options: map[string]string{"repo": bare}})
+		return b, func() error { return checkOutside(remote, "work", "repo.git") }
+	}},
+}
+
+// hostileEscapes all resolve to secret.txt outside the mount, or to the
+// mount root, when they aren't confined to the mount
+var hostileEscapes = []string{
+	"..",
+	"../secret.txt",
+	"/../secret.txt",
+	"../../../../secret.txt",
+	"/a/../../secret.txt",
+	`..\secret.txt`,
+	`/a/..\..\secret.txt`,
+	"/secret.txt\x00.jpg",
+	"/\uff0e\uff0e/secret.txt",
+	"/a\u2215..\u2215..\u2215secret.txt",
+}
+
+// hostileAliases are spellings of /inside.txt that are normalised
+var hostileAliases = []string{
+	"inside.txt",
+	"//inside.txt",
+	"/./inside.txt",
+	"/a/../inside.txt",
+	"/inside.txt/",
+}
+
+func TestHostilePaths(t *testing.T) {
+	for _, target := range hostileTargets {
+		t.Run(target.name, func(t *testing.T) {
+			backend, outside := target.setup(t)
+			stater, _ := backend.(types.Stater)
+			renamer, _ := backend.(types.Renamer)
+
+			for _, p := range hostileEscapes {
+				if data, err := backend.Read(p); err == nil && string(data) == hostileSecret {
+					t.Errorf("Read(%q) returned the secret outside the mount", p)
+				}
+				if files, err := backend.List(p); err == nil {
+					for _, f := range files {
+						if f.Name == "secret.txt" {
+							t.Errorf("List(%q) shows the secret outside the mount", p)
+						}
+					}
+				}
+				if stater != nil {
+					if info, err := stater.Stat(p); err == nil && info.Name == "secret.txt" {
+						t.Errorf("Stat(%q) found the secret outside the mount", p)
+					}
+				}
+			}
+
+			for _, p := range hostileEscapes {
+				if target.readOnly {
+					break
+				}
+				backend.Write(p, []byte("overwritten"))
+				if err := outside(); err != nil {
+					t.Fatalf("Write(%q): %v", p, err)
+				}
+				backend.Delete(p)
+				if err := outside(); err != nil {
+					t.Fatalf("Delete(%q): %v", p, err)
+				}
+				if renamer != nil {
+					renamer.Rename(p, "/moved-in.txt")
+					if err := outside(); err != nil {
+						t.Fatalf("Rename(%q, /moved-in.txt): %v", p, err)
+					}
+					if data, err := backend.Read("/moved-in.txt"); err == nil && string(data) == hostileSecret {
+						t.Fatalf("Rename(%q, /moved-in.txt) moved the secret into the mount", p)
+					}
+					if err := backend.Write("/inside.txt", []byte(hostileInside)); err != nil {
+						t.Fatalf("failed to restore inside.txt: %v", err)
+					}
+					renamer.Rename("/inside.txt", p)
+					if err := outside(); err != nil {
+						t.Fatalf("Rename(/inside.txt, %q): %v", p, err)
+					}
+				}
+				// Restore the mount for the next path
+				if err := backend.Write("/inside.txt", []byte(hostileInside)); err != nil {
+					t.Fatalf("failed to restore inside.txt after %q: %v", p, err)
+				}
+			}
+
+			if _, err := backend.List("/"); err != nil {
+				t.Fatalf("mount root is unusable after the hostile requests: %v", err)
+			}
+			for _, p := range hostileAliases {
+				data, err := backend.Read(p)
+				if err != nil {
+					t.Errorf("Read(%q) failed: %v", p, err)
+				} else if string(data) != hostileInside {
+					t.Errorf("Read(%q) = %q, want %q", p, data, hostileInside)
+				}
+			}
+
+			if !target.readOnly {
+				testUnicodeNames(t, backend)
+			}
+		})
+	}
+}
+
+// testUnicodeNames checks that names in either Unicode normalisation form
+// can be written and read back under the name they were written with.
+func testUnicodeNames(t *testing.T, backend types.Backend) {
+	names := []string{"/café.txt", "/nfd-café.txt"}
+	for _, name := range names {
+		if err := backend.Write(name, []byte(name)); err != nil {
+			t.Fatalf("Write(%q) failed: %v", name, err)
+		}
+		data, err := backend.Read(name)
+		if err != nil || string(data) != name {
+			t.Errorf("Read(%q) = %q, %v, want what was written", name, data, err)
+		}
+	}
+	files, err := backend.List("/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var listed []string
+	for _, f := range files {
+		listed = append(listed, "/"+f.Name)
+	}
+	sort.Strings(listed)
+	for _, name := range names {
+		found := false
+		for _, l := range listed {
+			found = found || l == name
+		}
+		if !found {
+			t.Errorf("List shows %q, want it to include %q", listed, name)
+		}
+	}
+}
+
+func TestLocalDirectorySymlinkEscape(t *testing.T) {
+	remote, mount, _ := hostileRemote(t)
+	escape := filepath.Join(remote, "elsewhere")
+	if err := os.Mkdir(escape, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"dir-link":      remote,
+		"file-link":     filepath.Join(remote, "secret.txt"),
+		"dangling-link": filepath.Join(escape, "missing.txt"),
+		"inner-link":    filepath.Join(mount, "inside.txt"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(mount, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := mustNew(t, LocalDirectoryDiskType{}, &models.Mount{Path: mount})
+
+	escapes := []struct {
+		name string
+		op   func() error
+	}{
+		{"read through a dir link", func() error { _, err := b.Read("/dir-link/secret.txt"); return err }},
+		{"list a dir link", func() error { _, err := b.List("/dir-link"); return err }},
+		{"read a file link", func() error { _, err := b.Read("/file-link"); return err }},
+		{"write through a dir link", func() error { return b.Write("/dir-link/new.txt", []byte("x")) }},
+		{"write through a file link", func() error { return b.Write("/file-link", []byte("x")) }},
+		{"write through a dangling link", func() error { return b.Write("/dangling-link", []byte("x")) }},
+		{"delete through a dir link", func() error { return b.Delete("/dir-link/secret.txt") }},
+		{"rename out through a dir link", func() error { return b.(types.Renamer).Rename("/inside.txt", "/dir-link/moved.txt") }},
+		{"rename in through a dir link", func() error { return b.(types.Renamer).Rename("/dir-link/secret.txt", "/moved.txt") }},
+	}
+	for _, tt := range escapes {
+		if err := tt.op(); !errors.Is(err, types.ErrInvalidPath) {
+			t.Errorf("%s: error = %v, want ErrInvalidPath", tt.name, err)
+		}
+		if err := checkOutside(remote, "mount", "elsewhere"); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+	}
+	if entries, _ := os.ReadDir(escape); len(entries) != 0 {
+		t.Fatalf("dangling link target was created outside the mount")
+	}
+
+	// Links inside the mount work, and a link itself can be removed
+	if data, err := b.Read("/inner-link"); err != nil || string(data) != hostileInside {
+		t.Errorf("Read of a link inside the mount = %q, %v", data, err)
+	}
+	if err := b.Delete("/file-link"); err != nil {
+		t.Errorf("Delete of a link failed: %v", err)
+	}
+	if err := checkOutside(remote, "mount", "elsewhere"); err != nil {
+		t.Fatalf("Delete of a link removed its target: %v", err)
+	}
+}
+
+// TestRemotePathMapping covers the path mapping of the disk types whose
+// servers can't run in the test.
+func TestRemotePathMapping(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(string) string
+		in   string
+		want string
+	}{
+		{"ftp", func(p string) string { return joinRemote("/srv/mount", p) }, "../../etc/passwd", "/srv/mount/etc/passwd"},
+		{"ftp", func(p string) string { return joinRemote("/srv/mount", p) }, "//a//b/", "/srv/mount/a/b"},
+		{"ftp", func(p string) string { return joinRemote("/srv/mount", p) }, "/a/../../..", "/srv/mount"},
+		{"ftp root", func(p string) string { return joinRemote("", p) }, "../x", "/x"},
+		{"smb", smbPath, "..", "."},
+		{"smb", smbPath, "/../../share/x", "share/x"},
+		{"smb", smbPath, "//a/", "a"},
+		{"smb", smbPath, "/a/./b/../c", "a/c"},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(tt.in); got != tt.want {
+			t.Errorf("%s: %q maps to %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+		if strings.Contains(tt.fn(tt.in), "..") {
+			t.Errorf("%s: %q maps to a path with ..", tt.name, tt.in)
+		}
+	}
+}
+
+func writeZip(t *testing.T, name string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for p, data := range files {
+		fw, err := w.Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(data))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newSFTPServer serves the local filesystem over SFTP on a loopback port,
+// for any user with the password "test".
+func newSFTPServer(t *testing.T) int {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "test" {
+				return nil, errors.New("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				// The payload of a subsystem request is the length prefixed name
+				req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+			}
+		}()
+		server, err := sftp.NewServer(channel)
+		if err != nil {
+			channel.Close()
+			continue
+		}
+		go func() {
+			server.Serve()
+			server.Close()
+		}()
+	}
+}
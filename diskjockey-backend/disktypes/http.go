@@ -0,0 +1,509 @@
+package disktypes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"golang.org/x/net/html"
+)
+
+// HTTPDiskType implements DiskType for browsing a web server's directory
+// indexes, such as nginx or Apache autoindex pages, as a read-only
+// filesystem. Servers can also list directories as JSON, in the format of
+// nginx's "autoindex_format json":
+//
+//	[{"name": "docs", "type": "directory", "mtime": "Mon, 02 Jan 2006 15:04:05 GMT"},
+//	 {"name": "app.tar.gz", "type": "file", "mtime": "...", "size": 1234}]
+
+type HTTPDiskType struct{}
+
+type HTTPBackend struct {
+	mount   *models.Mount
+	client  *http.Client
+	base    *url.URL // Directory URL of the mount root, without trailing slash
+	listing string   // Listing format: "" to detect it, "html" or "json"
+}
+
+func (HTTPDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &HTTPBackend{mount: mount}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (HTTPDiskType) Name() string {
+	return "http"
+}
+
+func (HTTPDiskType) Description() string {
+	return "Read-only web server directory index (autoindex or JSON listings)"
+}
+
+// ReadOnly implements types.ReadOnlyDiskType. Web servers are only read.
+func (HTTPDiskType) ReadOnly(mount *models.Mount) bool {
+	return true
+}
+
+func (HTTPDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return withReadCacheFields(types.DiskTypeConfigTemplate{
+		"url": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "URL of the directory to mount (e.g. https://artifacts.example.com/releases/)",
+			Required:    true,
+		},
+		"listing": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Directory listing format: auto, html or json (default auto, from the Content-Type)",
+			Required:    false,
+		},
+		"username": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Username for basic auth",
+			Required:    false,
+		},
+		"password": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Password for basic auth",
+			Required:    false,
+		},
+		"access_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Token sent as 'Authorization: Bearer <token>'",
+			Required:    false,
+		},
+		"ca_cert": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Path to a PEM encoded CA certificate to trust, for self-signed servers",
+			Required:    false,
+		},
+		"insecure_skip_verify": types.DiskTypeConfigField{
+			Type:        "bool",
+			Description: "Skip TLS certificate verification (not secure, testing only)",
+			Required:    false,
+		},
+	})
+}
+
+func (b *HTTPBackend) connect() error {
+	raw := b.mount.Option("url")
+	if raw == "" {
+		return fmt.Errorf("http: missing required config 'url'")
+	}
+	base, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("http: invalid url %q: %w", raw, err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return fmt.Errorf("http: url scheme must be http or https, got %q", base.Scheme)
+	}
+	if base.Host == "" {
+		return fmt.Errorf("http: url %q has no host", raw)
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	base.RawPath = ""
+	base.RawQuery = ""
+	base.Fragment = ""
+
+	switch listing := strings.ToLower(b.mount.Option("listing")); listing {
+	case "", "auto":
+		b.listing = ""
+	case "html", "json":
+		b.listing = listing
+	default:
+		return fmt.Errorf("http: unsupported listing format %q", listing)
+	}
+
+	transport, err := tlsTransport("http", b.mount)
+	if err != nil {
+		return err
+	}
+	transport.ResponseHeaderTimeout = 30 * time.Second
+
+	b.base = base
+	b.client = &http.Client{Transport: transport}
+	return nil
+}
+
+// url returns the URL of a path in the mount, with a trailing slash for
+// directories.
+func (b *HTTPBackend) url(p string, dir bool) *url.URL {
+	u := *b.base
+	u.Path = joinRemote(b.base.Path, p)
+	if dir && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &u
+}
+
+// request sends a request for a path, returning an error for any response
+// but a success.
+func (b *HTTPBackend) request(method string, u *url.URL, p string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if token := b.mount.AccessToken; token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if b.mount.Username != "" {
+		req.SetBasicAuth(b.mount.Username, b.mount.Password)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return nil, &fs.PathError{Op: strings.ToLower(method), Path: p, Err: &httpStatusError{status: resp.StatusCode}}
+}
+
+// httpStatusError is returned for a response that isn't a success. It wraps
+// fs.ErrNotExist for a missing path and types.ErrOffline for a server that's
+// unavailable.
+type httpStatusError struct {
+	status int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("server responded %d %s", e.status, http.StatusText(e.status))
+}
+
+func (e *httpStatusError) Unwrap() error {
+	switch e.status {
+	case http.StatusNotFound, http.StatusGone:
+		return fs.ErrNotExist
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return types.ErrOffline
+	default:
+		return nil
+	}
+}
+
+// hasStatus reports whether err is for a response with one of the statuses.
+func hasStatus(err error, statuses ...int) bool {
+	var statusErr *httpStatusError
+	return errors.As(err, &statusErr) && slices.Contains(statuses, statusErr.status)
+}
+
+// isDirResponse reports whether a response is for a directory, which is the
+// case when the server redirected the request to a URL with a trailing slash.
+func isDirResponse(resp *http.Response) bool {
+	return strings.HasSuffix(resp.Request.URL.Path, "/")
+}
+
+func (b *HTTPBackend) List(p string) ([]types.FileInfo, error) {
+	dirURL := b.url(p, true)
+	header := http.Header{"Accept": {"application/json, text/html;q=0.9, */*;q=0.1"}}
+	resp, err := b.request(http.MethodGet, dirURL, p, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	listing := b.listing
+	if listing == "" {
+		listing = "html"
+		if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "application/json" {
+			listing = "json"
+		}
+	}
+	if listing == "json" {
+		return parseJSONListing(resp.Body)
+	}
+	return parseHTMLListing(resp.Body, resp.Request.URL)
+}
+
+// httpJSONEntry is an entry of a JSON directory listing
+type httpJSONEntry struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"` // "directory" or "file"
+	IsDir bool   `json:"is_dir"`
+	Size  int64  `json:"size"`
+	MTime string `json:"mtime"`
+}
+
+func parseJSONListing(r io.Reader) ([]types.FileInfo, error) {
+	var entries []httpJSONEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("http: invalid JSON listing: %w", err)
+	}
+	var infos []types.FileInfo
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name, "/")
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+			continue
+		}
+		info := types.FileInfo{
+			Name:  name,
+			IsDir: entry.IsDir || entry.Type == "directory" || entry.Type == "dir" || strings.HasSuffix(entry.Name, "/"),
+		}
+		if !info.IsDir {
+			info.Size = entry.Size
+		}
+		for _, layout := range []string{time.RFC1123, time.RFC3339} {
+			if t, err := time.Parse(layout, entry.MTime); err == nil {
+				info.ModTime = t
+				break
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+var (
+	// Modification times as autoindex pages of nginx, Apache and lighttpd
+	// show them
+	httpIndexTime = regexp.MustCompile(`\d{2}-[A-Za-z]{3}-\d{4} \d{2}:\d{2}(:\d{2})?|\d{4}-\d{2}-\d{2} \d{2}:\d{2}(:\d{2})?|\d{4}-[A-Za-z]{3}-\d{2} \d{2}:\d{2}:\d{2}`)
+	// The size following the time, exact or rounded to a unit
+	httpIndexSize = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?)\s*([KMGT]i?B?)?(?:\s|$)`)
+)
+
+var httpIndexTimeLayouts = []string{
+	"02-Jan-2006 15:04", "02-Jan-2006 15:04:05",
+	"2006-01-02 15:04", "2006-01-02 15:04:05",
+	"2006-Jan-02 15:04:05",
+}
+
+// parseHTMLListing returns the entries of an autoindex page: the links to
+// the files and directories directly inside the listed one. Sort links,
+// links to the parent and links elsewhere are skipped. The text following
+// a link provides its modification time and size where the server shows them.
+func parseHTMLListing(r io.Reader, dirURL *url.URL) ([]types.FileInfo, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("http: invalid HTML listing: %w", err)
+	}
+
+	var order []string
+	entries := map[string]*types.FileInfo{}
+	texts := map[string]*strings.Builder{}
+	var current *strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			current = nil
+			for _, attr := range n.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				name, isDir, ok := httpIndexEntry(dirURL, attr.Val)
+				if !ok {
+					break
+				}
+				if _, seen := entries[name]; !seen {
+					order = append(order, name)
+					entries[name] = &types.FileInfo{Name: name, IsDir: isDir}
+					texts[name] = &strings.Builder{}
+				}
+				current = texts[name]
+			}
+			// The link text is the name, not details
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode {
+					walk(c)
+				}
+			}
+			return
+		}
+		if n.Type == html.TextNode && current != nil {
+			current.WriteString(n.Data)
+			current.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	infos := make([]types.FileInfo, 0, len(order))
+	for _, name := range order {
+		info := entries[name]
+		text := texts[name].String()
+		if loc := httpIndexTime.FindStringIndex(text); loc != nil {
+			for _, layout := range httpIndexTimeLayouts {
+				if t, err := time.Parse(layout, text[loc[0]:loc[1]]); err == nil {
+					info.ModTime = t
+					break
+				}
+			}
+			text = text[loc[1]:]
+		}
+		if match := httpIndexSize.FindStringSubmatch(text); match != nil && !info.IsDir {
+			info.Size = parseIndexSize(match[1], match[2])
+		}
+		infos = append(infos, *info)
+	}
+	return infos, nil
+}
+
+// httpIndexEntry resolves a link on the index page of dirURL, returning the
+// name of the entry it points to if that's directly inside the directory.
+func httpIndexEntry(dirURL *url.URL, href string) (string, bool, bool) {
+	ref, err := url.Parse(href)
+	if err != nil || ref.RawQuery != "" || (ref.Path == "" && ref.Fragment != "") {
+		return "", false, false
+	}
+	target := dirURL.ResolveReference(ref)
+	if target.Scheme != dirURL.Scheme || target.Host != dirURL.Host {
+		return "", false, false
+	}
+	rest, ok := strings.CutPrefix(target.Path, dirURL.Path)
+	if !ok {
+		return "", false, false
+	}
+	isDir := strings.HasSuffix(rest, "/")
+	name := strings.TrimSuffix(rest, "/")
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", false, false
+	}
+	return name, isDir, true
+}
+
+// parseIndexSize parses a size as autoindex pages show it, e.g. "1234",
+// "1.2K" or "3 MiB". Rounded sizes are approximate.
+func parseIndexSize(number, unit string) int64 {
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0
+	}
+	switch strings.ToUpper(unit[:min(len(unit), 1)]) {
+	case "K":
+		n *= 1 << 10
+	case "M":
+		n *= 1 << 20
+	case "G":
+		n *= 1 << 30
+	case "T":
+		n *= 1 << 40
+	}
+	return int64(n)
+}
+
+// Stat sends a HEAD request for the path. Servers redirect directories to
+// their URL with a trailing slash. Servers that don't support HEAD are
+// handled by listing the parent directory.
+func (b *HTTPBackend) Stat(p string) (types.FileInfo, error) {
+	if p == "/" {
+		return types.FileInfo{Name: "/", IsDir: true}, nil
+	}
+
+	resp, err := b.request(http.MethodHead, b.url(p, false), p, nil)
+	if hasStatus(err, http.StatusMethodNotAllowed, http.StatusNotImplemented) {
+		return b.statFromParent(p)
+	}
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	resp.Body.Close()
+
+	info := types.FileInfo{Name: path.Base(p), IsDir: isDirResponse(resp)}
+	if !info.IsDir {
+		info.Size = max(resp.ContentLength, 0)
+		info.ETag = resp.Header.Get("ETag")
+		if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+			info.ModTime = t
+		}
+	}
+	return info, nil
+}
+
+func (b *HTTPBackend) statFromParent(p string) (types.FileInfo, error) {
+	infos, err := b.List(path.Dir(p))
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	for _, info := range infos {
+		if info.Name == path.Base(p) {
+			return info, nil
+		}
+	}
+	return types.FileInfo{}, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+}
+
+func (b *HTTPBackend) Read(p string) ([]byte, error) {
+	resp, err := b.request(http.MethodGet, b.url(p, false), p, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if isDirResponse(resp) {
+		return nil, &fs.PathError{Op: "read", Path: p, Err: syscall.EISDIR}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ReadRange implements types.RangeReader with a ranged GET. Servers that
+// ignore the range send the whole file, which is cut down to it.
+func (b *HTTPBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	if length == 0 {
+		return []byte{}, nil
+	}
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}}
+	resp, err := b.request(http.MethodGet, b.url(p, false), p, header)
+	if hasStatus(err, http.StatusRequestedRangeNotSatisfiable) {
+		// The range starts past the end of the file
+		return []byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if isDirResponse(resp) {
+		return nil, &fs.PathError{Op: "read", Path: p, Err: syscall.EISDIR}
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		return io.ReadAll(io.LimitReader(resp.Body, length))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return types.SliceRange(data, offset, length), nil
+}
+
+func (b *HTTPBackend) Write(p string, data []byte) error {
+	return &fs.PathError{Op: "write", Path: p, Err: types.ErrReadOnly}
+}
+
+func (b *HTTPBackend) Delete(p string) error {
+	return &fs.PathError{Op: "delete", Path: p, Err: types.ErrReadOnly}
+}
+
+func (b *HTTPBackend) Rename(from, to string) error {
+	return &fs.PathError{Op: "rename", Path: from, Err: types.ErrReadOnly}
+}
+
+func (b *HTTPBackend) Reconnect() error {
+	if b.client != nil {
+		b.client.CloseIdleConnections()
+	}
+	return b.connect()
+}
+
+func (b *HTTPBackend) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
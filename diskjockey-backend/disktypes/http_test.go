@@ -0,0 +1,186 @@
+package disktypes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+func newHTTPBackend(t *testing.T, rawURL string, options map[string]string) *HTTPBackend {
+	t.Helper()
+	all := map[string]string{"url": rawURL}
+	for k, v := range options {
+		all[k] = v
+	}
+	return mustNew(t, HTTPDiskType{}, &models.Mount{Options: all}).(*HTTPBackend)
+}
+
+func TestHTTPListings(t *testing.T) {
+	pages := map[string]struct {
+		contentType string
+		body        string
+	}{
+		"nginx": {"text/html", `<html><body><h1>Index of /nginx/</h1><pre><a href="../">../</a>
+<a href="docs/">docs/</a>                                              02-Jan-2006 15:04                   -
+<a href="app.tar.gz">app.tar.gz</a>                                   03-Feb-2007 16:05                1234
+<a href="https://elsewhere.example.com/x">elsewhere</a>
+</pre></body></html>`},
+		"apache": {"text/html", `<html><body><table>
+<tr><th><a href="?C=N;O=D">Name</a></th></tr>
+<tr><td><a href="/">Parent Directory</a></td></tr>
+<tr><td><a href="docs/">docs/</a></td><td align="right">2006-01-02 15:04  </td><td align="right">  - </td></tr>
+<tr><td><a href="app.tar.gz">app.tar.gz</a></td><td align="right">2007-02-03 16:05  </td><td align="right">1.2K</td></tr>
+</table></body></html>`},
+		"json": {"application/json", `[{"name": "docs", "type": "directory", "mtime": "Mon, 02 Jan 2006 15:04:00 GMT"},
+{"name": "app.tar.gz", "type": "file", "mtime": "Sat, 03 Feb 2007 16:05:00 GMT", "size": 1234},
+{"name": "../escape", "type": "file"}]`},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[strings.Trim(r.URL.Path, "/")]
+		if !ok || !strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", page.contentType)
+		fmt.Fprint(w, page.body)
+	}))
+	defer server.Close()
+
+	for style := range pages {
+		t.Run(style, func(t *testing.T) {
+			b := newHTTPBackend(t, server.URL+"/"+style, nil)
+			infos, err := b.List("/")
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if len(infos) != 2 {
+				t.Fatalf("List = %+v, want docs and app.tar.gz", infos)
+			}
+			docs, app := infos[0], infos[1]
+			if docs.Name != "docs" || !docs.IsDir {
+				t.Errorf("first entry %+v, want the docs directory", docs)
+			}
+			if app.Name != "app.tar.gz" || app.IsDir || app.Size < 1200 || app.Size > 1234 {
+				t.Errorf("second entry %+v, want app.tar.gz of about 1234 bytes", app)
+			}
+			if want := time.Date(2007, 2, 3, 16, 5, 0, 0, time.UTC); !app.ModTime.Equal(want) {
+				t.Errorf("app.tar.gz modified %v, want %v", app.ModTime, want)
+			}
+		})
+	}
+}
+
+func TestHTTPStatAndRead(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "dir", "a.txt"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var noHead bool
+	files := http.FileServer(http.Dir(root))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/down/":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.Method == http.MethodHead && noHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			files.ServeHTTP(w, r)
+		}
+	}))
+	defer server.Close()
+	b := newHTTPBackend(t, server.URL, nil)
+
+	info, err := b.Stat("/dir/a.txt")
+	if err != nil || info.IsDir || info.Size != 10 || info.ModTime.IsZero() {
+		t.Errorf("Stat(/dir/a.txt) = %+v, %v", info, err)
+	}
+	if info, err := b.Stat("/dir"); err != nil || !info.IsDir {
+		t.Errorf("Stat(/dir) = %+v, %v, want a directory", info, err)
+	}
+	if _, err := b.Stat("/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a missing file = %v, want ErrNotExist", err)
+	}
+	noHead = true
+	if info, err := b.Stat("/dir/a.txt"); err != nil || info.Name != "a.txt" || info.IsDir {
+		t.Errorf("Stat without HEAD = %+v, %v, want it from the listing", info, err)
+	}
+
+	if data, err := b.Read("/dir/a.txt"); err != nil || string(data) != "0123456789" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+	if _, err := b.Read("/dir"); err == nil {
+		t.Errorf("Read of a directory succeeded")
+	}
+	ranges := []struct {
+		offset, length int64
+		want           string
+	}{
+		{2, 3, "234"},
+		{8, 10, "89"},
+		{10, 5, ""},
+		{3, 0, ""},
+	}
+	for _, r := range ranges {
+		if data, err := b.ReadRange("/dir/a.txt", r.offset, r.length); err != nil || string(data) != r.want {
+			t.Errorf("ReadRange(%d, %d) = %q, %v, want %q", r.offset, r.length, data, err, r.want)
+		}
+	}
+
+	if _, err := b.List("/down"); !errors.Is(err, types.ErrOffline) {
+		t.Errorf("List of an unavailable server = %v, want ErrOffline", err)
+	}
+	if err := b.Write("/dir/b.txt", []byte("b")); !errors.Is(err, types.ErrReadOnly) {
+		t.Errorf("Write = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestHTTPAuthorization(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, "[]")
+	}))
+	defer server.Close()
+
+	mounts := map[string]*models.Mount{
+		"Bearer token":           {AccessToken: "token"},
+		"Basic dXNlcjpzZWNyZXQ=": {Username: "user", Password: "secret"},
+		"":                       {},
+	}
+	for want, mount := range mounts {
+		mount.Options = map[string]string{"url": server.URL}
+		b := mustNew(t, HTTPDiskType{}, mount)
+		if _, err := b.List("/"); err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestHTTPOffersNoWriteOptions(t *testing.T) {
+	template := HTTPDiskType{}.ConfigTemplate()
+	for _, option := range []string{"write_back", "conflict_policy"} {
+		if _, ok := template[option]; ok {
+			t.Errorf("read-only http mounts offer %s", option)
+		}
+	}
+	if _, ok := template["cache"]; !ok {
+		t.Errorf("http mounts don't offer cache")
+	}
+}
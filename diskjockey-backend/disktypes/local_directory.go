@@ -1,12 +1,27 @@
 package disktypes
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/christhomas/diskjockey/diskjockey-backend/models"
 	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// Events for a path are held until it has been quiet for this long, so
+	// bursts like an editor's write-temp-and-rename save become one event
+	localWatchQuietPeriod = 250 * time.Millisecond
+	// Held events are delivered after this long even if the burst continues
+	localWatchMaxDelay = 2 * time.Second
 )
 
 // LocalDirectoryDiskType implements DiskType for mounting a local directory as a filesystem
@@ -52,14 +67,63 @@ func (b *LocalDirectoryBackend) connect() error {
 		return fmt.Errorf("localdirectory: missing required config 'path'")
 	}
 
-	b.Path = path
+	b.Path = filepath.Clean(path)
 
 	return nil
 }
 
+// localPath converts a mount path to a path on disk. Symlinks are allowed
+// inside the mounted directory, but a path that resolves outside of it is
+// rejected. If followLast is false, the last element itself may be a symlink
+// pointing anywhere, for operations that don't follow it, like removing it.
+func (b *LocalDirectoryBackend) localPath(p string, followLast bool) (string, error) {
+	clean, err := types.CleanPath(p)
+	if err != nil {
+		return "", err
+	}
+	full := filepath.Join(b.Path, filepath.FromSlash(clean))
+
+	root, err := filepath.EvalSymlinks(b.Path)
+	if err != nil {
+		return "", err
+	}
+
+	check := full
+	if !followLast && full != b.Path {
+		check = filepath.Dir(full)
+	}
+	// Resolve the longest part of the path that exists, anything after it
+	// will be created as real directories and files
+	resolved, rest := check, ""
+	for {
+		real, err := filepath.EvalSymlinks(resolved)
+		if err == nil {
+			resolved = filepath.Join(real, rest)
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) || filepath.Dir(resolved) == resolved {
+			return "", err
+		}
+		if _, err := os.Lstat(resolved); err == nil {
+			// A dangling symlink, following it could create a file anywhere
+			return "", fmt.Errorf("%w: %q is a broken symlink", types.ErrInvalidPath, p)
+		}
+		rest = filepath.Join(filepath.Base(resolved), rest)
+		resolved = filepath.Dir(resolved)
+	}
+
+	if resolved != root && !strings.HasPrefix(resolved, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q resolves outside the mount", types.ErrInvalidPath, p)
+	}
+	return full, nil
+}
+
 // Backend interface implementation
 func (b *LocalDirectoryBackend) List(path string) ([]types.FileInfo, error) {
-	dir := filepath.Join(b.Path, path)
+	dir, err := b.localPath(path, true)
+	if err != nil {
+		return nil, err
+	}
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, err
@@ -72,21 +136,49 @@ func (b *LocalDirectoryBackend) List(path string) ([]types.FileInfo, error) {
 			continue
 		}
 		infos = append(infos, types.FileInfo{
-			Name:  entry.Name(),
-			Size:  info.Size(),
-			IsDir: entry.IsDir(),
+			Name:    entry.Name(),
+			Size:    info.Size(),
+			IsDir:   entry.IsDir(),
+			ModTime: info.ModTime(),
 		})
 	}
 
 	return infos, nil
 }
 
+func (b *LocalDirectoryBackend) Stat(path string) (types.FileInfo, error) {
+	fullPath, err := b.localPath(path, true)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return types.FileInfo{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		IsDir:   info.IsDir(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
 func (b *LocalDirectoryBackend) Read(path string) ([]byte, error) {
-	return os.ReadFile(filepath.Join(b.Path, path))
+	fullPath, err := b.localPath(path, true)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(fullPath)
 }
 
 func (b *LocalDirectoryBackend) Write(path string, data []byte) error {
-	fullPath := filepath.Join(b.Path, path)
+	fullPath, err := b.localPath(path, true)
+	if err != nil {
+		return err
+	}
+	if fullPath == b.Path {
+		return fmt.Errorf("cannot write to root directory")
+	}
 	dir := filepath.Dir(fullPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -96,9 +188,212 @@ func (b *LocalDirectoryBackend) Write(path string, data []byte) error {
 }
 
 func (b *LocalDirectoryBackend) Delete(path string) error {
-	return os.Remove(filepath.Join(b.Path, path))
+	fullPath, err := b.localPath(path, false)
+	if err != nil {
+		return err
+	}
+	if fullPath == b.Path {
+		return fmt.Errorf("cannot delete root directory")
+	}
+	return os.Remove(fullPath)
+}
+
+func (b *LocalDirectoryBackend) Rename(from, to string) error {
+	fromPath, err := b.localPath(from, false)
+	if err != nil {
+		return err
+	}
+	toPath, err := b.localPath(to, false)
+	if err != nil {
+		return err
+	}
+	if fromPath == b.Path || toPath == b.Path {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	if err := os.MkdirAll(filepath.Dir(toPath), 0755); err != nil {
+		return err
+	}
+	return os.Rename(fromPath, toPath)
 }
 
 func (b *LocalDirectoryBackend) Reconnect() error {
 	return nil
 }
+
+// Watch implements types.Watcher using fsnotify. Every directory below the
+// mounted path is watched, including directories created while watching.
+// Events are coalesced per path and reported by comparing the path once it
+// settles with what was known about it before: a file created and removed
+// within a burst isn't reported at all, and a file replaced by a rename, as
+// editors do when saving, is reported as modified.
+func (b *LocalDirectoryBackend) Watch(ctx context.Context, state types.WatchState, emit func(types.ChangeEvent)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// Every path below the mounted path, and whether it is a directory
+	known := make(map[string]bool)
+	if err := b.watchTree(watcher, b.Path, func(p string, isDir bool) { known[p] = isDir }); err != nil {
+		return err
+	}
+
+	pending := make(map[string]struct{})
+	var firstPending time.Time
+	timer := time.NewTimer(localWatchQuietPeriod)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+
+			pending[event.Name] = struct{}{}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+					// Anything created in the directory before it was watched
+					// has to be reported too
+					b.watchTree(watcher, event.Name, func(p string, isDir bool) { pending[p] = struct{}{} })
+				}
+			}
+			if event.Has(fsnotify.Rename) || event.Has(fsnotify.Remove) {
+				unwatchTree(watcher, event.Name)
+			}
+
+			if firstPending.IsZero() {
+				firstPending = time.Now()
+			}
+			delay := localWatchQuietPeriod
+			if remaining := localWatchMaxDelay - time.Since(firstPending); remaining < delay {
+				delay = max(remaining, 0)
+			}
+			timer.Reset(delay)
+
+		case <-timer.C:
+			b.flushChanges(known, pending, emit)
+			pending = make(map[string]struct{})
+			firstPending = time.Time{}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if !errors.Is(err, fsnotify.ErrEventOverflow) {
+				return err
+			}
+			// Events were lost, so clients have to list everything again
+			fmt.Fprintf(os.Stderr, "[LocalDirectory] Watch events lost for %s\n", b.Path)
+			emit(types.ChangeEvent{Path: "/", Kind: types.ChangeModified, IsDir: true})
+		}
+	}
+}
+
+// watchTree adds a watch for dir and every directory below it, calling found
+// with every entry below dir. Directories that can't be watched are logged
+// and skipped.
+func (b *LocalDirectoryBackend) watchTree(watcher *fsnotify.Watcher, dir string, found func(p string, isDir bool)) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dir {
+				return err
+			}
+			return nil
+		}
+		if p != dir {
+			found(p, d.IsDir())
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(p); err != nil {
+			if p == b.Path {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "[LocalDirectory] Failed to watch %s: %v\n", p, err)
+		}
+		return nil
+	})
+}
+
+// unwatchTree removes the watches of a directory that was moved or removed
+// and of every directory below it.
+func unwatchTree(watcher *fsnotify.Watcher, dir string) {
+	prefix := dir + string(filepath.Separator)
+	for _, p := range watcher.WatchList() {
+		if p == dir || strings.HasPrefix(p, prefix) {
+			watcher.Remove(p)
+		}
+	}
+}
+
+// flushChanges emits an event for each pending path by comparing its current
+// state with the known one, and updates the known state.
+func (b *LocalDirectoryBackend) flushChanges(known map[string]bool, pending map[string]struct{}, emit func(types.ChangeEvent)) {
+	// Parents first, so entries of a removed directory can be recognised
+	names := make([]string, 0, len(pending))
+	for name := range pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rel, err := filepath.Rel(b.Path, name)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		event := types.ChangeEvent{Path: "/" + filepath.ToSlash(rel)}
+
+		wasDir, existed := known[name]
+		info, err := os.Lstat(name)
+		exists := err == nil
+
+		switch {
+		case exists && !existed:
+			event.Kind = types.ChangeCreated
+			event.IsDir = info.IsDir()
+		case exists && info.IsDir() != wasDir:
+			emit(types.ChangeEvent{Path: event.Path, Kind: types.ChangeDeleted, IsDir: wasDir})
+			forgetTree(known, name)
+			event.Kind = types.ChangeCreated
+			event.IsDir = info.IsDir()
+		case exists && info.IsDir():
+			// Changes inside a directory are reported for its entries
+			continue
+		case exists:
+			event.Kind = types.ChangeModified
+		case existed:
+			event.Kind = types.ChangeDeleted
+			event.IsDir = wasDir
+			forgetTree(known, name)
+		default:
+			// Created and removed again, e.g. an editor's temporary file
+			continue
+		}
+
+		if exists {
+			known[name] = info.IsDir()
+		}
+		emit(event)
+	}
+}
+
+// forgetTree removes a path and everything below it from the known paths.
+// Entries below a removed directory are covered by its event.
+func forgetTree(known map[string]bool, name string) {
+	delete(known, name)
+	prefix := name + string(filepath.Separator)
+	for p := range known {
+		if strings.HasPrefix(p, prefix) {
+			delete(known, p)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package disktypes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/fsnotify/fsnotify"
+)
+
+// localWatch runs Watch on a local directory mount until the test ends, and
+// returns the channel its events are sent to. It returns once the watch is
+// known to be running, which it finds out by touching a file it creates.
+func localWatch(t *testing.T, dir string) <-chan types.ChangeEvent {
+	t.Helper()
+	ready := filepath.Join(dir, ".ready")
+	if err := os.WriteFile(ready, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := mustNew(t, LocalDirectoryDiskType{}, &models.Mount{Path: dir}).(*LocalDirectoryBackend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan types.ChangeEvent, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Watch(ctx, &fakeWatchState{}, func(event types.ChangeEvent) { events <- event })
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Watch failed: %v", err)
+		}
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if err := os.WriteFile(ready, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := localEvents(t, events, localWatchQuietPeriod*2); slices.Contains(got, "modified /.ready") {
+			// The modification is reported once the watch is running, and
+			// anything else belongs to earlier attempts
+			return events
+		}
+	}
+	t.Fatal("Watch never reported a change")
+	return nil
+}
+
+// localEvents collects the events of the next flush, waiting up to wait for
+// the first, and returns them sorted as "kind path", with a trailing slash for
+// directories.
+func localEvents(t *testing.T, events <-chan types.ChangeEvent, wait time.Duration) []string {
+	t.Helper()
+	kinds := map[types.ChangeKind]string{
+		types.ChangeCreated:  "created",
+		types.ChangeModified: "modified",
+		types.ChangeDeleted:  "deleted",
+	}
+	var got []string
+	timeout := time.After(wait)
+	for {
+		select {
+		case event := <-events:
+			s := kinds[event.Kind] + " " + event.Path
+			if event.IsDir {
+				s += "/"
+			}
+			got = append(got, s)
+			// Everything from one flush arrives together
+			timeout = time.After(localWatchQuietPeriod / 2)
+		case <-timeout:
+			sort.Strings(got)
+			return got
+		}
+	}
+}
+
+func expectLocalEvents(t *testing.T, events <-chan types.ChangeEvent, want ...string) {
+	t.Helper()
+	got := localEvents(t, events, localWatchMaxDelay+2*time.Second)
+	sort.Strings(want)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+func writeLocal(t *testing.T, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalDirectoryWatchCoalesces(t *testing.T) {
+	dir := t.TempDir()
+	events := localWatch(t, dir)
+	a := filepath.Join(dir, "a.txt")
+
+	// A new file written several times is reported once, and a file created
+	// and removed again within the burst not at all
+	writeLocal(t, a, "1")
+	writeLocal(t, a, "12")
+	writeLocal(t, filepath.Join(dir, "tmp.txt"), "tmp")
+	if err := os.Remove(filepath.Join(dir, "tmp.txt")); err != nil {
+		t.Fatal(err)
+	}
+	expectLocalEvents(t, events, "created /a.txt")
+
+	writeLocal(t, a, "123")
+	writeLocal(t, a, "1234")
+	expectLocalEvents(t, events, "modified /a.txt")
+
+	// Saving like an editor, by renaming a temporary file over the original
+	writeLocal(t, filepath.Join(dir, "a.txt.swp"), "saved")
+	if err := os.Rename(filepath.Join(dir, "a.txt.swp"), a); err != nil {
+		t.Fatal(err)
+	}
+	expectLocalEvents(t, events, "modified /a.txt")
+
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	expectLocalEvents(t, events, "deleted /a.txt")
+
+	// A file replaced by a directory
+	if err := os.Mkdir(filepath.Join(dir, ".ready.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, ".ready")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, ".ready.d"), filepath.Join(dir, ".ready")); err != nil {
+		t.Fatal(err)
+	}
+	expectLocalEvents(t, events, "deleted /.ready", "created /.ready/")
+}
+
+func TestLocalDirectoryWatchSubdirectories(t *testing.T) {
+	dir := t.TempDir()
+	events := localWatch(t, dir)
+
+	// Entries created in a new directory before it is watched are found by
+	// walking it
+	deep := filepath.Join(dir, "sub", "deep")
+	if err := os.MkdirAll(deep, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeLocal(t, filepath.Join(deep, "c.txt"), "c")
+	expectLocalEvents(t, events, "created /sub/", "created /sub/deep/", "created /sub/deep/c.txt")
+
+	// and the new directories are watched from then on
+	writeLocal(t, filepath.Join(deep, "d.txt"), "d")
+	expectLocalEvents(t, events, "created /sub/deep/d.txt")
+
+	// A renamed directory is watched under its new name
+	if err := os.Rename(filepath.Join(dir, "sub"), filepath.Join(dir, "moved")); err != nil {
+		t.Fatal(err)
+	}
+	expectLocalEvents(t, events, "deleted /sub/", "created /moved/", "created /moved/deep/",
+		"created /moved/deep/c.txt", "created /moved/deep/d.txt")
+	writeLocal(t, filepath.Join(dir, "moved", "deep", "e.txt"), "e")
+	expectLocalEvents(t, events, "created /moved/deep/e.txt")
+
+	if err := os.RemoveAll(filepath.Join(dir, "moved")); err != nil {
+		t.Fatal(err)
+	}
+	expectLocalEvents(t, events, "deleted /moved/")
+}
+
+func TestUnwatchTree(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"sub/deep", "subway", "other"} {
+		if err := os.MkdirAll(filepath.Join(dir, p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	b := &LocalDirectoryBackend{Path: dir}
+	if err := b.watchTree(watcher, dir, func(string, bool) {}); err != nil {
+		t.Fatalf("watchTree failed: %v", err)
+	}
+	if got := len(watcher.WatchList()); got != 5 {
+		t.Errorf("watching %d directories, want 5", got)
+	}
+
+	unwatchTree(watcher, filepath.Join(dir, "sub"))
+	var got []string
+	for _, p := range watcher.WatchList() {
+		rel, _ := filepath.Rel(dir, p)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	sort.Strings(got)
+	if want := "[. other subway]"; fmt.Sprint(got) != want {
+		t.Errorf("still watching %v, want %v", got, want)
+	}
+}
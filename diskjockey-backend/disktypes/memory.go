@@ -0,0 +1,430 @@
+package disktypes
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// MemoryDiskType implements DiskType for a scratch filesystem kept in memory.
+// Its contents are lost when the mount is unmounted or the backend restarts.
+// Latency and failures can be simulated, which makes it useful for testing
+// the layers above the backends without a real remote.
+
+type MemoryDiskType struct{}
+
+type MemoryBackend struct {
+	mount *models.Mount
+
+	mu      sync.Mutex
+	nodes   map[string]*memoryNode // By clean path, including "/"
+	size    int64                  // Total size of file contents
+	maxSize int64                  // 0 for no limit
+	version uint64                 // Last version given to a file, used as its ETag
+
+	latency   time.Duration
+	errorRate float64
+	random    *rand.Rand
+	failures  map[string]error // Errors to fail operations with, by operation
+}
+
+type memoryNode struct {
+	isDir   bool
+	data    []byte
+	modTime time.Time
+	etag    string
+}
+
+// Operations failures can be injected into, "" fails all of them
+const (
+	MemoryOpList   = "list"
+	MemoryOpStat   = "stat"
+	MemoryOpRead   = "read"
+	MemoryOpWrite  = "write"
+	MemoryOpDelete = "delete"
+	MemoryOpRename = "rename"
+)
+
+func (MemoryDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &MemoryBackend{mount: mount}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (MemoryDiskType) Name() string {
+	return "memory"
+}
+
+func (MemoryDiskType) Description() string {
+	return "Scratch filesystem kept in memory, emptied on unmount"
+}
+
+func (MemoryDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return types.DiskTypeConfigTemplate{
+		"max_size": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Maximum total size of the files in bytes (default unlimited)",
+			Required:    false,
+		},
+		"latency_ms": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Milliseconds every operation is delayed by, to simulate a remote (default 0)",
+			Required:    false,
+		},
+		"error_rate": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Fraction of operations that fail as if the remote was unreachable, from 0 to 1 (default 0)",
+			Required:    false,
+		},
+		"error_seed": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Seed choosing which operations fail, for repeatable runs (default random)",
+			Required:    false,
+		},
+	}
+}
+
+func (b *MemoryBackend) connect() error {
+	b.nodes = map[string]*memoryNode{"/": {isDir: true, modTime: time.Now()}}
+	b.failures = map[string]error{}
+
+	if raw := b.mount.Option("max_size"); raw != "" {
+		size, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || size < 0 {
+			return fmt.Errorf("memory: invalid max_size %q", raw)
+		}
+		b.maxSize = size
+	}
+
+	if raw := b.mount.Option("latency_ms"); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms < 0 {
+			return fmt.Errorf("memory: invalid latency_ms %q", raw)
+		}
+		b.latency = time.Duration(ms) * time.Millisecond
+	}
+
+	if raw := b.mount.Option("error_rate"); raw != "" {
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return fmt.Errorf("memory: error_rate must be a number from 0 to 1, got %q", raw)
+		}
+		b.errorRate = rate
+	}
+
+	seed := time.Now().UnixNano()
+	if raw := b.mount.Option("error_seed"); raw != "" {
+		var err error
+		if seed, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return fmt.Errorf("memory: invalid error_seed %q", raw)
+		}
+	}
+	b.random = rand.New(rand.NewSource(seed))
+
+	return nil
+}
+
+// SetLatency changes the delay of every following operation.
+func (b *MemoryBackend) SetLatency(latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.latency = latency
+}
+
+// FailWith makes every following call of an operation (one of the MemoryOp
+// constants, or "" for all of them) fail with err, until it's called again
+// with a nil error.
+func (b *MemoryBackend) FailWith(op string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		delete(b.failures, op)
+		return
+	}
+	b.failures[op] = err
+}
+
+// begin simulates the latency of an operation, then locks the backend unless
+// a failure was injected into the operation.
+func (b *MemoryBackend) begin(op, p string) error {
+	b.mu.Lock()
+	latency := b.latency
+	b.mu.Unlock()
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	b.mu.Lock()
+	err, ok := b.failures[op]
+	if !ok {
+		err, ok = b.failures[""]
+	}
+	if !ok && b.errorRate > 0 && b.random.Float64() < b.errorRate {
+		err = fmt.Errorf("%w: simulated failure", types.ErrOffline)
+	}
+	if err != nil {
+		b.mu.Unlock()
+		return &fs.PathError{Op: op, Path: p, Err: err}
+	}
+	return nil
+}
+
+// lookup returns the node at a clean path.
+func (b *MemoryBackend) lookup(op, p string) (*memoryNode, error) {
+	node, ok := b.nodes[p]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: p, Err: fs.ErrNotExist}
+	}
+	return node, nil
+}
+
+func (n *memoryNode) info(name string) types.FileInfo {
+	return types.FileInfo{
+		Name:    name,
+		Size:    int64(len(n.data)),
+		IsDir:   n.isDir,
+		ModTime: n.modTime,
+		ETag:    n.etag,
+	}
+}
+
+// children returns the paths of the nodes directly below a directory.
+func (b *MemoryBackend) children(dir string) []string {
+	var paths []string
+	for p := range b.nodes {
+		if p != "/" && path.Dir(p) == dir {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// below returns the paths of a node and every node below it.
+func (b *MemoryBackend) below(p string) []string {
+	paths := []string{p}
+	for other := range b.nodes {
+		if strings.HasPrefix(other, p+"/") {
+			paths = append(paths, other)
+		}
+	}
+	return paths
+}
+
+func (b *MemoryBackend) List(p string) ([]types.FileInfo, error) {
+	clean, err := types.CleanPath(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.begin(MemoryOpList, clean); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	dir, err := b.lookup("list", clean)
+	if err != nil {
+		return nil, err
+	}
+	if !dir.isDir {
+		return nil, &fs.PathError{Op: "list", Path: clean, Err: syscall.ENOTDIR}
+	}
+	var infos []types.FileInfo
+	for _, child := range b.children(clean) {
+		infos = append(infos, b.nodes[child].info(path.Base(child)))
+	}
+	return infos, nil
+}
+
+func (b *MemoryBackend) Stat(p string) (types.FileInfo, error) {
+	clean, err := types.CleanPath(p)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	if err := b.begin(MemoryOpStat, clean); err != nil {
+		return types.FileInfo{}, err
+	}
+	defer b.mu.Unlock()
+
+	node, err := b.lookup("stat", clean)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return node.info(path.Base(clean)), nil
+}
+
+func (b *MemoryBackend) Read(p string) ([]byte, error) {
+	clean, err := types.CleanPath(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.begin(MemoryOpRead, clean); err != nil {
+		return nil, err
+	}
+	defer b.mu.Unlock()
+
+	node, err := b.lookup("read", clean)
+	if err != nil {
+		return nil, err
+	}
+	if node.isDir {
+		return nil, &fs.PathError{Op: "read", Path: clean, Err: syscall.EISDIR}
+	}
+	return append([]byte(nil), node.data...), nil
+}
+
+func (b *MemoryBackend) Write(p string, data []byte) error {
+	return b.write(p, data, nil)
+}
+
+// WriteIfMatch implements types.ConditionalWriter.
+func (b *MemoryBackend) WriteIfMatch(p string, data []byte, etag string) error {
+	return b.write(p, data, &etag)
+}
+
+// write replaces the contents of a file, creating it and its parent
+// directories if needed. If etag isn't nil, the file has to exist with that
+// ETag.
+func (b *MemoryBackend) write(p string, data []byte, etag *string) error {
+	clean, err := types.CleanPath(p)
+	if err != nil {
+		return err
+	}
+	if clean == "/" {
+		return fmt.Errorf("cannot write to root directory")
+	}
+	if err := b.begin(MemoryOpWrite, clean); err != nil {
+		return err
+	}
+	defer b.mu.Unlock()
+
+	node, exists := b.nodes[clean]
+	if exists && node.isDir {
+		return &fs.PathError{Op: "write", Path: clean, Err: syscall.EISDIR}
+	}
+	if etag != nil && (!exists || node.etag != *etag) {
+		return fmt.Errorf("%w: %s", types.ErrConflict, clean)
+	}
+	var oldSize int64
+	if exists {
+		oldSize = int64(len(node.data))
+	}
+	if b.maxSize > 0 && b.size-oldSize+int64(len(data)) > b.maxSize {
+		return &fs.PathError{Op: "write", Path: clean, Err: syscall.ENOSPC}
+	}
+	for dir := path.Dir(clean); ; dir = path.Dir(dir) {
+		if parent, ok := b.nodes[dir]; ok {
+			if !parent.isDir {
+				return &fs.PathError{Op: "write", Path: clean, Err: syscall.ENOTDIR}
+			}
+			break
+		}
+	}
+
+	now := time.Now()
+	for dir := path.Dir(clean); b.nodes[dir] == nil; dir = path.Dir(dir) {
+		b.nodes[dir] = &memoryNode{isDir: true, modTime: now}
+	}
+	b.version++
+	b.nodes[clean] = &memoryNode{
+		data:    append([]byte(nil), data...),
+		modTime: now,
+		etag:    strconv.FormatUint(b.version, 10),
+	}
+	b.size += int64(len(data)) - oldSize
+	return nil
+}
+
+// Delete removes a file, or a directory and everything in it.
+func (b *MemoryBackend) Delete(p string) error {
+	clean, err := types.CleanPath(p)
+	if err != nil {
+		return err
+	}
+	if clean == "/" {
+		return fmt.Errorf("cannot delete root directory")
+	}
+	if err := b.begin(MemoryOpDelete, clean); err != nil {
+		return err
+	}
+	defer b.mu.Unlock()
+
+	if _, err := b.lookup("delete", clean); err != nil {
+		return err
+	}
+	for _, removed := range b.below(clean) {
+		b.size -= int64(len(b.nodes[removed].data))
+		delete(b.nodes, removed)
+	}
+	return nil
+}
+
+// Rename moves a file or directory, replacing an existing file at the target.
+func (b *MemoryBackend) Rename(from, to string) error {
+	fromClean, err := types.CleanPath(from)
+	if err != nil {
+		return err
+	}
+	toClean, err := types.CleanPath(to)
+	if err != nil {
+		return err
+	}
+	if fromClean == "/" || toClean == "/" {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	if err := b.begin(MemoryOpRename, fromClean); err != nil {
+		return err
+	}
+	defer b.mu.Unlock()
+
+	node, err := b.lookup("rename", fromClean)
+	if err != nil {
+		return err
+	}
+	if fromClean == toClean {
+		return nil
+	}
+	if strings.HasPrefix(toClean, fromClean+"/") {
+		return fmt.Errorf("cannot move %s into itself", fromClean)
+	}
+	target, replaced := b.nodes[toClean]
+	if replaced && (target.isDir || node.isDir) {
+		return &fs.PathError{Op: "rename", Path: toClean, Err: fs.ErrExist}
+	}
+	for dir := path.Dir(toClean); ; dir = path.Dir(dir) {
+		if parent, ok := b.nodes[dir]; ok {
+			if !parent.isDir {
+				return &fs.PathError{Op: "rename", Path: toClean, Err: syscall.ENOTDIR}
+			}
+			break
+		}
+	}
+
+	if replaced {
+		b.size -= int64(len(target.data))
+	}
+	now := time.Now()
+	for dir := path.Dir(toClean); b.nodes[dir] == nil; dir = path.Dir(dir) {
+		b.nodes[dir] = &memoryNode{isDir: true, modTime: now}
+	}
+	for _, moved := range b.below(fromClean) {
+		b.nodes[toClean+strings.TrimPrefix(moved, fromClean)] = b.nodes[moved]
+		delete(b.nodes, moved)
+	}
+	return nil
+}
+
+func (b *MemoryBackend) Reconnect() error {
+	return nil
+}
@@ -0,0 +1,215 @@
+package disktypes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+func newMemoryBackend(t *testing.T, options map[string]string) *MemoryBackend {
+	t.Helper()
+	return mustNew(t, MemoryDiskType{}, &models.Mount{Options: options}).(*MemoryBackend)
+}
+
+// memoryTree returns every path below dir with its contents, "/" for
+// directories, in the order they are listed
+func memoryTree(t *testing.T, b *MemoryBackend, dir string) string {
+	t.Helper()
+	infos, err := b.List(dir)
+	if err != nil {
+		t.Fatalf("List(%s) failed: %v", dir, err)
+	}
+	var tree string
+	for _, info := range infos {
+		p := joinRemote(dir, info.Name)
+		if info.IsDir {
+			tree += p + "/ " + memoryTree(t, b, p)
+			continue
+		}
+		data, err := b.Read(p)
+		if err != nil {
+			t.Fatalf("Read(%s) failed: %v", p, err)
+		}
+		tree += fmt.Sprintf("%s=%s ", p, data)
+	}
+	return tree
+}
+
+func TestMemoryMaxSize(t *testing.T) {
+	b := newMemoryBackend(t, map[string]string{"max_size": "10"})
+	if err := b.Write("/a.txt", []byte("aaaaaa")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := b.Write("/b.txt", []byte("bbbb")); err != nil {
+		t.Fatalf("Write up to max_size failed: %v", err)
+	}
+
+	// Writes past the limit fail without touching what is stored
+	if err := b.Write("/c.txt", []byte("c")); !errors.Is(err, syscall.ENOSPC) {
+		t.Errorf("Write past max_size = %v, want ENOSPC", err)
+	}
+	if err := b.Write("/a.txt", []byte("aaaaaaa")); !errors.Is(err, syscall.ENOSPC) {
+		t.Errorf("growing a file past max_size = %v, want ENOSPC", err)
+	}
+	if err := b.Write("/dir/c.txt", []byte("ccccccccccc")); !errors.Is(err, syscall.ENOSPC) {
+		t.Errorf("Write of a file larger than max_size = %v, want ENOSPC", err)
+	}
+	if got, want := memoryTree(t, b, "/"), "/a.txt=aaaaaa /b.txt=bbbb "; got != want {
+		t.Errorf("stored %q after failed writes, want %q", got, want)
+	}
+
+	// Space freed by shrinking, deleting or replacing a file can be reused
+	if err := b.Write("/a.txt", []byte("aa")); err != nil {
+		t.Fatalf("shrinking a file failed: %v", err)
+	}
+	if err := b.Write("/c.txt", []byte("cccc")); err != nil {
+		t.Errorf("Write into freed space failed: %v", err)
+	}
+	if err := b.Delete("/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Rename("/c.txt", "/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Write("/d.txt", []byte("dddddd")); err != nil {
+		t.Errorf("Write after a delete and a replacing rename failed: %v", err)
+	}
+
+	for _, size := range []string{"-1", "lots"} {
+		if _, err := (MemoryDiskType{}).New(&models.Mount{Options: map[string]string{"max_size": size}}); err == nil {
+			t.Errorf("accepted max_size %q", size)
+		}
+	}
+}
+
+func TestMemoryErrorSeedIsReproducible(t *testing.T) {
+	// failures returns which of 50 reads of a backend with a fixed seed fail
+	failures := func(seed string) string {
+		b := newMemoryBackend(t, map[string]string{"error_rate": "0.5", "error_seed": seed})
+		var failed []byte
+		for range 50 {
+			_, err := b.Stat("/")
+			switch {
+			case err == nil:
+				failed = append(failed, '.')
+			case errors.Is(err, types.ErrOffline) && types.IsUnreachable(err):
+				failed = append(failed, 'x')
+			default:
+				t.Fatalf("Stat failed with %v, want a simulated outage", err)
+			}
+		}
+		return string(failed)
+	}
+
+	first := failures("42")
+	if again := failures("42"); again != first {
+		t.Errorf("error_seed 42 failed %s, then %s", first, again)
+	}
+	if other := failures("43"); other == first {
+		t.Errorf("error_seed 42 and 43 both failed %s", first)
+	}
+	if !strings.Contains(first, ".") || !strings.Contains(first, "x") {
+		t.Errorf("error_rate 0.5 failed %s, want a mix", first)
+	}
+
+	for _, option := range []map[string]string{{"error_rate": "1.5"}, {"error_rate": "-0.1"}, {"error_seed": "seed"}} {
+		if _, err := (MemoryDiskType{}).New(&models.Mount{Options: option}); err == nil {
+			t.Errorf("accepted %v", option)
+		}
+	}
+}
+
+func TestMemoryFailWith(t *testing.T) {
+	b := newMemoryBackend(t, nil)
+	if err := b.Write("/a.txt", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	b.FailWith(MemoryOpRead, syscall.ECONNRESET)
+	if _, err := b.Read("/a.txt"); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("Read = %v, want the injected error", err)
+	}
+	if _, err := b.Stat("/a.txt"); err != nil {
+		t.Errorf("Stat failed with only reads failing: %v", err)
+	}
+	b.FailWith("", syscall.ECONNREFUSED)
+	if err := b.Write("/b.txt", nil); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("Write = %v, want the error injected for every operation", err)
+	}
+	if _, err := b.Read("/a.txt"); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("Read = %v, want its own injected error first", err)
+	}
+
+	b.FailWith("", nil)
+	b.FailWith(MemoryOpRead, nil)
+	if data, err := b.Read("/a.txt"); err != nil || string(data) != "a" {
+		t.Errorf("Read after clearing the failures = %q, %v", data, err)
+	}
+}
+
+func TestMemoryLatency(t *testing.T) {
+	b := newMemoryBackend(t, map[string]string{"latency_ms": "30"})
+	start := time.Now()
+	b.List("/")
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("List took %v with latency_ms 30", elapsed)
+	}
+	b.SetLatency(0)
+	start = time.Now()
+	b.List("/")
+	if elapsed := time.Since(start); elapsed >= 30*time.Millisecond {
+		t.Errorf("List took %v after removing the latency", elapsed)
+	}
+}
+
+func TestMemoryRename(t *testing.T) {
+	b := newMemoryBackend(t, nil)
+	for p, data := range map[string]string{"/dir/a.txt": "a", "/dir/sub/b.txt": "b", "/dirt.txt": "t", "/file.txt": "f"} {
+		if err := b.Write(p, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A directory moves with everything below it, but not with siblings
+	// sharing its name as a prefix
+	if err := b.Rename("/dir", "/new/place"); err != nil {
+		t.Fatalf("Rename of a directory failed: %v", err)
+	}
+	if got, want := memoryTree(t, b, "/"), "/dirt.txt=t /file.txt=f /new/ /new/place/ /new/place/a.txt=a /new/place/sub/ /new/place/sub/b.txt=b "; got != want {
+		t.Errorf("tree after the rename = %q, want %q", got, want)
+	}
+	if _, err := b.Stat("/dir/sub"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of the old directory = %v, want ErrNotExist", err)
+	}
+
+	// Files replace files, but directories are never replaced or moved into
+	// themselves
+	if err := b.Rename("/dirt.txt", "/file.txt"); err != nil {
+		t.Errorf("Rename over a file failed: %v", err)
+	}
+	if data, err := b.Read("/file.txt"); err != nil || string(data) != "t" {
+		t.Errorf("replaced file holds %q, %v", data, err)
+	}
+	if err := b.Rename("/file.txt", "/new/place"); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Rename over a directory = %v, want ErrExist", err)
+	}
+	if err := b.Rename("/new", "/new/place/inner"); err == nil {
+		t.Error("moved a directory into itself")
+	}
+	if err := b.Rename("/new", "/file.txt/new"); !errors.Is(err, syscall.ENOTDIR) {
+		t.Errorf("Rename below a file = %v, want ENOTDIR", err)
+	}
+	if err := b.Rename("/missing", "/other"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Rename of a missing path = %v, want ErrNotExist", err)
+	}
+	if got, want := memoryTree(t, b, "/"), "/file.txt=t /new/ /new/place/ /new/place/a.txt=a /new/place/sub/ /new/place/sub/b.txt=b "; got != want {
+		t.Errorf("tree after failed renames = %q, want %q", got, want)
+	}
+}
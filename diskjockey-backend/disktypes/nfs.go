@@ -0,0 +1,456 @@
+package disktypes
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"syscall"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/willscott/go-nfs-client/nfs"
+	"github.com/willscott/go-nfs-client/nfs/rpc"
+	"github.com/willscott/go-nfs-client/nfs/xdr"
+)
+
+// NFSDiskType implements DiskType for NFSv3 exports, spoken directly over
+// TCP without the operating system's NFS client. Requests are sent with
+// AUTH_UNIX credentials, so the server applies the permissions of the
+// configured uid and gid. When running as root the client connects from a
+// privileged port, otherwise the export needs the "insecure" option on
+// Linux servers.
+
+type NFSDiskType struct{}
+
+type NFSBackend struct {
+	mount  *models.Mount
+	client *rpc.Client // Connection to the mount daemon, kept to unmount on Close
+	target *nfs.Target
+	export string
+	auth   rpc.Auth
+}
+
+const (
+	// Default uid and gid, of the nobody user, when the current ones are unknown
+	nfsNobody = 65534
+	// Size of the buffer reads are done through, so every READ call asks
+	// for as much as the server allows instead of what io.ReadAll asks for
+	nfsReadBufferSize = 1 << 20
+)
+
+func (NFSDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &NFSBackend{mount: mount}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (NFSDiskType) Name() string {
+	return "nfs"
+}
+
+func (NFSDiskType) Description() string {
+	return "NFSv3 export"
+}
+
+func (NFSDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return withCacheFields(types.DiskTypeConfigTemplate{
+		"host": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "NFS server hostname or IP",
+			Required:    true,
+		},
+		"path": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Exported directory to mount (e.g. /srv/share)",
+			Required:    true,
+		},
+		"port": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "NFS port, leave empty to ask the portmapper on port 111",
+			Required:    false,
+		},
+		"mount_port": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Mount daemon port (default the NFS port if one is set, otherwise asked from the portmapper)",
+			Required:    false,
+		},
+		"uid": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "User id sent to the server (default the current user's)",
+			Required:    false,
+		},
+		"gid": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Group id sent to the server (default the current user's)",
+			Required:    false,
+		},
+		"machine_name": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Machine name sent to the server (default this computer's hostname)",
+			Required:    false,
+		},
+	})
+}
+
+func (b *NFSBackend) connect() error {
+	host := b.mount.Host
+	if host == "" {
+		return fmt.Errorf("nfs: missing required config 'host'")
+	}
+	b.export = b.mount.Path
+	if b.export == "" {
+		return fmt.Errorf("nfs: missing required config 'path'")
+	}
+
+	uid, err := nfsID(b.mount.Option("uid"), os.Getuid())
+	if err != nil {
+		return fmt.Errorf("nfs: invalid uid %q", b.mount.Option("uid"))
+	}
+	gid, err := nfsID(b.mount.Option("gid"), os.Getgid())
+	if err != nil {
+		return fmt.Errorf("nfs: invalid gid %q", b.mount.Option("gid"))
+	}
+	machine := b.mount.Option("machine_name")
+	if machine == "" {
+		if machine, err = os.Hostname(); err != nil {
+			machine = "diskjockey"
+		}
+	}
+	b.auth = rpc.NewAuthUnix(machine, uid, gid).Auth()
+
+	nfsPort := b.mount.Port
+	mountPort := nfsPort
+	if raw := b.mount.Option("mount_port"); raw != "" {
+		if mountPort, err = strconv.Atoi(raw); err != nil || mountPort <= 0 || mountPort > 65535 {
+			return fmt.Errorf("nfs: invalid mount_port %q", raw)
+		}
+	}
+
+	client, err := nfsDial(host, nfs.MountProg, nfs.MountVers, mountPort)
+	if err != nil {
+		return fmt.Errorf("nfs: failed to connect to mount daemon: %w", err)
+	}
+	fh, err := nfsMount(client, b.export, b.auth)
+	if err != nil {
+		client.Close()
+		return err
+	}
+
+	conn, err := nfsDial(host, nfs.Nfs3Prog, nfs.Nfs3Vers, nfsPort)
+	if err != nil {
+		nfsUnmount(client, b.export, b.auth)
+		client.Close()
+		return fmt.Errorf("nfs: failed to connect: %w", err)
+	}
+	// Directory listings aren't cached by the client, the mount caches them
+	target, err := nfs.NewTargetWithClient(conn, b.auth, fh, b.export, 0)
+	if err != nil {
+		conn.Close()
+		nfsUnmount(client, b.export, b.auth)
+		client.Close()
+		return fmt.Errorf("nfs: %w", nfsError("mount", "/", err))
+	}
+
+	b.client = client
+	b.target = target
+	return nil
+}
+
+// nfsID parses a uid or gid option, falling back to the current id, or
+// nobody where there are no numeric ids.
+func nfsID(raw string, current int) (uint32, error) {
+	if raw == "" {
+		if current < 0 {
+			return nfsNobody, nil
+		}
+		return uint32(current), nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	return uint32(id), err
+}
+
+// nfsDial connects to an RPC program on the server, at port or at the port
+// the portmapper has registered for it when port is 0.
+func nfsDial(host string, prog, vers uint32, port int) (*rpc.Client, error) {
+	if port > 0 {
+		return nfs.DialServiceAtPort(host, port)
+	}
+	return nfs.DialService(host, rpc.Mapping{Prog: prog, Vers: vers, Prot: rpc.IPProtoTCP})
+}
+
+type nfsMountArgs struct {
+	rpc.Header
+	Dirpath string
+}
+
+// nfsMount asks the mount daemon for the file handle of an exported
+// directory (MOUNTPROC3_MNT).
+func nfsMount(client *rpc.Client, export string, auth rpc.Auth) ([]byte, error) {
+	res, err := client.Call(&nfsMountArgs{
+		Header: rpc.Header{
+			Rpcvers: 2,
+			Prog:    nfs.MountProg,
+			Vers:    nfs.MountVers,
+			Proc:    nfs.MountProc3MNT,
+			Cred:    auth,
+			Verf:    rpc.AuthNull,
+		},
+		Dirpath: export,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("nfs: mount failed: %w", nfsError("mount", "/", err))
+	}
+	status, err := xdr.ReadUint32(res)
+	if err != nil {
+		return nil, fmt.Errorf("nfs: invalid mount reply: %w", err)
+	}
+
+	switch status {
+	case nfs.MNT3Ok:
+		fh, err := xdr.ReadOpaque(res)
+		if err != nil {
+			return nil, fmt.Errorf("nfs: invalid mount reply: %w", err)
+		}
+		return fh, nil
+	case nfs.MNT3ErrNoEnt, nfs.MNT3ErrNotDir:
+		return nil, fmt.Errorf("nfs: export %q does not exist", export)
+	case nfs.MNT3ErrPerm, nfs.MNT3ErrAcces:
+		return nil, fmt.Errorf("nfs: access to export %q denied: %w", export, fs.ErrPermission)
+	default:
+		return nil, fmt.Errorf("nfs: mount of %q failed with status %d", export, status)
+	}
+}
+
+// nfsUnmount tells the mount daemon the export is no longer in use
+// (MOUNTPROC3_UMNT). Servers only use this for bookkeeping, so failures are
+// ignored.
+func nfsUnmount(client *rpc.Client, export string, auth rpc.Auth) {
+	client.Call(&nfsMountArgs{
+		Header: rpc.Header{
+			Rpcvers: 2,
+			Prog:    nfs.MountProg,
+			Vers:    nfs.MountVers,
+			Proc:    nfs.MountProc3UMNT,
+			Cred:    auth,
+			Verf:    rpc.AuthNull,
+		},
+		Dirpath: export,
+	})
+}
+
+func (b *NFSBackend) List(p string) ([]types.FileInfo, error) {
+	p = joinRemote("", p)
+	entries, err := b.target.ReadDirPlus(p)
+	if err != nil {
+		return nil, nfsError("list", p, err)
+	}
+
+	out := make([]types.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, types.FileInfo{
+			Name:    e.Name(),
+			IsDir:   e.IsDir(),
+			Size:    e.Size(),
+			ModTime: e.ModTime(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (b *NFSBackend) Stat(p string) (types.FileInfo, error) {
+	p = joinRemote("", p)
+	info, _, err := b.target.Lookup(p)
+	if err != nil {
+		return types.FileInfo{}, nfsError("stat", p, err)
+	}
+	return types.FileInfo{
+		Name:    path.Base(p),
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+func (b *NFSBackend) Read(p string) ([]byte, error) {
+	p = joinRemote("", p)
+	f, err := b.target.Open(p)
+	if err != nil {
+		return nil, nfsError("read", p, err)
+	}
+	data, err := io.ReadAll(bufio.NewReaderSize(f, nfsReadBufferSize))
+	if err != nil {
+		return nil, nfsError("read", p, err)
+	}
+	return data, nil
+}
+
+// ReadRange implements types.RangeReader with READ calls at the offset.
+func (b *NFSBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	p = joinRemote("", p)
+	f, err := b.target.Open(p)
+	if err != nil {
+		return nil, nfsError("read", p, err)
+	}
+	data, err := io.ReadAll(bufio.NewReaderSize(io.NewSectionReader(f, offset, length), nfsReadBufferSize))
+	if err != nil {
+		return nil, nfsError("read", p, err)
+	}
+	return data, nil
+}
+
+// Write replaces the contents of a file, creating it and any missing parent
+// directories first.
+func (b *NFSBackend) Write(p string, data []byte) error {
+	p = joinRemote("", p)
+	if p == "/" {
+		return fmt.Errorf("cannot write to root directory")
+	}
+
+	info, _, err := b.target.Lookup(p)
+	switch {
+	case err == nil && info.IsDir():
+		return &fs.PathError{Op: "write", Path: p, Err: syscall.EISDIR}
+	case err == nil:
+		if err := b.target.Setattr(p, nfs.Sattr3{Size: nfs.SetSize{SetIt: true}}); err != nil {
+			return nfsError("write", p, err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		if err := b.mkdirAll(path.Dir(p)); err != nil {
+			return err
+		}
+	default:
+		return nfsError("write", p, err)
+	}
+
+	f, err := b.target.OpenFile(p, 0644)
+	if err != nil {
+		return nfsError("write", p, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return nfsError("write", p, err)
+	}
+	// Close sends a COMMIT, so the data is on stable storage once it returns
+	return nfsError("write", p, f.Close())
+}
+
+// Delete removes a file, or a directory with everything in it.
+func (b *NFSBackend) Delete(p string) error {
+	p = joinRemote("", p)
+	if p == "/" {
+		return fmt.Errorf("cannot delete root directory")
+	}
+
+	info, _, err := b.target.Lookup(p)
+	if err != nil {
+		return nfsError("delete", p, err)
+	}
+	if info.IsDir() {
+		return nfsError("delete", p, b.target.RemoveAll(p))
+	}
+	return nfsError("delete", p, b.target.Remove(p))
+}
+
+// Rename moves a file or directory with a single RENAME call, replacing the
+// target like the other disk types do.
+func (b *NFSBackend) Rename(from, to string) error {
+	from, to = joinRemote("", from), joinRemote("", to)
+	if from == "/" || to == "/" {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	if err := b.mkdirAll(path.Dir(to)); err != nil {
+		return err
+	}
+	return nfsError("rename", from, b.target.Rename(from, to))
+}
+
+// mkdirAll creates a directory and any missing parents.
+func (b *NFSBackend) mkdirAll(dir string) error {
+	if dir == "/" {
+		return nil
+	}
+	info, _, err := b.target.Lookup(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: syscall.ENOTDIR}
+		}
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nfsError("mkdir", dir, err)
+	}
+
+	if err := b.mkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+	if _, err := b.target.Mkdir(dir, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nfsError("mkdir", dir, err)
+	}
+	return nil
+}
+
+func (b *NFSBackend) Close() error {
+	if b.target != nil {
+		b.target.Close()
+		b.target = nil
+	}
+	if b.client != nil {
+		nfsUnmount(b.client, b.export, b.auth)
+		b.client.Close()
+		b.client = nil
+	}
+	return nil
+}
+
+func (b *NFSBackend) Reconnect() error {
+	b.Close()
+	return b.connect()
+}
+
+// nfsError converts NFS status errors to the errors the other disk types
+// return, and marks a lost connection or a stale root handle, e.g. after
+// the server restarted with a different export, with types.ErrOffline so
+// the mount reconnects.
+func nfsError(op, p string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrExist) || errors.Is(err, fs.ErrPermission) {
+		return &fs.PathError{Op: op, Path: p, Err: err}
+	}
+
+	var nfsErr *nfs.Error
+	if errors.As(err, &nfsErr) {
+		switch nfsErr.ErrorNum {
+		case nfs.NFS3ErrNotDir:
+			return &fs.PathError{Op: op, Path: p, Err: syscall.ENOTDIR}
+		case nfs.NFS3ErrIsDir:
+			return &fs.PathError{Op: op, Path: p, Err: syscall.EISDIR}
+		case nfs.NFS3ErrNotEmpty:
+			return &fs.PathError{Op: op, Path: p, Err: syscall.ENOTEMPTY}
+		case nfs.NFS3ErrAcces:
+			return &fs.PathError{Op: op, Path: p, Err: fs.ErrPermission}
+		case nfs.NFS3ErrROFS:
+			return &fs.PathError{Op: op, Path: p, Err: types.ErrReadOnly}
+		case nfs.NFS3ErrNoSpc, nfs.NFS3ErrDQuot:
+			return &fs.PathError{Op: op, Path: p, Err: syscall.ENOSPC}
+		case nfs.NFS3ErrStale, nfs.NFS3ErrBadHandle:
+			return fmt.Errorf("%w: %s %s: %v", types.ErrOffline, op, p, err)
+		}
+		return &fs.PathError{Op: op, Path: p, Err: err}
+	}
+
+	// The RPC client gives up with this error after failing to reconnect
+	if err.Error() == "disconnected" {
+		return fmt.Errorf("%w: %s %s: %v", types.ErrOffline, op, p, err)
+	}
+	return err
+}
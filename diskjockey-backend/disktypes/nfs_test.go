@@ -0,0 +1,158 @@
+package disktypes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/go-git/go-billy/v5/osfs"
+	gonfs "github.com/willscott/go-nfs"
+	"github.com/willscott/go-nfs/helpers"
+)
+
+// newNFSServer exports a directory over NFSv3 on a local port, serving the
+// mount protocol on the same port, and returns the port.
+func newNFSServer(t *testing.T, dir string) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := helpers.NewCachingHandler(helpers.NewNullAuthHandler(osfs.New(dir, osfs.WithBoundOS())), 1024)
+	go gonfs.Serve(listener, handler)
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func newNFSBackend(t *testing.T, dir string) *NFSBackend {
+	t.Helper()
+	port := newNFSServer(t, dir)
+	return mustNew(t, NFSDiskType{}, &models.Mount{Host: "127.0.0.1", Port: port, Path: "/"}).(*NFSBackend)
+}
+
+func TestNFSFilesAndDirectories(t *testing.T) {
+	dir := t.TempDir()
+	b := newNFSBackend(t, dir)
+
+	if err := b.Write("/docs/sub/a b.txt", []byte("0123456789")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "docs", "sub", "a b.txt")); err != nil || string(data) != "0123456789" {
+		t.Errorf("exported file holds %q, %v", data, err)
+	}
+	if err := b.Write("/docs/b.txt", []byte("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := b.List("/docs")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, fmt.Sprintf("%s:%v", info.Name, info.IsDir))
+	}
+	if want := "[b.txt:false sub:true]"; fmt.Sprint(names) != want {
+		t.Errorf("List = %v, want %v", names, want)
+	}
+	if info, err := b.Stat("/docs/sub/a b.txt"); err != nil || info.IsDir || info.Size != 10 || info.Name != "a b.txt" {
+		t.Errorf("Stat = %+v, %v", info, err)
+	}
+	if data, err := b.ReadRange("/docs/sub/a b.txt", 2, 3); err != nil || string(data) != "234" {
+		t.Errorf("ReadRange = %q, %v", data, err)
+	}
+	if data, err := b.ReadRange("/docs/sub/a b.txt", 20, 3); err != nil || len(data) != 0 {
+		t.Errorf("ReadRange past the end = %q, %v", data, err)
+	}
+
+	// A shorter write truncates what was there
+	if err := b.Write("/docs/sub/a b.txt", []byte("short")); err != nil {
+		t.Fatalf("Write over a file failed: %v", err)
+	}
+	if data, err := b.Read("/docs/sub/a b.txt"); err != nil || string(data) != "short" {
+		t.Errorf("Read after a shorter write = %q, %v", data, err)
+	}
+
+	if err := b.Rename("/docs/sub/a b.txt", "/moved/b.txt"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if data, err := b.Read("/moved/b.txt"); err != nil || string(data) != "short" {
+		t.Errorf("Read after Rename = %q, %v", data, err)
+	}
+	if err := b.Delete("/docs"); err != nil {
+		t.Fatalf("Delete of a directory failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("deleted directory is still exported: %v", err)
+	}
+}
+
+func TestNFSLargeFile(t *testing.T) {
+	b := newNFSBackend(t, t.TempDir())
+	data := make([]byte, 3<<20+123)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	if err := b.Write("/large.bin", data); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if read, err := b.Read("/large.bin"); err != nil || !bytes.Equal(read, data) {
+		t.Fatalf("Read returned %d bytes that don't match the %d written: %v", len(read), len(data), err)
+	}
+	if read, err := b.ReadRange("/large.bin", 1<<20, 2<<20); err != nil || !bytes.Equal(read, data[1<<20:3<<20]) {
+		t.Errorf("ReadRange returned %d bytes that don't match: %v", len(read), err)
+	}
+}
+
+func TestNFSErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := newNFSBackend(t, dir)
+
+	if _, err := b.Read("/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read of a missing file = %v, want ErrNotExist", err)
+	}
+	if _, err := b.Stat("/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a missing file = %v, want ErrNotExist", err)
+	}
+	if err := b.Write("/dir", []byte("x")); !errors.Is(err, syscall.EISDIR) {
+		t.Errorf("Write over a directory = %v, want EISDIR", err)
+	}
+	if err := b.Write("/file.txt/a.txt", []byte("x")); !errors.Is(err, syscall.ENOTDIR) {
+		t.Errorf("Write below a file = %v, want ENOTDIR", err)
+	}
+	if err := b.Delete("/"); err == nil {
+		t.Error("deleted the root of the export")
+	}
+
+	if err := b.Reconnect(); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	if data, err := b.Read("/file.txt"); err != nil || string(data) != "x" {
+		t.Errorf("Read after Reconnect = %q, %v", data, err)
+	}
+}
+
+func TestNFSMissingServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	if _, err := (NFSDiskType{}).New(&models.Mount{Host: "127.0.0.1", Port: port, Path: "/"}); err == nil {
+		t.Error("mounted an export without a server")
+	}
+}
@@ -0,0 +1,78 @@
+package disktypes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"golang.org/x/oauth2"
+)
+
+// tokenRefreshMargin is how long before expiry an access token is refreshed
+const tokenRefreshMargin = 5 * time.Minute
+
+// refreshingTokenSource hands out the current access token and uses the
+// refresh token to fetch a new one shortly before it expires. Refreshed
+// tokens are saved to the store, if there is one.
+type refreshingTokenSource struct {
+	mu      sync.Mutex
+	config  *oauth2.Config
+	token   *oauth2.Token
+	store   types.TokenStore
+	mountID uint32
+}
+
+// newMountTokenSource builds a token source from the credentials the OAuth
+// flow stored on the mount: access_token, and the refresh_token and
+// token_expiry options.
+func newMountTokenSource(config *oauth2.Config, mount *models.Mount, store types.TokenStore) *refreshingTokenSource {
+	token := &oauth2.Token{
+		AccessToken:  mount.AccessToken,
+		RefreshToken: mount.Option("refresh_token"),
+	}
+	if expiry, err := time.Parse(time.RFC3339, mount.Option("token_expiry")); err == nil {
+		token.Expiry = expiry
+	}
+	return &refreshingTokenSource{config: config, token: token, store: store, mountID: uint32(mount.ID)}
+}
+
+// Token implements oauth2.TokenSource
+func (s *refreshingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.AccessToken != "" && (s.token.Expiry.IsZero() || time.Until(s.token.Expiry) > tokenRefreshMargin) {
+		return s.token, nil
+	}
+
+	if s.token.RefreshToken == "" {
+		return nil, fmt.Errorf("%w: access token expired and no refresh token is stored", types.ErrReauthRequired)
+	}
+
+	// A token without an access token is always refreshed by the oauth2 package
+	refreshed, err := s.config.TokenSource(context.Background(), &oauth2.Token{RefreshToken: s.token.RefreshToken}).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.Response != nil && retrieveErr.Response.StatusCode < http.StatusInternalServerError {
+			// The provider rejected the refresh token (revoked, expired or app removed)
+			return nil, fmt.Errorf("%w: %v", types.ErrReauthRequired, err)
+		}
+		return nil, err
+	}
+
+	s.token = refreshed
+	if s.store != nil {
+		// An unsaved token is only refreshed again after a restart, so this
+		// doesn't fail the request
+		if err := s.store.SaveToken(s.mountID, refreshed); err != nil {
+			fmt.Fprintf(os.Stderr, "[OAuth] Failed to save refreshed token of mount %d: %v\n", s.mountID, err)
+		}
+	}
+	return refreshed, nil
+}
@@ -0,0 +1,190 @@
+package disktypes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"golang.org/x/oauth2"
+)
+
+// fakeTokenStore records the tokens saved for each mount
+type fakeTokenStore struct {
+	mu     sync.Mutex
+	tokens map[uint32]*oauth2.Token
+}
+
+func (s *fakeTokenStore) SaveToken(mountID uint32, token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokens == nil {
+		s.tokens = map[uint32]*oauth2.Token{}
+	}
+	s.tokens[mountID] = token
+	return nil
+}
+
+func (s *fakeTokenStore) saved(mountID uint32) *oauth2.Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[mountID]
+}
+
+// newTokenServer serves a token endpoint that answers refresh requests with
+// status and body, and counts the requests it received.
+func newTokenServer(t *testing.T, status int, body map[string]interface{}) (*httptest.Server, *int) {
+	t.Helper()
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse token request: %v", err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "refresh_token" {
+			t.Errorf("grant_type = %q, want refresh_token", got)
+		}
+		if got := r.PostForm.Get("refresh_token"); got != "refresh-1" {
+			t.Errorf("refresh_token = %q, want refresh-1", got)
+		}
+		if got := r.PostForm.Get("client_id"); got != "app-key" {
+			t.Errorf("client_id = %q, want app-key", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func tokenMount(expiry time.Time) *models.Mount {
+	return &models.Mount{
+		ID:          7,
+		AccessToken: "access-1",
+		Options: map[string]string{
+			"refresh_token": "refresh-1",
+			"token_expiry":  expiry.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+func TestRefreshingTokenSource(t *testing.T) {
+	refreshed := map[string]interface{}{"access_token": "access-2", "token_type": "bearer", "expires_in": 3600}
+	rejected := map[string]interface{}{"error": "invalid_grant"}
+
+	tests := []struct {
+		name         string
+		expiry       time.Time
+		status       int
+		body         map[string]interface{}
+		wantToken    string
+		wantRequests int
+		wantSaved    bool
+		wantReauth   bool
+		wantErr      bool
+	}{
+		{name: "valid token is used", expiry: time.Now().Add(time.Hour), status: http.StatusOK, body: refreshed, wantToken: "access-1"},
+		{name: "expired token is refreshed", expiry: time.Now().Add(-time.Hour), status: http.StatusOK, body: refreshed, wantToken: "access-2", wantRequests: 1, wantSaved: true},
+		{name: "token close to expiry is refreshed", expiry: time.Now().Add(time.Minute), status: http.StatusOK, body: refreshed, wantToken: "access-2", wantRequests: 1, wantSaved: true},
+		{name: "revoked refresh token needs reauth", expiry: time.Now().Add(-time.Hour), status: http.StatusBadRequest, body: rejected, wantRequests: 1, wantReauth: true, wantErr: true},
+		{name: "server error is not reauth", expiry: time.Now().Add(-time.Hour), status: http.StatusInternalServerError, body: rejected, wantRequests: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newTokenServer(t, tt.status, tt.body)
+			store := &fakeTokenStore{}
+			config := &oauth2.Config{
+				ClientID: "app-key",
+				Endpoint: oauth2.Endpoint{TokenURL: server.URL, AuthStyle: oauth2.AuthStyleInParams},
+			}
+			source := newMountTokenSource(config, tokenMount(tt.expiry), store)
+
+			token, err := source.Token()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Token() succeeded, want error")
+				}
+				if got := errors.Is(err, types.ErrReauthRequired); got != tt.wantReauth {
+					t.Errorf("errors.Is(err, ErrReauthRequired) = %v, want %v (err %v)", got, tt.wantReauth, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Token() failed: %v", err)
+				}
+				if token.AccessToken != tt.wantToken {
+					t.Errorf("access token = %q, want %q", token.AccessToken, tt.wantToken)
+				}
+			}
+			if *requests != tt.wantRequests {
+				t.Errorf("token endpoint got %d requests, want %d", *requests, tt.wantRequests)
+			}
+
+			saved := store.saved(7)
+			if !tt.wantSaved {
+				if saved != nil {
+					t.Errorf("token was saved, want nothing saved")
+				}
+				return
+			}
+			if saved == nil {
+				t.Fatalf("refreshed token was not saved")
+			}
+			if saved.AccessToken != "access-2" {
+				t.Errorf("saved access token = %q, want access-2", saved.AccessToken)
+			}
+			// Dropbox doesn't return a new refresh token, the old one is kept
+			if saved.RefreshToken != "refresh-1" {
+				t.Errorf("saved refresh token = %q, want refresh-1", saved.RefreshToken)
+			}
+			if time.Until(saved.Expiry) < 50*time.Minute {
+				t.Errorf("saved expiry = %v, want about an hour from now", saved.Expiry)
+			}
+
+			// The refreshed token is used until it expires
+			if _, err := source.Token(); err != nil {
+				t.Fatalf("second Token() failed: %v", err)
+			}
+			if *requests != tt.wantRequests {
+				t.Errorf("valid refreshed token was refreshed again")
+			}
+		})
+	}
+}
+
+func TestRefreshingTokenSourceWithoutRefreshToken(t *testing.T) {
+	mount := tokenMount(time.Now().Add(-time.Hour))
+	delete(mount.Options, "refresh_token")
+	source := newMountTokenSource(&oauth2.Config{}, mount, nil)
+	if _, err := source.Token(); !errors.Is(err, types.ErrReauthRequired) {
+		t.Fatalf("Token() error = %v, want ErrReauthRequired", err)
+	}
+}
+
+func TestDropboxOAuthConfigAPIURL(t *testing.T) {
+	server, requests := newTokenServer(t, http.StatusOK, map[string]interface{}{"access_token": "access-2", "token_type": "bearer", "expires_in": 3600})
+	mount := tokenMount(time.Now().Add(-time.Hour))
+	mount.Options["app_key"] = "app-key"
+	mount.Options["api_url"] = server.URL + "/"
+
+	config, _, err := DropboxDiskType{}.OAuthConfig(mount)
+	if err != nil {
+		t.Fatalf("OAuthConfig failed: %v", err)
+	}
+	if want := server.URL + "/oauth2/token"; config.Endpoint.TokenURL != want {
+		t.Fatalf("token URL = %q, want %q", config.Endpoint.TokenURL, want)
+	}
+
+	if _, err := newMountTokenSource(config, mount, nil).Token(); err != nil {
+		t.Fatalf("Token() failed: %v", err)
+	}
+	if *requests != 1 {
+		t.Fatalf("token endpoint got %d requests, want 1", *requests)
+	}
+}
@@ -0,0 +1,772 @@
+package disktypes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"golang.org/x/oauth2"
+)
+
+// OneDriveDiskType implements DiskType for OneDrive and SharePoint document
+// libraries, using the drive API of Microsoft Graph.
+
+type OneDriveDiskType struct {
+	Tokens types.TokenStore // Saves access tokens refreshed while mounted
+}
+
+type OneDriveBackend struct {
+	mount    *models.Mount
+	tokens   types.TokenStore
+	client   *http.Client // Graph requests, carrying the access token
+	transfer *http.Client // Pre-authenticated download and upload URLs
+	drive    string       // Graph URL of the drive
+
+	mu    sync.Mutex
+	paths map[string]string // Item ID -> path, for changes that only name the ID
+}
+
+const (
+	onedriveDefaultGraphURL = "https://graph.microsoft.com/v1.0"
+	onedriveDefaultLoginURL = "https://login.microsoftonline.com"
+	// Largest file uploaded with a single request, bigger ones use an
+	// upload session
+	onedriveSimpleUploadLimit = 4 << 20
+	// Size of upload session chunks, which must be a multiple of 320 KiB
+	onedriveUploadChunkSize = 32 * 320 << 10
+	// Times a request is retried when throttled, or an upload chunk failed
+	onedriveMaxRetries = 3
+	// How often the delta link is checked for changes
+	onedriveDeltaInterval = 30 * time.Second
+)
+
+func (t OneDriveDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &OneDriveBackend{
+		mount:  mount,
+		tokens: t.Tokens,
+		paths:  map[string]string{},
+	}
+
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (OneDriveDiskType) Name() string {
+	return "onedrive"
+}
+
+func (OneDriveDiskType) Description() string {
+	return "OneDrive and SharePoint cloud storage"
+}
+
+func (OneDriveDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return withCacheFields(types.DiskTypeConfigTemplate{
+		"client_id": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Application (client) ID of the app registration, used to authorize the mount and refresh its access token",
+			Required:    false,
+		},
+		"tenant": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Directory to sign in to: common, organizations, consumers or a tenant ID (default common)",
+			Required:    false,
+		},
+		"drive_id": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "ID of the drive to mount, e.g. a SharePoint document library (default the user's OneDrive)",
+			Required:    false,
+		},
+		"access_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Microsoft Graph OAuth2 access token (set by authorization)",
+			Required:    false,
+		},
+		"refresh_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Microsoft Graph OAuth2 refresh token (set by authorization)",
+			Required:    false,
+		},
+		"redirect_port": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Fixed loopback port for the authorization redirect, if the app only allows registered redirect URIs",
+			Required:    false,
+		},
+		"graph_url": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Microsoft Graph endpoint, for national clouds (default " + onedriveDefaultGraphURL + ")",
+			Required:    false,
+		},
+		"login_url": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Microsoft identity platform endpoint, for national clouds (default " + onedriveDefaultLoginURL + ")",
+			Required:    false,
+		},
+	})
+}
+
+// OAuthConfig implements types.OAuthDiskType using the PKCE flow, which needs
+// no client secret. The offline_access scope returns a refresh token.
+func (OneDriveDiskType) OAuthConfig(mount *models.Mount) (*oauth2.Config, []oauth2.AuthCodeOption, error) {
+	config, err := onedriveOAuthConfig(mount)
+	if err != nil {
+		return nil, nil, err
+	}
+	return config, nil, nil
+}
+
+func onedriveOAuthConfig(mount *models.Mount) (*oauth2.Config, error) {
+	clientID := mount.Option("client_id")
+	if clientID == "" {
+		return nil, fmt.Errorf("missing required onedrive config field: client_id")
+	}
+	tenant := mount.Option("tenant")
+	if tenant == "" {
+		tenant = "common"
+	}
+	login := strings.TrimSuffix(mount.Option("login_url"), "/")
+	if login == "" {
+		login = onedriveDefaultLoginURL
+	}
+	return &oauth2.Config{
+		ClientID: clientID,
+		Scopes:   []string{"Files.ReadWrite.All", "offline_access"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   login + "/" + url.PathEscape(tenant) + "/oauth2/v2.0/authorize",
+			TokenURL:  login + "/" + url.PathEscape(tenant) + "/oauth2/v2.0/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}, nil
+}
+
+func (b *OneDriveBackend) connect() error {
+	var tokens oauth2.TokenSource
+	if b.mount.Option("refresh_token") != "" {
+		config, err := onedriveOAuthConfig(b.mount)
+		if err != nil {
+			return err
+		}
+		refreshing := newMountTokenSource(config, b.mount, b.tokens)
+		// Fail the mount early if the refresh token has been revoked
+		if _, err := refreshing.Token(); err != nil {
+			return err
+		}
+		tokens = refreshing
+	} else {
+		token := b.mount.AccessToken
+		if token == "" {
+			return fmt.Errorf("%w: missing required onedrive config field: access_token (authorize the mount)", types.ErrReauthRequired)
+		}
+		tokens = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	}
+
+	graph := strings.TrimSuffix(b.mount.Option("graph_url"), "/")
+	if graph == "" {
+		graph = onedriveDefaultGraphURL
+	}
+	if driveID := b.mount.Option("drive_id"); driveID != "" {
+		b.drive = graph + "/drives/" + url.PathEscape(driveID)
+	} else {
+		b.drive = graph + "/me/drive"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	b.client = &http.Client{
+		Transport: &oauth2.Transport{Source: tokens, Base: transport},
+		// Downloads redirect to a pre-authenticated URL, which is fetched
+		// without the access token
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	b.transfer = &http.Client{Transport: transport}
+	return nil
+}
+
+// onedriveItem is the part of a Graph driveItem the backend uses
+type onedriveItem struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	ETag            string    `json:"eTag"`
+	Size            int64     `json:"size"`
+	LastModified    time.Time `json:"lastModifiedDateTime"`
+	File            *struct{} `json:"file"`
+	Folder          *struct{} `json:"folder"`
+	Root            *struct{} `json:"root"`
+	Deleted         *struct{} `json:"deleted"`
+	ParentReference struct {
+		ID   string `json:"id"`
+		Path string `json:"path"`
+	} `json:"parentReference"`
+}
+
+// onedrivePage is a page of a collection, like the children of a folder or
+// the changes of a delta query
+type onedrivePage struct {
+	Value     []onedriveItem `json:"value"`
+	NextLink  string         `json:"@odata.nextLink"`
+	DeltaLink string         `json:"@odata.deltaLink"`
+}
+
+func (item *onedriveItem) info() types.FileInfo {
+	info := types.FileInfo{
+		Name:    item.Name,
+		IsDir:   item.Folder != nil,
+		ModTime: item.LastModified,
+	}
+	if !info.IsDir {
+		info.Size = item.Size
+		info.ETag = item.ETag
+	}
+	return info
+}
+
+// graphError is returned for a Graph response that isn't a success. It wraps
+// the sentinel error matching the status, if there is one.
+type graphError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *graphError) Error() string {
+	if e.code == "" {
+		return fmt.Sprintf("onedrive: server responded %d %s", e.status, http.StatusText(e.status))
+	}
+	return fmt.Sprintf("onedrive: %s: %s (%d)", e.code, e.message, e.status)
+}
+
+func (e *graphError) Unwrap() error {
+	switch e.status {
+	case http.StatusNotFound:
+		return fs.ErrNotExist
+	case http.StatusUnauthorized:
+		return types.ErrReauthRequired
+	case http.StatusPreconditionFailed:
+		return types.ErrConflict
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return types.ErrOffline
+	default:
+		return nil
+	}
+}
+
+// hasGraphStatus reports whether err is for a response with the status
+func hasGraphStatus(err error, status int) bool {
+	var graphErr *graphError
+	return errors.As(err, &graphErr) && graphErr.status == status
+}
+
+// onedrivePath escapes a mount path for the path based addressing of Graph
+func onedrivePath(p string) string {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// itemURL returns the Graph URL of the item at a path, followed by action
+// (e.g. "children") if it isn't empty.
+func (b *OneDriveBackend) itemURL(p, action string) string {
+	p = joinRemote("", p)
+	u := b.drive + "/root"
+	if p != "/" {
+		u += ":" + onedrivePath(p) + ":"
+	}
+	if action != "" {
+		u += "/" + action
+	}
+	return u
+}
+
+// send makes a request and returns the response of a success. Throttled
+// requests are retried after the time the server asks for.
+func (b *OneDriveBackend) send(client *http.Client, method, u, p string, header http.Header, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, u, reader)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		throttled := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+		if throttled && attempt < onedriveMaxRetries {
+			resp.Body.Close()
+			wait := time.Duration(1<<attempt) * time.Second
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds <= 60 {
+				wait = time.Duration(seconds) * time.Second
+			}
+			time.Sleep(wait)
+			continue
+		}
+
+		statusErr := &graphError{status: resp.StatusCode}
+		var errBody struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&errBody) == nil {
+			statusErr.code, statusErr.message = errBody.Error.Code, errBody.Error.Message
+		}
+		resp.Body.Close()
+		return nil, &fs.PathError{Op: strings.ToLower(method), Path: p, Err: statusErr}
+	}
+}
+
+// do makes a Graph request with the access token and decodes the JSON
+// response into out, if it isn't nil.
+func (b *OneDriveBackend) do(method, u, p string, in, out any) error {
+	var header http.Header
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+		header = http.Header{"Content-Type": {"application/json"}}
+	}
+	resp, err := b.send(b.client, method, u, p, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// item returns the metadata of the item at a path
+func (b *OneDriveBackend) item(p string) (*onedriveItem, error) {
+	var item onedriveItem
+	if err := b.do(http.MethodGet, b.itemURL(p, ""), p, nil, &item); err != nil {
+		return nil, err
+	}
+	b.rememberPath(item.ID, p)
+	return &item, nil
+}
+
+// rememberPath records the path of an item, to resolve changes that don't
+// include it. An empty path forgets the item.
+func (b *OneDriveBackend) rememberPath(id, p string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p == "" {
+		delete(b.paths, id)
+	} else {
+		b.paths[id] = p
+	}
+}
+
+func (b *OneDriveBackend) List(p string) ([]types.FileInfo, error) {
+	var out []types.FileInfo
+	u := b.itemURL(p, "children")
+	for u != "" {
+		var page onedrivePage
+		if err := b.do(http.MethodGet, u, p, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Value {
+			// Other items, like OneNote notebooks, can't be read as files
+			if item.File == nil && item.Folder == nil {
+				continue
+			}
+			b.rememberPath(item.ID, path.Join(p, item.Name))
+			out = append(out, item.info())
+		}
+		// Large folders are returned in pages
+		u = page.NextLink
+	}
+	return out, nil
+}
+
+func (b *OneDriveBackend) Stat(p string) (types.FileInfo, error) {
+	item, err := b.item(p)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	info := item.info()
+	if joinRemote("", p) == "/" {
+		info.Name = "/"
+	}
+	return info, nil
+}
+
+func (b *OneDriveBackend) Read(p string) ([]byte, error) {
+	resp, err := b.download(p, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// ReadRange implements types.RangeReader with a ranged download.
+func (b *OneDriveBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	if length == 0 {
+		return []byte{}, nil
+	}
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)}}
+	resp, err := b.download(p, header)
+	if hasGraphStatus(err, http.StatusRequestedRangeNotSatisfiable) {
+		// The range starts past the end of the file
+		return []byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusPartialContent {
+		return io.ReadAll(io.LimitReader(resp.Body, length))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return types.SliceRange(data, offset, length), nil
+}
+
+// download fetches the content of a file. Graph redirects to a
+// pre-authenticated URL, which must be requested without the access token.
+func (b *OneDriveBackend) download(p string, header http.Header) (*http.Response, error) {
+	resp, err := b.send(b.client, http.MethodGet, b.itemURL(p, "content"), p, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	location := resp.Header.Get("Location")
+	if resp.StatusCode < http.StatusMultipleChoices || location == "" {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return b.send(b.transfer, http.MethodGet, location, p, header, nil)
+}
+
+func (b *OneDriveBackend) Write(p string, data []byte) error {
+	return b.upload(p, data, "")
+}
+
+// WriteIfMatch writes the file only if its current eTag is etag, letting
+// Graph detect a conflicting change instead of overwriting it.
+func (b *OneDriveBackend) WriteIfMatch(p string, data []byte, etag string) error {
+	return b.upload(p, data, etag)
+}
+
+func (b *OneDriveBackend) upload(p string, data []byte, etag string) error {
+	if joinRemote("", p) == "/" {
+		return fmt.Errorf("cannot write to root directory")
+	}
+	if len(data) > onedriveSimpleUploadLimit {
+		return b.uploadSession(p, data, etag)
+	}
+
+	header := http.Header{"Content-Type": {"application/octet-stream"}}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	resp, err := b.send(b.client, http.MethodPut, b.itemURL(p, "content"), p, header, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// onedriveUploadStatus is the state of an upload session
+type onedriveUploadStatus struct {
+	UploadURL          string   `json:"uploadUrl"`
+	NextExpectedRanges []string `json:"nextExpectedRanges"`
+}
+
+// next returns the offset of the first byte the session is missing, or -1 if
+// it doesn't say.
+func (s *onedriveUploadStatus) next() int64 {
+	if len(s.NextExpectedRanges) == 0 {
+		return -1
+	}
+	start, _, _ := strings.Cut(s.NextExpectedRanges[0], "-")
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return offset
+}
+
+// uploadSession uploads data in onedriveUploadChunkSize chunks to an upload
+// session. When a chunk fails, the session is asked which bytes it is
+// missing and the upload resumes from there.
+func (b *OneDriveBackend) uploadSession(p string, data []byte, etag string) error {
+	var session onedriveUploadStatus
+	body := map[string]any{"item": map[string]string{"@microsoft.graph.conflictBehavior": "replace"}}
+	header := http.Header{"Content-Type": {"application/json"}}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := b.send(b.client, http.MethodPost, b.itemURL(p, "createUploadSession"), p, header, encoded)
+	if err != nil {
+		return err
+	}
+	err = json.NewDecoder(resp.Body).Decode(&session)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	total := int64(len(data))
+	offset, failures := int64(0), 0
+	for {
+		end := min(offset+onedriveUploadChunkSize, total)
+		header := http.Header{"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", offset, end-1, total)}}
+		resp, err := b.send(b.transfer, http.MethodPut, session.UploadURL, p, header, data[offset:end])
+		if err != nil {
+			var graphErr *graphError
+			if errors.As(err, &graphErr) && graphErr.status < http.StatusInternalServerError {
+				b.cancelUploadSession(session.UploadURL, p)
+				return err
+			}
+			if failures++; failures > onedriveMaxRetries {
+				b.cancelUploadSession(session.UploadURL, p)
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "[OneDriveBackend] Upload of %s failed at byte %d, resuming: %v\n", p, offset, err)
+			if offset, err = b.resumeOffset(session.UploadURL, p); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var status onedriveUploadStatus
+		decodeErr := json.NewDecoder(resp.Body).Decode(&status)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			// The last chunk returns the uploaded item
+			return nil
+		}
+		if next := status.next(); decodeErr == nil && next >= 0 {
+			offset = next
+		} else {
+			offset = end
+		}
+		failures = 0
+	}
+}
+
+// resumeOffset asks an upload session for the first byte it is missing
+func (b *OneDriveBackend) resumeOffset(uploadURL, p string) (int64, error) {
+	resp, err := b.send(b.transfer, http.MethodGet, uploadURL, p, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	var status onedriveUploadStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return 0, err
+	}
+	next := status.next()
+	if next < 0 {
+		return 0, fmt.Errorf("onedrive: upload session of %s has no expected ranges", p)
+	}
+	return next, nil
+}
+
+// cancelUploadSession discards the chunks an upload session received
+func (b *OneDriveBackend) cancelUploadSession(uploadURL, p string) {
+	if resp, err := b.send(b.transfer, http.MethodDelete, uploadURL, p, nil, nil); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (b *OneDriveBackend) Delete(p string) error {
+	if joinRemote("", p) == "/" {
+		return fmt.Errorf("cannot delete root directory")
+	}
+	return b.do(http.MethodDelete, b.itemURL(p, ""), p, nil, nil)
+}
+
+// Rename moves an item by changing its name and parent, replacing an item
+// already at the destination.
+func (b *OneDriveBackend) Rename(from, to string) error {
+	from, to = joinRemote("", from), joinRemote("", to)
+	if from == "/" || to == "/" {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	parent, err := b.item(path.Dir(to))
+	if err != nil {
+		return err
+	}
+	body := map[string]any{
+		"name":            path.Base(to),
+		"parentReference": map[string]string{"id": parent.ID},
+	}
+	u := b.itemURL(from, "") + "?@microsoft.graph.conflictBehavior=replace"
+	return b.do(http.MethodPatch, u, from, body, nil)
+}
+
+// Watch implements types.Watcher. It keeps the delta link of the drive in
+// the watch state and checks it for changes every onedriveDeltaInterval.
+//
+// Graph returns the current metadata of changed items without saying
+// whether they are new, so changed files are reported as modified and
+// changed folders as created.
+func (b *OneDriveBackend) Watch(ctx context.Context, state types.WatchState, emit func(types.ChangeEvent)) error {
+	cursor, err := state.Cursor()
+	if err != nil {
+		return err
+	}
+	// Changes on business drives name the parent ID instead of its path
+	if _, err := b.item("/"); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		if cursor == "" {
+			// Starting from the latest state skips listing the whole drive
+			if cursor, err = b.readChanges(b.itemURL("/", "delta")+"?token=latest", state, nil); err != nil {
+				return err
+			}
+		}
+
+		cursor, err = b.readChanges(cursor, state, emit)
+		if hasGraphStatus(err, http.StatusGone) {
+			cursor = b.resetCursor(state, emit)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(onedriveDeltaInterval):
+		}
+	}
+
+	return nil
+}
+
+// readChanges pages through the changes of a delta query, emitting an event
+// for each item (unless emit is nil) and storing the link to the next page
+// after every page. It returns the delta link for the next query.
+func (b *OneDriveBackend) readChanges(link string, state types.WatchState, emit func(types.ChangeEvent)) (string, error) {
+	for {
+		var page onedrivePage
+		if err := b.do(http.MethodGet, link, "/", nil, &page); err != nil {
+			return link, err
+		}
+
+		for i := range page.Value {
+			if emit != nil {
+				b.emitChange(&page.Value[i], emit)
+			}
+		}
+
+		link = page.NextLink
+		if link == "" {
+			link = page.DeltaLink
+		}
+		if link == "" {
+			return "", fmt.Errorf("onedrive: delta response has no next or delta link")
+		}
+		if err := state.SetCursor(link); err != nil {
+			return link, err
+		}
+		if page.NextLink == "" {
+			return link, nil
+		}
+	}
+}
+
+// emitChange reports a changed item. An item whose path can't be worked out
+// is reported as a change of the root, for clients to list everything again.
+func (b *OneDriveBackend) emitChange(item *onedriveItem, emit func(types.ChangeEvent)) {
+	if item.Root != nil {
+		b.rememberPath(item.ID, "/")
+		return
+	}
+	p, ok := b.itemPath(item)
+	if !ok {
+		emit(types.ChangeEvent{Path: "/", Kind: types.ChangeModified, IsDir: true})
+		return
+	}
+
+	switch {
+	case item.Deleted != nil:
+		b.rememberPath(item.ID, "")
+		emit(types.ChangeEvent{Path: p, Kind: types.ChangeDeleted, IsDir: item.Folder != nil})
+	case item.Folder != nil:
+		b.rememberPath(item.ID, p)
+		emit(types.ChangeEvent{Path: p, Kind: types.ChangeCreated, IsDir: true})
+	default:
+		b.rememberPath(item.ID, p)
+		emit(types.ChangeEvent{Path: p, Kind: types.ChangeModified})
+	}
+}
+
+// itemPath works out the path of a changed item from its parent's path, or
+// the paths of it or its parent seen before.
+func (b *OneDriveBackend) itemPath(item *onedriveItem) (string, bool) {
+	if _, parent, ok := strings.Cut(item.ParentReference.Path, "root:"); ok && item.Name != "" {
+		if unescaped, err := url.PathUnescape(parent); err == nil {
+			parent = unescaped
+		}
+		return path.Join("/", parent, item.Name), true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if parent, ok := b.paths[item.ParentReference.ID]; ok && item.Name != "" {
+		return path.Join(parent, item.Name), true
+	}
+	p, ok := b.paths[item.ID]
+	return p, ok
+}
+
+// resetCursor forgets a delta link Graph no longer accepts. Changes since it
+// was issued are lost, so the root is reported as modified for clients to
+// list everything again.
+func (b *OneDriveBackend) resetCursor(state types.WatchState, emit func(types.ChangeEvent)) string {
+	state.SetCursor("")
+	emit(types.ChangeEvent{Path: "/", Kind: types.ChangeModified, IsDir: true})
+	return ""
+}
+
+func (b *OneDriveBackend) Reconnect() error {
+	return b.connect()
+}
@@ -0,0 +1,600 @@
+package disktypes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// fakeGraph is an in-memory Microsoft Graph server implementing the drive
+// routes OneDriveBackend uses. Downloads redirect to, and upload sessions
+// upload to, URLs that must be requested without the access token.
+type fakeGraph struct {
+	t        *testing.T
+	server   *httptest.Server
+	pageSize int // Children per page
+
+	mu        sync.Mutex
+	items     map[string]*fakeGraphItem // Path -> item, "/" is the root
+	nextID    int
+	changes   []fakeGraphChange
+	sessions  map[string]*fakeGraphSession // Upload session ID -> upload
+	failChunk bool                         // Fail the next upload chunk once
+	chunks    int                          // Upload chunks received
+}
+
+type fakeGraphItem struct {
+	id       string
+	dir      bool
+	notebook bool // Neither a file nor a folder, like a OneNote notebook
+	data     []byte
+	etag     string
+	modified time.Time
+}
+
+// fakeGraphChange is an entry of the delta log
+type fakeGraphChange struct {
+	path    string
+	id      string
+	dir     bool
+	deleted bool
+}
+
+type fakeGraphSession struct {
+	path string
+	data []byte
+}
+
+func newFakeGraph(t *testing.T) *fakeGraph {
+	t.Helper()
+	f := &fakeGraph{
+		t:        t,
+		pageSize: 100,
+		items:    map[string]*fakeGraphItem{"/": {id: "root", dir: true}},
+		sessions: map[string]*fakeGraphSession{},
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// backend mounts the fake with a long-lived access token
+func (f *fakeGraph) backend() *OneDriveBackend {
+	f.t.Helper()
+	b, err := OneDriveDiskType{}.New(&models.Mount{
+		AccessToken: "test-token",
+		Options:     map[string]string{"graph_url": f.server.URL},
+	})
+	if err != nil {
+		f.t.Fatalf("failed to mount fake graph: %v", err)
+	}
+	return b.(*OneDriveBackend)
+}
+
+func (f *fakeGraph) put(p string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.putLocked(p, data)
+}
+
+// putLocked creates or replaces a file, creating its folders like Graph does
+// for path based uploads
+func (f *fakeGraph) putLocked(p string, data []byte) *fakeGraphItem {
+	for dir := path.Dir(p); dir != "/"; dir = path.Dir(dir) {
+		if _, ok := f.items[dir]; !ok {
+			f.items[dir] = &fakeGraphItem{id: f.newID(), dir: true}
+			f.changes = append(f.changes, fakeGraphChange{path: dir, id: f.items[dir].id, dir: true})
+		}
+	}
+	item, ok := f.items[p]
+	if !ok {
+		item = &fakeGraphItem{id: f.newID()}
+		f.items[p] = item
+	}
+	item.data = append([]byte(nil), data...)
+	item.etag = fmt.Sprintf("\"{%s},%d\"", item.id, f.nextID)
+	item.modified = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	f.nextID++
+	f.changes = append(f.changes, fakeGraphChange{path: p, id: item.id})
+	return item
+}
+
+func (f *fakeGraph) newID() string {
+	f.nextID++
+	return fmt.Sprintf("item%d", f.nextID)
+}
+
+func (f *fakeGraph) get(p string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[p]
+	if !ok || item.dir {
+		return nil, false
+	}
+	return item.data, true
+}
+
+func (f *fakeGraph) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Pre-authenticated URLs reject the access token, like the real ones
+	// may, so a backend sending it is caught
+	if id, ok := strings.CutPrefix(r.URL.Path, "/download/"); ok {
+		if r.Header.Get("Authorization") != "" {
+			f.graphError(w, http.StatusBadRequest, "invalidRequest")
+			return
+		}
+		for _, item := range f.items {
+			if item.id == id && !item.dir {
+				http.ServeContent(w, r, "", item.modified, bytes.NewReader(item.data))
+				return
+			}
+		}
+		f.graphError(w, http.StatusNotFound, "itemNotFound")
+		return
+	}
+	if id, ok := strings.CutPrefix(r.URL.Path, "/upload/"); ok {
+		if r.Header.Get("Authorization") != "" {
+			f.graphError(w, http.StatusBadRequest, "invalidRequest")
+			return
+		}
+		f.uploadChunk(w, r, id)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		f.graphError(w, http.StatusUnauthorized, "InvalidAuthenticationToken")
+		return
+	}
+	if r.URL.Path == "/delta" {
+		f.delta(w, r.URL.Query().Get("since"))
+		return
+	}
+
+	p, action, ok := fakeGraphTarget(r.URL.EscapedPath())
+	if !ok {
+		f.graphError(w, http.StatusBadRequest, "invalidRequest")
+		return
+	}
+	item, exists := f.items[p]
+
+	switch {
+	case r.Method == http.MethodGet && action == "delta":
+		writeJSON(w, map[string]any{"value": []any{}, "@odata.deltaLink": fmt.Sprintf("%s/delta?since=%d", f.server.URL, len(f.changes))})
+	case r.Method == http.MethodPut && action == "content":
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || item.etag != match) {
+			f.graphError(w, http.StatusPreconditionFailed, "resourceModified")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(f.itemJSON(p, f.putLocked(p, data)))
+	case r.Method == http.MethodPost && action == "createUploadSession":
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || item.etag != match) {
+			f.graphError(w, http.StatusPreconditionFailed, "resourceModified")
+			return
+		}
+		id := f.newID()
+		f.sessions[id] = &fakeGraphSession{path: p}
+		writeJSON(w, map[string]any{"uploadUrl": f.server.URL + "/upload/" + id})
+	case !exists:
+		f.graphError(w, http.StatusNotFound, "itemNotFound")
+	case r.Method == http.MethodGet && action == "":
+		writeJSON(w, f.itemJSON(p, item))
+	case r.Method == http.MethodGet && action == "children":
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		f.children(w, r, p, skip)
+	case r.Method == http.MethodGet && action == "content":
+		http.Redirect(w, r, f.server.URL+"/download/"+item.id, http.StatusFound)
+	case (r.Method == http.MethodDelete || r.Method == http.MethodPatch) && p == "/":
+		f.graphError(w, http.StatusForbidden, "accessDenied")
+	case r.Method == http.MethodDelete && action == "":
+		f.moveLocked(p, "")
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPatch && action == "":
+		var body struct {
+			Name            string `json:"name"`
+			ParentReference struct {
+				ID string `json:"id"`
+			} `json:"parentReference"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		parent := ""
+		for dir, candidate := range f.items {
+			if candidate.id == body.ParentReference.ID && candidate.dir {
+				parent = dir
+			}
+		}
+		if parent == "" || body.Name == "" {
+			f.graphError(w, http.StatusBadRequest, "invalidRequest")
+			return
+		}
+		to := path.Join(parent, body.Name)
+		if to == p || strings.HasPrefix(to, p+"/") {
+			f.graphError(w, http.StatusBadRequest, "invalidRequest")
+			return
+		}
+		f.moveLocked(to, "")
+		f.moveLocked(p, to)
+		writeJSON(w, f.itemJSON(to, f.items[to]))
+	default:
+		f.graphError(w, http.StatusMethodNotAllowed, "invalidRequest")
+	}
+}
+
+// fakeGraphTarget splits the path of a drive route, like
+// /me/drive/root:/dir/a.txt:/content, into the item path and the action.
+// Paths climbing out of the root are rejected.
+func fakeGraphTarget(escaped string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(escaped, "/me/drive/root")
+	if !ok {
+		return "", "", false
+	}
+	p := "/"
+	if strings.HasPrefix(rest, ":") {
+		end := strings.Index(rest[1:], ":")
+		if end < 0 {
+			return "", "", false
+		}
+		unescaped, err := url.PathUnescape(rest[1 : 1+end])
+		if err != nil || strings.ContainsRune(unescaped, 0) {
+			return "", "", false
+		}
+		for _, segment := range strings.Split(unescaped, "/") {
+			if segment == ".." || segment == "." {
+				return "", "", false
+			}
+		}
+		p = path.Clean("/" + unescaped)
+		rest = rest[2+end:]
+	}
+	return p, strings.TrimPrefix(rest, "/"), true
+}
+
+// moveLocked moves an item and everything below it to to, or deletes them
+// when to is ""
+func (f *fakeGraph) moveLocked(from, to string) {
+	var paths []string
+	for p := range f.items {
+		if p == from || strings.HasPrefix(p, from+"/") {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		item := f.items[p]
+		delete(f.items, p)
+		f.changes = append(f.changes, fakeGraphChange{path: p, id: item.id, dir: item.dir, deleted: true})
+		if to != "" {
+			moved := to + strings.TrimPrefix(p, from)
+			f.items[moved] = item
+			f.changes = append(f.changes, fakeGraphChange{path: moved, id: item.id, dir: item.dir})
+		}
+	}
+}
+
+func (f *fakeGraph) children(w http.ResponseWriter, r *http.Request, dir string, skip int) {
+	var names []string
+	for p := range f.items {
+		if p != "/" && path.Dir(p) == dir {
+			names = append(names, p)
+		}
+	}
+	sort.Strings(names)
+	skip = min(skip, len(names))
+	end := min(skip+f.pageSize, len(names))
+	page := map[string]any{}
+	value := []any{}
+	for _, p := range names[skip:end] {
+		value = append(value, f.itemJSON(p, f.items[p]))
+	}
+	page["value"] = value
+	if end < len(names) {
+		page["@odata.nextLink"] = fmt.Sprintf("%s%s?skip=%d", f.server.URL, r.URL.EscapedPath(), end)
+	}
+	writeJSON(w, page)
+}
+
+func (f *fakeGraph) uploadChunk(w http.ResponseWriter, r *http.Request, id string) {
+	session, ok := f.sessions[id]
+	if !ok {
+		f.graphError(w, http.StatusNotFound, "itemNotFound")
+		return
+	}
+	switch r.Method {
+	case http.MethodDelete:
+		delete(f.sessions, id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+		writeJSON(w, map[string]any{"nextExpectedRanges": []string{fmt.Sprintf("%d-", len(session.data))}})
+		return
+	}
+
+	f.chunks++
+	data, _ := io.ReadAll(r.Body)
+	if f.failChunk {
+		// The chunk arrived in part before the connection broke
+		f.failChunk = false
+		session.data = append(session.data, data[:len(data)/2]...)
+		f.graphError(w, http.StatusInternalServerError, "generalException")
+		return
+	}
+	var start, end, total int64
+	if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil || start != int64(len(session.data)) || end-start+1 != int64(len(data)) {
+		f.graphError(w, http.StatusRequestedRangeNotSatisfiable, "invalidRange")
+		return
+	}
+	session.data = append(session.data, data...)
+	if int64(len(session.data)) < total {
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]any{"nextExpectedRanges": []string{fmt.Sprintf("%d-", len(session.data))}})
+		return
+	}
+	delete(f.sessions, id)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(f.itemJSON(session.path, f.putLocked(session.path, session.data)))
+}
+
+// delta returns the changes after the since'th one of the log
+func (f *fakeGraph) delta(w http.ResponseWriter, since string) {
+	n, err := strconv.Atoi(since)
+	if err != nil || n > len(f.changes) {
+		f.graphError(w, http.StatusGone, "resyncRequired")
+		return
+	}
+	value := []any{}
+	for _, change := range f.changes[n:] {
+		item := map[string]any{
+			"id":              change.id,
+			"name":            path.Base(change.path),
+			"parentReference": map[string]any{"path": "/drive/root:" + strings.TrimSuffix(path.Dir(change.path), "/")},
+		}
+		if change.dir {
+			item["folder"] = map[string]any{}
+		} else {
+			item["file"] = map[string]any{}
+		}
+		if change.deleted {
+			item["deleted"] = map[string]any{}
+		}
+		value = append(value, item)
+	}
+	writeJSON(w, map[string]any{"value": value, "@odata.deltaLink": fmt.Sprintf("%s/delta?since=%d", f.server.URL, len(f.changes))})
+}
+
+func (f *fakeGraph) itemJSON(p string, item *fakeGraphItem) map[string]any {
+	m := map[string]any{
+		"id":                   item.id,
+		"name":                 path.Base(p),
+		"lastModifiedDateTime": item.modified.Format(time.RFC3339),
+	}
+	switch {
+	case p == "/":
+		m["name"] = "root"
+		m["root"] = map[string]any{}
+		m["folder"] = map[string]any{}
+	case item.notebook:
+		m["package"] = map[string]any{"type": "oneNote"}
+	case item.dir:
+		m["folder"] = map[string]any{}
+	default:
+		m["file"] = map[string]any{}
+		m["size"] = len(item.data)
+		m["eTag"] = item.etag
+	}
+	return m
+}
+
+func (f *fakeGraph) graphError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": code, "message": http.StatusText(status)}})
+}
+
+func TestOneDriveListPaginates(t *testing.T) {
+	fake := newFakeGraph(t)
+	fake.pageSize = 2
+	for i := 0; i < 5; i++ {
+		fake.put(fmt.Sprintf("/dir/file%d.txt", i), []byte("x"))
+	}
+	fake.put("/dir/sub/a.txt", []byte("a"))
+	fake.mu.Lock()
+	fake.items["/dir/notebook"] = &fakeGraphItem{id: "notebook", notebook: true}
+	fake.mu.Unlock()
+
+	infos, err := fake.backend().List("/dir")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, fmt.Sprintf("%s:%v", info.Name, info.IsDir))
+	}
+	want := "[file0.txt:false file1.txt:false file2.txt:false file3.txt:false file4.txt:false sub:true]"
+	if fmt.Sprint(names) != want {
+		t.Errorf("List = %v, want %v", names, want)
+	}
+}
+
+func TestOneDriveReadWrite(t *testing.T) {
+	fake := newFakeGraph(t)
+	b := fake.backend()
+
+	if err := b.Write("/dir/a b.txt", []byte("0123456789")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if data, err := b.Read("/dir/a b.txt"); err != nil || string(data) != "0123456789" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+	if data, err := b.ReadRange("/dir/a b.txt", 2, 3); err != nil || string(data) != "234" {
+		t.Errorf("ReadRange = %q, %v", data, err)
+	}
+	if data, err := b.ReadRange("/dir/a b.txt", 20, 3); err != nil || len(data) != 0 {
+		t.Errorf("ReadRange past the end = %q, %v", data, err)
+	}
+	info, err := b.Stat("/dir/a b.txt")
+	if err != nil || info.IsDir || info.Size != 10 || info.ETag == "" {
+		t.Errorf("Stat = %+v, %v", info, err)
+	}
+	if info, err := b.Stat("/"); err != nil || !info.IsDir || info.Name != "/" {
+		t.Errorf("Stat of the root = %+v, %v", info, err)
+	}
+	if _, err := b.Read("/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read of a missing file = %v, want ErrNotExist", err)
+	}
+
+	if err := b.Write("/other/b.txt", []byte("replaced")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Rename("/dir/a b.txt", "/other/b.txt"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if data, ok := fake.get("/other/b.txt"); !ok || string(data) != "0123456789" {
+		t.Errorf("renamed file holds %q", data)
+	}
+	if err := b.Delete("/other"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := b.Stat("/other/b.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat after Delete = %v, want ErrNotExist", err)
+	}
+}
+
+func TestOneDriveWriteIfMatch(t *testing.T) {
+	fake := newFakeGraph(t)
+	b := fake.backend()
+	fake.put("/a.txt", []byte("base"))
+	info, err := b.Stat("/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake.put("/a.txt", []byte("remote"))
+	if err := b.WriteIfMatch("/a.txt", []byte("local"), info.ETag); !errors.Is(err, types.ErrConflict) {
+		t.Errorf("WriteIfMatch over a changed file = %v, want ErrConflict", err)
+	}
+	if data, _ := fake.get("/a.txt"); string(data) != "remote" {
+		t.Errorf("remote file holds %q after the conflict", data)
+	}
+	info, _ = b.Stat("/a.txt")
+	if err := b.WriteIfMatch("/a.txt", []byte("local"), info.ETag); err != nil {
+		t.Errorf("WriteIfMatch over the current version failed: %v", err)
+	}
+}
+
+func TestOneDriveLargeUploadResumes(t *testing.T) {
+	fake := newFakeGraph(t)
+	b := fake.backend()
+	data := make([]byte, 2*onedriveUploadChunkSize+onedriveSimpleUploadLimit/2)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	fake.mu.Lock()
+	fake.failChunk = true
+	fake.mu.Unlock()
+	if err := b.Write("/large.bin", data); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	stored, ok := fake.get("/large.bin")
+	if !ok || !bytes.Equal(stored, data) {
+		t.Fatalf("stored %d bytes that don't match the %d written", len(stored), len(data))
+	}
+	fake.mu.Lock()
+	chunks := fake.chunks
+	fake.mu.Unlock()
+	// The failed chunk, then two from where it broke off instead of the start
+	if chunks != 3 {
+		t.Errorf("uploaded in %d chunks, want 3", chunks)
+	}
+}
+
+func TestOneDriveUnauthorized(t *testing.T) {
+	fake := newFakeGraph(t)
+	b, err := OneDriveDiskType{}.New(&models.Mount{
+		AccessToken: "revoked",
+		Options:     map[string]string{"graph_url": fake.server.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.List("/"); !errors.Is(err, types.ErrReauthRequired) {
+		t.Errorf("List with a revoked token = %v, want ErrReauthRequired", err)
+	}
+}
+
+func TestOneDriveWatch(t *testing.T) {
+	fake := newFakeGraph(t)
+	b := fake.backend()
+	fake.put("/dir/old.txt", []byte("old"))
+	fake.mu.Lock()
+	state := &fakeWatchState{cursor: fmt.Sprintf("%s/delta?since=%d", fake.server.URL, len(fake.changes))}
+	fake.mu.Unlock()
+	fake.put("/dir/new.txt", []byte("new"))
+	if err := b.Delete("/dir/old.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var events []string
+	err := b.Watch(ctx, state, func(event types.ChangeEvent) {
+		events = append(events, fmt.Sprintf("%s %v", event.Path, event.Kind))
+		if len(events) == 2 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	want := []string{
+		fmt.Sprintf("/dir/new.txt %v", types.ChangeModified),
+		fmt.Sprintf("/dir/old.txt %v", types.ChangeDeleted),
+	}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	fake.mu.Lock()
+	latest := fmt.Sprintf("%s/delta?since=%d", fake.server.URL, len(fake.changes))
+	fake.mu.Unlock()
+	if cursor, _ := state.Cursor(); cursor != latest {
+		t.Errorf("cursor = %q, want the latest delta link %q", cursor, latest)
+	}
+}
+
+func TestOneDriveWatchResetsExpiredCursor(t *testing.T) {
+	fake := newFakeGraph(t)
+	b := fake.backend()
+	state := &fakeWatchState{cursor: fake.server.URL + "/delta?since=expired"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var events []types.ChangeEvent
+	err := b.Watch(ctx, state, func(event types.ChangeEvent) {
+		events = append(events, event)
+		cancel()
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Path != "/" || events[0].Kind != types.ChangeModified {
+		t.Fatalf("events = %+v, want the root reported as modified", events)
+	}
+}
@@ -0,0 +1,14 @@
+package disktypes
+
+import "path"
+
+// joinRemote joins a mount path to the remote root of a backend. The mount
+// path is cleaned first, so it can never climb out of root even if a caller
+// bypassed the checks of the mount service.
+func joinRemote(root, p string) string {
+	clean := path.Clean("/" + p)
+	if root == "" {
+		return clean
+	}
+	return path.Join(root, clean)
+}
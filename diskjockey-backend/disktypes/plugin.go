@@ -0,0 +1,614 @@
+package disktypes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/plugin"
+	pluginapi "github.com/christhomas/diskjockey/diskjockey-backend/proto/plugin"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// PluginDiskType implements DiskType with an executable that speaks the
+// plugin protocol (see the plugin package), so disk types can be added
+// without rebuilding the backend. The plugin runs in its own process: when
+// it crashes or stops answering, its mounts go offline and it is restarted
+// after a delay that grows while it keeps failing. Mounts reopen their
+// backend in the new process on their next request.
+
+type PluginDiskType struct {
+	path        string
+	label       string // Used in log messages, the name of the executable
+	name        string
+	description string
+	config      types.DiskTypeConfigTemplate
+
+	mu           sync.Mutex
+	cmd          *exec.Cmd
+	stdin        io.Closer
+	conn         *plugin.Conn
+	generation   uint64 // Incremented every time the process is started
+	ready        bool   // Whether the current process completed the handshake
+	started      time.Time
+	exited       chan struct{} // Closed once the current process exited
+	pending      map[uint64]chan *pluginapi.Message
+	nextID       uint64
+	restartDelay time.Duration
+	closed       bool
+}
+
+type PluginBackend struct {
+	plugin *PluginDiskType
+	mount  *models.Mount
+
+	mu         sync.Mutex
+	handle     uint64 // Handle of the backend in the plugin, 0 if not open
+	generation uint64 // Plugin process the handle belongs to
+	caps       *pluginapi.OpenResponse
+}
+
+const (
+	pluginHandshakeTimeout = 10 * time.Second
+	// Requests carry whole files, so a slow remote can take a while. A
+	// plugin that doesn't answer in time is considered hung and restarted.
+	pluginCallTimeout = 5 * time.Minute
+	// Time a plugin gets to exit after its stdin is closed
+	pluginStopTimeout = 5 * time.Second
+	// Delay before restarting a plugin, doubled after every crash up to the
+	// maximum. A plugin that ran for longer than the maximum starts over.
+	pluginMinRestartDelay = time.Second
+	pluginMaxRestartDelay = time.Minute
+)
+
+// errPluginRestarted is returned for a request sent to a process that has
+// since been replaced, whose handles are no longer valid
+var errPluginRestarted = fmt.Errorf("%w: plugin restarted", types.ErrOffline)
+
+// LoadPlugins starts every executable in dir as a plugin. Plugins that fail
+// to start are logged and skipped. A missing dir means there are no plugins.
+func LoadPlugins(dir string) []*PluginDiskType {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "[Plugins] Failed to read %s: %v\n", dir, err)
+		}
+		return nil
+	}
+
+	var plugins []*PluginDiskType
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(dir, entry.Name())
+		// Stat follows symlinks, so plugins can be linked from elsewhere
+		info, err := os.Stat(p)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			fmt.Printf("[Plugins] Skipping %s, not an executable file\n", p)
+			continue
+		}
+		d, err := StartPlugin(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[Plugins] Failed to start %s: %v\n", p, err)
+			continue
+		}
+		plugins = append(plugins, d)
+	}
+	return plugins
+}
+
+// StartPlugin starts the plugin executable at path and asks it which disk
+// type it implements.
+func StartPlugin(path string) (*PluginDiskType, error) {
+	d := &PluginDiskType{
+		path:         path,
+		label:        filepath.Base(path),
+		pending:      make(map[uint64]chan *pluginapi.Message),
+		restartDelay: pluginMinRestartDelay,
+	}
+	if err := d.start(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *PluginDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &PluginBackend{plugin: d, mount: mount}
+	if _, _, err := b.open(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (d *PluginDiskType) Name() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.name
+}
+
+func (d *PluginDiskType) Description() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.description
+}
+
+// ConfigTemplate returns the plugin's config fields along with the cache and
+// polling fields every remote disk type has.
+func (d *PluginDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	template := withCacheFields(withPollingFields(types.DiskTypeConfigTemplate{}))
+	for key, field := range d.config {
+		template[key] = field
+	}
+	return template
+}
+
+// start runs the executable and performs the handshake. The disk type a
+// restarted plugin reports must be the one it reported at first.
+func (d *PluginDiskType) start() error {
+	cmd := exec.Command(d.path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	logger := &pluginLogger{label: d.label}
+	cmd.Stderr = logger
+	// Children of the plugin can keep its stderr open after it exited
+	cmd.WaitDelay = pluginStopTimeout
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	conn := plugin.NewConn(stdout, stdin)
+	exited := make(chan struct{})
+	d.mu.Lock()
+	d.generation++
+	gen := d.generation
+	d.cmd, d.stdin, d.conn, d.ready = cmd, stdin, conn, false
+	d.started = time.Now()
+	d.exited = exited
+	d.mu.Unlock()
+	go d.receive(conn, cmd, gen, logger, exited)
+
+	name := d.Name() // Set once the first process started
+	var resp pluginapi.HandshakeResponse
+	err = d.request(gen, pluginapi.MessageType_HANDSHAKE_REQUEST, &pluginapi.HandshakeRequest{ProtocolVersion: plugin.ProtocolVersion},
+		pluginapi.MessageType_HANDSHAKE_RESPONSE, &resp, pluginHandshakeTimeout)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("handshake failed: %w", err)
+	case resp.ProtocolVersion != plugin.ProtocolVersion:
+		err = fmt.Errorf("plugin uses protocol version %d, expected %d", resp.ProtocolVersion, plugin.ProtocolVersion)
+	case resp.Name == "":
+		err = fmt.Errorf("plugin did not report a disk type name")
+	case name != "" && resp.Name != name:
+		err = fmt.Errorf("plugin now implements %q instead of %q", resp.Name, name)
+	}
+	if err != nil {
+		d.kill(gen)
+		return err
+	}
+
+	config := make(types.DiskTypeConfigTemplate)
+	for key, field := range resp.Config {
+		config[key] = types.DiskTypeConfigField{
+			Type:        field.GetType(),
+			Description: field.GetDescription(),
+			Required:    field.GetRequired(),
+		}
+	}
+
+	d.mu.Lock()
+	d.name, d.description, d.config = resp.Name, resp.Description, config
+	d.ready = d.generation == gen && d.conn != nil
+	ready := d.ready
+	d.mu.Unlock()
+	if !ready {
+		return fmt.Errorf("plugin exited after the handshake")
+	}
+	fmt.Printf("[Plugin %s] Started disk type %s (pid %d)\n", d.label, resp.Name, cmd.Process.Pid)
+	return nil
+}
+
+// receive delivers responses to the requests waiting for them until the
+// process exits, then fails the requests still waiting and schedules a
+// restart.
+func (d *PluginDiskType) receive(conn *plugin.Conn, cmd *exec.Cmd, gen uint64, logger *pluginLogger, exited chan struct{}) {
+	for {
+		msg, err := conn.Receive()
+		if err != nil {
+			if err != io.EOF {
+				fmt.Fprintf(os.Stderr, "[Plugin %s] Error reading message: %v\n", d.label, err)
+			}
+			break
+		}
+		d.mu.Lock()
+		ch, ok := d.pending[msg.Id]
+		delete(d.pending, msg.Id)
+		d.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+
+	// A plugin that broke the protocol is stopped too, it can't be trusted
+	// to answer anything else
+	cmd.Process.Kill()
+
+	d.mu.Lock()
+	wasReady := d.ready
+	ranFor := time.Since(d.started)
+	d.cmd, d.stdin, d.conn, d.ready = nil, nil, nil, false
+	for id, ch := range d.pending {
+		close(ch)
+		delete(d.pending, id)
+	}
+	closed := d.closed
+	d.mu.Unlock()
+
+	waitErr := cmd.Wait()
+	logger.flush()
+	close(exited)
+
+	// Plugins that never became ready are retried by whoever started them
+	if closed || !wasReady {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[Plugin %s] Exited after %s: %v\n", d.label, ranFor.Round(time.Second), waitErr)
+	go d.restart(ranFor)
+}
+
+// restart starts the plugin again after a delay, retrying until it starts
+// or the disk type is closed.
+func (d *PluginDiskType) restart(ranFor time.Duration) {
+	d.mu.Lock()
+	if ranFor > pluginMaxRestartDelay {
+		d.restartDelay = pluginMinRestartDelay
+	}
+	delay := d.restartDelay
+	d.mu.Unlock()
+
+	for {
+		fmt.Printf("[Plugin %s] Restarting in %s\n", d.label, delay)
+		time.Sleep(delay)
+		delay = min(delay*2, pluginMaxRestartDelay)
+		d.mu.Lock()
+		d.restartDelay = delay
+		closed := d.closed
+		d.mu.Unlock()
+		if closed {
+			return
+		}
+
+		err := d.start()
+		if err == nil {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "[Plugin %s] Restart failed: %v\n", d.label, err)
+	}
+}
+
+// kill stops the process of generation gen, if it is still running.
+func (d *PluginDiskType) kill(gen uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.generation == gen && d.cmd != nil {
+		d.cmd.Process.Kill()
+	}
+}
+
+// current returns the generation of the running process and whether it is
+// ready for requests.
+func (d *PluginDiskType) current() (uint64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.generation, d.ready
+}
+
+// request sends a request to the process of generation gen and waits for
+// its response. Errors the plugin reports in the response are returned as
+// errors. A plugin that exits or doesn't answer in time makes the request
+// fail with types.ErrOffline.
+func (d *PluginDiskType) request(gen uint64, reqType pluginapi.MessageType, req proto.Message, respType pluginapi.MessageType, resp pluginResponse, timeout time.Duration) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", reqType, err)
+	}
+
+	d.mu.Lock()
+	if d.conn == nil || d.generation != gen {
+		d.mu.Unlock()
+		return errPluginRestarted
+	}
+	d.nextID++
+	id := d.nextID
+	ch := make(chan *pluginapi.Message, 1)
+	d.pending[id] = ch
+	conn := d.conn
+	d.mu.Unlock()
+
+	if err := conn.Send(&pluginapi.Message{Id: id, Type: reqType, Payload: payload}); err != nil {
+		d.forget(id)
+		return fmt.Errorf("%w: plugin %s: %v", types.ErrOffline, d.label, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			return fmt.Errorf("%w: plugin %s exited", types.ErrOffline, d.label)
+		}
+		if msg.Type == pluginapi.MessageType_UNKNOWN_TYPE {
+			var e pluginapi.Error
+			if err := proto.Unmarshal(msg.Payload, &e); err != nil {
+				return fmt.Errorf("plugin %s sent an invalid error: %w", d.label, err)
+			}
+			return plugin.DecodeError(&e)
+		}
+		if msg.Type != respType {
+			return fmt.Errorf("plugin %s answered %s with %s", d.label, reqType, msg.Type)
+		}
+		if err := proto.Unmarshal(msg.Payload, resp); err != nil {
+			return fmt.Errorf("plugin %s sent an invalid %s: %w", d.label, respType, err)
+		}
+		return plugin.DecodeError(resp.GetError())
+	case <-timer.C:
+		d.forget(id)
+		fmt.Fprintf(os.Stderr, "[Plugin %s] No answer to %s within %s, stopping it\n", d.label, reqType, timeout)
+		d.kill(gen)
+		return fmt.Errorf("%w: plugin %s did not answer %s", types.ErrOffline, d.label, reqType)
+	}
+}
+
+func (d *PluginDiskType) forget(id uint64) {
+	d.mu.Lock()
+	delete(d.pending, id)
+	d.mu.Unlock()
+}
+
+// pluginResponse is implemented by all response messages
+type pluginResponse interface {
+	proto.Message
+	GetError() *pluginapi.Error
+}
+
+// Close stops the plugin, giving it a moment to close its backends after
+// its stdin is closed.
+func (d *PluginDiskType) Close() error {
+	d.mu.Lock()
+	d.closed = true
+	stdin, exited, gen := d.stdin, d.exited, d.generation
+	d.mu.Unlock()
+	if stdin == nil {
+		return nil
+	}
+
+	stdin.Close()
+	select {
+	case <-exited:
+	case <-time.After(pluginStopTimeout):
+		d.kill(gen)
+		<-exited
+	}
+	return nil
+}
+
+// pluginLogger logs what a plugin writes to stderr line by line
+type pluginLogger struct {
+	label string
+	mu    sync.Mutex
+	buf   []byte
+}
+
+func (l *pluginLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		fmt.Fprintf(os.Stderr, "[Plugin %s] %s\n", l.label, l.buf[:i])
+		l.buf = l.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush logs an unterminated last line.
+func (l *pluginLogger) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.buf) > 0 {
+		fmt.Fprintf(os.Stderr, "[Plugin %s] %s\n", l.label, l.buf)
+		l.buf = nil
+	}
+}
+
+// open returns the handle of the backend in the running plugin process,
+// opening it if the plugin was restarted since it was last opened.
+func (b *PluginBackend) open() (uint64, uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	gen, ready := b.plugin.current()
+	if !ready {
+		return 0, 0, fmt.Errorf("%w: plugin %s is not running", types.ErrOffline, b.plugin.label)
+	}
+	if b.handle != 0 && b.generation == gen {
+		return b.handle, gen, nil
+	}
+
+	var resp pluginapi.OpenResponse
+	err := b.plugin.request(gen, pluginapi.MessageType_OPEN_REQUEST, &pluginapi.OpenRequest{Mount: plugin.EncodeMount(b.mount)},
+		pluginapi.MessageType_OPEN_RESPONSE, &resp, pluginCallTimeout)
+	if err != nil {
+		return 0, 0, err
+	}
+	b.handle, b.generation, b.caps = resp.Handle, gen, &resp
+	return b.handle, gen, nil
+}
+
+// do runs a request against the backend's handle, retrying it once with a
+// new handle when the plugin was restarted in the meantime.
+func (b *PluginBackend) do(reqType pluginapi.MessageType, req func(handle uint64) proto.Message, respType pluginapi.MessageType, resp pluginResponse) error {
+	for attempt := 0; ; attempt++ {
+		handle, gen, err := b.open()
+		if err != nil {
+			return err
+		}
+		err = b.plugin.request(gen, reqType, req(handle), respType, resp, pluginCallTimeout)
+		if errors.Is(err, errPluginRestarted) && attempt == 0 {
+			continue
+		}
+		return err
+	}
+}
+
+// supports reports an optional operation of the opened backend.
+func (b *PluginBackend) supports(op func(*pluginapi.OpenResponse) bool) bool {
+	if _, _, err := b.open(); err != nil {
+		// Let the request fail with the same error
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return op(b.caps)
+}
+
+// pluginBasicBackend hides the optional operations of a PluginBackend from
+// the generic implementations in types
+type pluginBasicBackend struct {
+	types.Backend
+}
+
+func (b *PluginBackend) List(path string) ([]types.FileInfo, error) {
+	var resp pluginapi.ListResponse
+	err := b.do(pluginapi.MessageType_LIST_REQUEST, func(handle uint64) proto.Message {
+		return &pluginapi.ListRequest{Handle: handle, Path: path}
+	}, pluginapi.MessageType_LIST_RESPONSE, &resp)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.FileInfo, 0, len(resp.Files))
+	for _, f := range resp.Files {
+		out = append(out, plugin.DecodeFileInfo(f))
+	}
+	return out, nil
+}
+
+func (b *PluginBackend) Stat(path string) (types.FileInfo, error) {
+	if !b.supports((*pluginapi.OpenResponse).GetStat) {
+		return types.Stat(pluginBasicBackend{b}, path)
+	}
+	var resp pluginapi.StatResponse
+	err := b.do(pluginapi.MessageType_STAT_REQUEST, func(handle uint64) proto.Message {
+		return &pluginapi.StatRequest{Handle: handle, Path: path}
+	}, pluginapi.MessageType_STAT_RESPONSE, &resp)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return plugin.DecodeFileInfo(resp.Info), nil
+}
+
+func (b *PluginBackend) Read(path string) ([]byte, error) {
+	var resp pluginapi.ReadResponse
+	err := b.do(pluginapi.MessageType_READ_REQUEST, func(handle uint64) proto.Message {
+		return &pluginapi.ReadRequest{Handle: handle, Path: path}
+	}, pluginapi.MessageType_READ_RESPONSE, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (b *PluginBackend) ReadRange(path string, offset, length int64) ([]byte, error) {
+	if !b.supports((*pluginapi.OpenResponse).GetReadRange) {
+		return types.ReadRange(pluginBasicBackend{b}, path, offset, length)
+	}
+	var resp pluginapi.ReadRangeResponse
+	err := b.do(pluginapi.MessageType_READ_RANGE_REQUEST, func(handle uint64) proto.Message {
+		return &pluginapi.ReadRangeRequest{Handle: handle, Path: path, Offset: offset, Length: length}
+	}, pluginapi.MessageType_READ_RANGE_RESPONSE, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (b *PluginBackend) Write(path string, data []byte) error {
+	var resp pluginapi.WriteResponse
+	return b.do(pluginapi.MessageType_WRITE_REQUEST, func(handle uint64) proto.Message {
+		return &pluginapi.WriteRequest{Handle: handle, Path: path, Data: data}
+	}, pluginapi.MessageType_WRITE_RESPONSE, &resp)
+}
+
+func (b *PluginBackend) Delete(path string) error {
+	var resp pluginapi.DeleteResponse
+	return b.do(pluginapi.MessageType_DELETE_REQUEST, func(handle uint64) proto.Message {
+		return &pluginapi.DeleteRequest{Handle: handle, Path: path}
+	}, pluginapi.MessageType_DELETE_RESPONSE, &resp)
+}
+
+func (b *PluginBackend) Rename(from, to string) error {
+	if !b.supports((*pluginapi.OpenResponse).GetRename) {
+		return types.Rename(pluginBasicBackend{b}, from, to)
+	}
+	var resp pluginapi.RenameResponse
+	return b.do(pluginapi.MessageType_RENAME_REQUEST, func(handle uint64) proto.Message {
+		return &pluginapi.RenameRequest{Handle: handle, From: from, To: to}
+	}, pluginapi.MessageType_RENAME_RESPONSE, &resp)
+}
+
+// Reconnect asks the plugin to reconnect the backend. After a restart of
+// the plugin the backend is opened again instead.
+func (b *PluginBackend) Reconnect() error {
+	b.mu.Lock()
+	gen, _ := b.plugin.current()
+	reopen := b.generation != gen
+	b.mu.Unlock()
+	if reopen {
+		_, _, err := b.open()
+		return err
+	}
+
+	var resp pluginapi.ReconnectResponse
+	return b.do(pluginapi.MessageType_RECONNECT_REQUEST, func(handle uint64) proto.Message {
+		return &pluginapi.ReconnectRequest{Handle: handle}
+	}, pluginapi.MessageType_RECONNECT_RESPONSE, &resp)
+}
+
+// Close releases the backend in the plugin. There's nothing to release
+// when the plugin was restarted since.
+func (b *PluginBackend) Close() error {
+	b.mu.Lock()
+	handle, gen := b.handle, b.generation
+	b.handle = 0
+	b.mu.Unlock()
+	if handle == 0 {
+		return nil
+	}
+
+	var resp pluginapi.CloseResponse
+	err := b.plugin.request(gen, pluginapi.MessageType_CLOSE_REQUEST, &pluginapi.CloseRequest{Handle: handle},
+		pluginapi.MessageType_CLOSE_RESPONSE, &resp, pluginStopTimeout)
+	if errors.Is(err, errPluginRestarted) {
+		return nil
+	}
+	return err
+}
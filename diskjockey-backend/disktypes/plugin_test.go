@@ -0,0 +1,199 @@
+package disktypes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/plugin"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// The plugin tests run the test binary itself as the plugin executable.
+// testPluginEnv tells the child process to serve testPluginDiskType instead
+// of running the tests, or to exit at once when it isn't "serve".
+const testPluginEnv = "DISKJOCKEY_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(testPluginEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "serve":
+		if err := plugin.Serve(testPluginDiskType{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	default:
+		// Exit without answering the handshake
+		os.Exit(1)
+	}
+}
+
+// testPluginDiskType is the memory disk type under another name, whose
+// process exits when /exit is written, like a plugin crashing
+type testPluginDiskType struct {
+	MemoryDiskType
+}
+
+type testPluginBackend struct {
+	*MemoryBackend
+}
+
+func (testPluginDiskType) Name() string {
+	return "testplugin"
+}
+
+func (t testPluginDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b, err := t.MemoryDiskType.New(mount)
+	if err != nil {
+		return nil, err
+	}
+	return testPluginBackend{b.(*MemoryBackend)}, nil
+}
+
+func (b testPluginBackend) Write(p string, data []byte) error {
+	if p == "/exit" {
+		os.Exit(3)
+	}
+	return b.MemoryBackend.Write(p, data)
+}
+
+// startTestPlugin starts the test binary as a plugin serving
+// testPluginDiskType
+func startTestPlugin(t *testing.T) *PluginDiskType {
+	t.Helper()
+	t.Setenv(testPluginEnv, "serve")
+	d, err := StartPlugin(os.Args[0])
+	if err != nil {
+		t.Fatalf("StartPlugin failed: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+func TestPluginDiskType(t *testing.T) {
+	d := startTestPlugin(t)
+	if d.Name() != "testplugin" || d.Description() != (MemoryDiskType{}).Description() {
+		t.Errorf("plugin reported %q: %q", d.Name(), d.Description())
+	}
+	template := d.ConfigTemplate()
+	for _, key := range []string{"max_size", "cache", "watch"} {
+		if _, ok := template[key]; !ok {
+			t.Errorf("config template has no %s field", key)
+		}
+	}
+
+	b := mustNew(t, d, &models.Mount{})
+	if err := b.Write("/dir/a.txt", []byte("0123456789")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if data, err := b.Read("/dir/a.txt"); err != nil || string(data) != "0123456789" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+	// The memory backend can't read ranges, so the whole file is read
+	if data, err := b.(types.RangeReader).ReadRange("/dir/a.txt", 2, 3); err != nil || string(data) != "234" {
+		t.Errorf("ReadRange = %q, %v", data, err)
+	}
+	if info, err := b.(types.Stater).Stat("/dir/a.txt"); err != nil || info.Size != 10 || info.IsDir {
+		t.Errorf("Stat = %+v, %v", info, err)
+	}
+	if err := b.(types.Renamer).Rename("/dir/a.txt", "/b.txt"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	infos, err := b.List("/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, fmt.Sprintf("%s:%v", info.Name, info.IsDir))
+	}
+	if want := "[b.txt:false dir:true]"; fmt.Sprint(names) != want {
+		t.Errorf("List = %v, want %v", names, want)
+	}
+	if err := b.Delete("/b.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	// Errors keep what they stand for across the process boundary
+	if _, err := b.Read("/b.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read of a deleted file = %v, want ErrNotExist", err)
+	}
+	if _, err := d.New(&models.Mount{Options: map[string]string{"max_size": "lots"}}); err == nil {
+		t.Error("opened a backend with an invalid option")
+	}
+	offline := mustNew(t, d, &models.Mount{Options: map[string]string{"error_rate": "1"}})
+	if _, err := offline.List("/"); !types.IsUnreachable(err) {
+		t.Errorf("List of an unreachable remote = %v, want it unreachable", err)
+	}
+}
+
+func TestPluginRestartsAfterCrash(t *testing.T) {
+	d := startTestPlugin(t)
+	b := mustNew(t, d, &models.Mount{})
+	if err := b.Write("/a.txt", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Write("/exit", nil); !errors.Is(err, types.ErrOffline) {
+		t.Fatalf("Write to a crashing plugin = %v, want ErrOffline", err)
+	}
+	// The plugin is restarted after pluginMinRestartDelay, and the backend
+	// opened again in the new process
+	deadline := time.Now().Add(10 * pluginMinRestartDelay)
+	for {
+		_, err := b.List("/")
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, types.ErrOffline) || time.Now().After(deadline) {
+			t.Fatalf("List after the crash = %v, want the plugin restarted", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := b.Write("/b.txt", []byte("b")); err != nil {
+		t.Errorf("Write after the restart failed: %v", err)
+	}
+	// The memory of the crashed process is gone
+	if _, err := b.Read("/a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Read of a file from before the crash = %v, want ErrNotExist", err)
+	}
+}
+
+func TestStartPluginFailsWithoutHandshake(t *testing.T) {
+	t.Setenv(testPluginEnv, "exit")
+	if d, err := StartPlugin(os.Args[0]); err == nil {
+		d.Close()
+		t.Fatal("started a plugin that exited without a handshake")
+	}
+}
+
+func TestLoadPlugins(t *testing.T) {
+	t.Setenv(testPluginEnv, "serve")
+	dir := t.TempDir()
+	for _, name := range []string{"testplugin", ".hidden"} {
+		if err := os.Symlink(os.Args[0], filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plugins := LoadPlugins(dir)
+	for _, d := range plugins {
+		t.Cleanup(func() { d.Close() })
+	}
+	if len(plugins) != 1 || plugins[0].Name() != "testplugin" {
+		t.Errorf("loaded %d plugins, want only the executable one", len(plugins))
+	}
+	if plugins := LoadPlugins(filepath.Join(dir, "missing")); len(plugins) != 0 {
+		t.Errorf("loaded %d plugins from a missing directory", len(plugins))
+	}
+}
@@ -0,0 +1,42 @@
+package disktypes
+
+import "github.com/christhomas/diskjockey/diskjockey-backend/types"
+
+// withPollingFields adds the options controlling how the backend polls a
+// mount for remote changes to the config template of a disk type without
+// change notification. Only disk types offering these options are polled, so
+// they are left out where walking the remote is costly or pointless, e.g.
+// for object stores, read-only and local disk types.
+func withPollingFields(template types.DiskTypeConfigTemplate) types.DiskTypeConfigTemplate {
+	template["watch"] = types.DiskTypeConfigField{
+		Type:        "bool",
+		Description: "Poll for changes made on the server (default true)",
+		Required:    false,
+	}
+	template["watch_paths"] = types.DiskTypeConfigField{
+		Type:        "string",
+		Description: "Comma separated directories to poll (default /)",
+		Required:    false,
+	}
+	template["watch_depth"] = types.DiskTypeConfigField{
+		Type:        "integer",
+		Description: "Directory levels below each watched path to poll (default 5)",
+		Required:    false,
+	}
+	template["watch_max_entries"] = types.DiskTypeConfigField{
+		Type:        "integer",
+		Description: "Maximum number of entries to poll (default 10000)",
+		Required:    false,
+	}
+	template["watch_interval"] = types.DiskTypeConfigField{
+		Type:        "integer",
+		Description: "Seconds between polls while changes are seen (default 30)",
+		Required:    false,
+	}
+	template["watch_max_interval"] = types.DiskTypeConfigField{
+		Type:        "integer",
+		Description: "Seconds between polls once idle (default 600)",
+		Required:    false,
+	}
+	return template
+}
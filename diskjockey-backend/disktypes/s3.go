@@ -0,0 +1,415 @@
+package disktypes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3DiskType implements DiskType for mounting a bucket of S3 compatible
+// object storage (AWS, MinIO, Ceph RGW, ...). Objects are files and the
+// common prefixes of their keys up to a "/" are directories.
+
+type S3DiskType struct{}
+
+type S3Backend struct {
+	mount    *models.Mount
+	client   *minio.Client
+	bucket   string
+	prefix   string // Key prefix of the mount root, without slashes around it
+	partSize uint64
+}
+
+// Writes larger than the part size are uploaded in parts
+const (
+	s3DefaultPartSize = 16 << 20
+	s3MinPartSize     = 5 << 20
+)
+
+func (S3DiskType) New(mount *models.Mount) (types.Backend, error) {
+	b := &S3Backend{mount: mount}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (S3DiskType) Name() string {
+	return "s3"
+}
+
+func (S3DiskType) Description() string {
+	return "S3 compatible object storage bucket"
+}
+
+func (S3DiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	return withCacheFields(types.DiskTypeConfigTemplate{
+		"endpoint": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Server endpoint, e.g. s3.amazonaws.com, minio.local:9000 or https://rgw.example.com",
+			Required:    true,
+		},
+		"region": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Region of the bucket (default us-east-1)",
+			Required:    false,
+		},
+		"bucket": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Name of the bucket to mount",
+			Required:    true,
+		},
+		"prefix": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Key prefix to mount instead of the whole bucket (e.g. assets/2024)",
+			Required:    false,
+		},
+		"access_key": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Access key ID, leave empty for anonymous access",
+			Required:    false,
+		},
+		"secret_key": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Secret access key",
+			Required:    false,
+		},
+		"path_style": types.DiskTypeConfigField{
+			Type:        "bool",
+			Description: "Address the bucket in the path instead of the host name, as most MinIO and Ceph setups need (default false)",
+			Required:    false,
+		},
+		"use_tls": types.DiskTypeConfigField{
+			Type:        "bool",
+			Description: "Connect with HTTPS (default true, unless the endpoint starts with http://)",
+			Required:    false,
+		},
+		"ca_cert": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Path to a PEM encoded CA certificate to trust, for self-signed servers",
+			Required:    false,
+		},
+		"insecure_skip_verify": types.DiskTypeConfigField{
+			Type:        "bool",
+			Description: "Skip TLS certificate verification (not secure, testing only)",
+			Required:    false,
+		},
+		"part_size": types.DiskTypeConfigField{
+			Type:        "integer",
+			Description: "Size in MiB of the parts larger files are uploaded in, at least 5 (default 16)",
+			Required:    false,
+		},
+	})
+}
+
+func (b *S3Backend) connect() error {
+	endpoint, secure, err := b.endpoint()
+	if err != nil {
+		return err
+	}
+
+	b.bucket = b.mount.Option("bucket")
+	if b.bucket == "" {
+		return fmt.Errorf("s3: missing required config 'bucket'")
+	}
+	b.prefix = strings.Trim(path.Clean("/"+b.mount.Option("prefix")), "/")
+
+	b.partSize = s3DefaultPartSize
+	if raw := b.mount.Option("part_size"); raw != "" {
+		mib, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return fmt.Errorf("s3: invalid part_size %q: %w", raw, err)
+		}
+		b.partSize = max(mib<<20, s3MinPartSize)
+	}
+
+	transport, err := tlsTransport("s3", b.mount)
+	if err != nil {
+		return err
+	}
+
+	lookup := minio.BucketLookupAuto
+	if b.mount.BoolOption("path_style") {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(b.mount.Option("access_key"), b.mount.Option("secret_key"), ""),
+		Secure:       secure,
+		Region:       b.mount.Option("region"),
+		BucketLookup: lookup,
+		Transport:    transport,
+	})
+	if err != nil {
+		return fmt.Errorf("s3: %w", err)
+	}
+
+	ok, err := client.BucketExists(context.Background(), b.bucket)
+	if err != nil {
+		return s3Error(err)
+	}
+	if !ok {
+		return fmt.Errorf("s3: bucket %q does not exist", b.bucket)
+	}
+
+	b.client = client
+	return nil
+}
+
+// endpoint returns the host (and port) of the configured endpoint, and
+// whether to connect to it with TLS. A scheme in the endpoint takes
+// precedence over the use_tls option.
+func (b *S3Backend) endpoint() (string, bool, error) {
+	raw := b.mount.Option("endpoint")
+	if raw == "" {
+		return "", false, fmt.Errorf("s3: missing required config 'endpoint'")
+	}
+
+	secure := true
+	if v := b.mount.Option("use_tls"); v != "" {
+		secure = b.mount.BoolOption("use_tls")
+	}
+
+	if !strings.Contains(raw, "://") {
+		return strings.TrimSuffix(raw, "/"), secure, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false, fmt.Errorf("s3: invalid endpoint %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false, fmt.Errorf("s3: endpoint scheme must be http or https, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", false, fmt.Errorf("s3: endpoint %q has no host", raw)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return "", false, fmt.Errorf("s3: endpoint %q can't have a path, use 'bucket' and 'prefix'", raw)
+	}
+	return u.Host, u.Scheme == "https", nil
+}
+
+// key returns the object key of a mount path, "" for the root of the mount
+// when the whole bucket is mounted.
+func (b *S3Backend) key(p string) string {
+	return strings.TrimPrefix(joinRemote("/"+b.prefix, p), "/")
+}
+
+// dirPrefix returns the key prefix of the objects in a directory.
+func (b *S3Backend) dirPrefix(p string) string {
+	if key := b.key(p); key != "" {
+		return key + "/"
+	}
+	return ""
+}
+
+func (b *S3Backend) List(p string) ([]types.FileInfo, error) {
+	prefix := b.dirPrefix(p)
+
+	var out []types.FileInfo
+	marker := false
+	for obj := range b.client.ListObjects(context.Background(), b.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if obj.Err != nil {
+			return nil, s3Error(obj.Err)
+		}
+		name := strings.TrimPrefix(obj.Key, prefix)
+		if name == "" {
+			// The empty object some tools create to keep a directory
+			marker = true
+			continue
+		}
+		if strings.HasSuffix(name, "/") {
+			out = append(out, types.FileInfo{Name: strings.TrimSuffix(name, "/"), IsDir: true})
+			continue
+		}
+		out = append(out, s3FileInfo(name, obj))
+	}
+
+	// Directories only exist while there are objects below them
+	if len(out) == 0 && !marker && b.key(p) != b.prefix {
+		return nil, &fs.PathError{Op: "list", Path: p, Err: fs.ErrNotExist}
+	}
+	return out, nil
+}
+
+func s3FileInfo(name string, obj minio.ObjectInfo) types.FileInfo {
+	return types.FileInfo{
+		Name:    name,
+		Size:    obj.Size,
+		ModTime: obj.LastModified,
+		ETag:    obj.ETag,
+	}
+}
+
+func (b *S3Backend) Stat(p string) (types.FileInfo, error) {
+	key := b.key(p)
+	if key == b.prefix {
+		return types.FileInfo{Name: "/", IsDir: true}, nil
+	}
+
+	obj, err := b.client.StatObject(context.Background(), b.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		return s3FileInfo(path.Base(key), obj), nil
+	}
+	if err = s3Error(err); !errors.Is(err, fs.ErrNotExist) {
+		return types.FileInfo{}, err
+	}
+
+	// Not an object, but it's a directory if any key starts with it
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for obj := range b.client.ListObjects(ctx, b.bucket, minio.ListObjectsOptions{Prefix: key + "/", MaxKeys: 1}) {
+		if obj.Err != nil {
+			return types.FileInfo{}, s3Error(obj.Err)
+		}
+		return types.FileInfo{Name: path.Base(key), IsDir: true}, nil
+	}
+	return types.FileInfo{}, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+}
+
+func (b *S3Backend) Read(p string) ([]byte, error) {
+	obj, err := b.client.GetObject(context.Background(), b.bucket, b.key(p), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	return data, nil
+}
+
+// Write uploads data in a single request, or as a multipart upload of
+// partSize parts when it's larger than that.
+func (b *S3Backend) Write(p string, data []byte) error {
+	key := b.key(p)
+	if key == b.prefix {
+		return fmt.Errorf("cannot write to root directory")
+	}
+	_, err := b.client.PutObject(context.Background(), b.bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		PartSize: b.partSize,
+	})
+	return s3Error(err)
+}
+
+// Delete removes an object, or every object below a directory.
+func (b *S3Backend) Delete(p string) error {
+	info, err := b.Stat(p)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir {
+		return s3Error(b.client.RemoveObject(context.Background(), b.bucket, b.key(p), minio.RemoveObjectOptions{}))
+	}
+	if b.key(p) == b.prefix {
+		return fmt.Errorf("cannot delete root directory")
+	}
+
+	keys, err := b.keysBelow(b.dirPrefix(p))
+	if err != nil {
+		return err
+	}
+	objects := make(chan minio.ObjectInfo, len(keys))
+	for _, key := range keys {
+		objects <- minio.ObjectInfo{Key: key}
+	}
+	close(objects)
+
+	// Every removal is reported, keep the first error
+	for removeErr := range b.client.RemoveObjects(context.Background(), b.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if err == nil {
+			err = s3Error(removeErr.Err)
+		}
+	}
+	return err
+}
+
+// keysBelow returns the keys of every object whose key starts with prefix.
+func (b *S3Backend) keysBelow(prefix string) ([]string, error) {
+	var keys []string
+	for obj := range b.client.ListObjects(context.Background(), b.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, s3Error(obj.Err)
+		}
+		keys = append(keys, obj.Key)
+	}
+	return keys, nil
+}
+
+// Rename copies objects to their new keys on the server and removes the
+// originals. S3 has no rename, so moving a directory copies every object
+// below it.
+func (b *S3Backend) Rename(from, to string) error {
+	info, err := b.Stat(from)
+	if err != nil {
+		return err
+	}
+	if b.key(from) == b.prefix || b.key(to) == b.prefix {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	if !info.IsDir {
+		return b.move(b.key(from), b.key(to))
+	}
+
+	fromPrefix, toPrefix := b.dirPrefix(from), b.dirPrefix(to)
+	if strings.HasPrefix(toPrefix, fromPrefix) {
+		return fmt.Errorf("cannot move %s into itself", from)
+	}
+	keys, err := b.keysBelow(fromPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := b.move(key, toPrefix+strings.TrimPrefix(key, fromPrefix)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// move copies an object to another key and removes the original.
+func (b *S3Backend) move(fromKey, toKey string) error {
+	ctx := context.Background()
+	_, err := b.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: b.bucket, Object: toKey},
+		minio.CopySrcOptions{Bucket: b.bucket, Object: fromKey},
+	)
+	if err != nil {
+		return s3Error(err)
+	}
+	return s3Error(b.client.RemoveObject(ctx, b.bucket, fromKey, minio.RemoveObjectOptions{}))
+}
+
+func (b *S3Backend) Reconnect() error {
+	return b.connect()
+}
+
+// s3Error maps missing keys and buckets to fs.ErrNotExist.
+func s3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	resp := minio.ToErrorResponse(err)
+	switch resp.Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return &fs.PathError{Op: "s3", Path: resp.Key, Err: fs.ErrNotExist}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return &fs.PathError{Op: "s3", Path: resp.Key, Err: fs.ErrNotExist}
+	}
+	return err
+}
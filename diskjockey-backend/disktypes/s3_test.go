@@ -0,0 +1,154 @@
+package disktypes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+// fakeS3 is an in-process S3 server holding the bucket "bucket"
+type fakeS3 struct {
+	t       *testing.T
+	url     string
+	backend *s3mem.Backend
+}
+
+func newFakeS3(t *testing.T) *fakeS3 {
+	t.Helper()
+	backend := s3mem.New()
+	if err := backend.CreateBucket("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	// Over TLS, like S3 itself, as the fake doesn't decode the chunked
+	// uploads minio sends over plain HTTP
+	server := httptest.NewTLSServer(gofakes3.New(backend).Server())
+	t.Cleanup(server.Close)
+	return &fakeS3{t: t, url: server.URL, backend: backend}
+}
+
+// mount returns a backend mounting prefix of the bucket, "" for all of it
+func (f *fakeS3) mount(prefix string, options map[string]string) *S3Backend {
+	f.t.Helper()
+	all := map[string]string{"endpoint": f.url, "bucket": "bucket", "prefix": prefix, "path_style": "true", "insecure_skip_verify": "true", "access_key": "key", "secret_key": "secret"}
+	for k, v := range options {
+		all[k] = v
+	}
+	return mustNew(f.t, S3DiskType{}, &models.Mount{Options: all}).(*S3Backend)
+}
+
+func (f *fakeS3) put(key string, data []byte) {
+	f.t.Helper()
+	// The server only adds Last-Modified to objects uploaded through it
+	meta := map[string]string{"Last-Modified": time.Now().UTC().Format(http.TimeFormat)}
+	if _, err := f.backend.PutObject("bucket", key, meta, bytes.NewReader(data), int64(len(data)), nil); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *fakeS3) get(key string) ([]byte, error) {
+	obj, err := f.backend.GetObject("bucket", key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Contents.Close()
+	return io.ReadAll(obj.Contents)
+}
+
+// keys returns the keys of every object in the bucket
+func (f *fakeS3) keys() []string {
+	f.t.Helper()
+	list, err := f.backend.ListBucket("bucket", nil, gofakes3.ListBucketPage{})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	var keys []string
+	for _, content := range list.Contents {
+		keys = append(keys, content.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestS3FilesAndDirectories(t *testing.T) {
+	f := newFakeS3(t)
+	f.put("outside.txt", []byte("outside"))
+	f.put("mount/a.txt", []byte("a"))
+	f.put("mount/dir/b.txt", []byte("b"))
+	b := f.mount("mount", nil)
+
+	infos, err := b.List("/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, fmt.Sprintf("%s:%v", info.Name, info.IsDir))
+	}
+	sort.Strings(names)
+	if fmt.Sprint(names) != "[a.txt:false dir:true]" {
+		t.Errorf("List = %v", names)
+	}
+	if info, err := b.Stat("/dir"); err != nil || !info.IsDir {
+		t.Errorf("Stat of a directory = %+v, %v", info, err)
+	}
+	if info, err := b.Stat("/a.txt"); err != nil || info.IsDir || info.Size != 1 || info.ETag == "" {
+		t.Errorf("Stat of a file = %+v, %v", info, err)
+	}
+	if _, err := b.Stat("/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a missing path = %v, want ErrNotExist", err)
+	}
+
+	if err := b.Write("/dir/c.txt", []byte("c")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if data, err := b.Read("/dir/c.txt"); err != nil || string(data) != "c" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+	if err := b.Rename("/dir", "/moved"); err != nil {
+		t.Fatalf("Rename of a directory failed: %v", err)
+	}
+	if err := b.Delete("/a.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	want := []string{"mount/moved/b.txt", "mount/moved/c.txt", "outside.txt"}
+	if keys := f.keys(); fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Errorf("bucket holds %v, want %v", keys, want)
+	}
+	if err := b.Rename("/moved", "/moved/inner"); err == nil {
+		t.Errorf("moved a directory into itself")
+	}
+}
+
+func TestS3MultipartWrite(t *testing.T) {
+	f := newFakeS3(t)
+	b := f.mount("", map[string]string{"part_size": "5"})
+	data := make([]byte, 11<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	if err := b.Write("/large.bin", data); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if stored, err := f.get("large.bin"); err != nil || !bytes.Equal(stored, data) {
+		t.Errorf("stored %d bytes, %v, that don't match the %d written", len(stored), err, len(data))
+	}
+}
+
+func TestS3MissingBucket(t *testing.T) {
+	f := newFakeS3(t)
+	mount := &models.Mount{Options: map[string]string{"endpoint": f.url, "bucket": "missing", "path_style": "true", "insecure_skip_verify": "true"}}
+	if _, err := (S3DiskType{}).New(mount); err == nil {
+		t.Errorf("mounted a bucket that doesn't exist")
+	}
+}
@@ -1,6 +1,7 @@
 package disktypes
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -42,7 +43,7 @@ func (SFTPDiskType) Description() string {
 }
 
 func (SFTPDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
-	return types.DiskTypeConfigTemplate{
+	return withCacheFields(withPollingFields(types.DiskTypeConfigTemplate{
 		"host": types.DiskTypeConfigField{
 			Type:        "string",
 			Description: "Remote SFTP server hostname",
@@ -73,7 +74,7 @@ func (SFTPDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
 			Description: "Remote path prefix for all requests",
 			Required:    true,
 		},
-	}
+	}))
 }
 
 func (b *SFTPBackend) connect() error {
@@ -88,7 +89,7 @@ func (b *SFTPBackend) connect() error {
 		return fmt.Errorf("missing required sftp config fields")
 	}
 
-	addr := host + ":" + string(port)
+	addr := fmt.Sprintf("%s:%d", host, port)
 	auths := []ssh.AuthMethod{}
 	if password != "" {
 		auths = append(auths, ssh.Password(password))
@@ -131,52 +132,71 @@ func (b *SFTPBackend) connect() error {
 }
 
 func (b *SFTPBackend) List(path string) ([]types.FileInfo, error) {
-	absPath := b.path + path
-	fmt.Println("SFTP List absPath:", absPath) // <-- Add this line
+	absPath := joinRemote(b.path, path)
 
 	files, err := b.client.ReadDir(absPath)
 	if err != nil {
-		return nil, err
+		return nil, sftpError(err)
 	}
 
 	var out []types.FileInfo
 	for _, f := range files {
 		out = append(out, types.FileInfo{
-			Name:  f.Name(),
-			IsDir: f.IsDir(),
-			Size:  f.Size(),
+			Name:    f.Name(),
+			IsDir:   f.IsDir(),
+			Size:    f.Size(),
+			ModTime: f.ModTime(),
 		})
 	}
 
 	return out, nil
 }
 
+func (b *SFTPBackend) Stat(path string) (types.FileInfo, error) {
+	f, err := b.client.Stat(joinRemote(b.path, path))
+	if err != nil {
+		return types.FileInfo{}, sftpError(err)
+	}
+	return types.FileInfo{
+		Name:    f.Name(),
+		IsDir:   f.IsDir(),
+		Size:    f.Size(),
+		ModTime: f.ModTime(),
+	}, nil
+}
+
 func (b *SFTPBackend) Read(path string) ([]byte, error) {
-	absPath := b.path + path
+	absPath := joinRemote(b.path, path)
 	f, err := b.client.Open(absPath)
 	if err != nil {
-		return nil, err
+		return nil, sftpError(err)
 	}
 	defer f.Close()
-	return io.ReadAll(f)
+	data, err := io.ReadAll(f)
+	return data, sftpError(err)
 }
 
 func (b *SFTPBackend) Write(path string, data []byte) error {
-	absPath := b.path + path
+	absPath := joinRemote(b.path, path)
 
 	f, err := b.client.Create(absPath)
 	if err != nil {
-		return err
+		return sftpError(err)
 	}
 	defer f.Close()
 
 	_, err = f.Write(data)
-	return err
+	return sftpError(err)
 }
 
 func (b *SFTPBackend) Delete(path string) error {
-	absPath := b.path + path
-	return b.client.Remove(absPath)
+	absPath := joinRemote(b.path, path)
+	return sftpError(b.client.Remove(absPath))
+}
+
+func (b *SFTPBackend) Rename(from, to string) error {
+	// PosixRename replaces an existing target, like the other disk types do
+	return sftpError(b.client.PosixRename(joinRemote(b.path, from), joinRemote(b.path, to)))
 }
 
 func (b *SFTPBackend) Close() error {
@@ -187,31 +207,13 @@ func (b *SFTPBackend) Reconnect() error {
 	if b.client != nil {
 		b.client.Close()
 	}
+	return b.connect()
+}
 
-	auths := []ssh.AuthMethod{}
-	if len(auths) == 0 {
-		return fmt.Errorf("no authentication method provided (set password or use_ssh_agent)")
-	}
-
-	sshConfig := &ssh.ClientConfig{
-		User:            "", // USERNAME should be set from config in connect()
-		Auth:            auths,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // WARNING: for demo only
-		Timeout:         5 * time.Second,
-	}
-
-	addr := "" // ADDR should be set from config in connect()
-	sshConn, err := ssh.Dial("tcp", addr, sshConfig)
-	if err != nil {
-		return fmt.Errorf("ssh dial failed: %w", err)
+// sftpError marks errors caused by a lost connection with types.ErrOffline.
+func sftpError(err error) error {
+	if errors.Is(err, sftp.ErrSSHFxConnectionLost) || errors.Is(err, sftp.ErrSSHFxNoConnection) {
+		return fmt.Errorf("%w: %v", types.ErrOffline, err)
 	}
-
-	sftpClient, err := sftp.NewClient(sshConn)
-	if err != nil {
-		return fmt.Errorf("sftp client failed: %w", err)
-	}
-
-	b.client = sftpClient
-
-	return nil
+	return err
 }
@@ -41,7 +41,7 @@ func (SMBDiskType) Description() string {
 }
 
 func (SMBDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
-	return types.DiskTypeConfigTemplate{
+	return withCacheFields(withPollingFields(types.DiskTypeConfigTemplate{
 		"host": types.DiskTypeConfigField{
 			Type:        "string",
 			Description: "SMB server hostname or IP",
@@ -67,7 +67,7 @@ func (SMBDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
 			Description: "Remote root directory (optional)",
 			Required:    false,
 		},
-	}
+	}))
 }
 
 func (b *SMBBackend) connect() error {
@@ -121,13 +121,26 @@ func (b *SMBBackend) Reconnect() error {
 	return b.connect()
 }
 
-func (b *SMBBackend) List(path string) ([]types.FileInfo, error) {
-	cleanPath := path
-	if cleanPath == "" || cleanPath == "/" {
-		cleanPath = "."
-	} else if cleanPath[0] == '/' {
-		cleanPath = cleanPath[1:]
+func (b *SMBBackend) Rename(from, to string) error {
+	fromPath, toPath := smbPath(from), smbPath(to)
+	if fromPath == "." || toPath == "." {
+		return fmt.Errorf("cannot rename root directory")
+	}
+	return b.share.Rename(fromPath, toPath)
+}
+
+// smbPath converts a mount path to a path relative to the share root, which
+// is "." for the root itself.
+func smbPath(p string) string {
+	clean := joinRemote("", p)
+	if clean == "/" {
+		return "."
 	}
+	return clean[1:]
+}
+
+func (b *SMBBackend) List(path string) ([]types.FileInfo, error) {
+	cleanPath := smbPath(path)
 
 	files, err := b.share.ReadDir(cleanPath)
 	if err != nil {
@@ -137,23 +150,31 @@ func (b *SMBBackend) List(path string) ([]types.FileInfo, error) {
 	var out []types.FileInfo
 	for _, f := range files {
 		out = append(out, types.FileInfo{
-			Name:  f.Name(),
-			IsDir: f.IsDir(),
-			Size:  f.Size(),
+			Name:    f.Name(),
+			IsDir:   f.IsDir(),
+			Size:    f.Size(),
+			ModTime: f.ModTime(),
 		})
 	}
 
 	return out, nil
 }
 
-func (b *SMBBackend) Read(path string) ([]byte, error) {
-	cleanPath := path
+func (b *SMBBackend) Stat(path string) (types.FileInfo, error) {
+	f, err := b.share.Stat(smbPath(path))
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return types.FileInfo{
+		Name:    f.Name(),
+		IsDir:   f.IsDir(),
+		Size:    f.Size(),
+		ModTime: f.ModTime(),
+	}, nil
+}
 
-	if cleanPath == "" || cleanPath == "/" {
-		cleanPath = "."
-	} else if cleanPath[0] == '/' {
-		cleanPath = cleanPath[1:]
-	}
+func (b *SMBBackend) Read(path string) ([]byte, error) {
+	cleanPath := smbPath(path)
 
 	f, err := b.share.Open(cleanPath)
 	if err != nil {
@@ -166,12 +187,9 @@ func (b *SMBBackend) Read(path string) ([]byte, error) {
 
 // Write implements Backend interface
 func (b *SMBBackend) Write(path string, data []byte) error {
-	cleanPath := path
-
-	if cleanPath == "" || cleanPath == "/" {
+	cleanPath := smbPath(path)
+	if cleanPath == "." {
 		return fmt.Errorf("cannot write to root directory")
-	} else if cleanPath[0] == '/' {
-		cleanPath = cleanPath[1:]
 	}
 
 	f, err := b.share.Create(cleanPath)
@@ -187,12 +205,9 @@ func (b *SMBBackend) Write(path string, data []byte) error {
 
 // Delete implements Backend interface (stub)
 func (b *SMBBackend) Delete(path string) error {
-	cleanPath := path
-
-	if cleanPath == "" || cleanPath == "/" {
+	cleanPath := smbPath(path)
+	if cleanPath == "." {
 		return fmt.Errorf("cannot delete root directory")
-	} else if cleanPath[0] == '/' {
-		cleanPath = cleanPath[1:]
 	}
 
 	return b.share.Remove(cleanPath)
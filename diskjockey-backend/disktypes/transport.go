@@ -0,0 +1,40 @@
+package disktypes
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+)
+
+// tlsTransport returns an HTTP transport for a disk type talking to its
+// server over HTTP, trusting the CA certificate in the mount's ca_cert
+// option or skipping verification entirely when insecure_skip_verify is
+// set. Errors are prefixed with the name of the disk type.
+func tlsTransport(name string, mount *models.Mount) (*http.Transport, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: mount.BoolOption("insecure_skip_verify"),
+	}
+
+	if caPath := mount.Option("ca_cert"); caPath != "" {
+		pem, err := os.ReadFile(caPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to read ca_cert: %w", name, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no certificates found in ca_cert %s", name, caPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
@@ -0,0 +1,58 @@
+package disktypes
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+)
+
+func TestTLSTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	caCert := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caCert, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	notPEM := filepath.Join(t.TempDir(), "not.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		options   map[string]string
+		connects  bool
+		configErr bool
+	}{
+		{name: "untrusted", options: nil},
+		{name: "ca_cert", options: map[string]string{"ca_cert": caCert}, connects: true},
+		{name: "insecure_skip_verify", options: map[string]string{"insecure_skip_verify": "true"}, connects: true},
+		{name: "missing ca_cert", options: map[string]string{"ca_cert": filepath.Join(t.TempDir(), "missing.pem")}, configErr: true},
+		{name: "ca_cert without certificates", options: map[string]string{"ca_cert": notPEM}, configErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := tlsTransport("test", &models.Mount{Options: tt.options})
+			if (err != nil) != tt.configErr {
+				t.Fatalf("tlsTransport error = %v, want error %v", err, tt.configErr)
+			}
+			if err != nil {
+				return
+			}
+			defer transport.CloseIdleConnections()
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err == nil) != tt.connects {
+				t.Errorf("request error = %v, want it to connect %v", err, tt.connects)
+			}
+		})
+	}
+}
@@ -2,8 +2,13 @@ package disktypes
 
 import (
 	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"runtime/debug"
+	"strconv"
 	"strings"
 
 	"github.com/christhomas/diskjockey/diskjockey-backend/models"
@@ -41,12 +46,17 @@ func (w WebDAVDiskType) Description() string {
 }
 
 func (w WebDAVDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
-	return types.DiskTypeConfigTemplate{
+	return withCacheFields(withPollingFields(types.DiskTypeConfigTemplate{
 		"url": types.DiskTypeConfigField{
 			Type:        "string",
 			Description: "WebDAV server URL (e.g. https://webdav.example.com). If omitted, specify host and port instead.",
 			Required:    false,
 		},
+		"scheme": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Scheme used with host and port: http or https (default https)",
+			Required:    false,
+		},
 		"host": types.DiskTypeConfigField{
 			Type:        "string",
 			Description: "WebDAV server host (e.g. webdav.example.com)",
@@ -62,75 +72,193 @@ func (w WebDAVDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
 			Description: "Path prefix to prepend to all requests (e.g. /username)",
 			Required:    false,
 		},
+		"auth": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Authentication mode: auto, basic, digest, bearer or none (default auto)",
+			Required:    false,
+		},
 		"username": types.DiskTypeConfigField{
 			Type:        "string",
-			Description: "WebDAV username",
-			Required:    true,
+			Description: "WebDAV username (auto, basic and digest auth)",
+			Required:    false,
 		},
 		"password": types.DiskTypeConfigField{
 			Type:        "string",
-			Description: "WebDAV password",
-			Required:    true,
+			Description: "WebDAV password (auto, basic and digest auth)",
+			Required:    false,
 		},
-	}
+		"access_token": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Token sent as 'Authorization: Bearer <token>' (bearer auth)",
+			Required:    false,
+		},
+		"ca_cert": types.DiskTypeConfigField{
+			Type:        "string",
+			Description: "Path to a PEM encoded CA certificate to trust, for self-signed servers",
+			Required:    false,
+		},
+		"insecure_skip_verify": types.DiskTypeConfigField{
+			Type:        "bool",
+			Description: "Skip TLS certificate verification (not secure, testing only)",
+			Required:    false,
+		},
+	}))
 }
 
-func (b *WebDAVBackend) connect() error {
+func (b *WebDAVBackend) connect() (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Fprintf(os.Stderr, "[WebDAV][PANIC] %v\n%s\n", r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
 		}
 	}()
-	fmt.Printf("[WebDAV][DEBUG] Mount config: %+v\n", b.mount)
 
-	host := b.mount.Host
-	port := b.mount.Port
-	username := b.mount.Username
-	password := b.mount.Password
-	path := b.mount.Path
+	baseURL, err := b.serverURL()
+	if err != nil {
+		return err
+	}
 
-	scheme := "https"
-	if host == "" {
-		return fmt.Errorf("webdav: missing required config 'host'")
+	authorizer, err := b.authorizer()
+	if err != nil {
+		return err
 	}
-	portStr := ""
-	if port != 0 {
-		portStr = fmt.Sprintf(":%d", port)
+
+	transport, err := tlsTransport("webdav", b.mount)
+	if err != nil {
+		return err
 	}
-	url := fmt.Sprintf("%s://%s%s", scheme, host, portStr)
-	b.pathPrefix = path
 
-	fmt.Printf("[WebDAV][DEBUG] Connecting to URL: %s\n", url)
-	fmt.Printf("[WebDAV][DEBUG] Username: %s\n", username)
+	client := gowebdav.NewAuthClient(baseURL, authorizer)
+	client.SetTransport(transport)
 
-	b.client = gowebdav.NewClient(url, username, password)
-	b.BaseURL = url
+	b.client = client
+	b.BaseURL = baseURL
+	b.pathPrefix = b.mount.Path
 
 	return nil
 }
 
-func (b *WebDAVBackend) fullPath(requested string) string {
-	// Always prepend b.Path (if set) to the requested path
-	if b.pathPrefix != "" {
-		// Ensure exactly one slash between b.Path and requested
-		cleanPath := b.pathPrefix
-		if !strings.HasPrefix(cleanPath, "/") {
-			cleanPath = "/" + cleanPath
+// serverURL builds the server URL from the 'url' option, or from scheme, host
+// and port when no url is configured.
+func (b *WebDAVBackend) serverURL() (string, error) {
+	if raw := b.mount.Option("url"); raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "", fmt.Errorf("webdav: invalid url %q: %w", raw, err)
 		}
-
-		if strings.HasSuffix(cleanPath, "/") {
-			cleanPath = strings.TrimRight(cleanPath, "/")
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return "", fmt.Errorf("webdav: url scheme must be http or https, got %q", u.Scheme)
+		}
+		if u.Host == "" {
+			return "", fmt.Errorf("webdav: url %q has no host", raw)
 		}
+		return u.String(), nil
+	}
+
+	host := b.mount.Host
+	if host == "" {
+		return "", fmt.Errorf("webdav: missing required config 'url' or 'host'")
+	}
 
-		req := requested
-		if !strings.HasPrefix(req, "/") {
-			req = "/" + req
+	scheme := strings.ToLower(b.mount.Option("scheme"))
+	switch scheme {
+	case "":
+		scheme = "https"
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("webdav: scheme must be http or https, got %q", scheme)
+	}
+
+	if b.mount.Port != 0 {
+		host = net.JoinHostPort(host, strconv.Itoa(b.mount.Port))
+	}
+
+	return (&url.URL{Scheme: scheme, Host: host}).String(), nil
+}
+
+// authorizer returns the gowebdav Authorizer for the configured auth mode.
+func (b *WebDAVBackend) authorizer() (gowebdav.Authorizer, error) {
+	username := b.mount.Username
+	password := b.mount.Password
+
+	switch mode := strings.ToLower(b.mount.Option("auth")); mode {
+	case "", "auto":
+		return gowebdav.NewAutoAuth(username, password), nil
+	case "basic":
+		return gowebdav.NewPreemptiveAuth(&webdavBasicAuth{username: username, password: password}), nil
+	case "digest":
+		auth := gowebdav.NewEmptyAuth()
+		auth.AddAuthenticator("digest", func(c *http.Client, rs *http.Response, path string) (gowebdav.Authenticator, error) {
+			return gowebdav.NewDigestAuth(username, password, rs)
+		})
+		return auth, nil
+	case "bearer":
+		if b.mount.AccessToken == "" {
+			return nil, fmt.Errorf("webdav: bearer auth requires config 'access_token'")
 		}
+		return gowebdav.NewPreemptiveAuth(&webdavBearerAuth{token: b.mount.AccessToken}), nil
+	case "none":
+		return gowebdav.NewEmptyAuth(), nil
+	default:
+		return nil, fmt.Errorf("webdav: unsupported auth mode %q", mode)
+	}
+}
+
+// webdavBasicAuth sends basic credentials with every request instead of
+// waiting for a challenge.
+type webdavBasicAuth struct {
+	username string
+	password string
+}
+
+func (a *webdavBasicAuth) Authorize(c *http.Client, rq *http.Request, path string) error {
+	rq.SetBasicAuth(a.username, a.password)
+	return nil
+}
+
+func (a *webdavBasicAuth) Verify(c *http.Client, rs *http.Response, path string) (bool, error) {
+	if rs.StatusCode == http.StatusUnauthorized {
+		return false, gowebdav.NewPathError("Authorize", path, rs.StatusCode)
+	}
+	return false, nil
+}
+
+func (a *webdavBasicAuth) Clone() gowebdav.Authenticator {
+	return a
+}
 
-		return cleanPath + req
+func (a *webdavBasicAuth) Close() error {
+	return nil
+}
+
+// webdavBearerAuth sends an OAuth style bearer token with every request.
+type webdavBearerAuth struct {
+	token string
+}
+
+func (a *webdavBearerAuth) Authorize(c *http.Client, rq *http.Request, path string) error {
+	rq.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *webdavBearerAuth) Verify(c *http.Client, rs *http.Response, path string) (bool, error) {
+	if rs.StatusCode == http.StatusUnauthorized {
+		return false, gowebdav.NewPathError("Authorize", path, rs.StatusCode)
 	}
+	return false, nil
+}
+
+func (a *webdavBearerAuth) Clone() gowebdav.Authenticator {
+	return a
+}
 
-	return requested
+func (a *webdavBearerAuth) Close() error {
+	return nil
+}
+
+func (b *WebDAVBackend) fullPath(requested string) string {
+	// Always prepend b.Path (if set) to the requested path
+	return joinRemote("/"+b.pathPrefix, requested)
 }
 
 func (b *WebDAVBackend) List(path string) (infos []types.FileInfo, err error) {
@@ -148,12 +276,7 @@ func (b *WebDAVBackend) List(path string) (infos []types.FileInfo, err error) {
 		}
 	}()
 
-	fmt.Printf("[WebDAV][DEBUG][List] Requested path: %s\n", path)
 	fullPath := b.fullPath(path)
-	fmt.Printf("[WebDAV][DEBUG][List] fullPath: %s\n", fullPath)
-	baseURL := b.BaseURL
-	fmt.Printf("[WebDAV][DEBUG][List] baseURL: %s\n", baseURL)
-	fmt.Printf("[WebDAV][DEBUG][List] Final URL: %s%s\n", baseURL, fullPath)
 
 	type readDirResult struct {
 		files []os.FileInfo
@@ -179,16 +302,41 @@ func (b *WebDAVBackend) List(path string) (infos []types.FileInfo, err error) {
 	}
 
 	for _, f := range res.files {
-		infos = append(infos, types.FileInfo{
-			Name:  f.Name(),
-			IsDir: f.IsDir(),
-			Size:  f.Size(),
-		})
+		info := types.FileInfo{
+			Name:    f.Name(),
+			IsDir:   f.IsDir(),
+			Size:    f.Size(),
+			ModTime: f.ModTime(),
+		}
+		if tagged, ok := f.(interface{ ETag() string }); ok {
+			info.ETag = tagged.ETag()
+		}
+		infos = append(infos, info)
 	}
 
 	return infos, nil
 }
 
+func (b *WebDAVBackend) Stat(path string) (types.FileInfo, error) {
+	f, err := b.client.Stat(b.fullPath(path))
+	if err != nil {
+		if gowebdav.IsErrNotFound(err) {
+			return types.FileInfo{}, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+		}
+		return types.FileInfo{}, err
+	}
+	info := types.FileInfo{
+		Name:    f.Name(),
+		IsDir:   f.IsDir(),
+		Size:    f.Size(),
+		ModTime: f.ModTime(),
+	}
+	if tagged, ok := f.(interface{ ETag() string }); ok {
+		info.ETag = tagged.ETag()
+	}
+	return info, nil
+}
+
 func (b *WebDAVBackend) Read(path string) (data []byte, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -223,6 +371,17 @@ func (b *WebDAVBackend) Delete(path string) (err error) {
 	return b.client.Remove(b.fullPath(path))
 }
 
+func (b *WebDAVBackend) Rename(from, to string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "[WebDAV][PANIC][Rename] %v\n%s\n", r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return b.client.Rename(b.fullPath(from), b.fullPath(to), true)
+}
+
 func (b *WebDAVBackend) Reconnect() error {
 	return b.connect()
 }
@@ -0,0 +1,277 @@
+package disktypes
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"golang.org/x/net/webdav"
+)
+
+// fakeWebDAV serves a temp dir over WebDAV, accepting requests only when they
+// carry the credentials for its auth mode. The challenge for a request
+// without credentials names the mode, so auto auth can pick it up.
+type fakeWebDAV struct {
+	mu      sync.Mutex
+	auth    string // basic, digest, bearer or none
+	handler http.Handler
+	// schemes records the Authorization scheme of every request
+	schemes []string
+}
+
+const (
+	fakeWebDAVUser   = "user"
+	fakeWebDAVPass   = "secret"
+	fakeWebDAVToken  = "test-token"
+	fakeWebDAVRealm  = "test"
+	fakeWebDAVNonce  = "dcd98b7102dd2f0e8b11d0f600bfb0c0"
+	fakeWebDAVDigest = `Digest realm="` + fakeWebDAVRealm + `", nonce="` + fakeWebDAVNonce + `"`
+)
+
+func newFakeWebDAV(t *testing.T, auth string) (*fakeWebDAV, string) {
+	t.Helper()
+	dir := t.TempDir()
+	f := &fakeWebDAV{
+		auth:    auth,
+		handler: &webdav.Handler{FileSystem: webdav.Dir(dir), LockSystem: webdav.NewMemLS()},
+	}
+	return f, dir
+}
+
+func (f *fakeWebDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	header := r.Header.Get("Authorization")
+	scheme, _, _ := strings.Cut(header, " ")
+	f.mu.Lock()
+	f.schemes = append(f.schemes, scheme)
+	f.mu.Unlock()
+
+	if !f.authorized(r, header) {
+		switch f.auth {
+		case "basic":
+			w.Header().Set("WWW-Authenticate", `Basic realm="`+fakeWebDAVRealm+`"`)
+		case "digest":
+			w.Header().Set("WWW-Authenticate", fakeWebDAVDigest)
+		case "bearer":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+fakeWebDAVRealm+`"`)
+		}
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	f.handler.ServeHTTP(w, r)
+}
+
+func (f *fakeWebDAV) authorized(r *http.Request, header string) bool {
+	switch f.auth {
+	case "basic":
+		user, pass, ok := r.BasicAuth()
+		return ok && user == fakeWebDAVUser && pass == fakeWebDAVPass
+	case "digest":
+		params, ok := strings.CutPrefix(header, "Digest ")
+		if !ok {
+			return false
+		}
+		fields := map[string]string{}
+		for _, part := range strings.Split(params, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			fields[key] = strings.Trim(value, `"`)
+		}
+		ha1 := md5Hex(fakeWebDAVUser + ":" + fakeWebDAVRealm + ":" + fakeWebDAVPass)
+		ha2 := md5Hex(r.Method + ":" + fields["uri"])
+		return fields["username"] == fakeWebDAVUser && fields["nonce"] == fakeWebDAVNonce &&
+			fields["response"] == md5Hex(ha1+":"+fakeWebDAVNonce+":"+ha2)
+	case "bearer":
+		return header == "Bearer "+fakeWebDAVToken
+	default:
+		return header == ""
+	}
+}
+
+func (f *fakeWebDAV) schemesLocked() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.schemes...)
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestWebDAVAuthModes(t *testing.T) {
+	tests := []struct {
+		name string
+		// server is the auth mode the server requires
+		server string
+		mount  models.Mount
+		// scheme is the Authorization scheme every request should carry
+		// once the client knows the server's mode
+		scheme string
+		// preemptive is whether the first request already carries it
+		preemptive bool
+	}{
+		{name: "auto basic", server: "basic", mount: models.Mount{Username: fakeWebDAVUser, Password: fakeWebDAVPass}, scheme: "Basic"},
+		{name: "auto digest", server: "digest", mount: models.Mount{Username: fakeWebDAVUser, Password: fakeWebDAVPass}, scheme: "Digest"},
+		{name: "basic", server: "basic", mount: models.Mount{Username: fakeWebDAVUser, Password: fakeWebDAVPass, Options: map[string]string{"auth": "basic"}}, scheme: "Basic", preemptive: true},
+		{name: "digest", server: "digest", mount: models.Mount{Username: fakeWebDAVUser, Password: fakeWebDAVPass, Options: map[string]string{"auth": "digest"}}, scheme: "Digest"},
+		{name: "bearer", server: "bearer", mount: models.Mount{AccessToken: fakeWebDAVToken, Options: map[string]string{"auth": "bearer"}}, scheme: "Bearer", preemptive: true},
+		{name: "none", server: "none", mount: models.Mount{Username: fakeWebDAVUser, Password: fakeWebDAVPass, Options: map[string]string{"auth": "none"}}, scheme: "", preemptive: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, dir := newFakeWebDAV(t, tt.server)
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			mount := tt.mount
+			mount.Options = map[string]string{"url": server.URL}
+			for k, v := range tt.mount.Options {
+				mount.Options[k] = v
+			}
+			b := mustNew(t, WebDAVDiskType{}, &mount).(*WebDAVBackend)
+
+			if err := b.Write("/a.txt", []byte("hello")); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if data, err := os.ReadFile(filepath.Join(dir, "a.txt")); err != nil || string(data) != "hello" {
+				t.Errorf("served file holds %q, %v", data, err)
+			}
+			if data, err := b.Read("/a.txt"); err != nil || string(data) != "hello" {
+				t.Errorf("Read = %q, %v", data, err)
+			}
+			if infos, err := b.List("/"); err != nil || len(infos) != 1 || infos[0].Name != "a.txt" {
+				t.Errorf("List = %+v, %v", infos, err)
+			}
+
+			schemes := fake.schemesLocked()
+			if tt.preemptive && schemes[0] != tt.scheme {
+				t.Errorf("first request sent %q credentials, want %q", schemes[0], tt.scheme)
+			}
+			if last := schemes[len(schemes)-1]; last != tt.scheme {
+				t.Errorf("last request sent %q credentials, want %q", last, tt.scheme)
+			}
+		})
+	}
+}
+
+func TestWebDAVAuthErrors(t *testing.T) {
+	fake, _ := newFakeWebDAV(t, "basic")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	wrong := mustNew(t, WebDAVDiskType{}, &models.Mount{Username: fakeWebDAVUser, Password: "wrong", Options: map[string]string{"url": server.URL, "auth": "basic"}})
+	if _, err := wrong.List("/"); err == nil {
+		t.Error("listed with the wrong password")
+	}
+	none := mustNew(t, WebDAVDiskType{}, &models.Mount{Username: fakeWebDAVUser, Password: fakeWebDAVPass, Options: map[string]string{"url": server.URL, "auth": "none"}})
+	if _, err := none.List("/"); err == nil {
+		t.Error("listed without sending credentials")
+	}
+
+	if _, err := (WebDAVDiskType{}).New(&models.Mount{Options: map[string]string{"url": server.URL, "auth": "bearer"}}); err == nil {
+		t.Error("bearer auth accepted without an access_token")
+	}
+	if _, err := (WebDAVDiskType{}).New(&models.Mount{Options: map[string]string{"url": server.URL, "auth": "ntlm"}}); err == nil {
+		t.Error("accepted an unsupported auth mode")
+	}
+}
+
+func TestWebDAVServerURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		mount models.Mount
+		want  string
+	}{
+		{name: "url", mount: models.Mount{Host: "ignored", Options: map[string]string{"url": "http://dav.example.com:8080/remote.php/dav"}}, want: "http://dav.example.com:8080/remote.php/dav"},
+		{name: "host defaults to https", mount: models.Mount{Host: "dav.example.com"}, want: "https://dav.example.com"},
+		{name: "host and port", mount: models.Mount{Host: "dav.example.com", Port: 5001}, want: "https://dav.example.com:5001"},
+		{name: "scheme", mount: models.Mount{Host: "dav.example.com", Port: 80, Options: map[string]string{"scheme": "HTTP"}}, want: "http://dav.example.com:80"},
+		{name: "ipv6 host", mount: models.Mount{Host: "::1", Port: 8080}, want: "https://[::1]:8080"},
+		{name: "no url or host", mount: models.Mount{}},
+		{name: "url scheme", mount: models.Mount{Options: map[string]string{"url": "ftp://dav.example.com"}}},
+		{name: "url without host", mount: models.Mount{Options: map[string]string{"url": "https:///dav"}}},
+		{name: "invalid url", mount: models.Mount{Options: map[string]string{"url": "http://[::1"}}},
+		{name: "invalid scheme", mount: models.Mount{Host: "dav.example.com", Options: map[string]string{"scheme": "ftp"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := (&WebDAVBackend{mount: &tt.mount}).serverURL()
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("serverURL = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("serverURL = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebDAVHostAndPath(t *testing.T) {
+	fake, dir := newFakeWebDAV(t, "none")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "user"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var port int
+	fmt.Sscan(u.Port(), &port)
+	b := mustNew(t, WebDAVDiskType{}, &models.Mount{Host: u.Hostname(), Port: port, Path: "/user", Options: map[string]string{"scheme": "http", "auth": "none"}})
+	if err := b.Write("/a.txt", []byte("a")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "user", "a.txt")); err != nil {
+		t.Errorf("Write didn't go below the path prefix: %v", err)
+	}
+	if _, err := b.(*WebDAVBackend).Stat("/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of a missing file = %v, want ErrNotExist", err)
+	}
+}
+
+func TestWebDAVCACert(t *testing.T) {
+	fake, _ := newFakeWebDAV(t, "none")
+	server := httptest.NewTLSServer(fake)
+	defer server.Close()
+	caCert := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caCert, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	untrusted := mustNew(t, WebDAVDiskType{}, &models.Mount{Options: map[string]string{"url": server.URL, "auth": "none"}})
+	if _, err := untrusted.List("/"); err == nil {
+		t.Error("listed a server with an untrusted certificate")
+	}
+	trusted := mustNew(t, WebDAVDiskType{}, &models.Mount{Options: map[string]string{"url": server.URL, "auth": "none", "ca_cert": caCert}})
+	if _, err := trusted.List("/"); err != nil {
+		t.Errorf("List with ca_cert failed: %v", err)
+	}
+	if _, err := (WebDAVDiskType{}).New(&models.Mount{Options: map[string]string{"url": server.URL, "ca_cert": filepath.Join(t.TempDir(), "missing.pem")}}); err == nil {
+		t.Error("mounted with a missing ca_cert")
+	}
+}
+
+func TestWebDAVConnectRecoversPanic(t *testing.T) {
+	// A backend without a mount panics on the first option it reads
+	if err := (&WebDAVBackend{}).connect(); err == nil {
+		t.Error("connect returned no error after a panic")
+	}
+}
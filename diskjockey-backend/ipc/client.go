@@ -6,10 +6,14 @@ import (
 	"io"
 	"net"
 	"os"
+	"sync"
 	"time"
 
-	"github.com/christhomas/diskjockey/diskjockey-backend/proto/api"
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
 	"github.com/christhomas/diskjockey/diskjockey-backend/services"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -17,14 +21,30 @@ type BackendClient struct {
 	conn            net.Conn
 	configService   *services.ConfigService
 	disktypeService *services.DiskTypeService
+	mountService    *services.MountService
+	oauthService    *services.OAuthService
+	changeService   *services.ChangeService
+	uploadService   *services.UploadService
+	pinService      *services.PinService
+	conflictService *services.ConflictService
+	cacheService    *services.CacheService
 	handshakeDone   bool
+	writeMu         sync.Mutex // Serialises responses and pushed events
+	unsubscribe     func()     // Cancels the change subscription, if any
 }
 
-func NewBackendClient(conn net.Conn, config *services.ConfigService, disktypes *services.DiskTypeService) *BackendClient {
+func NewBackendClient(conn net.Conn, config *services.ConfigService, disktypes *services.DiskTypeService, mounts *services.MountService, oauth *services.OAuthService, changes *services.ChangeService, uploads *services.UploadService, pins *services.PinService, conflicts *services.ConflictService, caches *services.CacheService) *BackendClient {
 	return &BackendClient{
 		conn:            conn,
 		configService:   config,
 		disktypeService: disktypes,
+		mountService:    mounts,
+		oauthService:    oauth,
+		changeService:   changes,
+		uploadService:   uploads,
+		pinService:      pins,
+		conflictService: conflicts,
+		cacheService:    caches,
 	}
 }
 
@@ -42,6 +62,8 @@ func (c *BackendClient) SendMessage(conn net.Conn, msgType api.MessageType, pb p
 	if err != nil {
 		return fmt.Errorf("failed to marshal Api_Message: %w", err)
 	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
 	var lenBuf [4]byte
 	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(msgBytes)))
 	fmt.Printf("[DEBUG] Sending Api_Message of type %s of %d bytes (not including 4-byte length prefix)\n", msgType.String(), len(msgBytes))
@@ -75,6 +97,11 @@ func (c *BackendClient) ReceiveMessage(conn net.Conn) (api.MessageType, []byte,
 // Start runs the main loop for the BackendClient, reading and handling messages until the connection closes.
 func (c *BackendClient) Start() {
 	defer c.conn.Close()
+	defer func() {
+		if c.unsubscribe != nil {
+			c.unsubscribe()
+		}
+	}()
 	fmt.Println("[BackendClient] Starting message loop...")
 	for {
 		msgType, msg, err := c.ReceiveMessage(c.conn)
@@ -158,11 +185,17 @@ func (c *BackendClient) handleMessage(msgType api.MessageType, msg []byte) error
 				if m.AccessToken != "" {
 					config["access_token"] = m.AccessToken
 				}
+				for k, v := range m.Options {
+					config[k] = v
+				}
+				status, statusErr := c.mountService.Status(uint32(m.ID))
 				resp.Mounts = append(resp.Mounts, &api.MountInfo{
-					Name:     m.Name,
-					DiskType: diskType,
-					Config:   config,
-					MountId:  uint32(m.ID),
+					Name:        m.Name,
+					DiskType:    diskType,
+					Config:      config,
+					MountId:     uint32(m.ID),
+					Status:      mountStatusToProto(status),
+					StatusError: statusErr,
 				})
 			}
 		}
@@ -183,7 +216,8 @@ func (c *BackendClient) handleMessage(msgType api.MessageType, msg []byte) error
 			_ = c.SendMessage(c.conn, api.MessageType_CREATE_MOUNT_RESPONSE, resp)
 			return nil
 		}
-		fmt.Printf("[BackendClient] Received CreateMountRequest: %+v\n", req)
+		// The config holds credentials, so only name and type are logged
+		fmt.Printf("[BackendClient] Received CreateMountRequest: name=%s type=%s\n", req.Name, req.DiskType)
 		mountID, err := c.configService.CreateMount(req.Name, req.DiskType, req.Config, c.disktypeService)
 		fmt.Printf("[BackendClient] Created mount with ID %d, error: %v\n", mountID, err)
 		resp := &api.CreateMountResponse{}
@@ -200,6 +234,94 @@ func (c *BackendClient) handleMessage(msgType api.MessageType, msg []byte) error
 		fmt.Println("[BackendClient] CreateMountResponse sent to application")
 		return nil
 
+	case api.MessageType_MOUNT_REQUEST:
+		var req api.MountRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal MountRequest: %w", err)
+		}
+		resp := &api.MountResponse{}
+		if err := c.mountService.Mount(req.MountId); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_MOUNT_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send MountResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] MountResponse sent to application")
+		return nil
+
+	case api.MessageType_UNMOUNT_REQUEST:
+		var req api.UnmountRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal UnmountRequest: %w", err)
+		}
+		resp := &api.UnmountResponse{}
+		if err := c.mountService.Unmount(req.MountId); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_UNMOUNT_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send UnmountResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] UnmountResponse sent to application")
+		return nil
+
+	case api.MessageType_OAUTH_START_REQUEST:
+		var req api.OAuthStartRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal OAuthStartRequest: %w", err)
+		}
+		resp := &api.OAuthStartResponse{}
+		authURL, redirectURL, err := c.oauthService.Start(req.MountId)
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.AuthUrl = authURL
+			resp.RedirectUrl = redirectURL
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_OAUTH_START_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send OAuthStartResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] OAuthStartResponse sent to application")
+		return nil
+
+	case api.MessageType_OAUTH_FINISH_REQUEST:
+		var req api.OAuthFinishRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal OAuthFinishRequest: %w", err)
+		}
+		// Finish waits for the user to authorize in the browser, so the
+		// response is sent once it returns, without blocking other requests
+		go func() {
+			resp := &api.OAuthFinishResponse{}
+			timeout := time.Duration(req.TimeoutSeconds) * time.Second
+			if err := c.oauthService.Finish(req.MountId, req.Code, timeout); err != nil {
+				resp.Error = err.Error()
+			}
+			if err := c.SendMessage(c.conn, api.MessageType_OAUTH_FINISH_RESPONSE, resp); err != nil {
+				fmt.Fprintf(os.Stderr, "[BackendClient] Failed to send OAuthFinishResponse: %v\n", err)
+				return
+			}
+			fmt.Println("[BackendClient] OAuthFinishResponse sent to application")
+		}()
+		return nil
+
+	case api.MessageType_SUBSCRIBE_CHANGES_REQUEST:
+		var req api.SubscribeChangesRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal SubscribeChangesRequest: %w", err)
+		}
+		if c.unsubscribe != nil {
+			c.unsubscribe()
+		}
+		// Send the response before any events can be pushed
+		if err := c.SendMessage(c.conn, api.MessageType_SUBSCRIBE_CHANGES_RESPONSE, &api.SubscribeChangesResponse{}); err != nil {
+			return fmt.Errorf("failed to send SubscribeChangesResponse: %w", err)
+		}
+		events, unsubscribe := c.changeService.Subscribe()
+		c.unsubscribe = unsubscribe
+		go c.forwardChanges(events, req.MountIds, unsubscribe)
+		fmt.Println("[BackendClient] Subscribed application to change events")
+		return nil
+
 	case api.MessageType_SHUTDOWN_REQUEST:
 		// Handle graceful shutdown
 		fmt.Println("[BackendClient] Received SHUTDOWN_REQUEST, initiating graceful shutdown...")
@@ -227,9 +349,490 @@ func (c *BackendClient) handleMessage(msgType api.MessageType, msg []byte) error
 
 		return nil
 
+	case api.MessageType_LIST_DIR_REQUEST:
+		var req api.ListDirRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal ListDirRequest: %w", err)
+		}
+		resp := &api.ListDirResponse{}
+		if mount, err := c.mountService.GetMount(req.MountId); err != nil {
+			resp.Error = err.Error()
+		} else if infos, err := mount.Backend.List(req.Path); err != nil {
+			resp.Error = err.Error()
+		} else {
+			for _, info := range infos {
+				resp.Files = append(resp.Files, fileInfoToProto(info))
+			}
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_LIST_DIR_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send ListDirResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] ListDirResponse sent to application")
+		return nil
+
+	case api.MessageType_STAT_REQUEST:
+		var req api.StatRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal StatRequest: %w", err)
+		}
+		resp := &api.StatResponse{}
+		if mount, err := c.mountService.GetMount(req.MountId); err != nil {
+			resp.Error = err.Error()
+		} else if info, err := types.Stat(mount.Backend, req.Path); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Info = fileInfoToProto(info)
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_STAT_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send StatResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] StatResponse sent to application")
+		return nil
+
+	case api.MessageType_READ_FILE_REQUEST:
+		var req api.ReadFileRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal ReadFileRequest: %w", err)
+		}
+		resp := &api.ReadFileResponse{}
+		if mount, err := c.mountService.GetMount(req.MountId); err != nil {
+			resp.Error = err.Error()
+		} else if data, err := readFile(mount.Backend, req.Path, req.Offset, req.Length); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Data = data
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_READ_FILE_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send ReadFileResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] ReadFileResponse sent to application")
+		return nil
+
+	case api.MessageType_WRITE_FILE_REQUEST:
+		var req api.WriteFileRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal WriteFileRequest: %w", err)
+		}
+		resp := &api.WriteFileResponse{}
+		if mount, err := c.mountService.GetMount(req.MountId); err != nil {
+			resp.Error = err.Error()
+		} else if err := mount.Backend.Write(req.Path, req.Data); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_WRITE_FILE_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send WriteFileResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] WriteFileResponse sent to application")
+		return nil
+
+	case api.MessageType_DELETE_FILE_REQUEST:
+		var req api.DeleteFileRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal DeleteFileRequest: %w", err)
+		}
+		resp := &api.DeleteFileResponse{}
+		if mount, err := c.mountService.GetMount(req.MountId); err != nil {
+			resp.Error = err.Error()
+		} else if err := mount.Backend.Delete(req.Path); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_DELETE_FILE_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send DeleteFileResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] DeleteFileResponse sent to application")
+		return nil
+
+	case api.MessageType_RENAME_REQUEST:
+		var req api.RenameRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal RenameRequest: %w", err)
+		}
+		resp := &api.RenameResponse{}
+		if mount, err := c.mountService.GetMount(req.MountId); err != nil {
+			resp.Error = err.Error()
+		} else if err := types.Rename(mount.Backend, req.From, req.To); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_RENAME_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send RenameResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] RenameResponse sent to application")
+		return nil
+
+	case api.MessageType_UPLOAD_QUEUE_REQUEST:
+		var req api.UploadQueueRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal UploadQueueRequest: %w", err)
+		}
+		resp := &api.UploadQueueResponse{}
+		if queue, err := c.uploadService.Queue(req.MountId); err != nil {
+			resp.Error = err.Error()
+		} else {
+			for _, record := range queue {
+				resp.Items = append(resp.Items, uploadRecordToProto(record))
+			}
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_UPLOAD_QUEUE_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send UploadQueueResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] UploadQueueResponse sent to application")
+		return nil
+
+	case api.MessageType_RETRY_UPLOADS_REQUEST:
+		var req api.RetryUploadsRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal RetryUploadsRequest: %w", err)
+		}
+		resp := &api.RetryUploadsResponse{}
+		if retried, err := c.uploadService.Retry(req.MountId, req.Path); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Retried = uint32(retried)
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_RETRY_UPLOADS_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send RetryUploadsResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] RetryUploadsResponse sent to application")
+		return nil
+
+	case api.MessageType_PIN_REQUEST:
+		var req api.PinRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal PinRequest: %w", err)
+		}
+		resp := &api.PinResponse{}
+		if err := c.pinService.Pin(req.MountId, req.Path); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_PIN_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send PinResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] PinResponse sent to application")
+		return nil
+
+	case api.MessageType_UNPIN_REQUEST:
+		var req api.UnpinRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal UnpinRequest: %w", err)
+		}
+		resp := &api.UnpinResponse{}
+		if err := c.pinService.Unpin(req.MountId, req.Path); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_UNPIN_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send UnpinResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] UnpinResponse sent to application")
+		return nil
+
+	case api.MessageType_LIST_PINS_REQUEST:
+		var req api.ListPinsRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal ListPinsRequest: %w", err)
+		}
+		resp := &api.ListPinsResponse{}
+		for _, pin := range c.pinService.Pins(req.MountId) {
+			info := &api.PinInfo{
+				MountId:   pin.MountID,
+				Path:      pin.Path,
+				Size:      pin.Size,
+				LastError: pin.LastError,
+			}
+			if !pin.Refreshed.IsZero() {
+				info.Refreshed = pin.Refreshed.Unix()
+			}
+			resp.Pins = append(resp.Pins, info)
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_LIST_PINS_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send ListPinsResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] ListPinsResponse sent to application")
+		return nil
+
+	case api.MessageType_LIST_CONFLICTS_REQUEST:
+		var req api.ListConflictsRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal ListConflictsRequest: %w", err)
+		}
+		resp := &api.ListConflictsResponse{}
+		if conflicts, err := c.conflictService.Conflicts(req.MountId); err != nil {
+			resp.Error = err.Error()
+		} else {
+			for _, record := range conflicts {
+				resp.Conflicts = append(resp.Conflicts, conflictToProto(record))
+			}
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_LIST_CONFLICTS_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send ListConflictsResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] ListConflictsResponse sent to application")
+		return nil
+
+	case api.MessageType_RESOLVE_CONFLICT_REQUEST:
+		var req api.ResolveConflictRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal ResolveConflictRequest: %w", err)
+		}
+		resp := &api.ResolveConflictResponse{}
+		if err := c.conflictService.Resolve(req.MountId, req.Path, conflictResolutionFromProto(req.Resolution)); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_RESOLVE_CONFLICT_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send ResolveConflictResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] ResolveConflictResponse sent to application")
+		return nil
+
+	case api.MessageType_CACHE_STATS_REQUEST:
+		var req api.CacheStatsRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal CacheStatsRequest: %w", err)
+		}
+		stats, size, maxSize := c.cacheService.Stats()
+		resp := &api.CacheStatsResponse{Size: size, MaxSize: maxSize}
+		for _, mount := range stats {
+			resp.Mounts = append(resp.Mounts, &api.CacheMountStats{
+				MountId:     mount.MountID,
+				Size:        mount.Size,
+				Entries:     uint32(mount.Entries),
+				PinnedBytes: mount.PinnedBytes,
+				DirtyBytes:  mount.DirtyBytes,
+				Hits:        mount.Hits,
+				Misses:      mount.Misses,
+			})
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_CACHE_STATS_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send CacheStatsResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] CacheStatsResponse sent to application")
+		return nil
+
+	case api.MessageType_LIST_CACHE_REQUEST:
+		var req api.ListCacheRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal ListCacheRequest: %w", err)
+		}
+		resp := &api.ListCacheResponse{}
+		if entries, err := c.cacheService.Entries(req.MountId, req.Path); err != nil {
+			resp.Error = err.Error()
+		} else {
+			for _, entry := range entries {
+				resp.Entries = append(resp.Entries, cacheEntryToProto(entry))
+			}
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_LIST_CACHE_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send ListCacheResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] ListCacheResponse sent to application")
+		return nil
+
+	case api.MessageType_PURGE_CACHE_REQUEST:
+		var req api.PurgeCacheRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal PurgeCacheRequest: %w", err)
+		}
+		resp := &api.PurgeCacheResponse{}
+		if entries, bytes, err := c.cacheService.Purge(req.MountId, req.Path, req.IncludePinned); err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Entries = uint32(entries)
+			resp.Bytes = bytes
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_PURGE_CACHE_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send PurgeCacheResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] PurgeCacheResponse sent to application")
+		return nil
+
+	case api.MessageType_SET_CACHE_SIZE_REQUEST:
+		var req api.SetCacheSizeRequest
+		if err := proto.Unmarshal(msg, &req); err != nil {
+			return fmt.Errorf("failed to unmarshal SetCacheSizeRequest: %w", err)
+		}
+		resp := &api.SetCacheSizeResponse{}
+		if err := c.cacheService.SetMaxSize(req.MaxSize); err != nil {
+			resp.Error = err.Error()
+		}
+		_, resp.Size, _ = c.cacheService.Stats()
+		if err := c.SendMessage(c.conn, api.MessageType_SET_CACHE_SIZE_RESPONSE, resp); err != nil {
+			return fmt.Errorf("failed to send SetCacheSizeResponse: %w", err)
+		}
+		fmt.Println("[BackendClient] SetCacheSizeResponse sent to application")
+		return nil
+
 	// Add other message types here
 	default:
 		fmt.Printf("[BackendClient] Unknown or unhandled message type: %d\n", msgType)
 	}
 	return nil
 }
+
+// forwardChanges pushes change events for the given mounts (all mounts if
+// empty) to the connection until the subscription is cancelled. The
+// subscription is cancelled when an event can't be sent, so a client that
+// went away doesn't keep it alive.
+func (c *BackendClient) forwardChanges(events <-chan types.ChangeEvent, mountIDs []uint32, unsubscribe func()) {
+	wanted := make(map[uint32]bool, len(mountIDs))
+	for _, id := range mountIDs {
+		wanted[id] = true
+	}
+	for event := range events {
+		if len(wanted) > 0 && !wanted[event.MountID] {
+			continue
+		}
+		msg := &api.ChangeEvent{
+			MountId: event.MountID,
+			Path:    event.Path,
+			Kind:    changeKindToProto(event.Kind),
+			IsDir:   event.IsDir,
+		}
+		if err := c.SendMessage(c.conn, api.MessageType_CHANGE_EVENT, msg); err != nil {
+			fmt.Fprintf(os.Stderr, "[BackendClient] Failed to push change event: %v\n", err)
+			unsubscribe()
+			return
+		}
+	}
+}
+
+// changeKindToProto converts a change kind to its protocol enum value.
+func changeKindToProto(kind types.ChangeKind) api.ChangeKind {
+	switch kind {
+	case types.ChangeCreated:
+		return api.ChangeKind_CHANGE_CREATED
+	case types.ChangeModified:
+		return api.ChangeKind_CHANGE_MODIFIED
+	case types.ChangeDeleted:
+		return api.ChangeKind_CHANGE_DELETED
+	default:
+		return api.ChangeKind_CHANGE_UNKNOWN
+	}
+}
+
+// mountStatusToProto converts a mount status to its protocol enum value.
+func mountStatusToProto(status types.MountStatus) api.MountStatus {
+	switch status {
+	case types.MountStatusMounted:
+		return api.MountStatus_MOUNTED
+	case types.MountStatusUnmounted:
+		return api.MountStatus_UNMOUNTED
+	case types.MountStatusError:
+		return api.MountStatus_ERROR
+	case types.MountStatusOffline:
+		return api.MountStatus_OFFLINE
+	default:
+		return api.MountStatus_UNKNOWN
+	}
+}
+
+// fileInfoToProto converts a file info to its protocol message.
+func fileInfoToProto(info types.FileInfo) *api.FileInfo {
+	msg := &api.FileInfo{
+		Name:  info.Name,
+		Size:  info.Size,
+		IsDir: info.IsDir,
+		Etag:  info.ETag,
+	}
+	if !info.ModTime.IsZero() {
+		msg.ModTime = info.ModTime.Unix()
+	}
+	return msg
+}
+
+// uploadRecordToProto converts a queued upload to its protocol message.
+func uploadRecordToProto(record metadata.UploadRecord) *api.UploadItem {
+	item := &api.UploadItem{
+		MountId:   record.MountID,
+		Path:      record.Path,
+		Status:    uploadStatusToProto(record.Status),
+		Attempts:  uint32(record.Attempts),
+		LastError: record.LastError,
+		Updated:   record.Updated.Unix(),
+	}
+	if record.Status == metadata.UploadPending && !record.NextAttempt.IsZero() {
+		item.NextAttempt = record.NextAttempt.Unix()
+	}
+	return item
+}
+
+// uploadStatusToProto converts an upload status to its protocol enum value.
+func uploadStatusToProto(status metadata.UploadStatus) api.UploadStatus {
+	switch status {
+	case metadata.UploadPending:
+		return api.UploadStatus_UPLOAD_PENDING
+	case metadata.UploadInProgress:
+		return api.UploadStatus_UPLOAD_IN_PROGRESS
+	case metadata.UploadSynced:
+		return api.UploadStatus_UPLOAD_SYNCED
+	case metadata.UploadFailed:
+		return api.UploadStatus_UPLOAD_FAILED
+	case metadata.UploadConflict:
+		return api.UploadStatus_UPLOAD_CONFLICT
+	default:
+		return api.UploadStatus_UPLOAD_UNKNOWN
+	}
+}
+
+// conflictToProto converts a conflict record to its protocol message.
+func conflictToProto(record metadata.ConflictRecord) *api.ConflictInfo {
+	info := &api.ConflictInfo{
+		MountId:       record.MountID,
+		Path:          record.Path,
+		Policy:        record.Policy,
+		CopyPath:      record.CopyPath,
+		RemoteDeleted: record.Remote == "",
+		Detected:      record.Detected.Unix(),
+	}
+	switch record.Resolution {
+	case metadata.ConflictKeptBoth:
+		info.Resolution = api.ConflictResolution_CONFLICT_KEEP_BOTH
+	case metadata.ConflictLocalWon:
+		info.Resolution = api.ConflictResolution_CONFLICT_LOCAL
+	case metadata.ConflictRemoteWon:
+		info.Resolution = api.ConflictResolution_CONFLICT_REMOTE
+	}
+	if !record.Resolved.IsZero() {
+		info.Resolved = record.Resolved.Unix()
+	}
+	return info
+}
+
+// conflictResolutionFromProto converts a protocol conflict resolution.
+func conflictResolutionFromProto(resolution api.ConflictResolution) metadata.ConflictResolution {
+	switch resolution {
+	case api.ConflictResolution_CONFLICT_KEEP_BOTH:
+		return metadata.ConflictKeptBoth
+	case api.ConflictResolution_CONFLICT_LOCAL:
+		return metadata.ConflictLocalWon
+	case api.ConflictResolution_CONFLICT_REMOTE:
+		return metadata.ConflictRemoteWon
+	default:
+		return metadata.ConflictUnresolved
+	}
+}
+
+// cacheEntryToProto converts a cache entry to its protocol message.
+func cacheEntryToProto(entry cache.CacheEntry) *api.CacheEntryInfo {
+	info := &api.CacheEntryInfo{
+		Size:     entry.Size,
+		Pinned:   entry.Pinned,
+		Dirty:    entry.Dirty,
+		LastUsed: entry.LastUsed.Unix(),
+	}
+	info.MountId, info.Path, _ = cache.SplitCachePath(entry.Path)
+	return info
+}
+
+// readFile reads a file, or the range of it a ReadFileRequest asks for.
+func readFile(backend types.Backend, path string, offset, length int64) ([]byte, error) {
+	if length > 0 {
+		return types.ReadRange(backend, path, offset, length)
+	}
+	data, err := backend.Read(path)
+	if err != nil || offset == 0 {
+		return data, err
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("read %s: invalid offset %d", path, offset)
+	}
+	return types.SliceRange(data, offset, int64(len(data))), nil
+}
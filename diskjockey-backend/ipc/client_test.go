@@ -0,0 +1,58 @@
+package ipc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
+	"github.com/christhomas/diskjockey/diskjockey-backend/services"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestForwardChanges(t *testing.T) {
+	conn, app := net.Pipe()
+	defer app.Close()
+	changes := services.NewChangeService()
+	c := NewBackendClient(conn, nil, nil, nil, nil, changes, nil, nil, nil, nil)
+	events, unsubscribe := changes.Subscribe()
+	done := make(chan struct{})
+	go func() {
+		c.forwardChanges(events, []uint32{2}, unsubscribe)
+		close(done)
+	}()
+
+	// Only events for the wanted mounts are pushed
+	changes.Publish(types.ChangeEvent{MountID: 1, Path: "/skipped.txt", Kind: types.ChangeCreated})
+	changes.Publish(types.ChangeEvent{MountID: 2, Path: "/a.txt", Kind: types.ChangeModified})
+	msgType, payload, err := c.ReceiveMessage(app)
+	if err != nil {
+		t.Fatalf("ReceiveMessage failed: %v", err)
+	}
+	var event api.ChangeEvent
+	if err := proto.Unmarshal(payload, &event); err != nil {
+		t.Fatal(err)
+	}
+	if msgType != api.MessageType_CHANGE_EVENT || event.MountId != 2 || event.Path != "/a.txt" || event.Kind != api.ChangeKind_CHANGE_MODIFIED {
+		t.Errorf("pushed %v %+v, want the change to /a.txt on mount 2", msgType, &event)
+	}
+
+	// Once the application goes away the subscription is cancelled, which
+	// closes its channel
+	app.Close()
+	changes.Publish(types.ChangeEvent{MountID: 2, Path: "/b.txt", Kind: types.ChangeModified})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("forwardChanges kept running after a failed send")
+	}
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("received an event after the subscription should have been cancelled")
+		}
+	case <-time.After(time.Second):
+		t.Error("the subscription was left open after a failed send")
+	}
+}
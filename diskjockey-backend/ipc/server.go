@@ -13,16 +13,30 @@ import (
 type BackendServer struct {
 	configService   *services.ConfigService
 	disktypeService *services.DiskTypeService
+	mountService    *services.MountService
+	oauthService    *services.OAuthService
+	changeService   *services.ChangeService
+	uploadService   *services.UploadService
+	pinService      *services.PinService
+	conflictService *services.ConflictService
+	cacheService    *services.CacheService
 	shutdownChan    chan struct{} // Channel to signal shutdown
 	listener        net.Listener  // Store the listener for graceful shutdown
 	lastActivityMu  sync.Mutex    // Protects lastActivity
 	lastActivity    time.Time     // Last time of activity
 }
 
-func NewBackendServer(config *services.ConfigService, disktypes *services.DiskTypeService) *BackendServer {
+func NewBackendServer(config *services.ConfigService, disktypes *services.DiskTypeService, mounts *services.MountService, oauth *services.OAuthService, changes *services.ChangeService, uploads *services.UploadService, pins *services.PinService, conflicts *services.ConflictService, caches *services.CacheService) *BackendServer {
 	s := &BackendServer{
 		configService:   config,
 		disktypeService: disktypes,
+		mountService:    mounts,
+		oauthService:    oauth,
+		changeService:   changes,
+		uploadService:   uploads,
+		pinService:      pins,
+		conflictService: conflicts,
+		cacheService:    caches,
 		shutdownChan:    make(chan struct{}),
 	}
 	s.lastActivity = time.Now()
@@ -63,7 +77,7 @@ func (s *BackendServer) RunServer() (int, error) {
 				}
 				continue
 			}
-			client := NewBackendClient(conn, s.configService, s.disktypeService)
+			client := NewBackendClient(conn, s.configService, s.disktypeService, s.mountService, s.oauthService, s.changeService, s.uploadService, s.pinService, s.conflictService, s.cacheService)
 			go client.Start()
 		}
 	}()
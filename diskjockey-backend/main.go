@@ -6,11 +6,15 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
 
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
 	"github.com/christhomas/diskjockey/diskjockey-backend/disktypes"
 	"github.com/christhomas/diskjockey/diskjockey-backend/ipc"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
 	"github.com/christhomas/diskjockey/diskjockey-backend/services"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
 )
 
 func main() {
@@ -49,19 +53,78 @@ func main() {
 	diskTypeService.RegisterDiskType(disktypes.FTPDiskType{})
 	diskTypeService.RegisterDiskType(disktypes.SFTPDiskType{})
 	diskTypeService.RegisterDiskType(disktypes.SMBDiskType{})
-	diskTypeService.RegisterDiskType(disktypes.DropboxDiskType{})
+	diskTypeService.RegisterDiskType(disktypes.NFSDiskType{})
 	diskTypeService.RegisterDiskType(disktypes.WebDAVDiskType{})
+	diskTypeService.RegisterDiskType(disktypes.S3DiskType{})
+	diskTypeService.RegisterDiskType(disktypes.AzureBlobDiskType{})
+	diskTypeService.RegisterDiskType(disktypes.GCSDiskType{})
+	diskTypeService.RegisterDiskType(disktypes.HTTPDiskType{})
+	diskTypeService.RegisterDiskType(disktypes.MemoryDiskType{})
+	diskTypeService.RegisterDiskType(disktypes.GitDiskType{})
+
+	metadataStore, err := metadata.OpenMetadataStore(filepath.Join(configDir, "metadata.db"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open metadata store: %v\n", err)
+		os.Exit(1)
+	}
+	defer metadataStore.Close()
+
+	cacheManager := newCacheManager(configService, configDir, metadataStore)
+	if err := cacheManager.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load cache: %v\n", err)
+		os.Exit(1)
+	}
+	defer cacheManager.Close()
+
+	uploadService := services.NewUploadService(metadataStore, cacheManager)
+	conflictService := services.NewConflictService(metadataStore)
+	mountService := services.NewMountService(configService, diskTypeService, metadataStore, cacheManager, uploadService, conflictService)
+	conflictService.Start(mountService)
+	// Archives can be stored on other mounts
+	diskTypeService.RegisterDiskType(disktypes.ArchiveDiskType{Mounts: mountService})
+	// OAuth disk types save the access tokens they refresh on the mount
+	diskTypeService.RegisterDiskType(disktypes.DropboxDiskType{Tokens: mountService})
+	diskTypeService.RegisterDiskType(disktypes.OneDriveDiskType{Tokens: mountService})
+	diskTypeService.RegisterDiskType(disktypes.GoogleDriveDiskType{Tokens: mountService})
+	// Disk types implemented by executables in the plugins dir, each running
+	// in its own process. os.Exit skips deferred calls, so their processes
+	// are also stopped before exiting on errors.
+	plugins := registerPlugins(diskTypeService, filepath.Join(configDir, "plugins"))
+	defer closePlugins(plugins)
 
 	fmt.Println("Registered disk types:")
 	for _, info := range diskTypeService.ListDiskTypes() {
 		fmt.Printf("- %s: %s\n", info.Name, info.Description)
 	}
 
+	changeService := services.NewChangeService()
+	changeService.AddListener(func(event types.ChangeEvent) {
+		mountService.Invalidate(event.MountID, event.Path)
+	})
+	services.NewWatchService(metadataStore, changeService, mountService)
+	mountService.RestoreMounts()
+	if err := uploadService.Start(mountService); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start uploads: %v\n", err)
+		closePlugins(plugins)
+		os.Exit(1)
+	}
+	defer uploadService.Close()
+	pinService := services.NewPinService(metadataStore, cacheManager)
+	if err := pinService.Start(mountService, changeService); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start pinning: %v\n", err)
+		closePlugins(plugins)
+		os.Exit(1)
+	}
+	defer pinService.Close()
+	oauthService := services.NewOAuthService(configService, diskTypeService, mountService)
+	cacheService := services.NewCacheService(configService, cacheManager)
+
 	// Start backend server (listen for incoming connections)
-	server := ipc.NewBackendServer(configService, diskTypeService)
+	server := ipc.NewBackendServer(configService, diskTypeService, mountService, oauthService, changeService, uploadService, pinService, conflictService, cacheService)
 	port, err := server.RunServer()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Backend server error: %v\n", err)
+		closePlugins(plugins)
 		os.Exit(1)
 	}
 	fmt.Printf("Listening on port %d\n", port)
@@ -75,3 +138,47 @@ func main() {
 	sig := <-sigChan // This will block until a signal is sent to the channel
 	fmt.Printf("Received signal %v, shutting down...\n", sig)
 }
+
+// newCacheManager creates the cache manager from the cache_dir and
+// max_cache_size config settings. A relative cache_dir is resolved against
+// the config dir.
+func newCacheManager(configService *services.ConfigService, configDir string, store *metadata.MetadataStore) *cache.CacheManager {
+	cacheDir, err := configService.GetConfig("cache_dir")
+	if err != nil || cacheDir == "" {
+		cacheDir = "./cache"
+	}
+	if !filepath.IsAbs(cacheDir) {
+		cacheDir = filepath.Join(configDir, cacheDir)
+	}
+
+	var maxCacheSize int64
+	if v, err := configService.GetConfig("max_cache_size"); err == nil {
+		maxCacheSize, _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	return cache.NewCacheManager(cacheDir, maxCacheSize, store)
+}
+
+// registerPlugins registers the disk types of the plugins in dir, and
+// returns the plugins registered. Plugins clashing with a registered disk
+// type are stopped again.
+func registerPlugins(diskTypeService *services.DiskTypeService, dir string) []*disktypes.PluginDiskType {
+	var plugins []*disktypes.PluginDiskType
+	for _, p := range disktypes.LoadPlugins(dir) {
+		if _, exists := diskTypeService.LookupDiskType(p.Name()); exists {
+			fmt.Fprintf(os.Stderr, "Plugin disk type %s clashes with an existing disk type, ignoring it\n", p.Name())
+			p.Close()
+			continue
+		}
+		diskTypeService.RegisterDiskType(p)
+		plugins = append(plugins, p)
+	}
+	return plugins
+}
+
+// closePlugins stops the processes of the plugins.
+func closePlugins(plugins []*disktypes.PluginDiskType) {
+	for _, p := range plugins {
+		p.Close()
+	}
+}
@@ -1,6 +1,11 @@
 package metadata
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"time"
+
 	"go.etcd.io/bbolt"
 )
 
@@ -10,11 +15,143 @@ type MetadataStore struct {
 	DB *bbolt.DB
 }
 
+var (
+	// cursorsBucket holds the remote change cursor of each mount
+	cursorsBucket = []byte("cursors")
+	// snapshotsBucket holds a bucket per mount with the last known state of
+	// each remote path
+	snapshotsBucket = []byte("snapshots")
+	// cacheBucket holds the index of the local file cache, keyed by cache path
+	cacheBucket = []byte("cache")
+	// uploadsBucket holds the write-back upload queue, keyed by cache path
+	uploadsBucket = []byte("uploads")
+	// pinsBucket holds the files and directories kept available offline,
+	// keyed by cache path
+	pinsBucket = []byte("pins")
+	// journalBucket holds a bucket per mount with the changes made while it
+	// was offline, keyed by sequence number
+	journalBucket = []byte("journal")
+	// conflictsBucket holds the last conflict detected for each file, keyed by
+	// cache path
+	conflictsBucket = []byte("conflicts")
+)
+
+// FileRecord is the last known state of a remote file or directory
+type FileRecord struct {
+	Size    int64     `json:"size"`
+	IsDir   bool      `json:"is_dir"`
+	ModTime time.Time `json:"mod_time"`
+	ETag    string    `json:"etag,omitempty"`
+}
+
+// CacheRecord is the persisted state of a file in the local cache
+type CacheRecord struct {
+	Size     int64     `json:"size"`
+	Checksum string    `json:"checksum"`
+	Pinned   bool      `json:"pinned,omitempty"`
+	LastUsed time.Time `json:"last_used"`
+	Version  string    `json:"version,omitempty"` // Remote version the contents were read at
+	Dirty    bool      `json:"dirty,omitempty"`   // Written locally and not uploaded yet
+	Base     string    `json:"base,omitempty"`    // Remote version dirty contents are based on
+}
+
+// UploadStatus is the state of a queued upload
+type UploadStatus string
+
+const (
+	UploadPending    UploadStatus = "pending"
+	UploadInProgress UploadStatus = "in_progress"
+	UploadSynced     UploadStatus = "synced"
+	UploadFailed     UploadStatus = "failed"
+	UploadConflict   UploadStatus = "conflict" // Held until the conflict is resolved
+)
+
+// UploadRecord is a write-back upload of a cached file to its mount
+type UploadRecord struct {
+	MountID     uint32       `json:"mount_id"`
+	Path        string       `json:"path"`
+	Status      UploadStatus `json:"status"`
+	Attempts    int          `json:"attempts,omitempty"`
+	LastError   string       `json:"last_error,omitempty"`
+	NextAttempt time.Time    `json:"next_attempt"`
+	Updated     time.Time    `json:"updated"`
+}
+
+// JournalOp is a change made to an offline mount
+type JournalOp string
+
+const (
+	JournalWrite  JournalOp = "write" // Contents are in the cache
+	JournalDelete JournalOp = "delete"
+	JournalRename JournalOp = "rename"
+)
+
+// JournalRecord is a change made to an offline mount, to be replayed on
+// the remote once it's reachable again
+type JournalRecord struct {
+	Op      JournalOp `json:"op"`
+	Path    string    `json:"path"`
+	To      string    `json:"to,omitempty"` // Target of a rename
+	Created time.Time `json:"created"`
+}
+
+// JournalEntry is a journal record with its position in the journal
+type JournalEntry struct {
+	Seq uint64
+	JournalRecord
+}
+
+// PinRecord is a file or directory tree kept available offline
+type PinRecord struct {
+	MountID   uint32    `json:"mount_id"`
+	Path      string    `json:"path"`
+	Created   time.Time `json:"created"`
+	Refreshed time.Time `json:"refreshed,omitempty"`  // Last time the pinned contents were brought up to date
+	LastError string    `json:"last_error,omitempty"` // Error of the last refresh, if it failed
+}
+
+// ConflictResolution is how a conflict was resolved
+type ConflictResolution string
+
+const (
+	ConflictUnresolved ConflictResolution = ""          // Waiting for the user
+	ConflictKeptBoth   ConflictResolution = "keep_both" // Local contents written to CopyPath
+	ConflictLocalWon   ConflictResolution = "local"     // Remote overwritten
+	ConflictRemoteWon  ConflictResolution = "remote"    // Local changes discarded
+)
+
+// ConflictRecord is a file whose local changes were based on a version the
+// remote no longer has
+type ConflictRecord struct {
+	MountID    uint32             `json:"mount_id"`
+	Path       string             `json:"path"`
+	Base       string             `json:"base"`   // Version the local changes were based on
+	Remote     string             `json:"remote"` // Version found on the remote, empty if it was deleted
+	Policy     string             `json:"policy"`
+	Resolution ConflictResolution `json:"resolution,omitempty"`
+	CopyPath   string             `json:"copy_path,omitempty"` // Where the local contents were kept
+	Detected   time.Time          `json:"detected"`
+	Resolved   time.Time          `json:"resolved,omitempty"`
+}
+
 func OpenMetadataStore(path string) (*MetadataStore, error) {
-	db, err := bbolt.Open(path, 0600, nil)
+	// Fail rather than wait forever if another backend has the store open
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 5 * time.Second})
 	if err != nil {
 		return nil, err
 	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{cursorsBucket, snapshotsBucket, cacheBucket, uploadsBucket, pinsBucket, journalBucket, conflictsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 	return &MetadataStore{DB: db}, nil
 }
 
@@ -24,4 +161,389 @@ func (m *MetadataStore) Close() {
 	}
 }
 
-// TODO: Add methods for file metadata, sync state, journaling
+// mountKey returns the key used for per-mount records
+func mountKey(mountID uint32) []byte {
+	return []byte(strconv.FormatUint(uint64(mountID), 10))
+}
+
+// GetCursor returns the stored change cursor for a mount, or "" if there is none.
+func (m *MetadataStore) GetCursor(mountID uint32) (string, error) {
+	var cursor string
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		cursor = string(tx.Bucket(cursorsBucket).Get(mountKey(mountID)))
+		return nil
+	})
+	return cursor, err
+}
+
+// SetCursor stores the change cursor for a mount. An empty cursor removes it.
+func (m *MetadataStore) SetCursor(mountID uint32, cursor string) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(cursorsBucket)
+		if cursor == "" {
+			return b.Delete(mountKey(mountID))
+		}
+		return b.Put(mountKey(mountID), []byte(cursor))
+	})
+}
+
+// GetSnapshot returns the stored snapshot of a mount keyed by path, or nil if
+// no snapshot was stored yet.
+func (m *MetadataStore) GetSnapshot(mountID uint32) (map[string]FileRecord, error) {
+	var records map[string]FileRecord
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(snapshotsBucket).Bucket(mountKey(mountID))
+		if b == nil {
+			return nil
+		}
+		records = make(map[string]FileRecord)
+		return b.ForEach(func(k, v []byte) error {
+			var record FileRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records[string(k)] = record
+			return nil
+		})
+	})
+	return records, err
+}
+
+// SetSnapshot replaces the stored snapshot of a mount.
+func (m *MetadataStore) SetSnapshot(mountID uint32, records map[string]FileRecord) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		parent := tx.Bucket(snapshotsBucket)
+		if parent.Bucket(mountKey(mountID)) != nil {
+			if err := parent.DeleteBucket(mountKey(mountID)); err != nil {
+				return err
+			}
+		}
+		b, err := parent.CreateBucket(mountKey(mountID))
+		if err != nil {
+			return err
+		}
+		for p, record := range records {
+			v, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(p), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteSnapshot removes the stored snapshot of a mount.
+func (m *MetadataStore) DeleteSnapshot(mountID uint32) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		err := tx.Bucket(snapshotsBucket).DeleteBucket(mountKey(mountID))
+		if err == bbolt.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
+// GetCacheRecords returns the whole cache index keyed by cache path.
+func (m *MetadataStore) GetCacheRecords() (map[string]CacheRecord, error) {
+	records := make(map[string]CacheRecord)
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(cacheBucket).ForEach(func(k, v []byte) error {
+			var record CacheRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records[string(k)] = record
+			return nil
+		})
+	})
+	return records, err
+}
+
+// UpdateCacheRecords stores and removes cache index records in one
+// transaction.
+func (m *MetadataStore) UpdateCacheRecords(records map[string]CacheRecord, deleted []string) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(cacheBucket)
+		for _, key := range deleted {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		for key, record := range records {
+			v, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(key), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteCacheRecords removes cache index records in one transaction.
+func (m *MetadataStore) DeleteCacheRecords(keys ...string) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(cacheBucket)
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetUploads returns the whole upload queue keyed by cache path.
+func (m *MetadataStore) GetUploads() (map[string]UploadRecord, error) {
+	records := make(map[string]UploadRecord)
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(uploadsBucket).ForEach(func(k, v []byte) error {
+			var record UploadRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records[string(k)] = record
+			return nil
+		})
+	})
+	return records, err
+}
+
+// GetUpload returns the queued upload of a cache path, if there is one.
+func (m *MetadataStore) GetUpload(key string) (UploadRecord, bool, error) {
+	var record UploadRecord
+	found := false
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(uploadsBucket).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &record)
+	})
+	return record, found, err
+}
+
+// PutUpload stores the queued upload of a cache path.
+func (m *MetadataStore) PutUpload(key string, record UploadRecord) error {
+	v, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(uploadsBucket).Put([]byte(key), v)
+	})
+}
+
+// DeleteUploads removes queued uploads in one transaction.
+func (m *MetadataStore) DeleteUploads(keys ...string) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(uploadsBucket)
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ResetUploads marks uploads left in progress, e.g. by a crash, as pending
+// again and returns how many there were.
+func (m *MetadataStore) ResetUploads() (int, error) {
+	reset := 0
+	err := m.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(uploadsBucket)
+		updates := make(map[string][]byte)
+		err := b.ForEach(func(k, v []byte) error {
+			var record UploadRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			if record.Status != UploadInProgress {
+				return nil
+			}
+			record.Status = UploadPending
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			updates[string(k)] = data
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// bbolt doesn't allow changing a bucket while iterating it
+		for k, v := range updates {
+			if err := b.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		reset = len(updates)
+		return nil
+	})
+	return reset, err
+}
+
+// GetPins returns all pins keyed by cache path.
+func (m *MetadataStore) GetPins() (map[string]PinRecord, error) {
+	records := make(map[string]PinRecord)
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(pinsBucket).ForEach(func(k, v []byte) error {
+			var record PinRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records[string(k)] = record
+			return nil
+		})
+	})
+	return records, err
+}
+
+// PutPin stores the pin of a cache path.
+func (m *MetadataStore) PutPin(key string, record PinRecord) error {
+	v, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(pinsBucket).Put([]byte(key), v)
+	})
+}
+
+// DeletePins removes pins in one transaction.
+func (m *MetadataStore) DeletePins(keys ...string) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(pinsBucket)
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AppendJournal adds a change to the end of the journal of a mount.
+func (m *MetadataStore) AppendJournal(mountID uint32, record JournalRecord) error {
+	v, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.Bucket(journalBucket).CreateBucketIfNotExists(mountKey(mountID))
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(seqKey(seq), v)
+	})
+}
+
+// GetJournal returns the journal of a mount, oldest change first.
+func (m *MetadataStore) GetJournal(mountID uint32) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(journalBucket).Bucket(mountKey(mountID))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			entry := JournalEntry{Seq: binary.BigEndian.Uint64(k)}
+			if err := json.Unmarshal(v, &entry.JournalRecord); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// DeleteJournalEntries removes replayed changes from the journal of a mount.
+func (m *MetadataStore) DeleteJournalEntries(mountID uint32, seqs ...uint64) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(journalBucket).Bucket(mountKey(mountID))
+		if b == nil {
+			return nil
+		}
+		for _, seq := range seqs {
+			if err := b.Delete(seqKey(seq)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetConflicts returns all conflicts keyed by cache path.
+func (m *MetadataStore) GetConflicts() (map[string]ConflictRecord, error) {
+	records := make(map[string]ConflictRecord)
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(conflictsBucket).ForEach(func(k, v []byte) error {
+			var record ConflictRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			records[string(k)] = record
+			return nil
+		})
+	})
+	return records, err
+}
+
+// GetConflict returns the conflict of a cache path, if there is one.
+func (m *MetadataStore) GetConflict(key string) (ConflictRecord, bool, error) {
+	var record ConflictRecord
+	found := false
+	err := m.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(conflictsBucket).Get([]byte(key))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &record)
+	})
+	return record, found, err
+}
+
+// PutConflict stores the conflict of a cache path.
+func (m *MetadataStore) PutConflict(key string, record ConflictRecord) error {
+	v, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(conflictsBucket).Put([]byte(key), v)
+	})
+}
+
+// DeleteConflicts removes conflicts in one transaction.
+func (m *MetadataStore) DeleteConflicts(keys ...string) error {
+	return m.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(conflictsBucket)
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// seqKey returns the key of a journal entry, which sorts in sequence order
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+)
+
+func init() {
+	RegisterMigration("20261018090000_add_mount_options", func(db *gorm.DB) (bool, error) {
+		fmt.Print(" [up migration] adding options column to mounts table... ")
+
+		// Adds the JSON encoded options column for disk type specific settings
+		if err := db.AutoMigrate(&models.Mount{}); err != nil {
+			return false, err
+		}
+
+		fmt.Println("done")
+		return true, nil
+	})
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,6 +19,7 @@ import (
 //   Password     - plain text password for authentication
 //   AccessToken  - token for OAuth or similar
 //   Share        - share name (for SMB, etc.)
+//   Options      - disk type specific settings that have no dedicated column
 //
 // Standard GORM fields: ID, CreatedAt, UpdatedAt, DeletedAt
 
@@ -37,6 +39,22 @@ type Mount struct {
 	Password    string
 	AccessToken string
 	Share       string
+	Options     map[string]string `gorm:"serializer:json;type:text"`
 
 	IsMounted bool `gorm:"not null;default:false"`
 }
+
+// Option returns the disk type specific option stored under key, or "" if unset.
+func (m *Mount) Option(key string) string {
+	if m.Options == nil {
+		return ""
+	}
+	return m.Options[key]
+}
+
+// BoolOption returns the option stored under key interpreted as a boolean.
+// Missing or unparsable values are treated as false.
+func (m *Mount) BoolOption(key string) bool {
+	v, err := strconv.ParseBool(m.Option(key))
+	return err == nil && v
+}
@@ -0,0 +1,225 @@
+// Package plugin implements the protocol between the backend and disk types
+// running in their own process, described in proto/plugin.proto.
+//
+// A plugin written in Go implements types.DiskType and types.Backend like
+// the built-in disk types do, and serves it from its main function:
+//
+//	func main() {
+//		if err := plugin.Serve(MyDiskType{}); err != nil {
+//			fmt.Fprintf(os.Stderr, "plugin failed: %v\n", err)
+//			os.Exit(1)
+//		}
+//	}
+//
+// The executable is then copied into the plugins directory of the config
+// dir, where the backend finds it at startup.
+package plugin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	pluginapi "github.com/christhomas/diskjockey/diskjockey-backend/proto/plugin"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtocolVersion is the version of the protocol sent in the handshake. The
+// backend only talks to plugins with the same version.
+const ProtocolVersion = 1
+
+// MaxMessageSize is the size of the largest message either side accepts
+const MaxMessageSize = 1 << 30
+
+// Conn sends and receives framed messages. Send can be called from several
+// goroutines, Receive only from one.
+type Conn struct {
+	r  io.Reader
+	w  io.Writer
+	mu sync.Mutex // Serialises writes
+}
+
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{r: r, w: w}
+}
+
+// Send writes a message with its length prefix.
+func (c *Conn) Send(msg *pluginapi.Message) error {
+	msgBytes, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if len(msgBytes) > MaxMessageSize {
+		return fmt.Errorf("message of %d bytes is too large", len(msgBytes))
+	}
+	buf := make([]byte, 4+len(msgBytes))
+	binary.BigEndian.PutUint32(buf, uint32(len(msgBytes)))
+	copy(buf[4:], msgBytes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Receive reads the next message. It returns io.EOF once the other side
+// closed the stream between messages.
+func (c *Conn) Receive() (*pluginapi.Message, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(c.r, lenBuf[:]); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read message length: %w", err)
+	}
+	msgLen := binary.BigEndian.Uint32(lenBuf[:])
+	if msgLen > MaxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes is too large", msgLen)
+	}
+	msgBytes := make([]byte, msgLen)
+	if _, err := io.ReadFull(c.r, msgBytes); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	var msg pluginapi.Message
+	if err := proto.Unmarshal(msgBytes, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	return &msg, nil
+}
+
+// errorCodes maps the errors the backend checks for to their codes, in the
+// order they are checked
+var errorCodes = []struct {
+	err  error
+	code pluginapi.ErrorCode
+}{
+	{types.ErrOffline, pluginapi.ErrorCode_ERROR_OFFLINE},
+	{types.ErrReauthRequired, pluginapi.ErrorCode_ERROR_REAUTH_REQUIRED},
+	{types.ErrConflict, pluginapi.ErrorCode_ERROR_CONFLICT},
+	{types.ErrReadOnly, pluginapi.ErrorCode_ERROR_READ_ONLY},
+	{types.ErrInvalidPath, pluginapi.ErrorCode_ERROR_INVALID_PATH},
+	{fs.ErrNotExist, pluginapi.ErrorCode_ERROR_NOT_EXIST},
+	{fs.ErrExist, pluginapi.ErrorCode_ERROR_EXIST},
+	{fs.ErrPermission, pluginapi.ErrorCode_ERROR_PERMISSION},
+	{syscall.EISDIR, pluginapi.ErrorCode_ERROR_IS_DIR},
+	{syscall.ENOTDIR, pluginapi.ErrorCode_ERROR_NOT_DIR},
+	{syscall.ENOTEMPTY, pluginapi.ErrorCode_ERROR_NOT_EMPTY},
+	{errors.ErrUnsupported, pluginapi.ErrorCode_ERROR_UNSUPPORTED},
+}
+
+// EncodeError converts an error to its message, nil for no error.
+func EncodeError(err error) *pluginapi.Error {
+	if err == nil {
+		return nil
+	}
+	code := pluginapi.ErrorCode_ERROR_UNKNOWN
+	if types.IsUnreachable(err) {
+		code = pluginapi.ErrorCode_ERROR_OFFLINE
+	} else {
+		for _, e := range errorCodes {
+			if errors.Is(err, e.err) {
+				code = e.code
+				break
+			}
+		}
+	}
+	return &pluginapi.Error{Code: code, Message: err.Error()}
+}
+
+// DecodeError converts an error message back to an error, which keeps the
+// text of the original and matches the error its code stands for with
+// errors.Is.
+func DecodeError(e *pluginapi.Error) error {
+	if e == nil {
+		return nil
+	}
+	for _, c := range errorCodes {
+		if c.code == e.Code {
+			return &remoteError{message: e.Message, err: c.err}
+		}
+	}
+	return errors.New(e.Message)
+}
+
+type remoteError struct {
+	message string
+	err     error
+}
+
+func (e *remoteError) Error() string { return e.message }
+func (e *remoteError) Unwrap() error { return e.err }
+
+// EncodeMount converts a mount to its message.
+func EncodeMount(m *models.Mount) *pluginapi.Mount {
+	return &pluginapi.Mount{
+		Id:          uint32(m.ID),
+		Name:        m.Name,
+		Path:        m.Path,
+		Host:        m.Host,
+		Port:        int32(m.Port),
+		Username:    m.Username,
+		Password:    m.Password,
+		AccessToken: m.AccessToken,
+		Share:       m.Share,
+		Options:     m.Options,
+	}
+}
+
+// DecodeMount converts a mount message back to a mount.
+func DecodeMount(m *pluginapi.Mount) *models.Mount {
+	options := m.GetOptions()
+	if options == nil {
+		options = map[string]string{}
+	}
+	return &models.Mount{
+		ID:          uint(m.GetId()),
+		Name:        m.GetName(),
+		Path:        m.GetPath(),
+		Host:        m.GetHost(),
+		Port:        int(m.GetPort()),
+		Username:    m.GetUsername(),
+		Password:    m.GetPassword(),
+		AccessToken: m.GetAccessToken(),
+		Share:       m.GetShare(),
+		Options:     options,
+	}
+}
+
+// EncodeFileInfo converts file info to its message.
+func EncodeFileInfo(info types.FileInfo) *pluginapi.FileInfo {
+	var modTime int64
+	if !info.ModTime.IsZero() {
+		modTime = info.ModTime.UnixNano()
+	}
+	return &pluginapi.FileInfo{
+		Name:    info.Name,
+		Size:    info.Size,
+		IsDir:   info.IsDir,
+		ModTime: modTime,
+		Etag:    info.ETag,
+	}
+}
+
+// DecodeFileInfo converts a file info message back to file info.
+func DecodeFileInfo(info *pluginapi.FileInfo) types.FileInfo {
+	var modTime time.Time
+	if info.GetModTime() != 0 {
+		modTime = time.Unix(0, info.GetModTime())
+	}
+	return types.FileInfo{
+		Name:    info.GetName(),
+		Size:    info.GetSize(),
+		IsDir:   info.GetIsDir(),
+		ModTime: modTime,
+		ETag:    info.GetEtag(),
+	}
+}
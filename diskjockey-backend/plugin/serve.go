@@ -0,0 +1,291 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"sync"
+
+	pluginapi "github.com/christhomas/diskjockey/diskjockey-backend/proto/plugin"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// Serve answers the backend's requests on stdin and stdout with dt until
+// the backend closes stdin. Output printed to stdout while serving goes to
+// stderr instead, so it can't corrupt the protocol.
+func Serve(dt types.DiskType) error {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	return ServeConn(NewConn(os.Stdin, stdout), dt)
+}
+
+// ServeConn answers requests on conn with dt until conn is closed. Every
+// request is handled in its own goroutine. Backends still open at the end
+// are closed if they implement io.Closer.
+func ServeConn(conn *Conn, dt types.DiskType) error {
+	s := &server{
+		conn:     conn,
+		diskType: dt,
+		backends: make(map[uint64]types.Backend),
+	}
+	defer s.closeAll()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		msg, err := conn.Receive()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(msg)
+		}()
+	}
+}
+
+type server struct {
+	conn     *Conn
+	diskType types.DiskType
+	mu       sync.Mutex
+	backends map[uint64]types.Backend // handle -> backend
+	next     uint64
+}
+
+func (s *server) handle(msg *pluginapi.Message) {
+	respType, resp := s.dispatch(msg)
+	payload, err := proto.Marshal(resp)
+	if err != nil {
+		respType = pluginapi.MessageType_UNKNOWN_TYPE
+		payload, _ = proto.Marshal(EncodeError(fmt.Errorf("failed to marshal response: %w", err)))
+	}
+	if err := s.conn.Send(&pluginapi.Message{Id: msg.Id, Type: respType, Payload: payload}); err != nil {
+		fmt.Fprintf(os.Stderr, "[Plugin] Failed to send %s: %v\n", respType, err)
+	}
+}
+
+// dispatch runs a request, turning a panic in the disk type into an error
+// response instead of taking the plugin down.
+func (s *server) dispatch(msg *pluginapi.Message) (respType pluginapi.MessageType, resp proto.Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "[Plugin][PANIC][%s] %v\n%s\n", msg.Type, r, debug.Stack())
+			respType, resp = pluginapi.MessageType_UNKNOWN_TYPE, EncodeError(fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	switch msg.Type {
+	case pluginapi.MessageType_HANDSHAKE_REQUEST:
+		var req pluginapi.HandshakeRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		return pluginapi.MessageType_HANDSHAKE_RESPONSE, s.handshake(&req)
+
+	case pluginapi.MessageType_OPEN_REQUEST:
+		var req pluginapi.OpenRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		return pluginapi.MessageType_OPEN_RESPONSE, s.open(&req)
+
+	case pluginapi.MessageType_CLOSE_REQUEST:
+		var req pluginapi.CloseRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		return pluginapi.MessageType_CLOSE_RESPONSE, &pluginapi.CloseResponse{Error: EncodeError(s.close(req.Handle))}
+
+	case pluginapi.MessageType_RECONNECT_REQUEST:
+		var req pluginapi.ReconnectRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		b, err := s.backend(req.Handle)
+		if err == nil {
+			err = b.Reconnect()
+		}
+		return pluginapi.MessageType_RECONNECT_RESPONSE, &pluginapi.ReconnectResponse{Error: EncodeError(err)}
+
+	case pluginapi.MessageType_LIST_REQUEST:
+		var req pluginapi.ListRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		resp := &pluginapi.ListResponse{}
+		b, err := s.backend(req.Handle)
+		if err == nil {
+			var files []types.FileInfo
+			files, err = b.List(req.Path)
+			for _, f := range files {
+				resp.Files = append(resp.Files, EncodeFileInfo(f))
+			}
+		}
+		resp.Error = EncodeError(err)
+		return pluginapi.MessageType_LIST_RESPONSE, resp
+
+	case pluginapi.MessageType_STAT_REQUEST:
+		var req pluginapi.StatRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		resp := &pluginapi.StatResponse{}
+		b, err := s.backend(req.Handle)
+		if err == nil {
+			var info types.FileInfo
+			if info, err = types.Stat(b, req.Path); err == nil {
+				resp.Info = EncodeFileInfo(info)
+			}
+		}
+		resp.Error = EncodeError(err)
+		return pluginapi.MessageType_STAT_RESPONSE, resp
+
+	case pluginapi.MessageType_READ_REQUEST:
+		var req pluginapi.ReadRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		resp := &pluginapi.ReadResponse{}
+		b, err := s.backend(req.Handle)
+		if err == nil {
+			resp.Data, err = b.Read(req.Path)
+		}
+		resp.Error = EncodeError(err)
+		return pluginapi.MessageType_READ_RESPONSE, resp
+
+	case pluginapi.MessageType_READ_RANGE_REQUEST:
+		var req pluginapi.ReadRangeRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		resp := &pluginapi.ReadRangeResponse{}
+		b, err := s.backend(req.Handle)
+		if err == nil {
+			resp.Data, err = types.ReadRange(b, req.Path, req.Offset, req.Length)
+		}
+		resp.Error = EncodeError(err)
+		return pluginapi.MessageType_READ_RANGE_RESPONSE, resp
+
+	case pluginapi.MessageType_WRITE_REQUEST:
+		var req pluginapi.WriteRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		b, err := s.backend(req.Handle)
+		if err == nil {
+			err = b.Write(req.Path, req.Data)
+		}
+		return pluginapi.MessageType_WRITE_RESPONSE, &pluginapi.WriteResponse{Error: EncodeError(err)}
+
+	case pluginapi.MessageType_DELETE_REQUEST:
+		var req pluginapi.DeleteRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		b, err := s.backend(req.Handle)
+		if err == nil {
+			err = b.Delete(req.Path)
+		}
+		return pluginapi.MessageType_DELETE_RESPONSE, &pluginapi.DeleteResponse{Error: EncodeError(err)}
+
+	case pluginapi.MessageType_RENAME_REQUEST:
+		var req pluginapi.RenameRequest
+		if err := proto.Unmarshal(msg.Payload, &req); err != nil {
+			return invalidRequest(err)
+		}
+		b, err := s.backend(req.Handle)
+		if err == nil {
+			err = types.Rename(b, req.From, req.To)
+		}
+		return pluginapi.MessageType_RENAME_RESPONSE, &pluginapi.RenameResponse{Error: EncodeError(err)}
+	}
+
+	return pluginapi.MessageType_UNKNOWN_TYPE, &pluginapi.Error{
+		Code:    pluginapi.ErrorCode_ERROR_UNSUPPORTED,
+		Message: fmt.Sprintf("unsupported message type %s", msg.Type),
+	}
+}
+
+func invalidRequest(err error) (pluginapi.MessageType, proto.Message) {
+	return pluginapi.MessageType_UNKNOWN_TYPE, EncodeError(fmt.Errorf("invalid request: %w", err))
+}
+
+func (s *server) handshake(req *pluginapi.HandshakeRequest) *pluginapi.HandshakeResponse {
+	resp := &pluginapi.HandshakeResponse{
+		ProtocolVersion: ProtocolVersion,
+		Name:            s.diskType.Name(),
+		Description:     s.diskType.Description(),
+		Config:          make(map[string]*pluginapi.ConfigField),
+	}
+	if req.ProtocolVersion != ProtocolVersion {
+		resp.Error = EncodeError(fmt.Errorf("unsupported protocol version %d, plugin uses %d", req.ProtocolVersion, ProtocolVersion))
+	}
+	for key, field := range s.diskType.ConfigTemplate() {
+		resp.Config[key] = &pluginapi.ConfigField{
+			Type:        field.Type,
+			Description: field.Description,
+			Required:    field.Required,
+		}
+	}
+	return resp
+}
+
+func (s *server) open(req *pluginapi.OpenRequest) *pluginapi.OpenResponse {
+	b, err := s.diskType.New(DecodeMount(req.GetMount()))
+	if err != nil {
+		return &pluginapi.OpenResponse{Error: EncodeError(err)}
+	}
+
+	s.mu.Lock()
+	s.next++
+	handle := s.next
+	s.backends[handle] = b
+	s.mu.Unlock()
+
+	_, stat := b.(types.Stater)
+	_, rename := b.(types.Renamer)
+	_, readRange := b.(types.RangeReader)
+	return &pluginapi.OpenResponse{Handle: handle, Stat: stat, Rename: rename, ReadRange: readRange}
+}
+
+func (s *server) backend(handle uint64) (types.Backend, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.backends[handle]
+	if !ok {
+		return nil, fmt.Errorf("unknown handle %d", handle)
+	}
+	return b, nil
+}
+
+func (s *server) close(handle uint64) error {
+	s.mu.Lock()
+	b, ok := s.backends[handle]
+	delete(s.backends, handle)
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if c, ok := b.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (s *server) closeAll() {
+	s.mu.Lock()
+	backends := s.backends
+	s.backends = make(map[uint64]types.Backend)
+	s.mu.Unlock()
+	for _, b := range backends {
+		if c, ok := b.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
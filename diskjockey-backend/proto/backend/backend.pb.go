@@ -56,6 +56,37 @@ const (
 	MessageType_STAT_RESPONSE                MessageType = 23
 	MessageType_DELETE_FILE_REQUEST          MessageType = 24
 	MessageType_DELETE_FILE_RESPONSE         MessageType = 25
+	MessageType_OAUTH_START_REQUEST          MessageType = 26
+	MessageType_OAUTH_START_RESPONSE         MessageType = 27
+	MessageType_OAUTH_FINISH_REQUEST         MessageType = 28
+	MessageType_OAUTH_FINISH_RESPONSE        MessageType = 29
+	MessageType_SUBSCRIBE_CHANGES_REQUEST    MessageType = 30
+	MessageType_SUBSCRIBE_CHANGES_RESPONSE   MessageType = 31
+	MessageType_CHANGE_EVENT                 MessageType = 32 // Pushed by the backend after SUBSCRIBE_CHANGES_REQUEST
+	MessageType_UPLOAD_QUEUE_REQUEST         MessageType = 33
+	MessageType_UPLOAD_QUEUE_RESPONSE        MessageType = 34
+	MessageType_PIN_REQUEST                  MessageType = 35
+	MessageType_PIN_RESPONSE                 MessageType = 36
+	MessageType_UNPIN_REQUEST                MessageType = 37
+	MessageType_UNPIN_RESPONSE               MessageType = 38
+	MessageType_LIST_PINS_REQUEST            MessageType = 39
+	MessageType_LIST_PINS_RESPONSE           MessageType = 40
+	MessageType_RENAME_REQUEST               MessageType = 41
+	MessageType_RENAME_RESPONSE              MessageType = 42
+	MessageType_LIST_CONFLICTS_REQUEST       MessageType = 43
+	MessageType_LIST_CONFLICTS_RESPONSE      MessageType = 44
+	MessageType_RESOLVE_CONFLICT_REQUEST     MessageType = 45
+	MessageType_RESOLVE_CONFLICT_RESPONSE    MessageType = 46
+	MessageType_CACHE_STATS_REQUEST          MessageType = 47
+	MessageType_CACHE_STATS_RESPONSE         MessageType = 48
+	MessageType_LIST_CACHE_REQUEST           MessageType = 49
+	MessageType_LIST_CACHE_RESPONSE          MessageType = 50
+	MessageType_PURGE_CACHE_REQUEST          MessageType = 51
+	MessageType_PURGE_CACHE_RESPONSE         MessageType = 52
+	MessageType_SET_CACHE_SIZE_REQUEST       MessageType = 53
+	MessageType_SET_CACHE_SIZE_RESPONSE      MessageType = 54
+	MessageType_RETRY_UPLOADS_REQUEST        MessageType = 55
+	MessageType_RETRY_UPLOADS_RESPONSE       MessageType = 56
 	MessageType_SHUTDOWN_REQUEST             MessageType = 99
 	MessageType_SHUTDOWN_RESPONSE            MessageType = 100
 )
@@ -89,6 +120,37 @@ var (
 		23:  "STAT_RESPONSE",
 		24:  "DELETE_FILE_REQUEST",
 		25:  "DELETE_FILE_RESPONSE",
+		26:  "OAUTH_START_REQUEST",
+		27:  "OAUTH_START_RESPONSE",
+		28:  "OAUTH_FINISH_REQUEST",
+		29:  "OAUTH_FINISH_RESPONSE",
+		30:  "SUBSCRIBE_CHANGES_REQUEST",
+		31:  "SUBSCRIBE_CHANGES_RESPONSE",
+		32:  "CHANGE_EVENT",
+		33:  "UPLOAD_QUEUE_REQUEST",
+		34:  "UPLOAD_QUEUE_RESPONSE",
+		35:  "PIN_REQUEST",
+		36:  "PIN_RESPONSE",
+		37:  "UNPIN_REQUEST",
+		38:  "UNPIN_RESPONSE",
+		39:  "LIST_PINS_REQUEST",
+		40:  "LIST_PINS_RESPONSE",
+		41:  "RENAME_REQUEST",
+		42:  "RENAME_RESPONSE",
+		43:  "LIST_CONFLICTS_REQUEST",
+		44:  "LIST_CONFLICTS_RESPONSE",
+		45:  "RESOLVE_CONFLICT_REQUEST",
+		46:  "RESOLVE_CONFLICT_RESPONSE",
+		47:  "CACHE_STATS_REQUEST",
+		48:  "CACHE_STATS_RESPONSE",
+		49:  "LIST_CACHE_REQUEST",
+		50:  "LIST_CACHE_RESPONSE",
+		51:  "PURGE_CACHE_REQUEST",
+		52:  "PURGE_CACHE_RESPONSE",
+		53:  "SET_CACHE_SIZE_REQUEST",
+		54:  "SET_CACHE_SIZE_RESPONSE",
+		55:  "RETRY_UPLOADS_REQUEST",
+		56:  "RETRY_UPLOADS_RESPONSE",
 		99:  "SHUTDOWN_REQUEST",
 		100: "SHUTDOWN_RESPONSE",
 	}
@@ -119,6 +181,37 @@ var (
 		"STAT_RESPONSE":                23,
 		"DELETE_FILE_REQUEST":          24,
 		"DELETE_FILE_RESPONSE":         25,
+		"OAUTH_START_REQUEST":          26,
+		"OAUTH_START_RESPONSE":         27,
+		"OAUTH_FINISH_REQUEST":         28,
+		"OAUTH_FINISH_RESPONSE":        29,
+		"SUBSCRIBE_CHANGES_REQUEST":    30,
+		"SUBSCRIBE_CHANGES_RESPONSE":   31,
+		"CHANGE_EVENT":                 32,
+		"UPLOAD_QUEUE_REQUEST":         33,
+		"UPLOAD_QUEUE_RESPONSE":        34,
+		"PIN_REQUEST":                  35,
+		"PIN_RESPONSE":                 36,
+		"UNPIN_REQUEST":                37,
+		"UNPIN_RESPONSE":               38,
+		"LIST_PINS_REQUEST":            39,
+		"LIST_PINS_RESPONSE":           40,
+		"RENAME_REQUEST":               41,
+		"RENAME_RESPONSE":              42,
+		"LIST_CONFLICTS_REQUEST":       43,
+		"LIST_CONFLICTS_RESPONSE":      44,
+		"RESOLVE_CONFLICT_REQUEST":     45,
+		"RESOLVE_CONFLICT_RESPONSE":    46,
+		"CACHE_STATS_REQUEST":          47,
+		"CACHE_STATS_RESPONSE":         48,
+		"LIST_CACHE_REQUEST":           49,
+		"LIST_CACHE_RESPONSE":          50,
+		"PURGE_CACHE_REQUEST":          51,
+		"PURGE_CACHE_RESPONSE":         52,
+		"SET_CACHE_SIZE_REQUEST":       53,
+		"SET_CACHE_SIZE_RESPONSE":      54,
+		"RETRY_UPLOADS_REQUEST":        55,
+		"RETRY_UPLOADS_RESPONSE":       56,
 		"SHUTDOWN_REQUEST":             99,
 		"SHUTDOWN_RESPONSE":            100,
 	}
@@ -151,6 +244,168 @@ func (MessageType) EnumDescriptor() ([]byte, []int) {
 	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{0}
 }
 
+type ChangeKind int32
+
+const (
+	ChangeKind_CHANGE_UNKNOWN  ChangeKind = 0
+	ChangeKind_CHANGE_CREATED  ChangeKind = 1
+	ChangeKind_CHANGE_MODIFIED ChangeKind = 2
+	ChangeKind_CHANGE_DELETED  ChangeKind = 3
+)
+
+// Enum value maps for ChangeKind.
+var (
+	ChangeKind_name = map[int32]string{
+		0: "CHANGE_UNKNOWN",
+		1: "CHANGE_CREATED",
+		2: "CHANGE_MODIFIED",
+		3: "CHANGE_DELETED",
+	}
+	ChangeKind_value = map[string]int32{
+		"CHANGE_UNKNOWN":  0,
+		"CHANGE_CREATED":  1,
+		"CHANGE_MODIFIED": 2,
+		"CHANGE_DELETED":  3,
+	}
+)
+
+func (x ChangeKind) Enum() *ChangeKind {
+	p := new(ChangeKind)
+	*p = x
+	return p
+}
+
+func (x ChangeKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChangeKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_diskjockey_backend_proto_backend_proto_enumTypes[1].Descriptor()
+}
+
+func (ChangeKind) Type() protoreflect.EnumType {
+	return &file_diskjockey_backend_proto_backend_proto_enumTypes[1]
+}
+
+func (x ChangeKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChangeKind.Descriptor instead.
+func (ChangeKind) EnumDescriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{1}
+}
+
+type UploadStatus int32
+
+const (
+	UploadStatus_UPLOAD_UNKNOWN     UploadStatus = 0
+	UploadStatus_UPLOAD_PENDING     UploadStatus = 1
+	UploadStatus_UPLOAD_IN_PROGRESS UploadStatus = 2
+	UploadStatus_UPLOAD_SYNCED      UploadStatus = 3
+	UploadStatus_UPLOAD_FAILED      UploadStatus = 4
+	UploadStatus_UPLOAD_CONFLICT    UploadStatus = 5 // Held until the conflict is resolved
+)
+
+// Enum value maps for UploadStatus.
+var (
+	UploadStatus_name = map[int32]string{
+		0: "UPLOAD_UNKNOWN",
+		1: "UPLOAD_PENDING",
+		2: "UPLOAD_IN_PROGRESS",
+		3: "UPLOAD_SYNCED",
+		4: "UPLOAD_FAILED",
+		5: "UPLOAD_CONFLICT",
+	}
+	UploadStatus_value = map[string]int32{
+		"UPLOAD_UNKNOWN":     0,
+		"UPLOAD_PENDING":     1,
+		"UPLOAD_IN_PROGRESS": 2,
+		"UPLOAD_SYNCED":      3,
+		"UPLOAD_FAILED":      4,
+		"UPLOAD_CONFLICT":    5,
+	}
+)
+
+func (x UploadStatus) Enum() *UploadStatus {
+	p := new(UploadStatus)
+	*p = x
+	return p
+}
+
+func (x UploadStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UploadStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_diskjockey_backend_proto_backend_proto_enumTypes[2].Descriptor()
+}
+
+func (UploadStatus) Type() protoreflect.EnumType {
+	return &file_diskjockey_backend_proto_backend_proto_enumTypes[2]
+}
+
+func (x UploadStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UploadStatus.Descriptor instead.
+func (UploadStatus) EnumDescriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{2}
+}
+
+type ConflictResolution int32
+
+const (
+	ConflictResolution_CONFLICT_UNRESOLVED ConflictResolution = 0
+	ConflictResolution_CONFLICT_KEEP_BOTH  ConflictResolution = 1 // Local contents written to copy_path
+	ConflictResolution_CONFLICT_LOCAL      ConflictResolution = 2 // Remote file overwritten
+	ConflictResolution_CONFLICT_REMOTE     ConflictResolution = 3 // Local changes discarded
+)
+
+// Enum value maps for ConflictResolution.
+var (
+	ConflictResolution_name = map[int32]string{
+		0: "CONFLICT_UNRESOLVED",
+		1: "CONFLICT_KEEP_BOTH",
+		2: "CONFLICT_LOCAL",
+		3: "CONFLICT_REMOTE",
+	}
+	ConflictResolution_value = map[string]int32{
+		"CONFLICT_UNRESOLVED": 0,
+		"CONFLICT_KEEP_BOTH":  1,
+		"CONFLICT_LOCAL":      2,
+		"CONFLICT_REMOTE":     3,
+	}
+)
+
+func (x ConflictResolution) Enum() *ConflictResolution {
+	p := new(ConflictResolution)
+	*p = x
+	return p
+}
+
+func (x ConflictResolution) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConflictResolution) Descriptor() protoreflect.EnumDescriptor {
+	return file_diskjockey_backend_proto_backend_proto_enumTypes[3].Descriptor()
+}
+
+func (ConflictResolution) Type() protoreflect.EnumType {
+	return &file_diskjockey_backend_proto_backend_proto_enumTypes[3]
+}
+
+func (x ConflictResolution) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConflictResolution.Descriptor instead.
+func (ConflictResolution) EnumDescriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{3}
+}
+
 // Mount status event (for event-driven updates)
 type MountStatus int32
 
@@ -159,6 +414,7 @@ const (
 	MountStatus_MOUNTED   MountStatus = 1
 	MountStatus_UNMOUNTED MountStatus = 2
 	MountStatus_ERROR     MountStatus = 3
+	MountStatus_OFFLINE   MountStatus = 4 // Mounted, served from the cache until the remote is reachable
 )
 
 // Enum value maps for MountStatus.
@@ -168,12 +424,14 @@ var (
 		1: "MOUNTED",
 		2: "UNMOUNTED",
 		3: "ERROR",
+		4: "OFFLINE",
 	}
 	MountStatus_value = map[string]int32{
 		"UNKNOWN":   0,
 		"MOUNTED":   1,
 		"UNMOUNTED": 2,
 		"ERROR":     3,
+		"OFFLINE":   4,
 	}
 )
 
@@ -188,11 +446,11 @@ func (x MountStatus) String() string {
 }
 
 func (MountStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_diskjockey_backend_proto_backend_proto_enumTypes[1].Descriptor()
+	return file_diskjockey_backend_proto_backend_proto_enumTypes[4].Descriptor()
 }
 
 func (MountStatus) Type() protoreflect.EnumType {
-	return &file_diskjockey_backend_proto_backend_proto_enumTypes[1]
+	return &file_diskjockey_backend_proto_backend_proto_enumTypes[4]
 }
 
 func (x MountStatus) Number() protoreflect.EnumNumber {
@@ -201,7 +459,7 @@ func (x MountStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use MountStatus.Descriptor instead.
 func (MountStatus) EnumDescriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{1}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{4}
 }
 
 type ConnectRequest_Role int32
@@ -240,11 +498,11 @@ func (x ConnectRequest_Role) String() string {
 }
 
 func (ConnectRequest_Role) Descriptor() protoreflect.EnumDescriptor {
-	return file_diskjockey_backend_proto_backend_proto_enumTypes[2].Descriptor()
+	return file_diskjockey_backend_proto_backend_proto_enumTypes[5].Descriptor()
 }
 
 func (ConnectRequest_Role) Type() protoreflect.EnumType {
-	return &file_diskjockey_backend_proto_backend_proto_enumTypes[2]
+	return &file_diskjockey_backend_proto_backend_proto_enumTypes[5]
 }
 
 func (x ConnectRequest_Role) Number() protoreflect.EnumNumber {
@@ -508,6 +766,8 @@ type ReadFileRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
 	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length        int64                  `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"` // 0 reads to the end of the file
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -556,6 +816,20 @@ func (x *ReadFileRequest) GetPath() string {
 	return ""
 }
 
+func (x *ReadFileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadFileRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
 type ReadFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
@@ -901,6 +1175,111 @@ func (x *DeleteFileResponse) GetError() string {
 	return ""
 }
 
+// Rename (move) a file or directory within a mount
+type RenameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameRequest) Reset() {
+	*x = RenameRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameRequest) ProtoMessage() {}
+
+func (x *RenameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameRequest.ProtoReflect.Descriptor instead.
+func (*RenameRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RenameRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *RenameRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *RenameRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type RenameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameResponse) Reset() {
+	*x = RenameResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameResponse) ProtoMessage() {}
+
+func (x *RenameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameResponse.ProtoReflect.Descriptor instead.
+func (*RenameResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RenameResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 // Stat (file metadata)
 type StatRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -912,7 +1291,7 @@ type StatRequest struct {
 
 func (x *StatRequest) Reset() {
 	*x = StatRequest{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[13]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -924,7 +1303,7 @@ func (x *StatRequest) String() string {
 func (*StatRequest) ProtoMessage() {}
 
 func (x *StatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[13]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -937,7 +1316,7 @@ func (x *StatRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatRequest.ProtoReflect.Descriptor instead.
 func (*StatRequest) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{13}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *StatRequest) GetMountId() uint32 {
@@ -964,7 +1343,7 @@ type StatResponse struct {
 
 func (x *StatResponse) Reset() {
 	*x = StatResponse{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[14]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -976,7 +1355,7 @@ func (x *StatResponse) String() string {
 func (*StatResponse) ProtoMessage() {}
 
 func (x *StatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[14]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -989,7 +1368,7 @@ func (x *StatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatResponse.ProtoReflect.Descriptor instead.
 func (*StatResponse) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{14}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *StatResponse) GetInfo() *FileInfo {
@@ -1015,7 +1394,7 @@ type ListDiskTypesRequest struct {
 
 func (x *ListDiskTypesRequest) Reset() {
 	*x = ListDiskTypesRequest{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[15]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1027,7 +1406,7 @@ func (x *ListDiskTypesRequest) String() string {
 func (*ListDiskTypesRequest) ProtoMessage() {}
 
 func (x *ListDiskTypesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[15]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1040,7 +1419,7 @@ func (x *ListDiskTypesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDiskTypesRequest.ProtoReflect.Descriptor instead.
 func (*ListDiskTypesRequest) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{15}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{17}
 }
 
 type ListDiskTypesResponse struct {
@@ -1053,7 +1432,7 @@ type ListDiskTypesResponse struct {
 
 func (x *ListDiskTypesResponse) Reset() {
 	*x = ListDiskTypesResponse{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[16]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1065,7 +1444,7 @@ func (x *ListDiskTypesResponse) String() string {
 func (*ListDiskTypesResponse) ProtoMessage() {}
 
 func (x *ListDiskTypesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[16]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1078,7 +1457,7 @@ func (x *ListDiskTypesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDiskTypesResponse.ProtoReflect.Descriptor instead.
 func (*ListDiskTypesResponse) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{16}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *ListDiskTypesResponse) GetDiskTypes() []*DiskTypeInfo {
@@ -1106,7 +1485,7 @@ type DiskTypeInfo struct {
 
 func (x *DiskTypeInfo) Reset() {
 	*x = DiskTypeInfo{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[17]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1118,7 +1497,7 @@ func (x *DiskTypeInfo) String() string {
 func (*DiskTypeInfo) ProtoMessage() {}
 
 func (x *DiskTypeInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[17]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1131,7 +1510,7 @@ func (x *DiskTypeInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DiskTypeInfo.ProtoReflect.Descriptor instead.
 func (*DiskTypeInfo) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{17}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *DiskTypeInfo) GetName() string {
@@ -1167,7 +1546,7 @@ type ConfigField struct {
 
 func (x *ConfigField) Reset() {
 	*x = ConfigField{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[18]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1179,7 +1558,7 @@ func (x *ConfigField) String() string {
 func (*ConfigField) ProtoMessage() {}
 
 func (x *ConfigField) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[18]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1192,7 +1571,7 @@ func (x *ConfigField) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfigField.ProtoReflect.Descriptor instead.
 func (*ConfigField) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{18}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *ConfigField) GetName() string {
@@ -1232,7 +1611,7 @@ type ListMountsRequest struct {
 
 func (x *ListMountsRequest) Reset() {
 	*x = ListMountsRequest{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[19]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1244,7 +1623,7 @@ func (x *ListMountsRequest) String() string {
 func (*ListMountsRequest) ProtoMessage() {}
 
 func (x *ListMountsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[19]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1257,7 +1636,7 @@ func (x *ListMountsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListMountsRequest.ProtoReflect.Descriptor instead.
 func (*ListMountsRequest) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{19}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{21}
 }
 
 type ListMountsResponse struct {
@@ -1270,7 +1649,7 @@ type ListMountsResponse struct {
 
 func (x *ListMountsResponse) Reset() {
 	*x = ListMountsResponse{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[20]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1282,7 +1661,7 @@ func (x *ListMountsResponse) String() string {
 func (*ListMountsResponse) ProtoMessage() {}
 
 func (x *ListMountsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[20]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1295,7 +1674,7 @@ func (x *ListMountsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListMountsResponse.ProtoReflect.Descriptor instead.
 func (*ListMountsResponse) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{20}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *ListMountsResponse) GetMounts() []*MountInfo {
@@ -1318,13 +1697,15 @@ type MountInfo struct {
 	DiskType      string                 `protobuf:"bytes,2,opt,name=disk_type,json=diskType,proto3" json:"disk_type,omitempty"`
 	Config        map[string]string      `protobuf:"bytes,3,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	MountId       uint32                 `protobuf:"varint,4,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Status        MountStatus            `protobuf:"varint,5,opt,name=status,proto3,enum=backend.MountStatus" json:"status,omitempty"`
+	StatusError   string                 `protobuf:"bytes,6,opt,name=status_error,json=statusError,proto3" json:"status_error,omitempty"` // Set when status is ERROR or OFFLINE
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *MountInfo) Reset() {
 	*x = MountInfo{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[21]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1336,7 +1717,7 @@ func (x *MountInfo) String() string {
 func (*MountInfo) ProtoMessage() {}
 
 func (x *MountInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[21]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1349,7 +1730,7 @@ func (x *MountInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MountInfo.ProtoReflect.Descriptor instead.
 func (*MountInfo) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{21}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *MountInfo) GetName() string {
@@ -1380,19 +1761,35 @@ func (x *MountInfo) GetMountId() uint32 {
 	return 0
 }
 
+func (x *MountInfo) GetStatus() MountStatus {
+	if x != nil {
+		return x.Status
+	}
+	return MountStatus_UNKNOWN
+}
+
+func (x *MountInfo) GetStatusError() string {
+	if x != nil {
+		return x.StatusError
+	}
+	return ""
+}
+
 // File metadata
 type FileInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
 	IsDir         bool                   `protobuf:"varint,3,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	ModTime       int64                  `protobuf:"varint,4,opt,name=mod_time,json=modTime,proto3" json:"mod_time,omitempty"` // Unix time in seconds, 0 if unknown
+	Etag          string                 `protobuf:"bytes,5,opt,name=etag,proto3" json:"etag,omitempty"`                       // Remote version of the contents, if known
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FileInfo) Reset() {
 	*x = FileInfo{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[22]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1404,7 +1801,7 @@ func (x *FileInfo) String() string {
 func (*FileInfo) ProtoMessage() {}
 
 func (x *FileInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[22]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1417,7 +1814,7 @@ func (x *FileInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
 func (*FileInfo) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{22}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *FileInfo) GetName() string {
@@ -1441,30 +1838,1867 @@ func (x *FileInfo) GetIsDir() bool {
 	return false
 }
 
-// Mount/Unmount management
-// --- Mount/Unmount now only activate/deactivate an existing mount by ID ---
-type MountRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"` // ID of mount to activate
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *FileInfo) GetModTime() int64 {
+	if x != nil {
+		return x.ModTime
+	}
+	return 0
+}
+
+func (x *FileInfo) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+// Mount/Unmount management
+// --- Mount/Unmount now only activate/deactivate an existing mount by ID ---
+type MountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"` // ID of mount to activate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MountRequest) Reset() {
+	*x = MountRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MountRequest) ProtoMessage() {}
+
+func (x *MountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MountRequest.ProtoReflect.Descriptor instead.
+func (*MountRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *MountRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+type MountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MountResponse) Reset() {
+	*x = MountResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MountResponse) ProtoMessage() {}
+
+func (x *MountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MountResponse.ProtoReflect.Descriptor instead.
+func (*MountResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *MountResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// --- Create/Delete are for DB row management ---
+type CreateMountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DiskType      string                 `protobuf:"bytes,2,opt,name=disk_type,json=diskType,proto3" json:"disk_type,omitempty"`
+	Config        map[string]string      `protobuf:"bytes,3,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMountRequest) Reset() {
+	*x = CreateMountRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMountRequest) ProtoMessage() {}
+
+func (x *CreateMountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMountRequest.ProtoReflect.Descriptor instead.
+func (*CreateMountRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CreateMountRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateMountRequest) GetDiskType() string {
+	if x != nil {
+		return x.DiskType
+	}
+	return ""
+}
+
+func (x *CreateMountRequest) GetConfig() map[string]string {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type CreateMountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMountResponse) Reset() {
+	*x = CreateMountResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMountResponse) ProtoMessage() {}
+
+func (x *CreateMountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMountResponse.ProtoReflect.Descriptor instead.
+func (*CreateMountResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CreateMountResponse) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *CreateMountResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type DeleteMountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMountRequest) Reset() {
+	*x = DeleteMountRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMountRequest) ProtoMessage() {}
+
+func (x *DeleteMountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMountRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMountRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DeleteMountRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+type DeleteMountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMountResponse) Reset() {
+	*x = DeleteMountResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMountResponse) ProtoMessage() {}
+
+func (x *DeleteMountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMountResponse.ProtoReflect.Descriptor instead.
+func (*DeleteMountResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *DeleteMountResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UnmountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnmountRequest) Reset() {
+	*x = UnmountRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnmountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnmountRequest) ProtoMessage() {}
+
+func (x *UnmountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnmountRequest.ProtoReflect.Descriptor instead.
+func (*UnmountRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UnmountRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+type UnmountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnmountResponse) Reset() {
+	*x = UnmountResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnmountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnmountResponse) ProtoMessage() {}
+
+func (x *UnmountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnmountResponse.ProtoReflect.Descriptor instead.
+func (*UnmountResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *UnmountResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// OAuth2 authorization for disk types that need it (e.g. dropbox)
+// Start returns the URL the user must open in a browser; the backend listens
+// on redirect_url for the provider's redirect.
+type OAuthStartRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OAuthStartRequest) Reset() {
+	*x = OAuthStartRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OAuthStartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OAuthStartRequest) ProtoMessage() {}
+
+func (x *OAuthStartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OAuthStartRequest.ProtoReflect.Descriptor instead.
+func (*OAuthStartRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *OAuthStartRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+type OAuthStartResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthUrl       string                 `protobuf:"bytes,1,opt,name=auth_url,json=authUrl,proto3" json:"auth_url,omitempty"`
+	RedirectUrl   string                 `protobuf:"bytes,2,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OAuthStartResponse) Reset() {
+	*x = OAuthStartResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OAuthStartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OAuthStartResponse) ProtoMessage() {}
+
+func (x *OAuthStartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OAuthStartResponse.ProtoReflect.Descriptor instead.
+func (*OAuthStartResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *OAuthStartResponse) GetAuthUrl() string {
+	if x != nil {
+		return x.AuthUrl
+	}
+	return ""
+}
+
+func (x *OAuthStartResponse) GetRedirectUrl() string {
+	if x != nil {
+		return x.RedirectUrl
+	}
+	return ""
+}
+
+func (x *OAuthStartResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Finish waits for the redirect to arrive, or completes the flow with a code
+// the user copied manually when the redirect could not reach the backend.
+type OAuthFinishRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MountId        uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Code           string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`                                            // Optional, manually entered authorization code
+	TimeoutSeconds uint32                 `protobuf:"varint,3,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"` // Optional, defaults to 300
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *OAuthFinishRequest) Reset() {
+	*x = OAuthFinishRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OAuthFinishRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OAuthFinishRequest) ProtoMessage() {}
+
+func (x *OAuthFinishRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OAuthFinishRequest.ProtoReflect.Descriptor instead.
+func (*OAuthFinishRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *OAuthFinishRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *OAuthFinishRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *OAuthFinishRequest) GetTimeoutSeconds() uint32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+type OAuthFinishResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OAuthFinishResponse) Reset() {
+	*x = OAuthFinishResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OAuthFinishResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OAuthFinishResponse) ProtoMessage() {}
+
+func (x *OAuthFinishResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OAuthFinishResponse.ProtoReflect.Descriptor instead.
+func (*OAuthFinishResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *OAuthFinishResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Subscribe this connection to remote change events. After the response the
+// backend pushes a CHANGE_EVENT message for every change it detects.
+type SubscribeChangesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountIds      []uint32               `protobuf:"varint,1,rep,packed,name=mount_ids,json=mountIds,proto3" json:"mount_ids,omitempty"` // Empty subscribes to all mounts
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeChangesRequest) Reset() {
+	*x = SubscribeChangesRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeChangesRequest) ProtoMessage() {}
+
+func (x *SubscribeChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeChangesRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeChangesRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SubscribeChangesRequest) GetMountIds() []uint32 {
+	if x != nil {
+		return x.MountIds
+	}
+	return nil
+}
+
+type SubscribeChangesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeChangesResponse) Reset() {
+	*x = SubscribeChangesResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeChangesResponse) ProtoMessage() {}
+
+func (x *SubscribeChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeChangesResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeChangesResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SubscribeChangesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ChangeEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Kind          ChangeKind             `protobuf:"varint,3,opt,name=kind,proto3,enum=backend.ChangeKind" json:"kind,omitempty"`
+	IsDir         bool                   `protobuf:"varint,4,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeEvent) Reset() {
+	*x = ChangeEvent{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeEvent) ProtoMessage() {}
+
+func (x *ChangeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeEvent.ProtoReflect.Descriptor instead.
+func (*ChangeEvent) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ChangeEvent) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *ChangeEvent) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetKind() ChangeKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ChangeKind_CHANGE_UNKNOWN
+}
+
+func (x *ChangeEvent) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+// Upload queue of write-back mounts
+type UploadQueueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"` // 0 for all mounts
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadQueueRequest) Reset() {
+	*x = UploadQueueRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadQueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadQueueRequest) ProtoMessage() {}
+
+func (x *UploadQueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadQueueRequest.ProtoReflect.Descriptor instead.
+func (*UploadQueueRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *UploadQueueRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+type UploadQueueResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*UploadItem          `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadQueueResponse) Reset() {
+	*x = UploadQueueResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadQueueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadQueueResponse) ProtoMessage() {}
+
+func (x *UploadQueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadQueueResponse.ProtoReflect.Descriptor instead.
+func (*UploadQueueResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *UploadQueueResponse) GetItems() []*UploadItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *UploadQueueResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UploadItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Status        UploadStatus           `protobuf:"varint,3,opt,name=status,proto3,enum=backend.UploadStatus" json:"status,omitempty"`
+	Attempts      uint32                 `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LastError     string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Updated       int64                  `protobuf:"varint,6,opt,name=updated,proto3" json:"updated,omitempty"`                            // Unix time in seconds of the last status change
+	NextAttempt   int64                  `protobuf:"varint,7,opt,name=next_attempt,json=nextAttempt,proto3" json:"next_attempt,omitempty"` // Unix time in seconds of the next retry of a pending upload
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadItem) Reset() {
+	*x = UploadItem{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadItem) ProtoMessage() {}
+
+func (x *UploadItem) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadItem.ProtoReflect.Descriptor instead.
+func (*UploadItem) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *UploadItem) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *UploadItem) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UploadItem) GetStatus() UploadStatus {
+	if x != nil {
+		return x.Status
+	}
+	return UploadStatus_UPLOAD_UNKNOWN
+}
+
+func (x *UploadItem) GetAttempts() uint32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *UploadItem) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *UploadItem) GetUpdated() int64 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *UploadItem) GetNextAttempt() int64 {
+	if x != nil {
+		return x.NextAttempt
+	}
+	return 0
+}
+
+// Queue failed uploads again with their attempts reset, and retry pending
+// ones right away
+type RetryUploadsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"` // 0 for all mounts
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                       // Defaults to the whole mount
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryUploadsRequest) Reset() {
+	*x = RetryUploadsRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryUploadsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryUploadsRequest) ProtoMessage() {}
+
+func (x *RetryUploadsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryUploadsRequest.ProtoReflect.Descriptor instead.
+func (*RetryUploadsRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *RetryUploadsRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *RetryUploadsRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type RetryUploadsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Retried       uint32                 `protobuf:"varint,1,opt,name=retried,proto3" json:"retried,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryUploadsResponse) Reset() {
+	*x = RetryUploadsResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryUploadsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryUploadsResponse) ProtoMessage() {}
+
+func (x *RetryUploadsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryUploadsResponse.ProtoReflect.Descriptor instead.
+func (*RetryUploadsResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *RetryUploadsResponse) GetRetried() uint32 {
+	if x != nil {
+		return x.Retried
+	}
+	return 0
+}
+
+func (x *RetryUploadsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Pin a file or directory tree, keeping it available offline
+type PinRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinRequest) Reset() {
+	*x = PinRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinRequest) ProtoMessage() {}
+
+func (x *PinRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinRequest.ProtoReflect.Descriptor instead.
+func (*PinRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *PinRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *PinRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type PinResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinResponse) Reset() {
+	*x = PinResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinResponse) ProtoMessage() {}
+
+func (x *PinResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinResponse.ProtoReflect.Descriptor instead.
+func (*PinResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *PinResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Unpin a path and everything pinned below it
+type UnpinRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnpinRequest) Reset() {
+	*x = UnpinRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpinRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpinRequest) ProtoMessage() {}
+
+func (x *UnpinRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpinRequest.ProtoReflect.Descriptor instead.
+func (*UnpinRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *UnpinRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *UnpinRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type UnpinResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnpinResponse) Reset() {
+	*x = UnpinResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpinResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpinResponse) ProtoMessage() {}
+
+func (x *UnpinResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpinResponse.ProtoReflect.Descriptor instead.
+func (*UnpinResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *UnpinResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListPinsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"` // 0 for all mounts
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPinsRequest) Reset() {
+	*x = ListPinsRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPinsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPinsRequest) ProtoMessage() {}
+
+func (x *ListPinsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPinsRequest.ProtoReflect.Descriptor instead.
+func (*ListPinsRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ListPinsRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+type ListPinsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pins          []*PinInfo             `protobuf:"bytes,1,rep,name=pins,proto3" json:"pins,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPinsResponse) Reset() {
+	*x = ListPinsResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPinsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPinsResponse) ProtoMessage() {}
+
+func (x *ListPinsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPinsResponse.ProtoReflect.Descriptor instead.
+func (*ListPinsResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ListPinsResponse) GetPins() []*PinInfo {
+	if x != nil {
+		return x.Pins
+	}
+	return nil
+}
+
+func (x *ListPinsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PinInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`           // Bytes of the pinned contents in the cache
+	Refreshed     int64                  `protobuf:"varint,4,opt,name=refreshed,proto3" json:"refreshed,omitempty"` // Unix time in seconds of the last refresh, 0 if none yet
+	LastError     string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinInfo) Reset() {
+	*x = PinInfo{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinInfo) ProtoMessage() {}
+
+func (x *PinInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinInfo.ProtoReflect.Descriptor instead.
+func (*PinInfo) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *PinInfo) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *PinInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PinInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *PinInfo) GetRefreshed() int64 {
+	if x != nil {
+		return x.Refreshed
+	}
+	return 0
+}
+
+func (x *PinInfo) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+// Conflicts between local changes and changes made on the remote meanwhile.
+// Mounts resolve them according to their conflict_policy option; with "ask"
+// they stay UNRESOLVED until resolved with ResolveConflictRequest.
+type ListConflictsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"` // 0 for all mounts
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConflictsRequest) Reset() {
+	*x = ListConflictsRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConflictsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConflictsRequest) ProtoMessage() {}
+
+func (x *ListConflictsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConflictsRequest.ProtoReflect.Descriptor instead.
+func (*ListConflictsRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ListConflictsRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+type ListConflictsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conflicts     []*ConflictInfo        `protobuf:"bytes,1,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConflictsResponse) Reset() {
+	*x = ListConflictsResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConflictsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConflictsResponse) ProtoMessage() {}
+
+func (x *ListConflictsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConflictsResponse.ProtoReflect.Descriptor instead.
+func (*ListConflictsResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *ListConflictsResponse) GetConflicts() []*ConflictInfo {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+func (x *ListConflictsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ConflictInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Policy        string                 `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"`
+	Resolution    ConflictResolution     `protobuf:"varint,4,opt,name=resolution,proto3,enum=backend.ConflictResolution" json:"resolution,omitempty"`
+	CopyPath      string                 `protobuf:"bytes,5,opt,name=copy_path,json=copyPath,proto3" json:"copy_path,omitempty"`
+	RemoteDeleted bool                   `protobuf:"varint,6,opt,name=remote_deleted,json=remoteDeleted,proto3" json:"remote_deleted,omitempty"` // The remote file was deleted rather than changed
+	Detected      int64                  `protobuf:"varint,7,opt,name=detected,proto3" json:"detected,omitempty"`                                // Unix time in seconds
+	Resolved      int64                  `protobuf:"varint,8,opt,name=resolved,proto3" json:"resolved,omitempty"`                                // Unix time in seconds, 0 while unresolved
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConflictInfo) Reset() {
+	*x = ConflictInfo{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConflictInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConflictInfo) ProtoMessage() {}
+
+func (x *ConflictInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConflictInfo.ProtoReflect.Descriptor instead.
+func (*ConflictInfo) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *ConflictInfo) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *ConflictInfo) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ConflictInfo) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *ConflictInfo) GetResolution() ConflictResolution {
+	if x != nil {
+		return x.Resolution
+	}
+	return ConflictResolution_CONFLICT_UNRESOLVED
+}
+
+func (x *ConflictInfo) GetCopyPath() string {
+	if x != nil {
+		return x.CopyPath
+	}
+	return ""
+}
+
+func (x *ConflictInfo) GetRemoteDeleted() bool {
+	if x != nil {
+		return x.RemoteDeleted
+	}
+	return false
+}
+
+func (x *ConflictInfo) GetDetected() int64 {
+	if x != nil {
+		return x.Detected
+	}
+	return 0
+}
+
+func (x *ConflictInfo) GetResolved() int64 {
+	if x != nil {
+		return x.Resolved
+	}
+	return 0
+}
+
+type ResolveConflictRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Resolution    ConflictResolution     `protobuf:"varint,3,opt,name=resolution,proto3,enum=backend.ConflictResolution" json:"resolution,omitempty"` // KEEP_BOTH, LOCAL or REMOTE
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveConflictRequest) Reset() {
+	*x = ResolveConflictRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveConflictRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveConflictRequest) ProtoMessage() {}
+
+func (x *ResolveConflictRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveConflictRequest.ProtoReflect.Descriptor instead.
+func (*ResolveConflictRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ResolveConflictRequest) GetMountId() uint32 {
+	if x != nil {
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *ResolveConflictRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ResolveConflictRequest) GetResolution() ConflictResolution {
+	if x != nil {
+		return x.Resolution
+	}
+	return ConflictResolution_CONFLICT_UNRESOLVED
+}
+
+type ResolveConflictResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveConflictResponse) Reset() {
+	*x = ResolveConflictResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveConflictResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveConflictResponse) ProtoMessage() {}
+
+func (x *ResolveConflictResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveConflictResponse.ProtoReflect.Descriptor instead.
+func (*ResolveConflictResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ResolveConflictResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Local file cache statistics and management
+type CacheStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheStatsRequest) Reset() {
+	*x = CacheStatsRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStatsRequest) ProtoMessage() {}
+
+func (x *CacheStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStatsRequest.ProtoReflect.Descriptor instead.
+func (*CacheStatsRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{57}
+}
+
+type CacheStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`                      // Bytes in the cache
+	MaxSize       int64                  `protobuf:"varint,2,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"` // Bytes the cache is kept under, 0 if unlimited
+	Mounts        []*CacheMountStats     `protobuf:"bytes,3,rep,name=mounts,proto3" json:"mounts,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheStatsResponse) Reset() {
+	*x = CacheStatsResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStatsResponse) ProtoMessage() {}
+
+func (x *CacheStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStatsResponse.ProtoReflect.Descriptor instead.
+func (*CacheStatsResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *CacheStatsResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CacheStatsResponse) GetMaxSize() int64 {
+	if x != nil {
+		return x.MaxSize
+	}
+	return 0
+}
+
+func (x *CacheStatsResponse) GetMounts() []*CacheMountStats {
+	if x != nil {
+		return x.Mounts
+	}
+	return nil
+}
+
+func (x *CacheStatsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
 }
 
-func (x *MountRequest) Reset() {
-	*x = MountRequest{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[23]
+type CacheMountStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Entries       uint32                 `protobuf:"varint,3,opt,name=entries,proto3" json:"entries,omitempty"`
+	PinnedBytes   int64                  `protobuf:"varint,4,opt,name=pinned_bytes,json=pinnedBytes,proto3" json:"pinned_bytes,omitempty"`
+	DirtyBytes    int64                  `protobuf:"varint,5,opt,name=dirty_bytes,json=dirtyBytes,proto3" json:"dirty_bytes,omitempty"` // Written locally and not uploaded yet
+	Hits          uint64                 `protobuf:"varint,6,opt,name=hits,proto3" json:"hits,omitempty"`                               // Reads served from the cache since the backend started
+	Misses        uint64                 `protobuf:"varint,7,opt,name=misses,proto3" json:"misses,omitempty"`                           // Reads of cacheable files that went to the remote
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheMountStats) Reset() {
+	*x = CacheMountStats{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MountRequest) String() string {
+func (x *CacheMountStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MountRequest) ProtoMessage() {}
+func (*CacheMountStats) ProtoMessage() {}
 
-func (x *MountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[23]
+func (x *CacheMountStats) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1475,40 +3709,83 @@ func (x *MountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MountRequest.ProtoReflect.Descriptor instead.
-func (*MountRequest) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use CacheMountStats.ProtoReflect.Descriptor instead.
+func (*CacheMountStats) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{59}
 }
 
-func (x *MountRequest) GetMountId() uint32 {
+func (x *CacheMountStats) GetMountId() uint32 {
 	if x != nil {
 		return x.MountId
 	}
 	return 0
 }
 
-type MountResponse struct {
+func (x *CacheMountStats) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CacheMountStats) GetEntries() uint32 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *CacheMountStats) GetPinnedBytes() int64 {
+	if x != nil {
+		return x.PinnedBytes
+	}
+	return 0
+}
+
+func (x *CacheMountStats) GetDirtyBytes() int64 {
+	if x != nil {
+		return x.DirtyBytes
+	}
+	return 0
+}
+
+func (x *CacheMountStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheMountStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+type ListCacheRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"` // 0 for all mounts
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                       // Defaults to the whole mount
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MountResponse) Reset() {
-	*x = MountResponse{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[24]
+func (x *ListCacheRequest) Reset() {
+	*x = ListCacheRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MountResponse) String() string {
+func (x *ListCacheRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MountResponse) ProtoMessage() {}
+func (*ListCacheRequest) ProtoMessage() {}
 
-func (x *MountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[24]
+func (x *ListCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1519,43 +3796,48 @@ func (x *MountResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MountResponse.ProtoReflect.Descriptor instead.
-func (*MountResponse) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use ListCacheRequest.ProtoReflect.Descriptor instead.
+func (*ListCacheRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{60}
 }
 
-func (x *MountResponse) GetError() string {
+func (x *ListCacheRequest) GetMountId() uint32 {
 	if x != nil {
-		return x.Error
+		return x.MountId
+	}
+	return 0
+}
+
+func (x *ListCacheRequest) GetPath() string {
+	if x != nil {
+		return x.Path
 	}
 	return ""
 }
 
-// --- Create/Delete are for DB row management ---
-type CreateMountRequest struct {
+type ListCacheResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	DiskType      string                 `protobuf:"bytes,2,opt,name=disk_type,json=diskType,proto3" json:"disk_type,omitempty"`
-	Config        map[string]string      `protobuf:"bytes,3,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Entries       []*CacheEntryInfo      `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateMountRequest) Reset() {
-	*x = CreateMountRequest{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[25]
+func (x *ListCacheResponse) Reset() {
+	*x = ListCacheResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateMountRequest) String() string {
+func (x *ListCacheResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateMountRequest) ProtoMessage() {}
+func (*ListCacheResponse) ProtoMessage() {}
 
-func (x *CreateMountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[25]
+func (x *ListCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1566,55 +3848,52 @@ func (x *CreateMountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateMountRequest.ProtoReflect.Descriptor instead.
-func (*CreateMountRequest) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use ListCacheResponse.ProtoReflect.Descriptor instead.
+func (*ListCacheResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *CreateMountRequest) GetName() string {
+func (x *ListCacheResponse) GetEntries() []*CacheEntryInfo {
 	if x != nil {
-		return x.Name
+		return x.Entries
 	}
-	return ""
+	return nil
 }
 
-func (x *CreateMountRequest) GetDiskType() string {
+func (x *ListCacheResponse) GetError() string {
 	if x != nil {
-		return x.DiskType
+		return x.Error
 	}
 	return ""
 }
 
-func (x *CreateMountRequest) GetConfig() map[string]string {
-	if x != nil {
-		return x.Config
-	}
-	return nil
-}
-
-type CreateMountResponse struct {
+type CacheEntryInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Pinned        bool                   `protobuf:"varint,4,opt,name=pinned,proto3" json:"pinned,omitempty"`
+	Dirty         bool                   `protobuf:"varint,5,opt,name=dirty,proto3" json:"dirty,omitempty"`
+	LastUsed      int64                  `protobuf:"varint,6,opt,name=last_used,json=lastUsed,proto3" json:"last_used,omitempty"` // Unix time in seconds
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateMountResponse) Reset() {
-	*x = CreateMountResponse{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[26]
+func (x *CacheEntryInfo) Reset() {
+	*x = CacheEntryInfo{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateMountResponse) String() string {
+func (x *CacheEntryInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateMountResponse) ProtoMessage() {}
+func (*CacheEntryInfo) ProtoMessage() {}
 
-func (x *CreateMountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[26]
+func (x *CacheEntryInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1625,47 +3904,78 @@ func (x *CreateMountResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateMountResponse.ProtoReflect.Descriptor instead.
-func (*CreateMountResponse) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use CacheEntryInfo.ProtoReflect.Descriptor instead.
+func (*CacheEntryInfo) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{62}
 }
 
-func (x *CreateMountResponse) GetMountId() uint32 {
+func (x *CacheEntryInfo) GetMountId() uint32 {
 	if x != nil {
 		return x.MountId
 	}
 	return 0
 }
 
-func (x *CreateMountResponse) GetError() string {
+func (x *CacheEntryInfo) GetPath() string {
 	if x != nil {
-		return x.Error
+		return x.Path
 	}
 	return ""
 }
 
-type DeleteMountRequest struct {
+func (x *CacheEntryInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CacheEntryInfo) GetPinned() bool {
+	if x != nil {
+		return x.Pinned
+	}
+	return false
+}
+
+func (x *CacheEntryInfo) GetDirty() bool {
+	if x != nil {
+		return x.Dirty
+	}
+	return false
+}
+
+func (x *CacheEntryInfo) GetLastUsed() int64 {
+	if x != nil {
+		return x.LastUsed
+	}
+	return 0
+}
+
+// Purge never removes contents waiting to be uploaded
+type PurgeCacheRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"` // 0 for all mounts
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                       // Defaults to the whole mount
+	IncludePinned bool                   `protobuf:"varint,3,opt,name=include_pinned,json=includePinned,proto3" json:"include_pinned,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteMountRequest) Reset() {
-	*x = DeleteMountRequest{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[27]
+func (x *PurgeCacheRequest) Reset() {
+	*x = PurgeCacheRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteMountRequest) String() string {
+func (x *PurgeCacheRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteMountRequest) ProtoMessage() {}
+func (*PurgeCacheRequest) ProtoMessage() {}
 
-func (x *DeleteMountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[27]
+func (x *PurgeCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1676,40 +3986,56 @@ func (x *DeleteMountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteMountRequest.ProtoReflect.Descriptor instead.
-func (*DeleteMountRequest) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{27}
+// Deprecated: Use PurgeCacheRequest.ProtoReflect.Descriptor instead.
+func (*PurgeCacheRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{63}
 }
 
-func (x *DeleteMountRequest) GetMountId() uint32 {
+func (x *PurgeCacheRequest) GetMountId() uint32 {
 	if x != nil {
 		return x.MountId
 	}
 	return 0
 }
 
-type DeleteMountResponse struct {
+func (x *PurgeCacheRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PurgeCacheRequest) GetIncludePinned() bool {
+	if x != nil {
+		return x.IncludePinned
+	}
+	return false
+}
+
+type PurgeCacheResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Entries       uint32                 `protobuf:"varint,1,opt,name=entries,proto3" json:"entries,omitempty"`
+	Bytes         int64                  `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteMountResponse) Reset() {
-	*x = DeleteMountResponse{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[28]
+func (x *PurgeCacheResponse) Reset() {
+	*x = PurgeCacheResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteMountResponse) String() string {
+func (x *PurgeCacheResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteMountResponse) ProtoMessage() {}
+func (*PurgeCacheResponse) ProtoMessage() {}
 
-func (x *DeleteMountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[28]
+func (x *PurgeCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1720,40 +4046,55 @@ func (x *DeleteMountResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteMountResponse.ProtoReflect.Descriptor instead.
-func (*DeleteMountResponse) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{28}
+// Deprecated: Use PurgeCacheResponse.ProtoReflect.Descriptor instead.
+func (*PurgeCacheResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *DeleteMountResponse) GetError() string {
+func (x *PurgeCacheResponse) GetEntries() uint32 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *PurgeCacheResponse) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *PurgeCacheResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-type UnmountRequest struct {
+// Changes max_cache_size, evicting entries right away if needed
+type SetCacheSizeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MountId       uint32                 `protobuf:"varint,1,opt,name=mount_id,json=mountId,proto3" json:"mount_id,omitempty"`
+	MaxSize       int64                  `protobuf:"varint,1,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"` // Bytes, 0 for unlimited
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UnmountRequest) Reset() {
-	*x = UnmountRequest{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[29]
+func (x *SetCacheSizeRequest) Reset() {
+	*x = SetCacheSizeRequest{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UnmountRequest) String() string {
+func (x *SetCacheSizeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UnmountRequest) ProtoMessage() {}
+func (*SetCacheSizeRequest) ProtoMessage() {}
 
-func (x *UnmountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[29]
+func (x *SetCacheSizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1764,40 +4105,41 @@ func (x *UnmountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UnmountRequest.ProtoReflect.Descriptor instead.
-func (*UnmountRequest) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{29}
+// Deprecated: Use SetCacheSizeRequest.ProtoReflect.Descriptor instead.
+func (*SetCacheSizeRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{65}
 }
 
-func (x *UnmountRequest) GetMountId() uint32 {
+func (x *SetCacheSizeRequest) GetMaxSize() int64 {
 	if x != nil {
-		return x.MountId
+		return x.MaxSize
 	}
 	return 0
 }
 
-type UnmountResponse struct {
+type SetCacheSizeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"` // Bytes in the cache after eviction
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UnmountResponse) Reset() {
-	*x = UnmountResponse{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[30]
+func (x *SetCacheSizeResponse) Reset() {
+	*x = SetCacheSizeResponse{}
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UnmountResponse) String() string {
+func (x *SetCacheSizeResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UnmountResponse) ProtoMessage() {}
+func (*SetCacheSizeResponse) ProtoMessage() {}
 
-func (x *UnmountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[30]
+func (x *SetCacheSizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1808,12 +4150,19 @@ func (x *UnmountResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UnmountResponse.ProtoReflect.Descriptor instead.
-func (*UnmountResponse) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{30}
+// Deprecated: Use SetCacheSizeResponse.ProtoReflect.Descriptor instead.
+func (*SetCacheSizeResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *UnmountResponse) GetError() string {
+func (x *SetCacheSizeResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SetCacheSizeResponse) GetError() string {
 	if x != nil {
 		return x.Error
 	}
@@ -1829,7 +4178,7 @@ type ShutdownRequest struct {
 
 func (x *ShutdownRequest) Reset() {
 	*x = ShutdownRequest{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[31]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1841,7 +4190,7 @@ func (x *ShutdownRequest) String() string {
 func (*ShutdownRequest) ProtoMessage() {}
 
 func (x *ShutdownRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[31]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1854,7 +4203,7 @@ func (x *ShutdownRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShutdownRequest.ProtoReflect.Descriptor instead.
 func (*ShutdownRequest) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{31}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{67}
 }
 
 type ShutdownResponse struct {
@@ -1867,7 +4216,7 @@ type ShutdownResponse struct {
 
 func (x *ShutdownResponse) Reset() {
 	*x = ShutdownResponse{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[32]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1879,7 +4228,7 @@ func (x *ShutdownResponse) String() string {
 func (*ShutdownResponse) ProtoMessage() {}
 
 func (x *ShutdownResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[32]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1892,7 +4241,7 @@ func (x *ShutdownResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShutdownResponse.ProtoReflect.Descriptor instead.
 func (*ShutdownResponse) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{32}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{68}
 }
 
 func (x *ShutdownResponse) GetSuccess() bool {
@@ -1920,7 +4269,7 @@ type MountStatusUpdate struct {
 
 func (x *MountStatusUpdate) Reset() {
 	*x = MountStatusUpdate{}
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[33]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1932,7 +4281,7 @@ func (x *MountStatusUpdate) String() string {
 func (*MountStatusUpdate) ProtoMessage() {}
 
 func (x *MountStatusUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[33]
+	mi := &file_diskjockey_backend_proto_backend_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1945,7 +4294,7 @@ func (x *MountStatusUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MountStatusUpdate.ProtoReflect.Descriptor instead.
 func (*MountStatusUpdate) Descriptor() ([]byte, []int) {
-	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{33}
+	return file_diskjockey_backend_proto_backend_proto_rawDescGZIP(), []int{69}
 }
 
 func (x *MountStatusUpdate) GetMountId() uint32 {
@@ -1986,10 +4335,12 @@ const file_diskjockey_backend_proto_backend_proto_rawDesc = "" +
 	"\x04path\x18\x02 \x01(\tR\x04path\"P\n" +
 	"\x0fListDirResponse\x12'\n" +
 	"\x05files\x18\x01 \x03(\v2\x11.backend.FileInfoR\x05files\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"@\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"p\n" +
 	"\x0fReadFileRequest\x12\x19\n" +
 	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
-	"\x04path\x18\x02 \x01(\tR\x04path\"<\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x03R\x06length\"<\n" +
 	"\x10ReadFileResponse\x12\x12\n" +
 	"\x04data\x18\x01 \x01(\fR\x04data\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\"U\n" +
@@ -2012,6 +4363,12 @@ const file_diskjockey_backend_proto_backend_proto_rawDesc = "" +
 	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\"*\n" +
 	"\x12DeleteFileResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"N\n" +
+	"\rRenameRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\"&\n" +
+	"\x0eRenameResponse\x12\x14\n" +
 	"\x05error\x18\x01 \x01(\tR\x05error\"<\n" +
 	"\vStatRequest\x12\x19\n" +
 	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
@@ -2036,19 +4393,23 @@ const file_diskjockey_backend_proto_backend_proto_rawDesc = "" +
 	"\x11ListMountsRequest\"V\n" +
 	"\x12ListMountsResponse\x12*\n" +
 	"\x06mounts\x18\x01 \x03(\v2\x12.backend.MountInfoR\x06mounts\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\xca\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x9b\x02\n" +
 	"\tMountInfo\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
 	"\tdisk_type\x18\x02 \x01(\tR\bdiskType\x126\n" +
 	"\x06config\x18\x03 \x03(\v2\x1e.backend.MountInfo.ConfigEntryR\x06config\x12\x19\n" +
-	"\bmount_id\x18\x04 \x01(\rR\amountId\x1a9\n" +
+	"\bmount_id\x18\x04 \x01(\rR\amountId\x12,\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x14.backend.MountStatusR\x06status\x12!\n" +
+	"\fstatus_error\x18\x06 \x01(\tR\vstatusError\x1a9\n" +
 	"\vConfigEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"I\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"x\n" +
 	"\bFileInfo\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x15\n" +
-	"\x06is_dir\x18\x03 \x01(\bR\x05isDir\")\n" +
+	"\x06is_dir\x18\x03 \x01(\bR\x05isDir\x12\x19\n" +
+	"\bmod_time\x18\x04 \x01(\x03R\amodTime\x12\x12\n" +
+	"\x04etag\x18\x05 \x01(\tR\x04etag\")\n" +
 	"\fMountRequest\x12\x19\n" +
 	"\bmount_id\x18\x01 \x01(\rR\amountId\"%\n" +
 	"\rMountResponse\x12\x14\n" +
@@ -2070,7 +4431,137 @@ const file_diskjockey_backend_proto_backend_proto_rawDesc = "" +
 	"\x0eUnmountRequest\x12\x19\n" +
 	"\bmount_id\x18\x01 \x01(\rR\amountId\"'\n" +
 	"\x0fUnmountResponse\x12\x14\n" +
-	"\x05error\x18\x01 \x01(\tR\x05error\"\x11\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\".\n" +
+	"\x11OAuthStartRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\"h\n" +
+	"\x12OAuthStartResponse\x12\x19\n" +
+	"\bauth_url\x18\x01 \x01(\tR\aauthUrl\x12!\n" +
+	"\fredirect_url\x18\x02 \x01(\tR\vredirectUrl\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"l\n" +
+	"\x12OAuthFinishRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12'\n" +
+	"\x0ftimeout_seconds\x18\x03 \x01(\rR\x0etimeoutSeconds\"+\n" +
+	"\x13OAuthFinishResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"6\n" +
+	"\x17SubscribeChangesRequest\x12\x1b\n" +
+	"\tmount_ids\x18\x01 \x03(\rR\bmountIds\"0\n" +
+	"\x18SubscribeChangesResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"|\n" +
+	"\vChangeEvent\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12'\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x13.backend.ChangeKindR\x04kind\x12\x15\n" +
+	"\x06is_dir\x18\x04 \x01(\bR\x05isDir\"/\n" +
+	"\x12UploadQueueRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\"V\n" +
+	"\x13UploadQueueResponse\x12)\n" +
+	"\x05items\x18\x01 \x03(\v2\x13.backend.UploadItemR\x05items\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xe2\x01\n" +
+	"\n" +
+	"UploadItem\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12-\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x15.backend.UploadStatusR\x06status\x12\x1a\n" +
+	"\battempts\x18\x04 \x01(\rR\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x12\x18\n" +
+	"\aupdated\x18\x06 \x01(\x03R\aupdated\x12!\n" +
+	"\fnext_attempt\x18\a \x01(\x03R\vnextAttempt\"D\n" +
+	"\x13RetryUploadsRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"F\n" +
+	"\x14RetryUploadsResponse\x12\x18\n" +
+	"\aretried\x18\x01 \x01(\rR\aretried\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\";\n" +
+	"\n" +
+	"PinRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"#\n" +
+	"\vPinResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"=\n" +
+	"\fUnpinRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"%\n" +
+	"\rUnpinResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\",\n" +
+	"\x0fListPinsRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\"N\n" +
+	"\x10ListPinsResponse\x12$\n" +
+	"\x04pins\x18\x01 \x03(\v2\x10.backend.PinInfoR\x04pins\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x89\x01\n" +
+	"\aPinInfo\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x1c\n" +
+	"\trefreshed\x18\x04 \x01(\x03R\trefreshed\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\"1\n" +
+	"\x14ListConflictsRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\"b\n" +
+	"\x15ListConflictsResponse\x123\n" +
+	"\tconflicts\x18\x01 \x03(\v2\x15.backend.ConflictInfoR\tconflicts\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x8e\x02\n" +
+	"\fConflictInfo\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06policy\x18\x03 \x01(\tR\x06policy\x12;\n" +
+	"\n" +
+	"resolution\x18\x04 \x01(\x0e2\x1b.backend.ConflictResolutionR\n" +
+	"resolution\x12\x1b\n" +
+	"\tcopy_path\x18\x05 \x01(\tR\bcopyPath\x12%\n" +
+	"\x0eremote_deleted\x18\x06 \x01(\bR\rremoteDeleted\x12\x1a\n" +
+	"\bdetected\x18\a \x01(\x03R\bdetected\x12\x1a\n" +
+	"\bresolved\x18\b \x01(\x03R\bresolved\"\x84\x01\n" +
+	"\x16ResolveConflictRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12;\n" +
+	"\n" +
+	"resolution\x18\x03 \x01(\x0e2\x1b.backend.ConflictResolutionR\n" +
+	"resolution\"/\n" +
+	"\x17ResolveConflictResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"\x13\n" +
+	"\x11CacheStatsRequest\"\x8b\x01\n" +
+	"\x12CacheStatsResponse\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12\x19\n" +
+	"\bmax_size\x18\x02 \x01(\x03R\amaxSize\x120\n" +
+	"\x06mounts\x18\x03 \x03(\v2\x18.backend.CacheMountStatsR\x06mounts\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xca\x01\n" +
+	"\x0fCacheMountStats\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x18\n" +
+	"\aentries\x18\x03 \x01(\rR\aentries\x12!\n" +
+	"\fpinned_bytes\x18\x04 \x01(\x03R\vpinnedBytes\x12\x1f\n" +
+	"\vdirty_bytes\x18\x05 \x01(\x03R\n" +
+	"dirtyBytes\x12\x12\n" +
+	"\x04hits\x18\x06 \x01(\x04R\x04hits\x12\x16\n" +
+	"\x06misses\x18\a \x01(\x04R\x06misses\"A\n" +
+	"\x10ListCacheRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"\\\n" +
+	"\x11ListCacheResponse\x121\n" +
+	"\aentries\x18\x01 \x03(\v2\x17.backend.CacheEntryInfoR\aentries\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x9e\x01\n" +
+	"\x0eCacheEntryInfo\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06pinned\x18\x04 \x01(\bR\x06pinned\x12\x14\n" +
+	"\x05dirty\x18\x05 \x01(\bR\x05dirty\x12\x1b\n" +
+	"\tlast_used\x18\x06 \x01(\x03R\blastUsed\"i\n" +
+	"\x11PurgeCacheRequest\x12\x19\n" +
+	"\bmount_id\x18\x01 \x01(\rR\amountId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12%\n" +
+	"\x0einclude_pinned\x18\x03 \x01(\bR\rincludePinned\"Z\n" +
+	"\x12PurgeCacheResponse\x12\x18\n" +
+	"\aentries\x18\x01 \x01(\rR\aentries\x12\x14\n" +
+	"\x05bytes\x18\x02 \x01(\x03R\x05bytes\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"0\n" +
+	"\x13SetCacheSizeRequest\x12\x19\n" +
+	"\bmax_size\x18\x01 \x01(\x03R\amaxSize\"@\n" +
+	"\x14SetCacheSizeResponse\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x11\n" +
 	"\x0fShutdownRequest\"F\n" +
 	"\x10ShutdownResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
@@ -2078,7 +4569,7 @@ const file_diskjockey_backend_proto_backend_proto_rawDesc = "" +
 	"\x11MountStatusUpdate\x12\x19\n" +
 	"\bmount_id\x18\x01 \x01(\rR\amountId\x12,\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x14.backend.MountStatusR\x06status\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error*\xab\x05\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error*\xb8\v\n" +
 	"\vMessageType\x12\x10\n" +
 	"\fUNKNOWN_TYPE\x10\x00\x12\v\n" +
 	"\aCONNECT\x10\x01\x12\x14\n" +
@@ -2106,14 +4597,64 @@ const file_diskjockey_backend_proto_backend_proto_rawDesc = "" +
 	"\fSTAT_REQUEST\x10\x16\x12\x11\n" +
 	"\rSTAT_RESPONSE\x10\x17\x12\x17\n" +
 	"\x13DELETE_FILE_REQUEST\x10\x18\x12\x18\n" +
-	"\x14DELETE_FILE_RESPONSE\x10\x19\x12\x14\n" +
+	"\x14DELETE_FILE_RESPONSE\x10\x19\x12\x17\n" +
+	"\x13OAUTH_START_REQUEST\x10\x1a\x12\x18\n" +
+	"\x14OAUTH_START_RESPONSE\x10\x1b\x12\x18\n" +
+	"\x14OAUTH_FINISH_REQUEST\x10\x1c\x12\x19\n" +
+	"\x15OAUTH_FINISH_RESPONSE\x10\x1d\x12\x1d\n" +
+	"\x19SUBSCRIBE_CHANGES_REQUEST\x10\x1e\x12\x1e\n" +
+	"\x1aSUBSCRIBE_CHANGES_RESPONSE\x10\x1f\x12\x10\n" +
+	"\fCHANGE_EVENT\x10 \x12\x18\n" +
+	"\x14UPLOAD_QUEUE_REQUEST\x10!\x12\x19\n" +
+	"\x15UPLOAD_QUEUE_RESPONSE\x10\"\x12\x0f\n" +
+	"\vPIN_REQUEST\x10#\x12\x10\n" +
+	"\fPIN_RESPONSE\x10$\x12\x11\n" +
+	"\rUNPIN_REQUEST\x10%\x12\x12\n" +
+	"\x0eUNPIN_RESPONSE\x10&\x12\x15\n" +
+	"\x11LIST_PINS_REQUEST\x10'\x12\x16\n" +
+	"\x12LIST_PINS_RESPONSE\x10(\x12\x12\n" +
+	"\x0eRENAME_REQUEST\x10)\x12\x13\n" +
+	"\x0fRENAME_RESPONSE\x10*\x12\x1a\n" +
+	"\x16LIST_CONFLICTS_REQUEST\x10+\x12\x1b\n" +
+	"\x17LIST_CONFLICTS_RESPONSE\x10,\x12\x1c\n" +
+	"\x18RESOLVE_CONFLICT_REQUEST\x10-\x12\x1d\n" +
+	"\x19RESOLVE_CONFLICT_RESPONSE\x10.\x12\x17\n" +
+	"\x13CACHE_STATS_REQUEST\x10/\x12\x18\n" +
+	"\x14CACHE_STATS_RESPONSE\x100\x12\x16\n" +
+	"\x12LIST_CACHE_REQUEST\x101\x12\x17\n" +
+	"\x13LIST_CACHE_RESPONSE\x102\x12\x17\n" +
+	"\x13PURGE_CACHE_REQUEST\x103\x12\x18\n" +
+	"\x14PURGE_CACHE_RESPONSE\x104\x12\x1a\n" +
+	"\x16SET_CACHE_SIZE_REQUEST\x105\x12\x1b\n" +
+	"\x17SET_CACHE_SIZE_RESPONSE\x106\x12\x19\n" +
+	"\x15RETRY_UPLOADS_REQUEST\x107\x12\x1a\n" +
+	"\x16RETRY_UPLOADS_RESPONSE\x108\x12\x14\n" +
 	"\x10SHUTDOWN_REQUEST\x10c\x12\x15\n" +
-	"\x11SHUTDOWN_RESPONSE\x10d*A\n" +
+	"\x11SHUTDOWN_RESPONSE\x10d*]\n" +
+	"\n" +
+	"ChangeKind\x12\x12\n" +
+	"\x0eCHANGE_UNKNOWN\x10\x00\x12\x12\n" +
+	"\x0eCHANGE_CREATED\x10\x01\x12\x13\n" +
+	"\x0fCHANGE_MODIFIED\x10\x02\x12\x12\n" +
+	"\x0eCHANGE_DELETED\x10\x03*\x89\x01\n" +
+	"\fUploadStatus\x12\x12\n" +
+	"\x0eUPLOAD_UNKNOWN\x10\x00\x12\x12\n" +
+	"\x0eUPLOAD_PENDING\x10\x01\x12\x16\n" +
+	"\x12UPLOAD_IN_PROGRESS\x10\x02\x12\x11\n" +
+	"\rUPLOAD_SYNCED\x10\x03\x12\x11\n" +
+	"\rUPLOAD_FAILED\x10\x04\x12\x13\n" +
+	"\x0fUPLOAD_CONFLICT\x10\x05*n\n" +
+	"\x12ConflictResolution\x12\x17\n" +
+	"\x13CONFLICT_UNRESOLVED\x10\x00\x12\x16\n" +
+	"\x12CONFLICT_KEEP_BOTH\x10\x01\x12\x12\n" +
+	"\x0eCONFLICT_LOCAL\x10\x02\x12\x13\n" +
+	"\x0fCONFLICT_REMOTE\x10\x03*N\n" +
 	"\vMountStatus\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\v\n" +
 	"\aMOUNTED\x10\x01\x12\r\n" +
 	"\tUNMOUNTED\x10\x02\x12\t\n" +
-	"\x05ERROR\x10\x03B*Z(diskjockey-backend/proto/backend;backendb\x06proto3"
+	"\x05ERROR\x10\x03\x12\v\n" +
+	"\aOFFLINE\x10\x04B*Z(diskjockey-backend/proto/backend;backendb\x06proto3"
 
 var (
 	file_diskjockey_backend_proto_backend_proto_rawDescOnce sync.Once
@@ -2127,65 +4668,114 @@ func file_diskjockey_backend_proto_backend_proto_rawDescGZIP() []byte {
 	return file_diskjockey_backend_proto_backend_proto_rawDescData
 }
 
-var file_diskjockey_backend_proto_backend_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_diskjockey_backend_proto_backend_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_diskjockey_backend_proto_backend_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_diskjockey_backend_proto_backend_proto_msgTypes = make([]protoimpl.MessageInfo, 72)
 var file_diskjockey_backend_proto_backend_proto_goTypes = []any{
-	(MessageType)(0),              // 0: backend.MessageType
-	(MountStatus)(0),              // 1: backend.MountStatus
-	(ConnectRequest_Role)(0),      // 2: backend.ConnectRequest.Role
-	(*Message)(nil),               // 3: backend.Message
-	(*HandshakeRequest)(nil),      // 4: backend.HandshakeRequest
-	(*HandshakeResponse)(nil),     // 5: backend.HandshakeResponse
-	(*ListDirRequest)(nil),        // 6: backend.ListDirRequest
-	(*ListDirResponse)(nil),       // 7: backend.ListDirResponse
-	(*ReadFileRequest)(nil),       // 8: backend.ReadFileRequest
-	(*ReadFileResponse)(nil),      // 9: backend.ReadFileResponse
-	(*WriteFileRequest)(nil),      // 10: backend.WriteFileRequest
-	(*WriteFileResponse)(nil),     // 11: backend.WriteFileResponse
-	(*ConnectRequest)(nil),        // 12: backend.ConnectRequest
-	(*ConnectResponse)(nil),       // 13: backend.ConnectResponse
-	(*DeleteFileRequest)(nil),     // 14: backend.DeleteFileRequest
-	(*DeleteFileResponse)(nil),    // 15: backend.DeleteFileResponse
-	(*StatRequest)(nil),           // 16: backend.StatRequest
-	(*StatResponse)(nil),          // 17: backend.StatResponse
-	(*ListDiskTypesRequest)(nil),  // 18: backend.ListDiskTypesRequest
-	(*ListDiskTypesResponse)(nil), // 19: backend.ListDiskTypesResponse
-	(*DiskTypeInfo)(nil),          // 20: backend.DiskTypeInfo
-	(*ConfigField)(nil),           // 21: backend.ConfigField
-	(*ListMountsRequest)(nil),     // 22: backend.ListMountsRequest
-	(*ListMountsResponse)(nil),    // 23: backend.ListMountsResponse
-	(*MountInfo)(nil),             // 24: backend.MountInfo
-	(*FileInfo)(nil),              // 25: backend.FileInfo
-	(*MountRequest)(nil),          // 26: backend.MountRequest
-	(*MountResponse)(nil),         // 27: backend.MountResponse
-	(*CreateMountRequest)(nil),    // 28: backend.CreateMountRequest
-	(*CreateMountResponse)(nil),   // 29: backend.CreateMountResponse
-	(*DeleteMountRequest)(nil),    // 30: backend.DeleteMountRequest
-	(*DeleteMountResponse)(nil),   // 31: backend.DeleteMountResponse
-	(*UnmountRequest)(nil),        // 32: backend.UnmountRequest
-	(*UnmountResponse)(nil),       // 33: backend.UnmountResponse
-	(*ShutdownRequest)(nil),       // 34: backend.ShutdownRequest
-	(*ShutdownResponse)(nil),      // 35: backend.ShutdownResponse
-	(*MountStatusUpdate)(nil),     // 36: backend.MountStatusUpdate
-	nil,                           // 37: backend.MountInfo.ConfigEntry
-	nil,                           // 38: backend.CreateMountRequest.ConfigEntry
+	(MessageType)(0),                 // 0: backend.MessageType
+	(ChangeKind)(0),                  // 1: backend.ChangeKind
+	(UploadStatus)(0),                // 2: backend.UploadStatus
+	(ConflictResolution)(0),          // 3: backend.ConflictResolution
+	(MountStatus)(0),                 // 4: backend.MountStatus
+	(ConnectRequest_Role)(0),         // 5: backend.ConnectRequest.Role
+	(*Message)(nil),                  // 6: backend.Message
+	(*HandshakeRequest)(nil),         // 7: backend.HandshakeRequest
+	(*HandshakeResponse)(nil),        // 8: backend.HandshakeResponse
+	(*ListDirRequest)(nil),           // 9: backend.ListDirRequest
+	(*ListDirResponse)(nil),          // 10: backend.ListDirResponse
+	(*ReadFileRequest)(nil),          // 11: backend.ReadFileRequest
+	(*ReadFileResponse)(nil),         // 12: backend.ReadFileResponse
+	(*WriteFileRequest)(nil),         // 13: backend.WriteFileRequest
+	(*WriteFileResponse)(nil),        // 14: backend.WriteFileResponse
+	(*ConnectRequest)(nil),           // 15: backend.ConnectRequest
+	(*ConnectResponse)(nil),          // 16: backend.ConnectResponse
+	(*DeleteFileRequest)(nil),        // 17: backend.DeleteFileRequest
+	(*DeleteFileResponse)(nil),       // 18: backend.DeleteFileResponse
+	(*RenameRequest)(nil),            // 19: backend.RenameRequest
+	(*RenameResponse)(nil),           // 20: backend.RenameResponse
+	(*StatRequest)(nil),              // 21: backend.StatRequest
+	(*StatResponse)(nil),             // 22: backend.StatResponse
+	(*ListDiskTypesRequest)(nil),     // 23: backend.ListDiskTypesRequest
+	(*ListDiskTypesResponse)(nil),    // 24: backend.ListDiskTypesResponse
+	(*DiskTypeInfo)(nil),             // 25: backend.DiskTypeInfo
+	(*ConfigField)(nil),              // 26: backend.ConfigField
+	(*ListMountsRequest)(nil),        // 27: backend.ListMountsRequest
+	(*ListMountsResponse)(nil),       // 28: backend.ListMountsResponse
+	(*MountInfo)(nil),                // 29: backend.MountInfo
+	(*FileInfo)(nil),                 // 30: backend.FileInfo
+	(*MountRequest)(nil),             // 31: backend.MountRequest
+	(*MountResponse)(nil),            // 32: backend.MountResponse
+	(*CreateMountRequest)(nil),       // 33: backend.CreateMountRequest
+	(*CreateMountResponse)(nil),      // 34: backend.CreateMountResponse
+	(*DeleteMountRequest)(nil),       // 35: backend.DeleteMountRequest
+	(*DeleteMountResponse)(nil),      // 36: backend.DeleteMountResponse
+	(*UnmountRequest)(nil),           // 37: backend.UnmountRequest
+	(*UnmountResponse)(nil),          // 38: backend.UnmountResponse
+	(*OAuthStartRequest)(nil),        // 39: backend.OAuthStartRequest
+	(*OAuthStartResponse)(nil),       // 40: backend.OAuthStartResponse
+	(*OAuthFinishRequest)(nil),       // 41: backend.OAuthFinishRequest
+	(*OAuthFinishResponse)(nil),      // 42: backend.OAuthFinishResponse
+	(*SubscribeChangesRequest)(nil),  // 43: backend.SubscribeChangesRequest
+	(*SubscribeChangesResponse)(nil), // 44: backend.SubscribeChangesResponse
+	(*ChangeEvent)(nil),              // 45: backend.ChangeEvent
+	(*UploadQueueRequest)(nil),       // 46: backend.UploadQueueRequest
+	(*UploadQueueResponse)(nil),      // 47: backend.UploadQueueResponse
+	(*UploadItem)(nil),               // 48: backend.UploadItem
+	(*RetryUploadsRequest)(nil),      // 49: backend.RetryUploadsRequest
+	(*RetryUploadsResponse)(nil),     // 50: backend.RetryUploadsResponse
+	(*PinRequest)(nil),               // 51: backend.PinRequest
+	(*PinResponse)(nil),              // 52: backend.PinResponse
+	(*UnpinRequest)(nil),             // 53: backend.UnpinRequest
+	(*UnpinResponse)(nil),            // 54: backend.UnpinResponse
+	(*ListPinsRequest)(nil),          // 55: backend.ListPinsRequest
+	(*ListPinsResponse)(nil),         // 56: backend.ListPinsResponse
+	(*PinInfo)(nil),                  // 57: backend.PinInfo
+	(*ListConflictsRequest)(nil),     // 58: backend.ListConflictsRequest
+	(*ListConflictsResponse)(nil),    // 59: backend.ListConflictsResponse
+	(*ConflictInfo)(nil),             // 60: backend.ConflictInfo
+	(*ResolveConflictRequest)(nil),   // 61: backend.ResolveConflictRequest
+	(*ResolveConflictResponse)(nil),  // 62: backend.ResolveConflictResponse
+	(*CacheStatsRequest)(nil),        // 63: backend.CacheStatsRequest
+	(*CacheStatsResponse)(nil),       // 64: backend.CacheStatsResponse
+	(*CacheMountStats)(nil),          // 65: backend.CacheMountStats
+	(*ListCacheRequest)(nil),         // 66: backend.ListCacheRequest
+	(*ListCacheResponse)(nil),        // 67: backend.ListCacheResponse
+	(*CacheEntryInfo)(nil),           // 68: backend.CacheEntryInfo
+	(*PurgeCacheRequest)(nil),        // 69: backend.PurgeCacheRequest
+	(*PurgeCacheResponse)(nil),       // 70: backend.PurgeCacheResponse
+	(*SetCacheSizeRequest)(nil),      // 71: backend.SetCacheSizeRequest
+	(*SetCacheSizeResponse)(nil),     // 72: backend.SetCacheSizeResponse
+	(*ShutdownRequest)(nil),          // 73: backend.ShutdownRequest
+	(*ShutdownResponse)(nil),         // 74: backend.ShutdownResponse
+	(*MountStatusUpdate)(nil),        // 75: backend.MountStatusUpdate
+	nil,                              // 76: backend.MountInfo.ConfigEntry
+	nil,                              // 77: backend.CreateMountRequest.ConfigEntry
 }
 var file_diskjockey_backend_proto_backend_proto_depIdxs = []int32{
 	0,  // 0: backend.Message.type:type_name -> backend.MessageType
-	25, // 1: backend.ListDirResponse.files:type_name -> backend.FileInfo
-	2,  // 2: backend.ConnectRequest.role:type_name -> backend.ConnectRequest.Role
-	25, // 3: backend.StatResponse.info:type_name -> backend.FileInfo
-	20, // 4: backend.ListDiskTypesResponse.disk_types:type_name -> backend.DiskTypeInfo
-	21, // 5: backend.DiskTypeInfo.config_fields:type_name -> backend.ConfigField
-	24, // 6: backend.ListMountsResponse.mounts:type_name -> backend.MountInfo
-	37, // 7: backend.MountInfo.config:type_name -> backend.MountInfo.ConfigEntry
-	38, // 8: backend.CreateMountRequest.config:type_name -> backend.CreateMountRequest.ConfigEntry
-	1,  // 9: backend.MountStatusUpdate.status:type_name -> backend.MountStatus
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	30, // 1: backend.ListDirResponse.files:type_name -> backend.FileInfo
+	5,  // 2: backend.ConnectRequest.role:type_name -> backend.ConnectRequest.Role
+	30, // 3: backend.StatResponse.info:type_name -> backend.FileInfo
+	25, // 4: backend.ListDiskTypesResponse.disk_types:type_name -> backend.DiskTypeInfo
+	26, // 5: backend.DiskTypeInfo.config_fields:type_name -> backend.ConfigField
+	29, // 6: backend.ListMountsResponse.mounts:type_name -> backend.MountInfo
+	76, // 7: backend.MountInfo.config:type_name -> backend.MountInfo.ConfigEntry
+	4,  // 8: backend.MountInfo.status:type_name -> backend.MountStatus
+	77, // 9: backend.CreateMountRequest.config:type_name -> backend.CreateMountRequest.ConfigEntry
+	1,  // 10: backend.ChangeEvent.kind:type_name -> backend.ChangeKind
+	48, // 11: backend.UploadQueueResponse.items:type_name -> backend.UploadItem
+	2,  // 12: backend.UploadItem.status:type_name -> backend.UploadStatus
+	57, // 13: backend.ListPinsResponse.pins:type_name -> backend.PinInfo
+	60, // 14: backend.ListConflictsResponse.conflicts:type_name -> backend.ConflictInfo
+	3,  // 15: backend.ConflictInfo.resolution:type_name -> backend.ConflictResolution
+	3,  // 16: backend.ResolveConflictRequest.resolution:type_name -> backend.ConflictResolution
+	65, // 17: backend.CacheStatsResponse.mounts:type_name -> backend.CacheMountStats
+	68, // 18: backend.ListCacheResponse.entries:type_name -> backend.CacheEntryInfo
+	4,  // 19: backend.MountStatusUpdate.status:type_name -> backend.MountStatus
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_diskjockey_backend_proto_backend_proto_init() }
@@ -2198,8 +4788,8 @@ func file_diskjockey_backend_proto_backend_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_diskjockey_backend_proto_backend_proto_rawDesc), len(file_diskjockey_backend_proto_backend_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   36,
+			NumEnums:      6,
+			NumMessages:   72,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
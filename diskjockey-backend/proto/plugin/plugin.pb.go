@@ -0,0 +1,1994 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: diskjockey-backend/proto/plugin.proto
+
+// Protocol between the backend and out-of-process disk type plugins.
+//
+// A plugin is an executable in the plugins directory of the config dir. The
+// backend starts it and exchanges Messages over its stdin and stdout, each
+// framed like the socket protocol: a 4-byte big-endian length followed by
+// the serialized Message. Anything the plugin writes to stderr is logged.
+//
+// The backend sends requests and the plugin answers each with the matching
+// response type and the id of the request. Requests can be answered in any
+// order. A plugin answers requests it doesn't know with UNKNOWN_TYPE and an
+// Error payload. When stdin is closed the plugin should exit.
+
+package plugin
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MessageType int32
+
+const (
+	MessageType_UNKNOWN_TYPE        MessageType = 0
+	MessageType_HANDSHAKE_REQUEST   MessageType = 1
+	MessageType_HANDSHAKE_RESPONSE  MessageType = 2
+	MessageType_OPEN_REQUEST        MessageType = 3
+	MessageType_OPEN_RESPONSE       MessageType = 4
+	MessageType_CLOSE_REQUEST       MessageType = 5
+	MessageType_CLOSE_RESPONSE      MessageType = 6
+	MessageType_RECONNECT_REQUEST   MessageType = 7
+	MessageType_RECONNECT_RESPONSE  MessageType = 8
+	MessageType_LIST_REQUEST        MessageType = 9
+	MessageType_LIST_RESPONSE       MessageType = 10
+	MessageType_STAT_REQUEST        MessageType = 11
+	MessageType_STAT_RESPONSE       MessageType = 12
+	MessageType_READ_REQUEST        MessageType = 13
+	MessageType_READ_RESPONSE       MessageType = 14
+	MessageType_READ_RANGE_REQUEST  MessageType = 15
+	MessageType_READ_RANGE_RESPONSE MessageType = 16
+	MessageType_WRITE_REQUEST       MessageType = 17
+	MessageType_WRITE_RESPONSE      MessageType = 18
+	MessageType_DELETE_REQUEST      MessageType = 19
+	MessageType_DELETE_RESPONSE     MessageType = 20
+	MessageType_RENAME_REQUEST      MessageType = 21
+	MessageType_RENAME_RESPONSE     MessageType = 22
+)
+
+// Enum value maps for MessageType.
+var (
+	MessageType_name = map[int32]string{
+		0:  "UNKNOWN_TYPE",
+		1:  "HANDSHAKE_REQUEST",
+		2:  "HANDSHAKE_RESPONSE",
+		3:  "OPEN_REQUEST",
+		4:  "OPEN_RESPONSE",
+		5:  "CLOSE_REQUEST",
+		6:  "CLOSE_RESPONSE",
+		7:  "RECONNECT_REQUEST",
+		8:  "RECONNECT_RESPONSE",
+		9:  "LIST_REQUEST",
+		10: "LIST_RESPONSE",
+		11: "STAT_REQUEST",
+		12: "STAT_RESPONSE",
+		13: "READ_REQUEST",
+		14: "READ_RESPONSE",
+		15: "READ_RANGE_REQUEST",
+		16: "READ_RANGE_RESPONSE",
+		17: "WRITE_REQUEST",
+		18: "WRITE_RESPONSE",
+		19: "DELETE_REQUEST",
+		20: "DELETE_RESPONSE",
+		21: "RENAME_REQUEST",
+		22: "RENAME_RESPONSE",
+	}
+	MessageType_value = map[string]int32{
+		"UNKNOWN_TYPE":        0,
+		"HANDSHAKE_REQUEST":   1,
+		"HANDSHAKE_RESPONSE":  2,
+		"OPEN_REQUEST":        3,
+		"OPEN_RESPONSE":       4,
+		"CLOSE_REQUEST":       5,
+		"CLOSE_RESPONSE":      6,
+		"RECONNECT_REQUEST":   7,
+		"RECONNECT_RESPONSE":  8,
+		"LIST_REQUEST":        9,
+		"LIST_RESPONSE":       10,
+		"STAT_REQUEST":        11,
+		"STAT_RESPONSE":       12,
+		"READ_REQUEST":        13,
+		"READ_RESPONSE":       14,
+		"READ_RANGE_REQUEST":  15,
+		"READ_RANGE_RESPONSE": 16,
+		"WRITE_REQUEST":       17,
+		"WRITE_RESPONSE":      18,
+		"DELETE_REQUEST":      19,
+		"DELETE_RESPONSE":     20,
+		"RENAME_REQUEST":      21,
+		"RENAME_RESPONSE":     22,
+	}
+)
+
+func (x MessageType) Enum() *MessageType {
+	p := new(MessageType)
+	*p = x
+	return p
+}
+
+func (x MessageType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MessageType) Descriptor() protoreflect.EnumDescriptor {
+	return file_diskjockey_backend_proto_plugin_proto_enumTypes[0].Descriptor()
+}
+
+func (MessageType) Type() protoreflect.EnumType {
+	return &file_diskjockey_backend_proto_plugin_proto_enumTypes[0]
+}
+
+func (x MessageType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MessageType.Descriptor instead.
+func (MessageType) EnumDescriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{0}
+}
+
+// Error kinds the backend maps to its own errors, so a missing file or an
+// unreachable remote is handled like it is for the built-in disk types
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_UNKNOWN         ErrorCode = 0
+	ErrorCode_ERROR_NOT_EXIST       ErrorCode = 1
+	ErrorCode_ERROR_EXIST           ErrorCode = 2
+	ErrorCode_ERROR_PERMISSION      ErrorCode = 3
+	ErrorCode_ERROR_IS_DIR          ErrorCode = 4
+	ErrorCode_ERROR_NOT_DIR         ErrorCode = 5
+	ErrorCode_ERROR_NOT_EMPTY       ErrorCode = 6
+	ErrorCode_ERROR_OFFLINE         ErrorCode = 7 // The remote can't be reached, the mount goes offline
+	ErrorCode_ERROR_CONFLICT        ErrorCode = 8
+	ErrorCode_ERROR_READ_ONLY       ErrorCode = 9
+	ErrorCode_ERROR_INVALID_PATH    ErrorCode = 10
+	ErrorCode_ERROR_REAUTH_REQUIRED ErrorCode = 11
+	ErrorCode_ERROR_UNSUPPORTED     ErrorCode = 12
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0:  "ERROR_UNKNOWN",
+		1:  "ERROR_NOT_EXIST",
+		2:  "ERROR_EXIST",
+		3:  "ERROR_PERMISSION",
+		4:  "ERROR_IS_DIR",
+		5:  "ERROR_NOT_DIR",
+		6:  "ERROR_NOT_EMPTY",
+		7:  "ERROR_OFFLINE",
+		8:  "ERROR_CONFLICT",
+		9:  "ERROR_READ_ONLY",
+		10: "ERROR_INVALID_PATH",
+		11: "ERROR_REAUTH_REQUIRED",
+		12: "ERROR_UNSUPPORTED",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_UNKNOWN":         0,
+		"ERROR_NOT_EXIST":       1,
+		"ERROR_EXIST":           2,
+		"ERROR_PERMISSION":      3,
+		"ERROR_IS_DIR":          4,
+		"ERROR_NOT_DIR":         5,
+		"ERROR_NOT_EMPTY":       6,
+		"ERROR_OFFLINE":         7,
+		"ERROR_CONFLICT":        8,
+		"ERROR_READ_ONLY":       9,
+		"ERROR_INVALID_PATH":    10,
+		"ERROR_REAUTH_REQUIRED": 11,
+		"ERROR_UNSUPPORTED":     12,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_diskjockey_backend_proto_plugin_proto_enumTypes[1].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_diskjockey_backend_proto_plugin_proto_enumTypes[1]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{1}
+}
+
+// Message wrapper that contains the actual message and its type
+type Message struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // Chosen by the backend for a request, copied to its response
+	Type          MessageType            `protobuf:"varint,2,opt,name=type,proto3,enum=plugin.MessageType" json:"type,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"` // Serialized message data
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Message) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Message) GetType() MessageType {
+	if x != nil {
+		return x.Type
+	}
+	return MessageType_UNKNOWN_TYPE
+}
+
+func (x *Message) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ErrorCode              `protobuf:"varint,1,opt,name=code,proto3,enum=plugin.ErrorCode" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Error) GetCode() ErrorCode {
+	if x != nil {
+		return x.Code
+	}
+	return ErrorCode_ERROR_UNKNOWN
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Sent first after the plugin started
+type HandshakeRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ProtocolVersion uint32                 `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HandshakeRequest) Reset() {
+	*x = HandshakeRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeRequest) ProtoMessage() {}
+
+func (x *HandshakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeRequest.ProtoReflect.Descriptor instead.
+func (*HandshakeRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HandshakeRequest) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+// Describes the disk type the plugin implements
+type HandshakeResponse struct {
+	state           protoimpl.MessageState  `protogen:"open.v1"`
+	ProtocolVersion uint32                  `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	Name            string                  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"` // Disk type name, must not clash with another disk type
+	Description     string                  `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Config          map[string]*ConfigField `protobuf:"bytes,4,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Error           *Error                  `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HandshakeResponse) Reset() {
+	*x = HandshakeResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeResponse) ProtoMessage() {}
+
+func (x *HandshakeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeResponse.ProtoReflect.Descriptor instead.
+func (*HandshakeResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HandshakeResponse) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *HandshakeResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HandshakeResponse) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *HandshakeResponse) GetConfig() map[string]*ConfigField {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *HandshakeResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ConfigField struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // e.g. "string", "integer", "bool"
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigField) Reset() {
+	*x = ConfigField{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigField) ProtoMessage() {}
+
+func (x *ConfigField) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigField.ProtoReflect.Descriptor instead.
+func (*ConfigField) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ConfigField) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ConfigField) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ConfigField) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+type Mount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Host          string                 `protobuf:"bytes,4,opt,name=host,proto3" json:"host,omitempty"`
+	Port          int32                  `protobuf:"varint,5,opt,name=port,proto3" json:"port,omitempty"`
+	Username      string                 `protobuf:"bytes,6,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,7,opt,name=password,proto3" json:"password,omitempty"`
+	AccessToken   string                 `protobuf:"bytes,8,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	Share         string                 `protobuf:"bytes,9,opt,name=share,proto3" json:"share,omitempty"`
+	Options       map[string]string      `protobuf:"bytes,10,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mount) Reset() {
+	*x = Mount{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mount) ProtoMessage() {}
+
+func (x *Mount) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mount.ProtoReflect.Descriptor instead.
+func (*Mount) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Mount) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Mount) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Mount) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Mount) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Mount) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Mount) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Mount) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Mount) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *Mount) GetShare() string {
+	if x != nil {
+		return x.Share
+	}
+	return ""
+}
+
+func (x *Mount) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+// Creates a backend for a mount, the equivalent of DiskType.New
+type OpenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mount         *Mount                 `protobuf:"bytes,1,opt,name=mount,proto3" json:"mount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenRequest) Reset() {
+	*x = OpenRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenRequest) ProtoMessage() {}
+
+func (x *OpenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenRequest.ProtoReflect.Descriptor instead.
+func (*OpenRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OpenRequest) GetMount() *Mount {
+	if x != nil {
+		return x.Mount
+	}
+	return nil
+}
+
+type OpenResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Handle uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"` // Identifies the backend in later requests
+	// Optional operations the backend implements. The backend falls back to
+	// listing the parent directory, copying and reading whole files otherwise.
+	Stat          bool   `protobuf:"varint,2,opt,name=stat,proto3" json:"stat,omitempty"`
+	Rename        bool   `protobuf:"varint,3,opt,name=rename,proto3" json:"rename,omitempty"`
+	ReadRange     bool   `protobuf:"varint,4,opt,name=read_range,json=readRange,proto3" json:"read_range,omitempty"`
+	Error         *Error `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenResponse) Reset() {
+	*x = OpenResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenResponse) ProtoMessage() {}
+
+func (x *OpenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenResponse.ProtoReflect.Descriptor instead.
+func (*OpenResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *OpenResponse) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+func (x *OpenResponse) GetStat() bool {
+	if x != nil {
+		return x.Stat
+	}
+	return false
+}
+
+func (x *OpenResponse) GetRename() bool {
+	if x != nil {
+		return x.Rename
+	}
+	return false
+}
+
+func (x *OpenResponse) GetReadRange() bool {
+	if x != nil {
+		return x.ReadRange
+	}
+	return false
+}
+
+func (x *OpenResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type CloseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseRequest) Reset() {
+	*x = CloseRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseRequest) ProtoMessage() {}
+
+func (x *CloseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseRequest.ProtoReflect.Descriptor instead.
+func (*CloseRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CloseRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+type CloseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseResponse) Reset() {
+	*x = CloseResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseResponse) ProtoMessage() {}
+
+func (x *CloseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseResponse.ProtoReflect.Descriptor instead.
+func (*CloseResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CloseResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ReconnectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconnectRequest) Reset() {
+	*x = ReconnectRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconnectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconnectRequest) ProtoMessage() {}
+
+func (x *ReconnectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconnectRequest.ProtoReflect.Descriptor instead.
+func (*ReconnectRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ReconnectRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+type ReconnectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconnectResponse) Reset() {
+	*x = ReconnectResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconnectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconnectResponse) ProtoMessage() {}
+
+func (x *ReconnectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconnectResponse.ProtoReflect.Descriptor instead.
+func (*ReconnectResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReconnectResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type FileInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	IsDir         bool                   `protobuf:"varint,3,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	ModTime       int64                  `protobuf:"varint,4,opt,name=mod_time,json=modTime,proto3" json:"mod_time,omitempty"` // Unix time in nanoseconds, 0 if unknown
+	Etag          string                 `protobuf:"bytes,5,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FileInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileInfo) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+func (x *FileInfo) GetModTime() int64 {
+	if x != nil {
+		return x.ModTime
+	}
+	return 0
+}
+
+func (x *FileInfo) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+func (x *ListRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	Error         *Error                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListResponse) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ListResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type StatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatRequest) Reset() {
+	*x = StatRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatRequest) ProtoMessage() {}
+
+func (x *StatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatRequest.ProtoReflect.Descriptor instead.
+func (*StatRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StatRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+func (x *StatRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type StatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *FileInfo              `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Error         *Error                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatResponse) Reset() {
+	*x = StatResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatResponse) ProtoMessage() {}
+
+func (x *StatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatResponse.ProtoReflect.Descriptor instead.
+func (*StatResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StatResponse) GetInfo() *FileInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *StatResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ReadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadRequest) Reset() {
+	*x = ReadRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadRequest) ProtoMessage() {}
+
+func (x *ReadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadRequest.ProtoReflect.Descriptor instead.
+func (*ReadRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReadRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+func (x *ReadRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ReadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Error         *Error                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadResponse) Reset() {
+	*x = ReadResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadResponse) ProtoMessage() {}
+
+func (x *ReadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadResponse.ProtoReflect.Descriptor instead.
+func (*ReadResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReadResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ReadResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type ReadRangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length        int64                  `protobuf:"varint,4,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadRangeRequest) Reset() {
+	*x = ReadRangeRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadRangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadRangeRequest) ProtoMessage() {}
+
+func (x *ReadRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadRangeRequest.ProtoReflect.Descriptor instead.
+func (*ReadRangeRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ReadRangeRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+func (x *ReadRangeRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReadRangeRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadRangeRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+type ReadRangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Error         *Error                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadRangeResponse) Reset() {
+	*x = ReadRangeResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadRangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadRangeResponse) ProtoMessage() {}
+
+func (x *ReadRangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadRangeResponse.ProtoReflect.Descriptor instead.
+func (*ReadRangeResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ReadRangeResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ReadRangeResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type WriteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteRequest) Reset() {
+	*x = WriteRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteRequest) ProtoMessage() {}
+
+func (x *WriteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteRequest.ProtoReflect.Descriptor instead.
+func (*WriteRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *WriteRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+func (x *WriteRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *WriteRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type WriteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteResponse) Reset() {
+	*x = WriteResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteResponse) ProtoMessage() {}
+
+func (x *WriteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteResponse.ProtoReflect.Descriptor instead.
+func (*WriteResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WriteResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+func (x *DeleteRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DeleteResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+type RenameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Handle        uint64                 `protobuf:"varint,1,opt,name=handle,proto3" json:"handle,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameRequest) Reset() {
+	*x = RenameRequest{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameRequest) ProtoMessage() {}
+
+func (x *RenameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameRequest.ProtoReflect.Descriptor instead.
+func (*RenameRequest) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RenameRequest) GetHandle() uint64 {
+	if x != nil {
+		return x.Handle
+	}
+	return 0
+}
+
+func (x *RenameRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *RenameRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type RenameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         *Error                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameResponse) Reset() {
+	*x = RenameResponse{}
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameResponse) ProtoMessage() {}
+
+func (x *RenameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_diskjockey_backend_proto_plugin_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameResponse.ProtoReflect.Descriptor instead.
+func (*RenameResponse) Descriptor() ([]byte, []int) {
+	return file_diskjockey_backend_proto_plugin_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RenameResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+var File_diskjockey_backend_proto_plugin_proto protoreflect.FileDescriptor
+
+const file_diskjockey_backend_proto_plugin_proto_rawDesc = "" +
+	"\n" +
+	"%diskjockey-backend/proto/plugin.proto\x12\x06plugin\"\\\n" +
+	"\aMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12'\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x13.plugin.MessageTypeR\x04type\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\"H\n" +
+	"\x05Error\x12%\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x11.plugin.ErrorCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"=\n" +
+	"\x10HandshakeRequest\x12)\n" +
+	"\x10protocol_version\x18\x01 \x01(\rR\x0fprotocolVersion\"\xa8\x02\n" +
+	"\x11HandshakeResponse\x12)\n" +
+	"\x10protocol_version\x18\x01 \x01(\rR\x0fprotocolVersion\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12=\n" +
+	"\x06config\x18\x04 \x03(\v2%.plugin.HandshakeResponse.ConfigEntryR\x06config\x12#\n" +
+	"\x05error\x18\x05 \x01(\v2\r.plugin.ErrorR\x05error\x1aN\n" +
+	"\vConfigEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12)\n" +
+	"\x05value\x18\x02 \x01(\v2\x13.plugin.ConfigFieldR\x05value:\x028\x01\"_\n" +
+	"\vConfigField\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\brequired\x18\x03 \x01(\bR\brequired\"\xca\x02\n" +
+	"\x05Mount\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x12\n" +
+	"\x04host\x18\x04 \x01(\tR\x04host\x12\x12\n" +
+	"\x04port\x18\x05 \x01(\x05R\x04port\x12\x1a\n" +
+	"\busername\x18\x06 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\a \x01(\tR\bpassword\x12!\n" +
+	"\faccess_token\x18\b \x01(\tR\vaccessToken\x12\x14\n" +
+	"\x05share\x18\t \x01(\tR\x05share\x124\n" +
+	"\aoptions\x18\n" +
+	" \x03(\v2\x1a.plugin.Mount.OptionsEntryR\aoptions\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"2\n" +
+	"\vOpenRequest\x12#\n" +
+	"\x05mount\x18\x01 \x01(\v2\r.plugin.MountR\x05mount\"\x96\x01\n" +
+	"\fOpenResponse\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\x12\x12\n" +
+	"\x04stat\x18\x02 \x01(\bR\x04stat\x12\x16\n" +
+	"\x06rename\x18\x03 \x01(\bR\x06rename\x12\x1d\n" +
+	"\n" +
+	"read_range\x18\x04 \x01(\bR\treadRange\x12#\n" +
+	"\x05error\x18\x05 \x01(\v2\r.plugin.ErrorR\x05error\"&\n" +
+	"\fCloseRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\"4\n" +
+	"\rCloseResponse\x12#\n" +
+	"\x05error\x18\x01 \x01(\v2\r.plugin.ErrorR\x05error\"*\n" +
+	"\x10ReconnectRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\"8\n" +
+	"\x11ReconnectResponse\x12#\n" +
+	"\x05error\x18\x01 \x01(\v2\r.plugin.ErrorR\x05error\"x\n" +
+	"\bFileInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x15\n" +
+	"\x06is_dir\x18\x03 \x01(\bR\x05isDir\x12\x19\n" +
+	"\bmod_time\x18\x04 \x01(\x03R\amodTime\x12\x12\n" +
+	"\x04etag\x18\x05 \x01(\tR\x04etag\"9\n" +
+	"\vListRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"[\n" +
+	"\fListResponse\x12&\n" +
+	"\x05files\x18\x01 \x03(\v2\x10.plugin.FileInfoR\x05files\x12#\n" +
+	"\x05error\x18\x02 \x01(\v2\r.plugin.ErrorR\x05error\"9\n" +
+	"\vStatRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"Y\n" +
+	"\fStatResponse\x12$\n" +
+	"\x04info\x18\x01 \x01(\v2\x10.plugin.FileInfoR\x04info\x12#\n" +
+	"\x05error\x18\x02 \x01(\v2\r.plugin.ErrorR\x05error\"9\n" +
+	"\vReadRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"G\n" +
+	"\fReadResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12#\n" +
+	"\x05error\x18\x02 \x01(\v2\r.plugin.ErrorR\x05error\"n\n" +
+	"\x10ReadRangeRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x03R\x06length\"L\n" +
+	"\x11ReadRangeResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12#\n" +
+	"\x05error\x18\x02 \x01(\v2\r.plugin.ErrorR\x05error\"N\n" +
+	"\fWriteRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"4\n" +
+	"\rWriteResponse\x12#\n" +
+	"\x05error\x18\x01 \x01(\v2\r.plugin.ErrorR\x05error\";\n" +
+	"\rDeleteRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"5\n" +
+	"\x0eDeleteResponse\x12#\n" +
+	"\x05error\x18\x01 \x01(\v2\r.plugin.ErrorR\x05error\"K\n" +
+	"\rRenameRequest\x12\x16\n" +
+	"\x06handle\x18\x01 \x01(\x04R\x06handle\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\"5\n" +
+	"\x0eRenameResponse\x12#\n" +
+	"\x05error\x18\x01 \x01(\v2\r.plugin.ErrorR\x05error*\xe2\x03\n" +
+	"\vMessageType\x12\x10\n" +
+	"\fUNKNOWN_TYPE\x10\x00\x12\x15\n" +
+	"\x11HANDSHAKE_REQUEST\x10\x01\x12\x16\n" +
+	"\x12HANDSHAKE_RESPONSE\x10\x02\x12\x10\n" +
+	"\fOPEN_REQUEST\x10\x03\x12\x11\n" +
+	"\rOPEN_RESPONSE\x10\x04\x12\x11\n" +
+	"\rCLOSE_REQUEST\x10\x05\x12\x12\n" +
+	"\x0eCLOSE_RESPONSE\x10\x06\x12\x15\n" +
+	"\x11RECONNECT_REQUEST\x10\a\x12\x16\n" +
+	"\x12RECONNECT_RESPONSE\x10\b\x12\x10\n" +
+	"\fLIST_REQUEST\x10\t\x12\x11\n" +
+	"\rLIST_RESPONSE\x10\n" +
+	"\x12\x10\n" +
+	"\fSTAT_REQUEST\x10\v\x12\x11\n" +
+	"\rSTAT_RESPONSE\x10\f\x12\x10\n" +
+	"\fREAD_REQUEST\x10\r\x12\x11\n" +
+	"\rREAD_RESPONSE\x10\x0e\x12\x16\n" +
+	"\x12READ_RANGE_REQUEST\x10\x0f\x12\x17\n" +
+	"\x13READ_RANGE_RESPONSE\x10\x10\x12\x11\n" +
+	"\rWRITE_REQUEST\x10\x11\x12\x12\n" +
+	"\x0eWRITE_RESPONSE\x10\x12\x12\x12\n" +
+	"\x0eDELETE_REQUEST\x10\x13\x12\x13\n" +
+	"\x0fDELETE_RESPONSE\x10\x14\x12\x12\n" +
+	"\x0eRENAME_REQUEST\x10\x15\x12\x13\n" +
+	"\x0fRENAME_RESPONSE\x10\x16*\x9a\x02\n" +
+	"\tErrorCode\x12\x11\n" +
+	"\rERROR_UNKNOWN\x10\x00\x12\x13\n" +
+	"\x0fERROR_NOT_EXIST\x10\x01\x12\x0f\n" +
+	"\vERROR_EXIST\x10\x02\x12\x14\n" +
+	"\x10ERROR_PERMISSION\x10\x03\x12\x10\n" +
+	"\fERROR_IS_DIR\x10\x04\x12\x11\n" +
+	"\rERROR_NOT_DIR\x10\x05\x12\x13\n" +
+	"\x0fERROR_NOT_EMPTY\x10\x06\x12\x11\n" +
+	"\rERROR_OFFLINE\x10\a\x12\x12\n" +
+	"\x0eERROR_CONFLICT\x10\b\x12\x13\n" +
+	"\x0fERROR_READ_ONLY\x10\t\x12\x16\n" +
+	"\x12ERROR_INVALID_PATH\x10\n" +
+	"\x12\x19\n" +
+	"\x15ERROR_REAUTH_REQUIRED\x10\v\x12\x15\n" +
+	"\x11ERROR_UNSUPPORTED\x10\fB(Z&diskjockey-backend/proto/plugin;pluginb\x06proto3"
+
+var (
+	file_diskjockey_backend_proto_plugin_proto_rawDescOnce sync.Once
+	file_diskjockey_backend_proto_plugin_proto_rawDescData []byte
+)
+
+func file_diskjockey_backend_proto_plugin_proto_rawDescGZIP() []byte {
+	file_diskjockey_backend_proto_plugin_proto_rawDescOnce.Do(func() {
+		file_diskjockey_backend_proto_plugin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_diskjockey_backend_proto_plugin_proto_rawDesc), len(file_diskjockey_backend_proto_plugin_proto_rawDesc)))
+	})
+	return file_diskjockey_backend_proto_plugin_proto_rawDescData
+}
+
+var file_diskjockey_backend_proto_plugin_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_diskjockey_backend_proto_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_diskjockey_backend_proto_plugin_proto_goTypes = []any{
+	(MessageType)(0),          // 0: plugin.MessageType
+	(ErrorCode)(0),            // 1: plugin.ErrorCode
+	(*Message)(nil),           // 2: plugin.Message
+	(*Error)(nil),             // 3: plugin.Error
+	(*HandshakeRequest)(nil),  // 4: plugin.HandshakeRequest
+	(*HandshakeResponse)(nil), // 5: plugin.HandshakeResponse
+	(*ConfigField)(nil),       // 6: plugin.ConfigField
+	(*Mount)(nil),             // 7: plugin.Mount
+	(*OpenRequest)(nil),       // 8: plugin.OpenRequest
+	(*OpenResponse)(nil),      // 9: plugin.OpenResponse
+	(*CloseRequest)(nil),      // 10: plugin.CloseRequest
+	(*CloseResponse)(nil),     // 11: plugin.CloseResponse
+	(*ReconnectRequest)(nil),  // 12: plugin.ReconnectRequest
+	(*ReconnectResponse)(nil), // 13: plugin.ReconnectResponse
+	(*FileInfo)(nil),          // 14: plugin.FileInfo
+	(*ListRequest)(nil),       // 15: plugin.ListRequest
+	(*ListResponse)(nil),      // 16: plugin.ListResponse
+	(*StatRequest)(nil),       // 17: plugin.StatRequest
+	(*StatResponse)(nil),      // 18: plugin.StatResponse
+	(*ReadRequest)(nil),       // 19: plugin.ReadRequest
+	(*ReadResponse)(nil),      // 20: plugin.ReadResponse
+	(*ReadRangeRequest)(nil),  // 21: plugin.ReadRangeRequest
+	(*ReadRangeResponse)(nil), // 22: plugin.ReadRangeResponse
+	(*WriteRequest)(nil),      // 23: plugin.WriteRequest
+	(*WriteResponse)(nil),     // 24: plugin.WriteResponse
+	(*DeleteRequest)(nil),     // 25: plugin.DeleteRequest
+	(*DeleteResponse)(nil),    // 26: plugin.DeleteResponse
+	(*RenameRequest)(nil),     // 27: plugin.RenameRequest
+	(*RenameResponse)(nil),    // 28: plugin.RenameResponse
+	nil,                       // 29: plugin.HandshakeResponse.ConfigEntry
+	nil,                       // 30: plugin.Mount.OptionsEntry
+}
+var file_diskjockey_backend_proto_plugin_proto_depIdxs = []int32{
+	0,  // 0: plugin.Message.type:type_name -> plugin.MessageType
+	1,  // 1: plugin.Error.code:type_name -> plugin.ErrorCode
+	29, // 2: plugin.HandshakeResponse.config:type_name -> plugin.HandshakeResponse.ConfigEntry
+	3,  // 3: plugin.HandshakeResponse.error:type_name -> plugin.Error
+	30, // 4: plugin.Mount.options:type_name -> plugin.Mount.OptionsEntry
+	7,  // 5: plugin.OpenRequest.mount:type_name -> plugin.Mount
+	3,  // 6: plugin.OpenResponse.error:type_name -> plugin.Error
+	3,  // 7: plugin.CloseResponse.error:type_name -> plugin.Error
+	3,  // 8: plugin.ReconnectResponse.error:type_name -> plugin.Error
+	14, // 9: plugin.ListResponse.files:type_name -> plugin.FileInfo
+	3,  // 10: plugin.ListResponse.error:type_name -> plugin.Error
+	14, // 11: plugin.StatResponse.info:type_name -> plugin.FileInfo
+	3,  // 12: plugin.StatResponse.error:type_name -> plugin.Error
+	3,  // 13: plugin.ReadResponse.error:type_name -> plugin.Error
+	3,  // 14: plugin.ReadRangeResponse.error:type_name -> plugin.Error
+	3,  // 15: plugin.WriteResponse.error:type_name -> plugin.Error
+	3,  // 16: plugin.DeleteResponse.error:type_name -> plugin.Error
+	3,  // 17: plugin.RenameResponse.error:type_name -> plugin.Error
+	6,  // 18: plugin.HandshakeResponse.ConfigEntry.value:type_name -> plugin.ConfigField
+	19, // [19:19] is the sub-list for method output_type
+	19, // [19:19] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_diskjockey_backend_proto_plugin_proto_init() }
+func file_diskjockey_backend_proto_plugin_proto_init() {
+	if File_diskjockey_backend_proto_plugin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_diskjockey_backend_proto_plugin_proto_rawDesc), len(file_diskjockey_backend_proto_plugin_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   29,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_diskjockey_backend_proto_plugin_proto_goTypes,
+		DependencyIndexes: file_diskjockey_backend_proto_plugin_proto_depIdxs,
+		EnumInfos:         file_diskjockey_backend_proto_plugin_proto_enumTypes,
+		MessageInfos:      file_diskjockey_backend_proto_plugin_proto_msgTypes,
+	}.Build()
+	File_diskjockey_backend_proto_plugin_proto = out.File
+	file_diskjockey_backend_proto_plugin_proto_goTypes = nil
+	file_diskjockey_backend_proto_plugin_proto_depIdxs = nil
+}
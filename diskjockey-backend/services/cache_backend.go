@@ -0,0 +1,605 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// defaultListTTL is how long a directory listing is reused when the mount
+// doesn't set cache_list_ttl
+const defaultListTTL = 10 * time.Second
+
+// cachingBackend serves reads from the CacheManager while the remote version
+// of a file is unchanged, and reuses directory listings for a short time.
+// Mounts enable it with the cache option. Without it, only pinned files are
+// served from the cache, which they also are while the remote is unreachable.
+//
+// With the write_back option, writes are stored in the cache and queued with
+// the UploadService instead of waiting for the remote. Until uploaded, such
+// files are read from the cache and added to listings of their directory.
+//
+// Mounts that set conflict_policy or write_back detect conflicts: the remote
+// version of every file read is remembered as the base of later changes to
+// it. Changes are only uploaded over the base version, otherwise the mount's
+// ConflictPolicy decides what happens. Other mounts don't look up versions
+// unless they cache, so reads and writes cost no extra requests.
+//
+// Mounts of read-only disk types only cache reads, their changes are refused
+// with ErrReadOnly whatever the options say.
+type cachingBackend struct {
+	types.Backend
+	mountID   uint32
+	cache     *cache.CacheManager
+	uploads   *UploadService   // nil unless writing back
+	conflicts *ConflictService // nil unless detecting conflicts
+	policy    ConflictPolicy
+	caching   bool // Whether files that were read are cached
+	readOnly  bool // Whether the disk type refuses changes
+	listTTL   time.Duration
+
+	mu    sync.Mutex
+	lists map[string]cachedListing // directory path -> listing
+	bases map[string]string        // path -> remote version last read or written
+}
+
+type cachedListing struct {
+	infos   []types.FileInfo
+	expires time.Time
+}
+
+// newCachingBackend wraps backend according to the cache, write_back and
+// conflict_policy options of the mount. It returns backend unchanged if there
+// is no cache.
+func newCachingBackend(backend types.Backend, model *models.Mount, cacheManager *cache.CacheManager, uploads *UploadService, conflicts *ConflictService, readOnly bool) types.Backend {
+	if cacheManager == nil {
+		return backend
+	}
+	if !model.BoolOption("write_back") || readOnly {
+		uploads = nil
+	}
+	if (model.Option("conflict_policy") == "" && uploads == nil) || readOnly {
+		conflicts = nil
+	}
+	caching := model.BoolOption("cache")
+	var ttl time.Duration
+	if caching {
+		ttl = defaultListTTL
+		if n, err := strconv.Atoi(model.Option("cache_list_ttl")); err == nil && n >= 0 {
+			ttl = time.Duration(n) * time.Second
+		}
+	}
+	return &cachingBackend{
+		Backend:   backend,
+		mountID:   uint32(model.ID),
+		cache:     cacheManager,
+		uploads:   uploads,
+		conflicts: conflicts,
+		policy:    conflictPolicy(model),
+		caching:   caching,
+		readOnly:  readOnly,
+		listTTL:   ttl,
+		lists:     make(map[string]cachedListing),
+		bases:     make(map[string]string),
+	}
+}
+
+func (c *cachingBackend) Unwrap() types.Backend {
+	return c.Backend
+}
+
+func (c *cachingBackend) List(path string) ([]types.FileInfo, error) {
+	c.mu.Lock()
+	listing, ok := c.lists[path]
+	c.mu.Unlock()
+	if ok && time.Now().Before(listing.expires) {
+		return c.withDirty(path, listing.infos), nil
+	}
+
+	infos, err := c.Backend.List(path)
+	if err != nil {
+		return nil, err
+	}
+	if c.listTTL > 0 {
+		c.mu.Lock()
+		c.lists[path] = cachedListing{infos: infos, expires: time.Now().Add(c.listTTL)}
+		c.mu.Unlock()
+	}
+	return c.withDirty(path, infos), nil
+}
+
+// withDirty returns a copy of a directory listing with the files that are
+// waiting to be uploaded to it added or updated.
+func (c *cachingBackend) withDirty(dir string, infos []types.FileInfo) []types.FileInfo {
+	infos = append([]types.FileInfo(nil), infos...)
+	if c.uploads == nil {
+		return infos
+	}
+	for _, entry := range c.cache.DirtyFiles() {
+		mountID, p, ok := cache.SplitCachePath(entry.Path)
+		if !ok || mountID != c.mountID || path.Dir(p) != dir {
+			continue
+		}
+		info := dirtyFileInfo(entry)
+		replaced := false
+		for i := range infos {
+			if infos[i].Name == info.Name {
+				infos[i] = info
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// dirtyFileInfo describes a file that is waiting to be uploaded.
+func dirtyFileInfo(entry cache.CacheEntry) types.FileInfo {
+	return types.FileInfo{
+		Name:    path.Base(entry.Path),
+		Size:    entry.Size,
+		ModTime: entry.LastUsed,
+	}
+}
+
+// Read returns the cached contents if the cache holds the current remote
+// version of the file, otherwise it reads the file and caches it. Pinned
+// files are read from the cache when the remote can't be reached.
+func (c *cachingBackend) Read(path string) ([]byte, error) {
+	entry, cached := c.cache.GetFile(cache.CachePath(c.mountID, path))
+	if cached && entry.Dirty {
+		// Newer than the remote, which may not even have it yet
+		if data, ok := c.cache.Get(c.mountID, path); ok {
+			c.cache.CountRead(c.mountID, true)
+			return data, nil
+		}
+	}
+	pinned := cached && entry.Pinned
+	if !c.caching && !pinned {
+		if c.conflicts == nil {
+			return c.Backend.Read(path)
+		}
+		version := c.version(path)
+		data, err := c.Backend.Read(path)
+		if err == nil {
+			c.setBase(path, version)
+		}
+		return data, err
+	}
+
+	info, err := c.Stat(path)
+	if err != nil {
+		if pinned && !errors.Is(err, fs.ErrNotExist) {
+			if data, ok := c.cache.Get(c.mountID, path); ok {
+				c.cache.CountRead(c.mountID, true)
+				return data, nil
+			}
+		}
+		return c.Backend.Read(path)
+	}
+	version, ok := remoteVersion(info)
+	if !ok {
+		// Without a version there is no way to tell a cached copy is current
+		c.cache.CountRead(c.mountID, false)
+		return c.Backend.Read(path)
+	}
+
+	if cached && entry.Version == version {
+		if data, ok := c.cache.Get(c.mountID, path); ok {
+			c.cache.CountRead(c.mountID, true)
+			c.setBase(path, version)
+			return data, nil
+		}
+	}
+	c.cache.CountRead(c.mountID, false)
+
+	data, err := c.Backend.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	c.setBase(path, version)
+	// Put keeps pinned files pinned
+	if err := c.cache.Put(c.mountID, path, data, version); err != nil {
+		fmt.Fprintf(os.Stderr, "[CachingBackend] Failed to cache %s: %v\n", path, err)
+	}
+	return data, nil
+}
+
+// ReadRange reads part of a file. Files that are cached, or are going to be,
+// are read in full and the range is taken from the local copy.
+func (c *cachingBackend) ReadRange(path string, offset, length int64) ([]byte, error) {
+	entry, cached := c.cache.GetFile(cache.CachePath(c.mountID, path))
+	if c.caching || (cached && (entry.Dirty || entry.Pinned)) {
+		data, err := c.Read(path)
+		if err != nil {
+			return nil, err
+		}
+		return types.SliceRange(data, offset, length), nil
+	}
+	return types.ReadRange(c.Backend, path, offset, length)
+}
+
+// Write uploads the file, or when writing back stores it in the cache and
+// queues the upload. When the upload conflicts with a remote change and the
+// local contents weren't kept in a conflicted copy, an error wrapping
+// types.ErrConflict is returned.
+func (c *cachingBackend) Write(path string, data []byte) error {
+	if c.readOnly {
+		return &fs.PathError{Op: "write", Path: path, Err: types.ErrReadOnly}
+	}
+	base := c.base(path)
+	if c.uploads == nil {
+		_, err := c.push(path, data, base)
+		var conflict *conflictError
+		if errors.As(err, &conflict) {
+			if conflict.record.Resolution == metadata.ConflictUnresolved {
+				// Held in the cache until the conflict is resolved
+				if err := c.cache.PutDirty(c.mountID, path, data, base); err != nil {
+					return err
+				}
+				c.dropListings(path)
+				return err
+			}
+			c.dropLocal(path, data)
+			if conflict.record.Resolution == metadata.ConflictKeptBoth {
+				return nil
+			}
+			return err
+		}
+		c.Invalidate(path)
+		return err
+	}
+	if err := c.cache.PutDirty(c.mountID, path, data, base); err != nil {
+		return err
+	}
+	c.dropListings(path)
+	return c.uploads.Enqueue(c.mountID, path)
+}
+
+// Delete deletes the file on the remote and cancels any upload of it. A file
+// that was never uploaded only exists in the cache.
+func (c *cachingBackend) Delete(path string) error {
+	if c.readOnly {
+		return &fs.PathError{Op: "delete", Path: path, Err: types.ErrReadOnly}
+	}
+	c.forgetBases(path)
+	if c.uploads == nil {
+		if err := c.Backend.Delete(path); err != nil {
+			c.Invalidate(path)
+			return err
+		}
+		c.cache.Discard(c.mountID, path)
+		c.dropListings(path)
+		return nil
+	}
+
+	waiting, err := c.uploads.Cancel(c.mountID, path)
+	if err != nil {
+		return err
+	}
+	c.cache.Discard(c.mountID, path)
+	c.dropListings(path)
+	if waiting {
+		if _, err := types.Stat(c.Backend, path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+	}
+	return c.Backend.Delete(path)
+}
+
+// Rename renames the file or directory on the remote and moves its cached
+// contents along. When writing back, files that weren't uploaded yet are
+// queued again under their new path.
+func (c *cachingBackend) Rename(from, to string) error {
+	if c.readOnly {
+		return &fs.PathError{Op: "rename", Path: from, Err: types.ErrReadOnly}
+	}
+	c.forgetBases(from)
+	c.forgetBases(to)
+	defer func() {
+		c.dropListings(from)
+		c.dropListings(to)
+	}()
+	if c.uploads == nil {
+		if err := types.Rename(c.Backend, from, to); err != nil {
+			c.Invalidate(from)
+			return err
+		}
+		c.move(from, to)
+		return nil
+	}
+
+	dirty := false
+	for _, entry := range c.cache.Files(c.mountID, from) {
+		dirty = dirty || entry.Dirty
+	}
+	// Keep the uploader from writing to the old path meanwhile
+	if _, err := c.uploads.Cancel(c.mountID, from); err != nil {
+		return err
+	}
+	err := types.Rename(c.Backend, from, to)
+	if errors.Is(err, fs.ErrNotExist) && dirty {
+		// Only exists in the cache so far
+		err = nil
+	}
+	queued := from
+	if err == nil {
+		c.move(from, to)
+		queued = to
+	}
+	for _, entry := range c.cache.Files(c.mountID, queued) {
+		if _, p, ok := cache.SplitCachePath(entry.Path); ok && entry.Dirty {
+			if err := c.uploads.Enqueue(c.mountID, p); err != nil {
+				return err
+			}
+		}
+	}
+	return err
+}
+
+// move moves cached contents after a rename, dropping them if that fails.
+func (c *cachingBackend) move(from, to string) {
+	if err := c.cache.Move(c.mountID, from, to); err != nil {
+		fmt.Fprintf(os.Stderr, "[CachingBackend] Failed to move cached %s to %s: %v\n", from, to, err)
+		c.cache.Invalidate(c.mountID, from)
+		c.cache.Invalidate(c.mountID, to)
+	}
+}
+
+// Stat uses the backend's own Stat if it has one, otherwise it looks the
+// path up in the cached listing of its parent. Files waiting to be uploaded
+// are described from the cache.
+func (c *cachingBackend) Stat(path string) (types.FileInfo, error) {
+	if c.uploads != nil {
+		if entry, ok := c.cache.GetFile(cache.CachePath(c.mountID, path)); ok && entry.Dirty {
+			return dirtyFileInfo(*entry), nil
+		}
+	}
+	if s, ok := c.Backend.(types.Stater); ok {
+		return s.Stat(path)
+	}
+	// Hide this Stat, so types.Stat falls back to listing through c
+	return types.Stat(struct{ types.Backend }{c}, path)
+}
+
+// Invalidate drops the cached contents of a path and everything below it,
+// and the listings that contain them.
+func (c *cachingBackend) Invalidate(p string) {
+	c.cache.Invalidate(c.mountID, p)
+	c.dropListings(p)
+}
+
+// dropListings drops the cached listings of a path, its parent and
+// everything below it.
+func (c *cachingBackend) dropListings(p string) {
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	parent := path.Dir(p)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for dir := range c.lists {
+		if dir == p || dir == parent || strings.HasPrefix(dir, prefix) {
+			delete(c.lists, dir)
+		}
+	}
+}
+
+// version returns the current remote version of a file, or "" if it's
+// unknown or the file doesn't exist.
+func (c *cachingBackend) version(p string) string {
+	info, err := types.Stat(c.Backend, p)
+	if err != nil {
+		return ""
+	}
+	version, _ := remoteVersion(info)
+	return version
+}
+
+// base returns the remote version local changes to a file are based on:
+// the one dirty contents were written over, or else the one last read or
+// written. It's "" if unknown.
+func (c *cachingBackend) base(p string) string {
+	entry, cached := c.cache.GetFile(cache.CachePath(c.mountID, p))
+	if cached && entry.Dirty {
+		return entry.Base
+	}
+	c.mu.Lock()
+	base, ok := c.bases[p]
+	c.mu.Unlock()
+	if !ok && cached {
+		base = entry.Version
+	}
+	return base
+}
+
+func (c *cachingBackend) setBase(p string, version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version == "" {
+		delete(c.bases, p)
+	} else {
+		c.bases[p] = version
+	}
+}
+
+// forgetBases forgets the bases of a path and everything below it.
+func (c *cachingBackend) forgetBases(p string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for base := range c.bases {
+		if isSubpath(base, p) {
+			delete(c.bases, base)
+		}
+	}
+}
+
+// push uploads local contents of a file that are based on the remote version
+// base, and returns the version written. If the remote has another version
+// by now, the contents are handled according to the conflict policy and a
+// *conflictError is returned unless they were written over the remote file
+// anyway. Without a base, or without conflict detection, the remote file is
+// overwritten. The version written is "" unless the mount caches or detects
+// conflicts, which are the only reasons to look it up.
+func (c *cachingBackend) push(p string, data []byte, base string) (string, error) {
+	if base != "" && c.conflicts != nil {
+		if remote := c.version(p); remote != base {
+			return c.conflict(p, data, base, remote)
+		}
+	}
+	if err := c.writeOver(p, data, base); errors.Is(err, types.ErrConflict) && c.conflicts != nil {
+		// Changed since it was checked above
+		return c.conflict(p, data, base, c.version(p))
+	} else if err != nil {
+		return "", err
+	}
+	if !c.caching && c.conflicts == nil {
+		return "", nil
+	}
+	version := c.version(p)
+	c.setBase(p, version)
+	return version, nil
+}
+
+// writeOver writes a file, making the remote refuse the write if the file no
+// longer has the base version where it supports that.
+func (c *cachingBackend) writeOver(p string, data []byte, base string) error {
+	if w, ok := c.Backend.(types.ConditionalWriter); ok && strings.HasPrefix(base, "etag:") && c.conflicts != nil {
+		return w.WriteIfMatch(p, data, strings.TrimPrefix(base, "etag:"))
+	}
+	return c.Backend.Write(p, data)
+}
+
+// conflict applies the conflict policy to local contents of a file that are
+// based on a version the remote no longer has, and records the conflict.
+func (c *cachingBackend) conflict(p string, data []byte, base, remote string) (string, error) {
+	now := time.Now()
+	record := metadata.ConflictRecord{
+		MountID:  c.mountID,
+		Path:     p,
+		Base:     base,
+		Remote:   remote,
+		Policy:   string(c.policy),
+		Detected: now,
+	}
+	switch c.policy {
+	case ConflictLocalWins:
+		record.Resolution = metadata.ConflictLocalWon
+	case ConflictRemoteWins:
+		record.Resolution = metadata.ConflictRemoteWon
+	case ConflictKeepBoth:
+		record.Resolution = metadata.ConflictKeptBoth
+		if remote == "" {
+			// Deleted on the remote, so there is nothing to keep
+			record.Resolution = metadata.ConflictLocalWon
+		}
+	}
+
+	version := ""
+	switch record.Resolution {
+	case metadata.ConflictLocalWon:
+		if err := c.Backend.Write(p, data); err != nil {
+			return "", err
+		}
+		version = c.version(p)
+		c.setBase(p, version)
+	case metadata.ConflictKeptBoth:
+		record.CopyPath = conflictedCopyPath(p, now)
+		if err := c.Backend.Write(record.CopyPath, data); err != nil {
+			return "", err
+		}
+		c.setBase(p, "")
+		c.dropListings(record.CopyPath)
+	}
+	if record.Resolution != metadata.ConflictUnresolved {
+		record.Resolved = now
+	}
+	c.conflicts.add(record)
+
+	conflict := &conflictError{record: record}
+	fmt.Fprintf(os.Stderr, "[CachingBackend] Conflict on mount %d: %v\n", c.mountID, conflict)
+	if record.Resolution == metadata.ConflictLocalWon {
+		return version, nil
+	}
+	return "", conflict
+}
+
+// dropLocal drops the local contents of a file that weren't written to the
+// remote because of a conflict, unless they were changed again since.
+func (c *cachingBackend) dropLocal(p string, data []byte) {
+	sum := sha256.Sum256(data)
+	if c.cache.MarkClean(c.mountID, p, hex.EncodeToString(sum[:]), "") {
+		c.cache.Invalidate(c.mountID, p)
+	}
+	c.dropListings(p)
+}
+
+// resolve resolves a conflict that was held for the user and returns the
+// conflicted copy the local contents were written to, if any.
+func (c *cachingBackend) resolve(record metadata.ConflictRecord, resolution metadata.ConflictResolution) (string, error) {
+	p := record.Path
+	data, ok := c.cache.Get(c.mountID, p)
+	if entry, cached := c.cache.GetFile(cache.CachePath(c.mountID, p)); !ok || !cached || !entry.Dirty {
+		return "", fmt.Errorf("local changes to %s are gone", p)
+	}
+
+	copyPath := ""
+	switch resolution {
+	case metadata.ConflictLocalWon:
+		// Upload over whatever the remote has now
+		c.cache.SetBase(c.mountID, p, record.Remote)
+		if c.uploads != nil {
+			return "", c.uploads.Enqueue(c.mountID, p)
+		}
+		version, err := c.push(p, data, record.Remote)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		c.cache.MarkClean(c.mountID, p, hex.EncodeToString(sum[:]), version)
+		c.dropListings(p)
+		return "", nil
+	case metadata.ConflictKeptBoth:
+		copyPath = conflictedCopyPath(p, time.Now())
+		if err := c.Backend.Write(copyPath, data); err != nil {
+			return "", err
+		}
+		c.dropListings(copyPath)
+	}
+	if c.uploads != nil {
+		if _, err := c.uploads.Cancel(c.mountID, p); err != nil {
+			return "", err
+		}
+	}
+	c.dropLocal(p, data)
+	return copyPath, nil
+}
+
+// remoteVersion identifies the version of a file from its metadata: the
+// ETag if the remote provides one, otherwise its size and modification time.
+func remoteVersion(info types.FileInfo) (string, bool) {
+	if info.IsDir {
+		return "", false
+	}
+	if info.ETag != "" {
+		return "etag:" + info.ETag, true
+	}
+	if info.ModTime.IsZero() {
+		return "", false
+	}
+	return fmt.Sprintf("%d:%d", info.Size, info.ModTime.UnixNano()), true
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/disktypes"
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// statCountingBackend counts the Stat requests sent to the remote
+type statCountingBackend struct {
+	*disktypes.MemoryBackend
+	stats atomic.Int32
+}
+
+func (b *statCountingBackend) Stat(p string) (types.FileInfo, error) {
+	b.stats.Add(1)
+	return b.MemoryBackend.Stat(p)
+}
+
+func newTestCachingBackend(t *testing.T, options map[string]string) (*cachingBackend, *statCountingBackend) {
+	return newTestCachingBackendOf(t, options, false)
+}
+
+func newTestCachingBackendOf(t *testing.T, options map[string]string, readOnly bool) (*cachingBackend, *statCountingBackend) {
+	t.Helper()
+	store := newTestMetadataStore(t)
+	remote := &statCountingBackend{MemoryBackend: newTestMemoryBackend(t, nil)}
+	model := &models.Mount{Options: options}
+	model.ID = 1
+	backend := newCachingBackend(remote, model, cache.NewCacheManager(t.TempDir(), 0, store), nil, NewConflictService(store), readOnly)
+	c, ok := backend.(*cachingBackend)
+	if !ok {
+		t.Fatalf("newCachingBackend returned %T", backend)
+	}
+	return c, remote
+}
+
+func TestCachingBackendStatsOnlyWhenNeeded(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]string
+		stats   bool
+	}{
+		{name: "plain", stats: false},
+		{name: "cache", options: map[string]string{"cache": "true"}, stats: true},
+		{name: "conflict policy", options: map[string]string{"conflict_policy": "keep_both"}, stats: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, remote := newTestCachingBackend(t, tt.options)
+			writeFiles(t, remote.MemoryBackend, map[string]string{"/a.txt": "a"})
+
+			if _, err := c.Read("/a.txt"); err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+			if err := c.Write("/a.txt", []byte("b")); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if stats := remote.stats.Load(); (stats > 0) != tt.stats {
+				t.Errorf("read and write sent %d Stat requests", stats)
+			}
+		})
+	}
+}
+
+func TestCachingBackendConflictPolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		remote string // contents of /a.txt afterwards
+		copy   bool   // whether a conflicted copy holds the local contents
+		err    bool
+	}{
+		// Without a policy there is no conflict detection, the write wins
+		{policy: "", remote: "local"},
+		{policy: "keep_both", remote: "remote", copy: true},
+		{policy: "local_wins", remote: "local"},
+		{policy: "remote_wins", remote: "remote", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			options := map[string]string{}
+			if tt.policy != "" {
+				options["conflict_policy"] = tt.policy
+			}
+			c, remote := newTestCachingBackend(t, options)
+			writeFiles(t, remote.MemoryBackend, map[string]string{"/a.txt": "base"})
+			if _, err := c.Read("/a.txt"); err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+			// Someone else changes the file after it was read
+			writeFiles(t, remote.MemoryBackend, map[string]string{"/a.txt": "remote"})
+
+			err := c.Write("/a.txt", []byte("local"))
+			if (err != nil) != tt.err {
+				t.Fatalf("Write error = %v, want error %v", err, tt.err)
+			}
+			if err != nil && !errors.Is(err, types.ErrConflict) {
+				t.Errorf("Write error = %v, want ErrConflict", err)
+			}
+			if data, _ := remote.Read("/a.txt"); string(data) != tt.remote {
+				t.Errorf("remote file holds %q, want %q", data, tt.remote)
+			}
+
+			infos, err := remote.List("/")
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			var names []string
+			for _, info := range infos {
+				names = append(names, info.Name)
+			}
+			sort.Strings(names)
+			copied := len(names) == 2 && strings.Contains(names[0], "conflicted copy")
+			if copied != tt.copy {
+				t.Errorf("remote holds %v, want a conflicted copy %v", names, tt.copy)
+			}
+			if copied {
+				if data, _ := remote.Read("/" + names[0]); string(data) != "local" {
+					t.Errorf("conflicted copy holds %q", data)
+				}
+			}
+		})
+	}
+}
+
+func TestCachingBackendPushVersion(t *testing.T) {
+	c, _ := newTestCachingBackend(t, nil)
+	version, err := c.push("/a.txt", []byte("a"), "")
+	if err != nil || version != "" {
+		t.Fatalf("push = %q, %v, want no version looked up", version, err)
+	}
+
+	c, remote := newTestCachingBackend(t, map[string]string{"cache": "true"})
+	version, err = c.push("/a.txt", []byte("a"), "")
+	if err != nil {
+		t.Fatalf("push failed: %v", err)
+	}
+	info, _ := remote.Stat("/a.txt")
+	if want, _ := remoteVersion(info); version != want {
+		t.Errorf("push = %q, want the version written %q", version, want)
+	}
+}
+
+func TestCachingBackendRefusesChangesWhenReadOnly(t *testing.T) {
+	options := map[string]string{"cache": "true", "write_back": "true", "conflict_policy": "ask"}
+	c, remote := newTestCachingBackendOf(t, options, true)
+	writeFiles(t, remote.MemoryBackend, map[string]string{"/a.txt": "a"})
+	if c.conflicts != nil {
+		t.Errorf("read-only mount detects conflicts")
+	}
+
+	changes := map[string]func() error{
+		"write":  func() error { return c.Write("/b.txt", []byte("b")) },
+		"delete": func() error { return c.Delete("/a.txt") },
+		"rename": func() error { return c.Rename("/a.txt", "/b.txt") },
+	}
+	for op, change := range changes {
+		if err := change(); !errors.Is(err, types.ErrReadOnly) {
+			t.Errorf("%s error = %v, want ErrReadOnly", op, err)
+		}
+	}
+	if data, err := c.Read("/a.txt"); err != nil || string(data) != "a" {
+		t.Errorf("Read = %q, %v", data, err)
+	}
+	if _, ok := c.cache.Get(c.mountID, "/b.txt"); ok {
+		t.Errorf("refused write was cached")
+	}
+}
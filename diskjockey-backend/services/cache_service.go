@@ -0,0 +1,69 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// CacheService reports on the local file cache and manages it at runtime.
+type CacheService struct {
+	configService *ConfigService
+	cacheManager  *cache.CacheManager
+}
+
+// NewCacheService creates a CacheService for cacheManager, persisting
+// settings with config.
+func NewCacheService(config *ConfigService, cacheManager *cache.CacheManager) *CacheService {
+	return &CacheService{configService: config, cacheManager: cacheManager}
+}
+
+// Stats returns the statistics of every mount with cached contents, the
+// total size of the cache and the size it's kept under.
+func (s *CacheService) Stats() ([]cache.MountStats, int64, int64) {
+	return s.cacheManager.Stats(), s.cacheManager.Size(), s.cacheManager.MaxSize()
+}
+
+// Entries returns the cached entries of a path in a mount and everything
+// below it, or of the path in every mount if mountID is 0, ordered by path.
+func (s *CacheService) Entries(mountID uint32, filePath string) ([]cache.CacheEntry, error) {
+	clean, err := types.CleanPath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	entries := s.cacheManager.Files(mountID, clean)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// Purge removes the cached contents of a path in a mount, or of the path in
+// every mount if mountID is 0, and returns how many entries and bytes were
+// removed.
+// Contents waiting to be uploaded are kept, and so are pinned ones unless
+// pinned is set; those are downloaded again on the next refresh of their pin.
+func (s *CacheService) Purge(mountID uint32, filePath string, pinned bool) (int, int64, error) {
+	clean, err := types.CleanPath(filePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	entries, bytes := s.cacheManager.Purge(mountID, clean, pinned)
+	fmt.Printf("[CacheService] Purged %d entries (%d bytes) of mount %d under %s\n", entries, bytes, mountID, clean)
+	return entries, bytes, nil
+}
+
+// SetMaxSize changes max_cache_size, 0 for unlimited, and evicts entries
+// right away if the cache is over the new limit.
+func (s *CacheService) SetMaxSize(maxSize int64) error {
+	if maxSize < 0 {
+		return errors.New("max cache size can't be negative")
+	}
+	if err := s.configService.SetConfig("max_cache_size", strconv.FormatInt(maxSize, 10)); err != nil {
+		return err
+	}
+	s.cacheManager.SetMaxSize(maxSize)
+	return nil
+}
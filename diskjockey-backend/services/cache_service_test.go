@@ -0,0 +1,133 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+)
+
+// newCacheServiceTest creates a CacheService for an unlimited cache holding
+// files of two mounts: some plain, one pinned and one waiting to be uploaded.
+func newCacheServiceTest(t *testing.T) (*CacheService, *ConfigService, *cache.CacheManager) {
+	t.Helper()
+	configService, _ := newTestConfigService(t)
+	cacheManager := cache.NewCacheManager(t.TempDir(), 0, newTestMetadataStore(t))
+	puts := []error{
+		cacheManager.Put(1, "/docs/a.txt", []byte("aaaa"), "v1"),
+		cacheManager.Put(1, "/other.txt", []byte("oo"), "v1"),
+		cacheManager.PutPinned(1, "/docs/pinned.txt", []byte("pppppp"), "v1"),
+		cacheManager.Put(2, "/docs/b.txt", []byte("bbb"), "v1"),
+		cacheManager.PutDirty(2, "/docs/dirty.txt", []byte("dd"), ""),
+	}
+	for _, err := range puts {
+		if err != nil {
+			t.Fatalf("failed to fill the cache: %v", err)
+		}
+	}
+	return NewCacheService(configService, cacheManager), configService, cacheManager
+}
+
+func entryPaths(entries []cache.CacheEntry) []string {
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+	return paths
+}
+
+func TestCacheServiceStatsMatchEntries(t *testing.T) {
+	s, _, _ := newCacheServiceTest(t)
+	stats, size, maxSize := s.Stats()
+	if size != 17 || maxSize != 0 {
+		t.Errorf("Stats size = %d of %d, want 17 of unlimited", size, maxSize)
+	}
+
+	var total int64
+	for _, mount := range stats {
+		entries, err := s.Entries(mount.MountID, "/")
+		if err != nil {
+			t.Fatalf("Entries failed: %v", err)
+		}
+		var bytes, pinned, dirty int64
+		for _, entry := range entries {
+			bytes += entry.Size
+			if entry.Pinned {
+				pinned += entry.Size
+			}
+			if entry.Dirty {
+				dirty += entry.Size
+			}
+		}
+		if mount.Entries != len(entries) || mount.Size != bytes || mount.PinnedBytes != pinned || mount.DirtyBytes != dirty {
+			t.Errorf("mount %d stats = %+v, but its %d entries hold %d bytes, %d pinned and %d dirty",
+				mount.MountID, mount, len(entries), bytes, pinned, dirty)
+		}
+		total += mount.Size
+	}
+	if len(stats) != 2 || total != size {
+		t.Errorf("stats of %d mounts add up to %d bytes, want 2 mounts with %d", len(stats), total, size)
+	}
+}
+
+func TestCacheServiceAllMounts(t *testing.T) {
+	s, _, _ := newCacheServiceTest(t)
+
+	// Mount 0 stands for the path in every mount, when listing and purging alike
+	entries, err := s.Entries(0, "/docs")
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if got, want := fmt.Sprint(entryPaths(entries)), "[1/docs/a.txt 1/docs/pinned.txt 2/docs/b.txt 2/docs/dirty.txt]"; got != want {
+		t.Errorf("Entries of every mount = %v, want %v", got, want)
+	}
+	if entries, _ := s.Entries(1, "/docs"); len(entries) != 2 {
+		t.Errorf("Entries of mount 1 = %v, want its two files", entryPaths(entries))
+	}
+	if _, err := s.Entries(0, "/../docs"); err == nil {
+		t.Error("listed a path outside of the mounts")
+	}
+
+	// Dirty and pinned contents are kept
+	removed, bytes, err := s.Purge(0, "/docs", false)
+	if err != nil || removed != 2 || bytes != 7 {
+		t.Errorf("Purge = %d entries, %d bytes, %v, want 2 entries of 7 bytes", removed, bytes, err)
+	}
+	entries, _ = s.Entries(0, "/")
+	if got, want := fmt.Sprint(entryPaths(entries)), "[1/docs/pinned.txt 1/other.txt 2/docs/dirty.txt]"; got != want {
+		t.Errorf("cache holds %v after the purge, want %v", got, want)
+	}
+	if removed, _, _ := s.Purge(0, "/", true); removed != 2 {
+		t.Errorf("Purge including pinned contents removed %d entries, want 2", removed)
+	}
+}
+
+func TestCacheServiceSetMaxSize(t *testing.T) {
+	s, configService, cacheManager := newCacheServiceTest(t)
+
+	if err := s.SetMaxSize(9); err != nil {
+		t.Fatalf("SetMaxSize failed: %v", err)
+	}
+	if value, err := configService.GetConfig("max_cache_size"); err != nil || value != "9" {
+		t.Errorf("max_cache_size config = %q, %v, want 9", value, err)
+	}
+	if _, _, maxSize := s.Stats(); maxSize != 9 {
+		t.Errorf("cache is kept under %d bytes, want 9", maxSize)
+	}
+	// Evicting the least recently used files gets the cache from 17 bytes
+	// under 9, keeping what is pinned or dirty
+	if size := cacheManager.Size(); size > 9 {
+		t.Errorf("cache holds %d bytes after lowering its size", size)
+	}
+	entries, _ := s.Entries(0, "/")
+	if got, want := fmt.Sprint(entryPaths(entries)), "[1/docs/pinned.txt 2/docs/dirty.txt]"; got != want {
+		t.Errorf("cache holds %v after lowering its size, want %v", got, want)
+	}
+
+	if err := s.SetMaxSize(-1); err == nil {
+		t.Error("set a negative size")
+	}
+	if value, _ := configService.GetConfig("max_cache_size"); value != "9" {
+		t.Errorf("max_cache_size config = %q after a rejected change, want 9", value)
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// changeSubscriberBuffer is how many events a slow subscriber may fall behind
+// before further events are dropped for it
+const changeSubscriberBuffer = 256
+
+// ChangeService distributes remote change events to subscribers, such as
+// IPC clients, and to in-process listeners, such as the cache.
+type ChangeService struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]chan types.ChangeEvent
+	listeners   []func(types.ChangeEvent)
+}
+
+// NewChangeService creates a ChangeService with no subscribers.
+func NewChangeService() *ChangeService {
+	return &ChangeService{
+		subscribers: make(map[int]chan types.ChangeEvent),
+	}
+}
+
+// AddListener registers a function called synchronously for every event.
+func (cs *ChangeService) AddListener(listener func(types.ChangeEvent)) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.listeners = append(cs.listeners, listener)
+}
+
+// Subscribe returns a channel receiving every published event, and a function
+// that cancels the subscription and closes the channel.
+func (cs *ChangeService) Subscribe() (<-chan types.ChangeEvent, func()) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	id := cs.nextID
+	cs.nextID++
+	ch := make(chan types.ChangeEvent, changeSubscriberBuffer)
+	cs.subscribers[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			cs.mu.Lock()
+			delete(cs.subscribers, id)
+			cs.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers an event to all listeners and subscribers.
+func (cs *ChangeService) Publish(event types.ChangeEvent) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	for _, listener := range cs.listeners {
+		listener(event)
+	}
+	for id, ch := range cs.subscribers {
+		select {
+		case ch <- event:
+		default:
+			fmt.Fprintf(os.Stderr, "[ChangeService] Subscriber %d is not keeping up, dropped event for %s\n", id, event.Path)
+		}
+	}
+}
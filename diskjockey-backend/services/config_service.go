@@ -6,8 +6,20 @@ import (
 	"strconv"
 
 	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"gorm.io/gorm"
 )
 
+// mountColumns lists the config keys stored in dedicated Mount columns.
+var mountColumns = map[string]struct{}{
+	"host":         {},
+	"port":         {},
+	"username":     {},
+	"password":     {},
+	"path":         {},
+	"share":        {},
+	"access_token": {},
+}
+
 // ConfigService provides access to config, mount, and socket path data from the database.
 
 type ConfigService struct {
@@ -79,6 +91,22 @@ func (cs *ConfigService) CreateMount(name string, disktype string, config map[st
 	if v, ok := config["path"]; ok {
 		mount.Path = v
 	}
+	if v, ok := config["share"]; ok {
+		mount.Share = v
+	}
+	if v, ok := config["access_token"]; ok {
+		mount.AccessToken = v
+	}
+	// Anything without a dedicated column is kept as a disk type option
+	for k, v := range config {
+		if _, ok := mountColumns[k]; ok {
+			continue
+		}
+		if mount.Options == nil {
+			mount.Options = map[string]string{}
+		}
+		mount.Options[k] = v
+	}
 	// Enforce no overlapping mounts
 	var existing []models.Mount
 	if err := db.Find(&existing).Error; err != nil {
@@ -89,12 +117,12 @@ func (cs *ConfigService) CreateMount(name string, disktype string, config map[st
 			return 0, errors.New("mount path overlaps with existing mount: " + ex.Path)
 		}
 	}
-	fmt.Printf("[ConfigService] Creating mount: %+v\n", mount)
+	fmt.Printf("[ConfigService] Creating mount: name=%s type=%s\n", mount.Name, mount.DiskType)
 	if err := db.Create(&mount).Error; err != nil {
 		fmt.Printf("[ConfigService] Failed to create mount: %v\n", err)
 		return 0, err
 	}
-	fmt.Printf("[ConfigService] Created mount: %+v\n", mount)
+	fmt.Printf("[ConfigService] Created mount: id=%d name=%s\n", mount.ID, mount.Name)
 	return uint32(mount.ID), nil
 }
 
@@ -131,3 +159,33 @@ func (cs *ConfigService) SetMountMounted(mountID uint32, mounted bool) error {
 	db := cs.db.GetDB()
 	return db.Model(&models.Mount{}).Where("id = ?", mountID).Update("is_mounted", mounted).Error
 }
+
+// UpdateMount saves all fields of an existing mount, e.g. refreshed credentials.
+func (cs *ConfigService) UpdateMount(mount *models.Mount) error {
+	db := cs.db.GetDB()
+	return db.Save(mount).Error
+}
+
+// GetConfig returns the value of a config setting.
+func (cs *ConfigService) GetConfig(key string) (string, error) {
+	db := cs.db.GetDB()
+	var cfg models.Config
+	if err := db.Where("key = ?", key).First(&cfg).Error; err != nil {
+		return "", err
+	}
+	return cfg.Value, nil
+}
+
+// SetConfig creates or updates a config setting.
+func (cs *ConfigService) SetConfig(key string, value string) error {
+	db := cs.db.GetDB()
+	var cfg models.Config
+	err := db.Where("key = ?", key).First(&cfg).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return db.Create(&models.Config{Key: key, Value: value}).Error
+	}
+	if err != nil {
+		return err
+	}
+	return db.Model(&cfg).Update("value", value).Error
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// ConflictPolicy decides what happens when local changes to a file are
+// uploaded after the file changed on the remote. Mounts choose one with the
+// conflict_policy option.
+type ConflictPolicy string
+
+const (
+	// Keep the remote file and write the local contents next to it as a
+	// conflicted copy
+	ConflictKeepBoth   ConflictPolicy = "keep_both"
+	ConflictLocalWins  ConflictPolicy = "local_wins"
+	ConflictRemoteWins ConflictPolicy = "remote_wins"
+	// Hold the local changes until the user resolves the conflict
+	ConflictAsk ConflictPolicy = "ask"
+)
+
+// How long resolved conflicts are kept for reporting
+const conflictRetention = 7 * 24 * time.Hour
+
+// conflictPolicy returns the policy set by the conflict_policy option of a
+// mount, keep_both if it isn't set or unknown.
+func conflictPolicy(model *models.Mount) ConflictPolicy {
+	switch policy := ConflictPolicy(model.Option("conflict_policy")); policy {
+	case ConflictKeepBoth, ConflictLocalWins, ConflictRemoteWins, ConflictAsk:
+		return policy
+	default:
+		return ConflictKeepBoth
+	}
+}
+
+// conflictedCopyPath returns the path local contents of a conflicting file
+// are kept at, next to it: "notes (conflicted copy 2006-01-02 150405).txt".
+func conflictedCopyPath(p string, detected time.Time) string {
+	ext := path.Ext(p)
+	if ext == path.Base(p) {
+		// A dotfile has no extension
+		ext = ""
+	}
+	return fmt.Sprintf("%s (conflicted copy %s)%s", strings.TrimSuffix(p, ext), detected.Format("2006-01-02 150405"), ext)
+}
+
+// conflictError is returned when local contents weren't written to their
+// path because the file changed on the remote
+type conflictError struct {
+	record metadata.ConflictRecord
+}
+
+func (e *conflictError) Error() string {
+	switch e.record.Resolution {
+	case metadata.ConflictKeptBoth:
+		return fmt.Sprintf("%s changed on the remote, local contents were written to %s", e.record.Path, e.record.CopyPath)
+	case metadata.ConflictRemoteWon:
+		return fmt.Sprintf("%s changed on the remote, local changes were discarded", e.record.Path)
+	default:
+		return fmt.Sprintf("%s changed on the remote, local changes are held until the conflict is resolved", e.record.Path)
+	}
+}
+
+func (e *conflictError) Unwrap() error {
+	return types.ErrConflict
+}
+
+// ConflictService keeps track of conflicts between local changes and changes
+// made on the remote meanwhile, and resolves the ones held for the user.
+//
+// Conflicts are detected by the caching layer of each mount, which remembers
+// the remote version of every file when it's read and compares it with the
+// remote version before uploading changes.
+type ConflictService struct {
+	store        *metadata.MetadataStore
+	mountService *MountService
+	mu           sync.Mutex
+}
+
+// NewConflictService creates a ConflictService keeping conflicts in store.
+// Call Start once the MountService exists.
+func NewConflictService(store *metadata.MetadataStore) *ConflictService {
+	return &ConflictService{store: store}
+}
+
+// Start lets the service resolve conflicts on the mounts of mountService.
+func (s *ConflictService) Start(mountService *MountService) {
+	s.mountService = mountService
+}
+
+// add records a conflict, replacing an earlier one of the same file, and
+// removes resolved conflicts past their retention.
+func (s *ConflictService) add(record metadata.ConflictRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.store.PutConflict(cache.CachePath(record.MountID, record.Path), record); err != nil {
+		fmt.Fprintf(os.Stderr, "[ConflictService] Failed to record conflict of %s: %v\n", record.Path, err)
+	}
+	records, err := s.store.GetConflicts()
+	if err != nil {
+		return
+	}
+	var expired []string
+	for key, record := range records {
+		if record.Resolution != metadata.ConflictUnresolved && time.Since(record.Resolved) > conflictRetention {
+			expired = append(expired, key)
+		}
+	}
+	if err := s.store.DeleteConflicts(expired...); err != nil {
+		fmt.Fprintf(os.Stderr, "[ConflictService] Failed to remove resolved conflicts: %v\n", err)
+	}
+}
+
+// Conflicts returns the conflicts of a mount, or of all mounts if mountID is
+// 0, ordered by mount and path.
+func (s *ConflictService) Conflicts(mountID uint32) ([]metadata.ConflictRecord, error) {
+	records, err := s.store.GetConflicts()
+	if err != nil {
+		return nil, err
+	}
+	conflicts := make([]metadata.ConflictRecord, 0, len(records))
+	for _, record := range records {
+		if mountID == 0 || record.MountID == mountID {
+			conflicts = append(conflicts, record)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].MountID != conflicts[j].MountID {
+			return conflicts[i].MountID < conflicts[j].MountID
+		}
+		return conflicts[i].Path < conflicts[j].Path
+	})
+	return conflicts, nil
+}
+
+// Resolve resolves a conflict held for the user: keep_both writes the local
+// contents to a conflicted copy, local uploads them over the remote file and
+// remote discards them.
+func (s *ConflictService) Resolve(mountID uint32, filePath string, resolution metadata.ConflictResolution) error {
+	clean, err := types.CleanPath(filePath)
+	if err != nil {
+		return err
+	}
+	switch resolution {
+	case metadata.ConflictKeptBoth, metadata.ConflictLocalWon, metadata.ConflictRemoteWon:
+	default:
+		return fmt.Errorf("unknown resolution %q", resolution)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := cache.CachePath(mountID, clean)
+	record, ok, err := s.store.GetConflict(key)
+	if err != nil {
+		return err
+	}
+	if !ok || record.Resolution != metadata.ConflictUnresolved {
+		return fmt.Errorf("%s has no unresolved conflict", clean)
+	}
+	caching := s.mountService.cachingBackend(mountID)
+	if caching == nil {
+		return fmt.Errorf("mount %d is not mounted", mountID)
+	}
+
+	copyPath, err := caching.resolve(record, resolution)
+	if err != nil {
+		return err
+	}
+	record.Resolution = resolution
+	record.CopyPath = copyPath
+	record.Resolved = time.Now()
+	fmt.Printf("[ConflictService] Resolved conflict of %s on mount %d: %s\n", clean, mountID, resolution)
+	return s.store.PutConflict(key, record)
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/disktypes"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+)
+
+// newConflictTest holds a conflict of /a.txt for the user: the remote holds
+// "remote" and the mount the local contents "local"
+func newConflictTest(t *testing.T) *uploadTest {
+	t.Helper()
+	u := newUploadTest(t, map[string]string{"conflict_policy": "ask"})
+	writeFiles(t, u.remote, map[string]string{"/a.txt": "base"})
+	if _, err := u.mount.Backend.Read("/a.txt"); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	writeFiles(t, u.remote, map[string]string{"/a.txt": "remote"})
+	if err := u.mount.Backend.Write("/a.txt", []byte("local")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	u.waitFor(t, "/a.txt", metadata.UploadConflict)
+	return u
+}
+
+func (u *uploadTest) conflict(t *testing.T, p string) metadata.ConflictRecord {
+	t.Helper()
+	conflicts, err := u.conflicts.Conflicts(u.mountID)
+	if err != nil {
+		t.Fatalf("Conflicts failed: %v", err)
+	}
+	for _, record := range conflicts {
+		if record.Path == p {
+			return record
+		}
+	}
+	t.Fatalf("no conflict recorded for %s", p)
+	return metadata.ConflictRecord{}
+}
+
+func TestConflictResolve(t *testing.T) {
+	tests := []struct {
+		resolution metadata.ConflictResolution
+		remote     string // contents of /a.txt afterwards
+		copy       bool   // whether the local contents went to a conflicted copy
+	}{
+		{resolution: metadata.ConflictKeptBoth, remote: "remote", copy: true},
+		{resolution: metadata.ConflictLocalWon, remote: "local"},
+		{resolution: metadata.ConflictRemoteWon, remote: "remote"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.resolution), func(t *testing.T) {
+			u := newConflictTest(t)
+			if err := u.conflicts.Resolve(u.mountID, "/a.txt", tt.resolution); err != nil {
+				t.Fatalf("Resolve failed: %v", err)
+			}
+			record := u.conflict(t, "/a.txt")
+			if record.Resolution != tt.resolution {
+				t.Errorf("conflict resolved as %q", record.Resolution)
+			}
+			if tt.resolution == metadata.ConflictLocalWon {
+				u.waitFor(t, "/a.txt", metadata.UploadSynced)
+			}
+			u.remoteHolds(t, "/a.txt", tt.remote)
+			if data, err := u.mount.Backend.Read("/a.txt"); err != nil || string(data) != tt.remote {
+				t.Errorf("mount reads %q, %v, want %q", data, err, tt.remote)
+			}
+			if copied := strings.Contains(record.CopyPath, "conflicted copy"); copied != tt.copy {
+				t.Fatalf("conflicted copy %q, want one %v", record.CopyPath, tt.copy)
+			}
+			if tt.copy {
+				u.remoteHolds(t, record.CopyPath, "local")
+			}
+			if err := u.conflicts.Resolve(u.mountID, "/a.txt", tt.resolution); err == nil {
+				t.Errorf("resolved the same conflict twice")
+			}
+		})
+	}
+}
+
+func TestConflictResolveKeepsConflictWhenRemoteRejects(t *testing.T) {
+	u := newConflictTest(t)
+	u.remote.FailWith(disktypes.MemoryOpWrite, errors.New("quota exceeded"))
+
+	if err := u.conflicts.Resolve(u.mountID, "/a.txt", metadata.ConflictKeptBoth); err == nil {
+		t.Fatal("Resolve succeeded while the remote rejects writes")
+	}
+	if record := u.conflict(t, "/a.txt"); record.Resolution != metadata.ConflictUnresolved {
+		t.Errorf("conflict resolved as %q after the failed copy", record.Resolution)
+	}
+	// The local contents must survive for the next attempt
+	if data, err := u.mount.Backend.Read("/a.txt"); err != nil || string(data) != "local" {
+		t.Errorf("mount reads %q, %v, want the local contents", data, err)
+	}
+
+	u.remote.FailWith(disktypes.MemoryOpWrite, nil)
+	if err := u.conflicts.Resolve(u.mountID, "/a.txt", metadata.ConflictKeptBoth); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	u.remoteHolds(t, u.conflict(t, "/a.txt").CopyPath, "local")
+}
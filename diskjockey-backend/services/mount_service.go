@@ -0,0 +1,443 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"golang.org/x/oauth2"
+)
+
+// MountService activates mounts by creating their disk type backends and
+// tracks the status of every mount.
+type MountService struct {
+	mu              sync.RWMutex
+	configService   *ConfigService
+	disktypeService *DiskTypeService
+	store           *metadata.MetadataStore
+	cacheManager    *cache.CacheManager
+	uploadService   *UploadService
+	conflictService *ConflictService
+	mounts          map[uint32]*types.Mount // mount ID -> active mount
+	statuses        map[uint32]mountState   // mount ID -> last known status
+	listeners       []func(mountID uint32, mount *types.Mount)
+}
+
+type mountState struct {
+	status types.MountStatus
+	err    string
+}
+
+// NewMountService creates a MountService using the given config and disk type
+// services. Mounts that enable caching use cacheManager, and those that write
+// back queue their uploads with uploads. Changes made while a mount is offline
+// are journaled in store, and conflicts with remote changes are recorded with
+// conflicts.
+func NewMountService(config *ConfigService, disktypes *DiskTypeService, store *metadata.MetadataStore, cacheManager *cache.CacheManager, uploads *UploadService, conflicts *ConflictService) *MountService {
+	return &MountService{
+		configService:   config,
+		disktypeService: disktypes,
+		store:           store,
+		cacheManager:    cacheManager,
+		uploadService:   uploads,
+		conflictService: conflicts,
+		mounts:          make(map[uint32]*types.Mount),
+		statuses:        make(map[uint32]mountState),
+	}
+}
+
+// AddListener registers a function called whenever a mount is mounted,
+// remounted or unmounted. mount is nil when the mount is no longer active.
+func (ms *MountService) AddListener(listener func(mountID uint32, mount *types.Mount)) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.listeners = append(ms.listeners, listener)
+}
+
+func (ms *MountService) notify(mountID uint32, mount *types.Mount) {
+	ms.mu.RLock()
+	listeners := append([]func(uint32, *types.Mount){}, ms.listeners...)
+	ms.mu.RUnlock()
+	for _, listener := range listeners {
+		listener(mountID, mount)
+	}
+}
+
+// RestoreMounts activates every mount that was mounted when the backend last ran.
+func (ms *MountService) RestoreMounts() {
+	mounts, err := ms.configService.ListMountpoints()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[MountService] Failed to list mounts: %v\n", err)
+		return
+	}
+	for _, m := range mounts {
+		if !m.IsMounted {
+			continue
+		}
+		if err := ms.Mount(uint32(m.ID)); err != nil {
+			fmt.Fprintf(os.Stderr, "[MountService] Failed to restore mount %s: %v\n", m.Name, err)
+		}
+	}
+}
+
+// Mount creates the backend for a mount and marks it as mounted.
+// Any failure is recorded as the mount's ERROR status, except that a mount
+// whose remote can't be reached is mounted OFFLINE if it has a cache.
+func (ms *MountService) Mount(mountID uint32) error {
+	model, err := ms.configService.GetMountByID(mountID)
+	if err != nil {
+		return err
+	}
+
+	diskType, ok := ms.disktypeService.LookupDiskType(model.DiskType)
+	if !ok {
+		err := errors.New("disk type does not exist: " + model.DiskType)
+		ms.setStatus(mountID, types.MountStatusError, err)
+		return err
+	}
+
+	offlineCapable := ms.store != nil && ms.cacheManager != nil
+	backend, err := diskType.New(model)
+	unreachable := offlineCapable && types.IsUnreachable(err)
+	if unreachable {
+		backend = &lazyBackend{create: func() (types.Backend, error) { return diskType.New(model) }}
+	} else if err != nil {
+		ms.setStatus(mountID, types.MountStatusError, err)
+		return err
+	}
+
+	readOnly := types.IsReadOnly(diskType, model)
+	backend = newCachingBackend(backend, model, ms.cacheManager, ms.uploadService, ms.conflictService, readOnly)
+	var offline *offlineBackend
+	if offlineCapable {
+		offline = newOfflineBackend(backend, mountID, ms, readOnly)
+		backend = offline
+	}
+	mount := &types.Mount{
+		ID:       mountID,
+		Name:     model.Name,
+		DiskType: model.DiskType,
+		Backend: &statusBackend{
+			Backend: &pathBackend{Backend: backend},
+			mountID: mountID,
+			service: ms,
+		},
+	}
+
+	ms.mu.Lock()
+	previous := ms.mounts[mountID]
+	ms.mounts[mountID] = mount
+	ms.statuses[mountID] = mountState{status: types.MountStatusMounted}
+	ms.mu.Unlock()
+
+	if previous != nil {
+		closeBackend(previous.Backend)
+	}
+	if offline != nil {
+		var cause error
+		if unreachable {
+			cause = err
+		}
+		offline.resume(cause)
+	}
+	ms.notify(mountID, mount)
+
+	return ms.configService.SetMountMounted(mountID, true)
+}
+
+// Unmount releases the backend for a mount and marks it as unmounted.
+func (ms *MountService) Unmount(mountID uint32) error {
+	ms.mu.Lock()
+	mount := ms.mounts[mountID]
+	delete(ms.mounts, mountID)
+	ms.statuses[mountID] = mountState{status: types.MountStatusUnmounted}
+	ms.mu.Unlock()
+
+	if mount != nil {
+		closeBackend(mount.Backend)
+		ms.notify(mountID, nil)
+	}
+
+	return ms.configService.SetMountMounted(mountID, false)
+}
+
+// Remount recreates the backend of a mount that is mounted, offline or in an
+// error state, e.g. after its credentials changed.
+func (ms *MountService) Remount(mountID uint32) error {
+	status, _ := ms.Status(mountID)
+	if status != types.MountStatusMounted && status != types.MountStatusError && status != types.MountStatusOffline {
+		return nil
+	}
+	return ms.Mount(mountID)
+}
+
+// Invalidate drops everything cached for a path in a mount and below it,
+// e.g. because it changed on the remote.
+func (ms *MountService) Invalidate(mountID uint32, path string) {
+	if caching := ms.cachingBackend(mountID); caching != nil {
+		caching.Invalidate(path)
+		return
+	}
+	if ms.cacheManager != nil {
+		ms.cacheManager.Invalidate(mountID, path)
+	}
+}
+
+// cachingBackend returns the caching layer of an active mount, or nil if the
+// mount isn't mounted or doesn't cache.
+func (ms *MountService) cachingBackend(mountID uint32) *cachingBackend {
+	caching, _ := ms.layer(mountID, func(b types.Backend) bool {
+		_, ok := b.(*cachingBackend)
+		return ok
+	}).(*cachingBackend)
+	return caching
+}
+
+// offlineBackend returns the offline layer of a mount, nil if it isn't
+// mounted or has none.
+func (ms *MountService) offlineBackend(mountID uint32) *offlineBackend {
+	offline, _ := ms.layer(mountID, func(b types.Backend) bool {
+		_, ok := b.(*offlineBackend)
+		return ok
+	}).(*offlineBackend)
+	return offline
+}
+
+// layer returns the first layer of a mount's backend that match accepts,
+// nil if there is none or the mount isn't mounted.
+func (ms *MountService) layer(mountID uint32, match func(types.Backend) bool) types.Backend {
+	ms.mu.RLock()
+	mount := ms.mounts[mountID]
+	ms.mu.RUnlock()
+	if mount == nil {
+		return nil
+	}
+	for b := mount.Backend; b != nil; {
+		if match(b) {
+			return b
+		}
+		wrapper, ok := b.(types.Wrapper)
+		if !ok {
+			break
+		}
+		b = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// rawBackend returns the disk type backend below the layers added by the
+// mount service.
+func rawBackend(b types.Backend) types.Backend {
+	for {
+		wrapper, ok := b.(types.Wrapper)
+		if !ok || wrapper.Unwrap() == nil {
+			return b
+		}
+		b = wrapper.Unwrap()
+	}
+}
+
+// GetMount returns the active mount for the given ID.
+func (ms *MountService) GetMount(mountID uint32) (*types.Mount, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	mount, ok := ms.mounts[mountID]
+	if !ok {
+		return nil, fmt.Errorf("mount %d is not mounted", mountID)
+	}
+	return mount, nil
+}
+
+// GetMountByName returns the active mount with the given name. It implements
+// types.MountRegistry.
+func (ms *MountService) GetMountByName(name string) (*types.Mount, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	for _, mount := range ms.mounts {
+		if mount.Name == name {
+			return mount, nil
+		}
+	}
+	return nil, fmt.Errorf("mount %q is not mounted", name)
+}
+
+// SaveToken stores a refreshed OAuth2 token on a mount, so it is used the
+// next time the mount is mounted. It implements types.TokenStore.
+func (ms *MountService) SaveToken(mountID uint32, token *oauth2.Token) error {
+	mount, err := ms.configService.GetMountByID(mountID)
+	if err != nil {
+		return err
+	}
+	storeOAuthToken(mount, token)
+	return ms.configService.UpdateMount(mount)
+}
+
+// Status returns the status of a mount and the error that caused an ERROR or
+// OFFLINE status.
+func (ms *MountService) Status(mountID uint32) (types.MountStatus, string) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	if state, ok := ms.statuses[mountID]; ok {
+		return state.status, state.err
+	}
+	return types.MountStatusUnmounted, ""
+}
+
+func (ms *MountService) setStatus(mountID uint32, status types.MountStatus, err error) {
+	state := mountState{status: status}
+	if err != nil {
+		state.err = err.Error()
+	}
+	ms.mu.Lock()
+	ms.statuses[mountID] = state
+	ms.mu.Unlock()
+	if status == types.MountStatusError {
+		fmt.Fprintf(os.Stderr, "[MountService] Mount %d error: %s\n", mountID, state.err)
+	}
+}
+
+// observe records errors from backend operations that leave the mount
+// unusable until the user acts, such as expired credentials.
+func (ms *MountService) observe(mountID uint32, err error) error {
+	if errors.Is(err, types.ErrReauthRequired) {
+		ms.setStatus(mountID, types.MountStatusError, err)
+	}
+	return err
+}
+
+// closeBackend releases connections and stops background work of every
+// layer of a backend that supports it.
+func closeBackend(b types.Backend) {
+	for b != nil {
+		if c, ok := b.(io.Closer); ok {
+			c.Close()
+		}
+		wrapper, ok := b.(types.Wrapper)
+		if !ok {
+			return
+		}
+		b = wrapper.Unwrap()
+	}
+}
+
+// statusBackend reports errors from the wrapped backend to the MountService.
+type statusBackend struct {
+	types.Backend
+	mountID uint32
+	service *MountService
+}
+
+func (s *statusBackend) Unwrap() types.Backend {
+	return s.Backend
+}
+
+func (s *statusBackend) List(path string) ([]types.FileInfo, error) {
+	infos, err := s.Backend.List(path)
+	return infos, s.service.observe(s.mountID, err)
+}
+
+func (s *statusBackend) Read(path string) ([]byte, error) {
+	data, err := s.Backend.Read(path)
+	return data, s.service.observe(s.mountID, err)
+}
+
+func (s *statusBackend) ReadRange(path string, offset, length int64) ([]byte, error) {
+	data, err := types.ReadRange(s.Backend, path, offset, length)
+	return data, s.service.observe(s.mountID, err)
+}
+
+func (s *statusBackend) Write(path string, data []byte) error {
+	return s.service.observe(s.mountID, s.Backend.Write(path, data))
+}
+
+func (s *statusBackend) Delete(path string) error {
+	return s.service.observe(s.mountID, s.Backend.Delete(path))
+}
+
+func (s *statusBackend) Stat(path string) (types.FileInfo, error) {
+	info, err := types.Stat(s.Backend, path)
+	return info, s.service.observe(s.mountID, err)
+}
+
+func (s *statusBackend) Rename(from, to string) error {
+	return s.service.observe(s.mountID, types.Rename(s.Backend, from, to))
+}
+
+func (s *statusBackend) Reconnect() error {
+	return s.service.observe(s.mountID, s.Backend.Reconnect())
+}
+
+// pathBackend validates and cleans every path before it reaches the wrapped
+// backend, so disk types only ever see canonical paths inside the mount.
+type pathBackend struct {
+	types.Backend
+}
+
+func (p *pathBackend) Unwrap() types.Backend {
+	return p.Backend
+}
+
+func (p *pathBackend) List(path string) ([]types.FileInfo, error) {
+	clean, err := types.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Backend.List(clean)
+}
+
+func (p *pathBackend) Read(path string) ([]byte, error) {
+	clean, err := types.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Backend.Read(clean)
+}
+
+func (p *pathBackend) ReadRange(path string, offset, length int64) ([]byte, error) {
+	clean, err := types.CleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return types.ReadRange(p.Backend, clean, offset, length)
+}
+
+func (p *pathBackend) Write(path string, data []byte) error {
+	clean, err := types.CleanPath(path)
+	if err != nil {
+		return err
+	}
+	return p.Backend.Write(clean, data)
+}
+
+func (p *pathBackend) Delete(path string) error {
+	clean, err := types.CleanPath(path)
+	if err != nil {
+		return err
+	}
+	return p.Backend.Delete(clean)
+}
+
+func (p *pathBackend) Stat(path string) (types.FileInfo, error) {
+	clean, err := types.CleanPath(path)
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return types.Stat(p.Backend, clean)
+}
+
+func (p *pathBackend) Rename(from, to string) error {
+	cleanFrom, err := types.CleanPath(from)
+	if err != nil {
+		return err
+	}
+	cleanTo, err := types.CleanPath(to)
+	if err != nil {
+		return err
+	}
+	return types.Rename(p.Backend, cleanFrom, cleanTo)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/disktypes"
+	"golang.org/x/oauth2"
+)
+
+// newTestConfigService opens a config database in a temp dir, and registers
+// the memory disk type for the mounts of the test.
+func newTestConfigService(t *testing.T) (*ConfigService, *DiskTypeService) {
+	t.Helper()
+	sqliteService := NewSQLiteService(filepath.Join(t.TempDir(), "diskjockey.sqlite"))
+	if err := sqliteService.Start(); err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { sqliteService.Stop() })
+	if err := sqliteService.Migrate(); err != nil {
+		t.Fatalf("failed to migrate db: %v", err)
+	}
+	diskTypeService := NewDiskTypeService()
+	diskTypeService.RegisterDiskType(disktypes.MemoryDiskType{})
+	return NewConfigService(sqliteService), diskTypeService
+}
+
+func TestSaveToken(t *testing.T) {
+	configService, diskTypeService := newTestConfigService(t)
+	mountService := NewMountService(configService, diskTypeService, nil, nil, nil, nil)
+
+	mountID, err := configService.CreateMount("drive", "memory", map[string]string{
+		"access_token":  "access-1",
+		"refresh_token": "refresh-1",
+		"app_key":       "app-key",
+	}, diskTypeService)
+	if err != nil {
+		t.Fatalf("CreateMount failed: %v", err)
+	}
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := mountService.SaveToken(mountID, &oauth2.Token{AccessToken: "access-2", Expiry: expiry}); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+
+	mount, err := configService.GetMountByID(mountID)
+	if err != nil {
+		t.Fatalf("GetMountByID failed: %v", err)
+	}
+	if mount.AccessToken != "access-2" {
+		t.Errorf("access token = %q, want access-2", mount.AccessToken)
+	}
+	// A token without a refresh token keeps the stored one
+	if got := mount.Option("refresh_token"); got != "refresh-1" {
+		t.Errorf("refresh_token = %q, want refresh-1", got)
+	}
+	if got := mount.Option("token_expiry"); got != expiry.UTC().Format(time.RFC3339) {
+		t.Errorf("token_expiry = %q, want %q", got, expiry.UTC().Format(time.RFC3339))
+	}
+	if got := mount.Option("app_key"); got != "app-key" {
+		t.Errorf("app_key = %q, want the other options kept", got)
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// How long the loopback listener waits for the provider's redirect
+	oauthFlowLifetime = 10 * time.Minute
+	// Default time Finish waits for the redirect when no timeout is given
+	oauthDefaultFinishTimeout = 5 * time.Minute
+)
+
+// OAuthService runs OAuth2 authorization code flows with PKCE for mounts whose
+// disk type implements types.OAuthDiskType. The provider redirects the browser
+// to a loopback listener in the backend, which exchanges the code for tokens
+// and stores them on the mount.
+type OAuthService struct {
+	mu              sync.Mutex
+	configService   *ConfigService
+	disktypeService *DiskTypeService
+	mountService    *MountService
+	flows           map[uint32]*oauthFlow // mount ID -> flow in progress
+}
+
+type oauthFlow struct {
+	mountID  uint32
+	config   *oauth2.Config
+	verifier string
+	state    string
+	server   *http.Server
+	timer    *time.Timer
+	done     chan struct{}
+	once     sync.Once
+	err      error
+}
+
+// NewOAuthService creates an OAuthService.
+func NewOAuthService(config *ConfigService, disktypes *DiskTypeService, mounts *MountService) *OAuthService {
+	return &OAuthService{
+		configService:   config,
+		disktypeService: disktypes,
+		mountService:    mounts,
+		flows:           make(map[uint32]*oauthFlow),
+	}
+}
+
+// Start begins an authorization flow for a mount, replacing any flow already
+// in progress for it. It returns the URL the user must open in a browser and
+// the loopback redirect URL the backend is listening on.
+func (s *OAuthService) Start(mountID uint32) (string, string, error) {
+	mount, err := s.configService.GetMountByID(mountID)
+	if err != nil {
+		return "", "", err
+	}
+
+	diskType, ok := s.disktypeService.LookupDiskType(mount.DiskType)
+	if !ok {
+		return "", "", errors.New("disk type does not exist: " + mount.DiskType)
+	}
+	oauthType, ok := diskType.(types.OAuthDiskType)
+	if !ok {
+		return "", "", fmt.Errorf("disk type %s does not use OAuth authorization", mount.DiskType)
+	}
+
+	config, authOpts, err := oauthType.OAuthConfig(mount)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Providers that require an exact redirect URI can be given a fixed port
+	port, _ := strconv.Atoi(mount.Option("redirect_port"))
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to listen for oauth redirect: %w", err)
+	}
+	config.RedirectURL = fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	verifier, err := randomToken(32)
+	if err != nil {
+		listener.Close()
+		return "", "", err
+	}
+	state, err := randomToken(16)
+	if err != nil {
+		listener.Close()
+		return "", "", err
+	}
+
+	flow := &oauthFlow{
+		mountID:  mountID,
+		config:   config,
+		verifier: verifier,
+		state:    state,
+		done:     make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		s.handleCallback(flow, w, r)
+	})
+	flow.server = &http.Server{Handler: mux}
+	flow.timer = time.AfterFunc(oauthFlowLifetime, func() {
+		s.finishFlow(flow, errors.New("authorization timed out"))
+	})
+
+	s.mu.Lock()
+	previous := s.flows[mountID]
+	s.flows[mountID] = flow
+	s.mu.Unlock()
+	if previous != nil {
+		s.finishFlow(previous, errors.New("authorization restarted"))
+	}
+
+	go func() {
+		if err := flow.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "[OAuthService] Redirect listener error: %v\n", err)
+		}
+	}()
+
+	challenge := sha256.Sum256([]byte(verifier))
+	authOpts = append(authOpts,
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+	authURL := config.AuthCodeURL(state, authOpts...)
+
+	fmt.Printf("[OAuthService] Started authorization for mount %d, redirect to %s\n", mountID, config.RedirectURL)
+	return authURL, config.RedirectURL, nil
+}
+
+// Finish waits for the flow of a mount to complete and returns its result.
+// If code is set it is exchanged directly, for when the user copied the code
+// because the redirect could not reach the backend.
+func (s *OAuthService) Finish(mountID uint32, code string, timeout time.Duration) error {
+	s.mu.Lock()
+	flow := s.flows[mountID]
+	s.mu.Unlock()
+	if flow == nil {
+		return fmt.Errorf("no authorization in progress for mount %d", mountID)
+	}
+
+	if code != "" {
+		s.finishFlow(flow, s.exchange(flow, code))
+	}
+
+	if timeout <= 0 {
+		timeout = oauthDefaultFinishTimeout
+	}
+
+	select {
+	case <-flow.done:
+		return flow.err
+	case <-time.After(timeout):
+		return errors.New("timed out waiting for authorization")
+	}
+}
+
+func (s *OAuthService) handleCallback(flow *oauthFlow, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("state") != flow.state {
+		http.Error(w, "Invalid authorization state", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if providerErr := query.Get("error"); providerErr != "" {
+		err = fmt.Errorf("authorization denied: %s %s", providerErr, query.Get("error_description"))
+	} else {
+		err = s.exchange(flow, query.Get("code"))
+	}
+
+	if err != nil {
+		http.Error(w, "Authorization failed: "+err.Error(), http.StatusBadRequest)
+	} else {
+		fmt.Fprintln(w, "DiskJockey is now authorized. You can close this window.")
+	}
+
+	// Shut the listener down once this response has been written
+	go s.finishFlow(flow, err)
+}
+
+// exchange trades an authorization code for tokens, stores them on the mount
+// and remounts it so the backend picks them up.
+func (s *OAuthService) exchange(flow *oauthFlow, code string) error {
+	if code == "" {
+		return errors.New("missing authorization code")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	token, err := flow.config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", flow.verifier))
+	if err != nil {
+		return fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	mount, err := s.configService.GetMountByID(flow.mountID)
+	if err != nil {
+		return err
+	}
+	storeOAuthToken(mount, token)
+	if err := s.configService.UpdateMount(mount); err != nil {
+		return err
+	}
+
+	if err := s.mountService.Remount(flow.mountID); err != nil {
+		return fmt.Errorf("authorized, but remounting failed: %w", err)
+	}
+
+	fmt.Printf("[OAuthService] Mount %d authorized\n", flow.mountID)
+	return nil
+}
+
+// finishFlow records the result of a flow once, stops its listener and
+// forgets it.
+func (s *OAuthService) finishFlow(flow *oauthFlow, err error) {
+	flow.once.Do(func() {
+		flow.err = err
+		flow.timer.Stop()
+		close(flow.done)
+
+		s.mu.Lock()
+		if s.flows[flow.mountID] == flow {
+			delete(s.flows, flow.mountID)
+		}
+		s.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		flow.server.Shutdown(ctx)
+	})
+}
+
+// storeOAuthToken copies a token onto the mount: the access token in its
+// column, and the refresh token and expiry as options.
+func storeOAuthToken(mount *models.Mount, token *oauth2.Token) {
+	if mount.Options == nil {
+		mount.Options = map[string]string{}
+	}
+	mount.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		mount.Options["refresh_token"] = token.RefreshToken
+	}
+	if token.Expiry.IsZero() {
+		delete(mount.Options, "token_expiry")
+	} else {
+		mount.Options["token_expiry"] = token.Expiry.UTC().Format(time.RFC3339)
+	}
+}
+
+// randomToken returns n random bytes encoded as unpadded base64url, which is
+// valid both as an OAuth state and as a PKCE code verifier.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,756 @@
+package services
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+const (
+	// Delay before trying to reach an offline remote again, doubled after
+	// every failed attempt
+	offlineRetryMin = 5 * time.Second
+	offlineRetryMax = 2 * time.Minute
+	// Directory listings kept for going offline, the least recently listed
+	// are dropped beyond this
+	offlineMaxListings = 1000
+)
+
+// offlineBackend keeps a mount usable while its remote can't be reached.
+//
+// When an operation fails because the remote is unreachable the mount goes
+// offline. Listings and stats are then served from the last known metadata,
+// which is the poll snapshot plus the listings seen since mounting, and reads
+// from the cache. Writes, deletes and renames are applied to the cache and
+// recorded in a journal in the metadata store. The remote is retried with
+// backoff, and once it's reachable the journal is replayed in order and the
+// mount goes back online. A journal left over from a previous run is replayed
+// when the mount is mounted.
+//
+// Mounts of read-only disk types refuse changes right away, whether online
+// or not, rather than journaling changes that could never be replayed.
+type offlineBackend struct {
+	types.Backend
+	mountID  uint32
+	service  *MountService
+	store    *metadata.MetadataStore
+	cache    *cache.CacheManager
+	readOnly bool
+
+	mu       sync.Mutex
+	offline  bool
+	closed   bool
+	view     *offlineView             // Built on demand while offline
+	listings map[string]*list.Element // Directory listings seen while online
+	recent   *list.List               // Of *offlineListing, most recently listed first
+	stop     chan struct{}
+}
+
+// offlineListing is the listing of a directory seen while online
+type offlineListing struct {
+	dir   string
+	infos []types.FileInfo
+}
+
+func newOfflineBackend(backend types.Backend, mountID uint32, service *MountService, readOnly bool) *offlineBackend {
+	return &offlineBackend{
+		Backend:  backend,
+		mountID:  mountID,
+		service:  service,
+		store:    service.store,
+		cache:    service.cacheManager,
+		readOnly: readOnly,
+		listings: make(map[string]*list.Element),
+		recent:   list.New(),
+		stop:     make(chan struct{}),
+	}
+}
+
+func (o *offlineBackend) Unwrap() types.Backend {
+	return o.Backend
+}
+
+// Close stops trying to reach the remote.
+func (o *offlineBackend) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.closed {
+		o.closed = true
+		close(o.stop)
+	}
+	return nil
+}
+
+// resume replays a journal left over from a previous run, or takes the mount
+// offline straight away if its remote couldn't be reached when mounting.
+func (o *offlineBackend) resume(unreachable error) {
+	if unreachable != nil {
+		o.goOffline(unreachable, offlineRetryMin)
+		return
+	}
+	entries, err := o.store.GetJournal(o.mountID)
+	if err == nil && len(entries) > 0 {
+		fmt.Printf("[OfflineBackend] Mount %d has %d offline changes to replay\n", o.mountID, len(entries))
+		o.goOffline(nil, 0)
+	}
+}
+
+// goOffline takes the mount offline, if it isn't already, and starts trying
+// to reach the remote again after delay.
+func (o *offlineBackend) goOffline(cause error, delay time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.offline || o.closed {
+		return
+	}
+	o.offline = true
+	o.view = nil
+	if cause != nil {
+		fmt.Fprintf(os.Stderr, "[OfflineBackend] Mount %d is offline: %v\n", o.mountID, cause)
+		o.service.setStatus(o.mountID, types.MountStatusOffline, cause)
+	}
+	go o.reconnectLoop(delay)
+}
+
+func (o *offlineBackend) isOffline() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.offline
+}
+
+// fallBack reports whether an operation that failed with err should be
+// served offline instead, taking the mount offline if err means the remote
+// couldn't be reached.
+func (o *offlineBackend) fallBack(err error) bool {
+	if !types.IsUnreachable(err) {
+		return false
+	}
+	o.goOffline(err, offlineRetryMin)
+	return true
+}
+
+func (o *offlineBackend) List(p string) ([]types.FileInfo, error) {
+	if !o.isOffline() {
+		infos, err := o.Backend.List(p)
+		if err == nil {
+			o.remember(p, infos)
+		}
+		if !o.fallBack(err) {
+			return infos, err
+		}
+	}
+
+	view, err := o.currentView()
+	if err != nil {
+		return nil, err
+	}
+	return view.list(p)
+}
+
+// remember keeps the listing of a directory for going offline, dropping the
+// least recently listed directory if there are too many.
+func (o *offlineBackend) remember(dir string, infos []types.FileInfo) {
+	listing := &offlineListing{dir: dir, infos: append([]types.FileInfo(nil), infos...)}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if elem, ok := o.listings[dir]; ok {
+		elem.Value = listing
+		o.recent.MoveToFront(elem)
+		return
+	}
+	o.listings[dir] = o.recent.PushFront(listing)
+	if o.recent.Len() > offlineMaxListings {
+		oldest := o.recent.Remove(o.recent.Back()).(*offlineListing)
+		delete(o.listings, oldest.dir)
+	}
+}
+
+func (o *offlineBackend) Stat(p string) (types.FileInfo, error) {
+	if !o.isOffline() {
+		info, err := types.Stat(o.Backend, p)
+		if !o.fallBack(err) {
+			return info, err
+		}
+	}
+
+	view, err := o.currentView()
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return view.stat(p)
+}
+
+func (o *offlineBackend) Read(p string) ([]byte, error) {
+	if !o.isOffline() {
+		data, err := o.Backend.Read(p)
+		if !o.fallBack(err) {
+			return data, err
+		}
+	}
+
+	if data, ok := o.cache.Get(o.mountID, p); ok {
+		return data, nil
+	}
+	view, err := o.currentView()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := view.stat(p); err != nil {
+		return nil, err
+	}
+	return nil, &fs.PathError{Op: "read", Path: p, Err: fmt.Errorf("not available offline: %w", types.ErrOffline)}
+}
+
+func (o *offlineBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	if !o.isOffline() {
+		data, err := types.ReadRange(o.Backend, p, offset, length)
+		if !o.fallBack(err) {
+			return data, err
+		}
+	}
+
+	data, err := o.Read(p)
+	if err != nil {
+		return nil, err
+	}
+	return types.SliceRange(data, offset, length), nil
+}
+
+func (o *offlineBackend) Write(p string, data []byte) error {
+	if o.readOnly {
+		return &fs.PathError{Op: "write", Path: p, Err: types.ErrReadOnly}
+	}
+	if !o.isOffline() {
+		err := o.Backend.Write(p, data)
+		if !o.fallBack(err) {
+			return err
+		}
+	}
+
+	base := ""
+	if caching := o.caching(); caching != nil {
+		base = caching.base(p)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.cache.PutDirty(o.mountID, p, data, base); err != nil {
+		return err
+	}
+	return o.journal(metadata.JournalWrite, p, "")
+}
+
+func (o *offlineBackend) Delete(p string) error {
+	if o.readOnly {
+		return &fs.PathError{Op: "delete", Path: p, Err: types.ErrReadOnly}
+	}
+	if !o.isOffline() {
+		err := o.Backend.Delete(p)
+		if !o.fallBack(err) {
+			return err
+		}
+	}
+
+	view, err := o.currentView()
+	if err != nil {
+		return err
+	}
+	if _, err := view.stat(p); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if caching := o.caching(); caching != nil && caching.uploads != nil {
+		if _, err := caching.uploads.Cancel(o.mountID, p); err != nil {
+			return err
+		}
+	}
+	o.cache.Discard(o.mountID, p)
+	return o.journal(metadata.JournalDelete, p, "")
+}
+
+func (o *offlineBackend) Rename(from, to string) error {
+	if o.readOnly {
+		return &fs.PathError{Op: "rename", Path: from, Err: types.ErrReadOnly}
+	}
+	if !o.isOffline() {
+		err := types.Rename(o.Backend, from, to)
+		if !o.fallBack(err) {
+			return err
+		}
+	}
+
+	view, err := o.currentView()
+	if err != nil {
+		return err
+	}
+	if _, err := view.stat(from); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if caching := o.caching(); caching != nil && caching.uploads != nil {
+		// Queued again under the new path when the rename is replayed
+		if _, err := caching.uploads.Cancel(o.mountID, from); err != nil {
+			return err
+		}
+	}
+	if err := o.cache.Move(o.mountID, from, to); err != nil {
+		return err
+	}
+	return o.journal(metadata.JournalRename, from, to)
+}
+
+// journal records a change made while offline. o.mu must be held.
+func (o *offlineBackend) journal(op metadata.JournalOp, p, to string) error {
+	o.view = nil
+	return o.store.AppendJournal(o.mountID, metadata.JournalRecord{Op: op, Path: p, To: to, Created: time.Now()})
+}
+
+// caching returns the caching layer below this backend, if there is one.
+func (o *offlineBackend) caching() *cachingBackend {
+	for b := o.Backend; b != nil; {
+		if caching, ok := b.(*cachingBackend); ok {
+			return caching
+		}
+		wrapper, ok := b.(types.Wrapper)
+		if !ok {
+			break
+		}
+		b = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// reconnectLoop tries to reach the remote, with backoff, until it succeeds
+// and the mount is back online or the backend is closed.
+func (o *offlineBackend) reconnectLoop(delay time.Duration) {
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-time.After(delay):
+		}
+
+		err := o.reconnect()
+		if err == nil {
+			return
+		}
+		if delay *= 2; delay < offlineRetryMin {
+			delay = offlineRetryMin
+		} else if delay > offlineRetryMax {
+			delay = offlineRetryMax
+		}
+		fmt.Fprintf(os.Stderr, "[OfflineBackend] Mount %d is still offline, retrying in %s: %v\n", o.mountID, delay, err)
+		o.mu.Lock()
+		if !o.closed {
+			o.service.setStatus(o.mountID, types.MountStatusOffline, err)
+		}
+		o.mu.Unlock()
+	}
+}
+
+// reconnect reconnects to the remote, replays the journal and takes the
+// mount back online.
+func (o *offlineBackend) reconnect() error {
+	if err := o.Backend.Reconnect(); err != nil {
+		return err
+	}
+	// Listing the root of the disk type itself makes sure the remote is
+	// reachable, rather than just answered from a cache
+	if _, err := rawBackend(o.Backend).List("/"); err != nil {
+		return err
+	}
+
+	for {
+		if err := o.replay(); err != nil {
+			return err
+		}
+
+		o.mu.Lock()
+		entries, err := o.store.GetJournal(o.mountID)
+		if err == nil && len(entries) == 0 {
+			o.offline = false
+			o.view = nil
+			if !o.closed {
+				o.service.setStatus(o.mountID, types.MountStatusMounted, nil)
+			}
+			o.mu.Unlock()
+			if caching := o.caching(); caching != nil {
+				// The remote was changed by the replay
+				caching.dropListings("/")
+			}
+			fmt.Printf("[OfflineBackend] Mount %d is back online\n", o.mountID)
+			return nil
+		}
+		o.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// replay applies the journal to the remote, oldest change first, removing
+// each change once applied. It stops at the first change that fails because
+// the remote is unreachable or needs authorizing. Changes the remote rejects
+// are logged and dropped, leaving written contents in the cache.
+func (o *offlineBackend) replay() error {
+	entries, err := o.store.GetJournal(o.mountID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		select {
+		case <-o.stop:
+			return errors.New("mount was unmounted")
+		default:
+		}
+
+		err := o.apply(entry.JournalRecord)
+		if types.IsUnreachable(err) || errors.Is(err, types.ErrReauthRequired) {
+			return err
+		}
+		if errors.Is(err, types.ErrConflict) {
+			fmt.Fprintf(os.Stderr, "[OfflineBackend] Mount %d: offline %s of %s conflicted: %v\n", o.mountID, entry.Op, entry.Path, err)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "[OfflineBackend] Mount %d: dropping offline %s of %s: %v\n", o.mountID, entry.Op, entry.Path, err)
+		}
+		if err := o.store.DeleteJournalEntries(o.mountID, entry.Seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply replays a single journaled change.
+func (o *offlineBackend) apply(record metadata.JournalRecord) error {
+	switch record.Op {
+	case metadata.JournalWrite:
+		if _, ok := o.cache.GetFile(cache.CachePath(o.mountID, record.Path)); !ok {
+			// Deleted or renamed later on, which is replayed separately
+			return nil
+		}
+		return o.upload(record.Path)
+
+	case metadata.JournalDelete:
+		err := o.Backend.Delete(record.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+
+	case metadata.JournalRename:
+		err := types.Rename(o.Backend, record.Path, record.To)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		// Files written while offline only exist in the cache, and those
+		// that do exist on the remote may have changed since
+		for _, entry := range o.cache.Files(o.mountID, record.To) {
+			if _, p, ok := cache.SplitCachePath(entry.Path); ok && entry.Dirty {
+				if err := o.upload(p); err != nil {
+					return err
+				}
+			}
+		}
+		if err != nil && len(o.cache.Files(o.mountID, record.To)) == 0 {
+			return err
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown journal operation %q", record.Op)
+	}
+}
+
+// upload writes the cached contents of a file to the remote. Mounts that
+// write back queue the upload instead.
+func (o *offlineBackend) upload(p string) error {
+	data, ok := o.cache.Get(o.mountID, p)
+	if !ok {
+		return &fs.PathError{Op: "upload", Path: p, Err: errors.New("cached contents are missing")}
+	}
+	if err := o.Backend.Write(p, data); err != nil {
+		return err
+	}
+	if caching := o.caching(); caching == nil || caching.uploads == nil {
+		sum := sha256.Sum256(data)
+		o.cache.MarkClean(o.mountID, p, hex.EncodeToString(sum[:]), "")
+		o.cache.Invalidate(o.mountID, p)
+	}
+	return nil
+}
+
+// currentView returns the view of the mount while offline, building it if
+// needed.
+func (o *offlineBackend) currentView() (*offlineView, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.view != nil {
+		return o.view, nil
+	}
+
+	view := &offlineView{
+		entries: make(map[string]types.FileInfo),
+		known:   map[string]bool{"/": true},
+	}
+	snapshot, err := o.store.GetSnapshot(o.mountID)
+	if err != nil {
+		return nil, err
+	}
+	for p, record := range snapshot {
+		view.entries[p] = types.FileInfo{
+			Name:    path.Base(p),
+			Size:    record.Size,
+			IsDir:   record.IsDir,
+			ModTime: record.ModTime,
+			ETag:    record.ETag,
+		}
+		view.known[path.Dir(p)] = true
+	}
+	for dir, elem := range o.listings {
+		infos := elem.Value.(*offlineListing).infos
+		// Listings are newer than the snapshot
+		for p := range view.entries {
+			if path.Dir(p) == dir {
+				delete(view.entries, p)
+			}
+		}
+		view.known[dir] = true
+		for _, info := range infos {
+			view.entries[path.Join(dir, info.Name)] = info
+		}
+	}
+
+	journal, err := o.store.GetJournal(o.mountID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range journal {
+		view.apply(entry.JournalRecord)
+	}
+
+	// The cache holds the contents written locally, wherever they were moved
+	for _, entry := range o.cache.DirtyFiles() {
+		if mountID, p, ok := cache.SplitCachePath(entry.Path); ok && mountID == o.mountID {
+			view.add(p, dirtyFileInfo(entry))
+		}
+	}
+
+	o.view = view
+	return view, nil
+}
+
+// offlineView is the last known state of an offline mount with the changes
+// made since applied
+type offlineView struct {
+	entries map[string]types.FileInfo // path -> info
+	known   map[string]bool           // Directories whose contents are known
+}
+
+// add adds or replaces a file, creating the directories leading to it.
+func (v *offlineView) add(p string, info types.FileInfo) {
+	v.entries[p] = info
+	for dir := path.Dir(p); dir != "/"; dir = path.Dir(dir) {
+		v.known[dir] = v.known[dir] || !v.entries[dir].IsDir
+		v.entries[dir] = types.FileInfo{Name: path.Base(dir), IsDir: true}
+	}
+	v.known[path.Dir(p)] = true
+}
+
+// apply applies a journaled change.
+func (v *offlineView) apply(record metadata.JournalRecord) {
+	switch record.Op {
+	case metadata.JournalWrite:
+		if _, ok := v.entries[record.Path]; !ok {
+			v.add(record.Path, types.FileInfo{Name: path.Base(record.Path), ModTime: record.Created})
+		}
+	case metadata.JournalDelete:
+		v.remove(record.Path)
+	case metadata.JournalRename:
+		moved := make(map[string]types.FileInfo)
+		for p, info := range v.entries {
+			if isSubpath(p, record.Path) {
+				moved[record.To+strings.TrimPrefix(p, record.Path)] = info
+			}
+		}
+		known := make(map[string]bool)
+		for dir := range v.known {
+			if isSubpath(dir, record.Path) {
+				known[record.To+strings.TrimPrefix(dir, record.Path)] = true
+			}
+		}
+		v.remove(record.Path)
+		v.remove(record.To)
+		for p, info := range moved {
+			if p == record.To {
+				info.Name = path.Base(p)
+			}
+			v.add(p, info)
+		}
+		for dir := range known {
+			v.known[dir] = true
+		}
+	}
+}
+
+// remove removes a path and everything below it.
+func (v *offlineView) remove(p string) {
+	for entry := range v.entries {
+		if isSubpath(entry, p) {
+			delete(v.entries, entry)
+		}
+	}
+	for dir := range v.known {
+		if dir != "/" && isSubpath(dir, p) {
+			delete(v.known, dir)
+		}
+	}
+}
+
+func (v *offlineView) list(dir string) ([]types.FileInfo, error) {
+	if !v.known[dir] {
+		if info, ok := v.entries[dir]; ok && !info.IsDir {
+			return nil, &fs.PathError{Op: "list", Path: dir, Err: errors.New("not a directory")}
+		}
+		return nil, &fs.PathError{Op: "list", Path: dir, Err: fmt.Errorf("contents not available offline: %w", types.ErrOffline)}
+	}
+	var infos []types.FileInfo
+	for p, info := range v.entries {
+		if p != "/" && path.Dir(p) == dir {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+func (v *offlineView) stat(p string) (types.FileInfo, error) {
+	if p == "/" {
+		return types.FileInfo{Name: "/", IsDir: true}, nil
+	}
+	if info, ok := v.entries[p]; ok {
+		return info, nil
+	}
+	if v.known[path.Dir(p)] {
+		return types.FileInfo{}, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+	}
+	return types.FileInfo{}, &fs.PathError{Op: "stat", Path: p, Err: fmt.Errorf("not available offline: %w", types.ErrOffline)}
+}
+
+// lazyBackend stands in for the backend of a mount whose remote couldn't be
+// reached when it was mounted. The backend is created once Reconnect succeeds.
+type lazyBackend struct {
+	mu      sync.Mutex
+	create  func() (types.Backend, error)
+	backend types.Backend
+}
+
+func (l *lazyBackend) get() (types.Backend, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.backend == nil {
+		return nil, types.ErrOffline
+	}
+	return l.backend, nil
+}
+
+func (l *lazyBackend) Unwrap() types.Backend {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.backend
+}
+
+func (l *lazyBackend) List(p string) ([]types.FileInfo, error) {
+	b, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return b.List(p)
+}
+
+func (l *lazyBackend) Read(p string) ([]byte, error) {
+	b, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return b.Read(p)
+}
+
+func (l *lazyBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	b, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return types.ReadRange(b, p, offset, length)
+}
+
+func (l *lazyBackend) Write(p string, data []byte) error {
+	b, err := l.get()
+	if err != nil {
+		return err
+	}
+	return b.Write(p, data)
+}
+
+func (l *lazyBackend) Delete(p string) error {
+	b, err := l.get()
+	if err != nil {
+		return err
+	}
+	return b.Delete(p)
+}
+
+func (l *lazyBackend) WriteIfMatch(p string, data []byte, etag string) error {
+	b, err := l.get()
+	if err != nil {
+		return err
+	}
+	if w, ok := b.(types.ConditionalWriter); ok {
+		return w.WriteIfMatch(p, data, etag)
+	}
+	return b.Write(p, data)
+}
+
+func (l *lazyBackend) Stat(p string) (types.FileInfo, error) {
+	b, err := l.get()
+	if err != nil {
+		return types.FileInfo{}, err
+	}
+	return types.Stat(b, p)
+}
+
+func (l *lazyBackend) Rename(from, to string) error {
+	b, err := l.get()
+	if err != nil {
+		return err
+	}
+	return types.Rename(b, from, to)
+}
+
+// Reconnect creates the backend if it doesn't exist yet.
+func (l *lazyBackend) Reconnect() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.backend != nil {
+		return l.backend.Reconnect()
+	}
+	backend, err := l.create()
+	if err != nil {
+		return err
+	}
+	l.backend = backend
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"syscall"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/disktypes"
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// readOnlyDiskType is the memory disk type reporting its mounts as read-only,
+// like the http and archive disk types do
+type readOnlyDiskType struct {
+	disktypes.MemoryDiskType
+}
+
+func (readOnlyDiskType) Name() string {
+	return "readonly"
+}
+
+func (readOnlyDiskType) ReadOnly(mount *models.Mount) bool {
+	return true
+}
+
+// newOfflineTest mounts a memory disk type with a cache, returning the
+// mount, its offline layer and the memory backend standing in for the remote
+func newOfflineTest(t *testing.T, diskType string) (*types.Mount, *offlineBackend, *disktypes.MemoryBackend) {
+	t.Helper()
+	configService, diskTypeService := newTestConfigService(t)
+	diskTypeService.RegisterDiskType(readOnlyDiskType{})
+	store := newTestMetadataStore(t)
+	mounts := NewMountService(configService, diskTypeService, store, cache.NewCacheManager(t.TempDir(), 0, store), nil, NewConflictService(store))
+
+	mountID, err := configService.CreateMount("scratch", diskType, map[string]string{"cache": "true"}, diskTypeService)
+	if err != nil {
+		t.Fatalf("CreateMount failed: %v", err)
+	}
+	if err := mounts.Mount(mountID); err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	t.Cleanup(func() { mounts.Unmount(mountID) })
+	mount, err := mounts.GetMount(mountID)
+	if err != nil {
+		t.Fatalf("GetMount failed: %v", err)
+	}
+	return mount, mounts.offlineBackend(mountID), rawBackend(mount.Backend).(*disktypes.MemoryBackend)
+}
+
+func listNames(t *testing.T, b types.Backend, dir string) []string {
+	t.Helper()
+	infos, err := b.List(dir)
+	if err != nil {
+		t.Fatalf("List(%s) failed: %v", dir, err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestOfflineBackendJournalsChanges(t *testing.T) {
+	mount, offline, remote := newOfflineTest(t, "memory")
+	writeFiles(t, remote, map[string]string{"/a.txt": "a", "/b.txt": "b"})
+	if _, err := mount.Backend.Read("/a.txt"); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	listNames(t, mount.Backend, "/")
+
+	remote.FailWith("", syscall.ECONNREFUSED)
+	if err := mount.Backend.Write("/c.txt", []byte("c")); err != nil {
+		t.Fatalf("offline Write failed: %v", err)
+	}
+	if !offline.isOffline() {
+		t.Fatal("mount didn't go offline")
+	}
+	if err := mount.Backend.Delete("/b.txt"); err != nil {
+		t.Fatalf("offline Delete failed: %v", err)
+	}
+	// Served from the listing seen online, with the offline changes
+	if names := listNames(t, mount.Backend, "/"); fmt.Sprint(names) != "[a.txt c.txt]" {
+		t.Errorf("offline listing is %v", names)
+	}
+	if data, err := mount.Backend.Read("/a.txt"); err != nil || string(data) != "a" {
+		t.Errorf("offline Read = %q, %v, want the cached contents", data, err)
+	}
+
+	remote.FailWith("", nil)
+	if err := offline.reconnect(); err != nil {
+		t.Fatalf("reconnect failed: %v", err)
+	}
+	if names := listNames(t, remote, "/"); fmt.Sprint(names) != "[a.txt c.txt]" {
+		t.Errorf("remote holds %v after the replay", names)
+	}
+}
+
+func TestOfflineBackendRejectsChangesToReadOnlyMounts(t *testing.T) {
+	mount, offline, remote := newOfflineTest(t, "readonly")
+	writeFiles(t, remote, map[string]string{"/a.txt": "a", "/b.txt": "b"})
+	listNames(t, mount.Backend, "/")
+	remote.FailWith("", syscall.ECONNREFUSED)
+	// Nothing cached for it, so the read has to go to the remote
+	mount.Backend.Read("/b.txt")
+	if !offline.isOffline() {
+		t.Fatal("mount didn't go offline")
+	}
+
+	changes := map[string]func() error{
+		"write":  func() error { return mount.Backend.Write("/c.txt", []byte("c")) },
+		"delete": func() error { return mount.Backend.Delete("/a.txt") },
+		"rename": func() error { return types.Rename(mount.Backend, "/a.txt", "/c.txt") },
+	}
+	for op, change := range changes {
+		if err := change(); !errors.Is(err, types.ErrReadOnly) {
+			t.Errorf("offline %s error = %v, want ErrReadOnly", op, err)
+		}
+	}
+	journal, err := offline.store.GetJournal(offline.mountID)
+	if err != nil {
+		t.Fatalf("GetJournal failed: %v", err)
+	}
+	if len(journal) != 0 {
+		t.Errorf("journaled %d changes to a read-only mount", len(journal))
+	}
+}
+
+func TestOfflineBackendBoundsListings(t *testing.T) {
+	mount, offline, remote := newOfflineTest(t, "memory")
+	files := make(map[string]string)
+	for i := 0; i < offlineMaxListings+10; i++ {
+		files[fmt.Sprintf("/dir%d/a.txt", i)] = "a"
+	}
+	writeFiles(t, remote, files)
+	for i := 0; i < offlineMaxListings+10; i++ {
+		listNames(t, mount.Backend, fmt.Sprintf("/dir%d", i))
+	}
+
+	offline.mu.Lock()
+	kept, recent := len(offline.listings), offline.recent.Len()
+	_, oldest := offline.listings["/dir0"]
+	_, newest := offline.listings[fmt.Sprintf("/dir%d", offlineMaxListings+9)]
+	offline.mu.Unlock()
+	if kept != offlineMaxListings || recent != offlineMaxListings {
+		t.Errorf("kept %d listings (%d in order), want %d", kept, recent, offlineMaxListings)
+	}
+	if oldest || !newest {
+		t.Errorf("kept the oldest listing %v and the newest %v, want only the newest", oldest, newest)
+	}
+}
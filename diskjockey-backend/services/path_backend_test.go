@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// recordingBackend records the paths every call receives
+type recordingBackend struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (r *recordingBackend) record(op string, paths ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths = append(r.paths, fmt.Sprint(append([]string{op}, paths...)))
+}
+
+func (r *recordingBackend) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	paths := r.paths
+	r.paths = nil
+	return paths
+}
+
+func (r *recordingBackend) List(p string) ([]types.FileInfo, error) {
+	r.record("list", p)
+	return nil, nil
+}
+
+func (r *recordingBackend) Read(p string) ([]byte, error) {
+	r.record("read", p)
+	return nil, nil
+}
+
+func (r *recordingBackend) ReadRange(p string, offset, length int64) ([]byte, error) {
+	r.record("readrange", p)
+	return nil, nil
+}
+
+func (r *recordingBackend) Write(p string, data []byte) error {
+	r.record("write", p)
+	return nil
+}
+
+func (r *recordingBackend) Delete(p string) error {
+	r.record("delete", p)
+	return nil
+}
+
+func (r *recordingBackend) Stat(p string) (types.FileInfo, error) {
+	r.record("stat", p)
+	return types.FileInfo{}, nil
+}
+
+func (r *recordingBackend) Rename(from, to string) error {
+	r.record("rename", from, to)
+	return nil
+}
+
+func (r *recordingBackend) Reconnect() error {
+	return nil
+}
+
+// hostilePaths are paths a client could send to escape a mount or confuse a
+// remote, with the path the disk type should see, "" if it must be rejected
+var hostilePaths = []struct {
+	name string
+	in   string
+	want string
+}{
+	{"parent", "..", ""},
+	{"parent of root", "/../etc/passwd", ""},
+	{"relative parent", "../../etc/passwd", ""},
+	{"parent after a dir", "/a/../../etc/passwd", ""},
+	{"backslash parent", `..\..\etc\passwd`, ""},
+	{"mixed separators", `/a/..\..\etc`, ""},
+	{"nul", "/a.txt\x00.jpg", ""},
+	{"parent inside the mount", "/a/b/../c", "/a/c"},
+	{"relative", "a/b", "/a/b"},
+	{"double slash", "//a//b", "/a/b"},
+	{"only slashes", "//", "/"},
+	{"trailing slash", "/a/b/", "/a/b"},
+	{"dot", "/./a/.", "/a"},
+	{"nfc", "/caf\u00e9", "/caf\u00e9"},
+	{"nfd", "/cafe\u0301", "/cafe\u0301"},
+	{"fullwidth dots", "/\uff0e\uff0e/etc", "/\uff0e\uff0e/etc"},
+	{"division slash", "/a\u2215..\u2215b", "/a\u2215..\u2215b"},
+}
+
+func TestPathBackendRejectsOrNormalizes(t *testing.T) {
+	recorder := &recordingBackend{}
+	backend := &pathBackend{Backend: recorder}
+
+	ops := []struct {
+		name string
+		call func(p string) error
+		want func(clean string) []string
+	}{
+		{"list", func(p string) error { _, err := backend.List(p); return err }, nil},
+		{"read", func(p string) error { _, err := backend.Read(p); return err }, nil},
+		{"readrange", func(p string) error { _, err := backend.ReadRange(p, 0, 1); return err }, nil},
+		{"write", func(p string) error { return backend.Write(p, []byte("x")) }, nil},
+		{"delete", func(p string) error { return backend.Delete(p) }, nil},
+		{"stat", func(p string) error { _, err := backend.Stat(p); return err }, nil},
+		{"rename", func(p string) error { return backend.Rename(p, "/b") }, func(clean string) []string {
+			return []string{fmt.Sprint([]string{"rename", clean, "/b"})}
+		}},
+		{"rename to", func(p string) error { return backend.Rename("/b", p) }, func(clean string) []string {
+			return []string{fmt.Sprint([]string{"rename", "/b", clean})}
+		}},
+	}
+
+	for _, tt := range hostilePaths {
+		for _, op := range ops {
+			t.Run(tt.name+"/"+op.name, func(t *testing.T) {
+				err := op.call(tt.in)
+				calls := recorder.take()
+				if tt.want == "" {
+					if !errors.Is(err, types.ErrInvalidPath) {
+						t.Errorf("%s(%q) error = %v, want ErrInvalidPath", op.name, tt.in, err)
+					}
+					if len(calls) != 0 {
+						t.Errorf("rejected path reached the disk type: %v", calls)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("%s(%q) failed: %v", op.name, tt.in, err)
+				}
+				want := []string{fmt.Sprint([]string{op.name, tt.want})}
+				if op.want != nil {
+					want = op.want(tt.want)
+				}
+				if fmt.Sprint(calls) != fmt.Sprint(want) {
+					t.Errorf("disk type got %q, want %q", calls, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// How often pinned contents are checked for remote changes that weren't
+// reported by the mount's watcher
+const pinRefreshInterval = 15 * time.Minute
+
+// PinService keeps pinned files and directory trees available offline.
+//
+// Pinned contents are downloaded into the cache and marked pinned, so they
+// are never evicted. They are refreshed when a pin is added, when its mount
+// is mounted, when a change below it is reported and every
+// pinRefreshInterval.
+type PinService struct {
+	store        *metadata.MetadataStore
+	cacheManager *cache.CacheManager
+	mountService *MountService
+	mu           sync.Mutex
+	pins         map[string]metadata.PinRecord // cache path -> pin
+	due          map[string]bool               // pins waiting to be refreshed
+	wake         chan struct{}
+	stop         chan struct{}
+}
+
+// PinInfo is a pin with the size of its contents in the cache
+type PinInfo struct {
+	metadata.PinRecord
+	Size int64
+}
+
+// NewPinService creates a PinService for the pins in store, downloading into
+// cacheManager. Call Start once the MountService exists.
+func NewPinService(store *metadata.MetadataStore, cacheManager *cache.CacheManager) *PinService {
+	return &PinService{
+		store:        store,
+		cacheManager: cacheManager,
+		pins:         make(map[string]metadata.PinRecord),
+		due:          make(map[string]bool),
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start loads the pins, follows mount and remote changes and starts
+// refreshing pinned contents.
+func (s *PinService) Start(mountService *MountService, changes *ChangeService) error {
+	s.mountService = mountService
+
+	pins, err := s.store.GetPins()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	for key, pin := range pins {
+		s.pins[key] = pin
+		s.due[key] = true
+	}
+	s.mu.Unlock()
+
+	mountService.AddListener(func(mountID uint32, mount *types.Mount) {
+		if mount != nil {
+			s.schedule(func(pin metadata.PinRecord) bool { return pin.MountID == mountID })
+		}
+	})
+	changes.AddListener(func(event types.ChangeEvent) {
+		s.schedule(func(pin metadata.PinRecord) bool {
+			return pin.MountID == event.MountID && (isSubpath(event.Path, pin.Path) || isSubpath(pin.Path, event.Path))
+		})
+	})
+
+	go s.run()
+	return nil
+}
+
+// Close stops refreshing pinned contents.
+func (s *PinService) Close() {
+	close(s.stop)
+}
+
+// Pin keeps a file or directory tree of a mounted mount available offline.
+func (s *PinService) Pin(mountID uint32, filePath string) error {
+	clean, err := types.CleanPath(filePath)
+	if err != nil {
+		return err
+	}
+	mount, err := s.mountService.GetMount(mountID)
+	if err != nil {
+		return err
+	}
+	if _, err := types.Stat(mount.Backend, clean); err != nil {
+		return err
+	}
+
+	key := cache.CachePath(mountID, clean)
+	pin := metadata.PinRecord{MountID: mountID, Path: clean, Created: time.Now()}
+	s.mu.Lock()
+	err = s.store.PutPin(key, pin)
+	if err == nil {
+		s.pins[key] = pin
+		s.due[key] = true
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.signal()
+	return nil
+}
+
+// Unpin removes the pins of a path and everything below it. Their cached
+// contents become evictable, unless they are still covered by another pin.
+func (s *PinService) Unpin(mountID uint32, filePath string) error {
+	clean, err := types.CleanPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	var keys []string
+	for key, pin := range s.pins {
+		if pin.MountID == mountID && isSubpath(pin.Path, clean) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		s.mu.Unlock()
+		return fmt.Errorf("%s is not pinned", clean)
+	}
+	if err := s.store.DeletePins(keys...); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	for _, key := range keys {
+		delete(s.pins, key)
+		delete(s.due, key)
+	}
+	s.mu.Unlock()
+
+	for _, entry := range s.cacheManager.Files(mountID, clean) {
+		_, p, ok := cache.SplitCachePath(entry.Path)
+		if ok && entry.Pinned && !s.IsPinned(mountID, p) {
+			s.cacheManager.SetPinned(mountID, p, false)
+		}
+	}
+	return nil
+}
+
+// IsPinned reports whether a path is pinned itself or lies in a pinned directory.
+func (s *PinService) IsPinned(mountID uint32, filePath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pin := range s.pins {
+		if pin.MountID == mountID && isSubpath(filePath, pin.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pins returns the pins of a mount, or of all mounts if mountID is 0,
+// ordered by mount and path.
+func (s *PinService) Pins(mountID uint32) []PinInfo {
+	s.mu.Lock()
+	pins := make([]PinInfo, 0, len(s.pins))
+	for _, pin := range s.pins {
+		if mountID == 0 || pin.MountID == mountID {
+			pins = append(pins, PinInfo{PinRecord: pin})
+		}
+	}
+	s.mu.Unlock()
+
+	for i := range pins {
+		for _, entry := range s.cacheManager.Files(pins[i].MountID, pins[i].Path) {
+			pins[i].Size += entry.Size
+		}
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		if pins[i].MountID != pins[j].MountID {
+			return pins[i].MountID < pins[j].MountID
+		}
+		return pins[i].Path < pins[j].Path
+	})
+	return pins
+}
+
+// schedule marks the pins matching affected for refreshing.
+func (s *PinService) schedule(affected func(metadata.PinRecord) bool) {
+	s.mu.Lock()
+	scheduled := false
+	for key, pin := range s.pins {
+		if affected(pin) {
+			s.due[key] = true
+			scheduled = true
+		}
+	}
+	s.mu.Unlock()
+	if scheduled {
+		s.signal()
+	}
+}
+
+func (s *PinService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *PinService) run() {
+	ticker := time.NewTicker(pinRefreshInterval)
+	defer ticker.Stop()
+	for {
+		s.refreshDue()
+		select {
+		case <-s.stop:
+			return
+		case <-s.wake:
+		case <-ticker.C:
+			s.schedule(func(metadata.PinRecord) bool { return true })
+		}
+	}
+}
+
+// refreshDue refreshes the pins marked for refreshing whose mount is mounted.
+// The others are refreshed when their mount is mounted.
+func (s *PinService) refreshDue() {
+	s.mu.Lock()
+	due := make(map[string]metadata.PinRecord, len(s.due))
+	for key := range s.due {
+		due[key] = s.pins[key]
+	}
+	s.due = make(map[string]bool)
+	s.mu.Unlock()
+
+	for key, pin := range due {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		caching := s.mountService.cachingBackend(pin.MountID)
+		if caching == nil {
+			continue
+		}
+
+		downloaded, err := s.download(caching, pin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[PinService] Failed to refresh %s on mount %d: %v\n", pin.Path, pin.MountID, err)
+		} else if downloaded > 0 {
+			fmt.Printf("[PinService] Downloaded %d files for %s on mount %d\n", downloaded, pin.Path, pin.MountID)
+		}
+
+		s.mu.Lock()
+		if current, ok := s.pins[key]; ok {
+			current.Refreshed = time.Now()
+			current.LastError = ""
+			if err != nil {
+				current.LastError = err.Error()
+			}
+			if err := s.store.PutPin(key, current); err != nil {
+				fmt.Fprintf(os.Stderr, "[PinService] Failed to update pin %s: %v\n", pin.Path, err)
+			}
+			s.pins[key] = current
+		}
+		s.mu.Unlock()
+	}
+}
+
+// download brings the cached contents of a pin up to date and returns how
+// many files it downloaded. Once a directory tree was listed completely,
+// cached files that no longer exist in it are removed.
+func (s *PinService) download(caching *cachingBackend, pin metadata.PinRecord) (int, error) {
+	backend := caching.Backend
+	info, err := types.Stat(backend, pin.Path)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir {
+		return s.fetch(caching, pin.Path, info)
+	}
+
+	downloaded := 0
+	seen := make(map[string]bool)
+	var firstErr error
+	queue := []string{pin.Path}
+	for len(queue) > 0 {
+		select {
+		case <-s.stop:
+			return downloaded, nil
+		default:
+		}
+		dir := queue[0]
+		queue = queue[1:]
+
+		infos, err := backend.List(dir)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, info := range infos {
+			p := path.Join(dir, info.Name)
+			if info.IsDir {
+				queue = append(queue, p)
+				continue
+			}
+			seen[p] = true
+			n, err := s.fetch(caching, p, info)
+			downloaded += n
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr != nil {
+		return downloaded, firstErr
+	}
+
+	for _, entry := range s.cacheManager.Files(pin.MountID, pin.Path) {
+		if _, p, ok := cache.SplitCachePath(entry.Path); ok && !seen[p] && !entry.Dirty {
+			s.cacheManager.Remove(pin.MountID, p)
+		}
+	}
+	return downloaded, nil
+}
+
+// fetch makes sure the cache holds the current version of a file, pinned,
+// and returns 1 if the cached contents had to be replaced.
+func (s *PinService) fetch(caching *cachingBackend, p string, info types.FileInfo) (int, error) {
+	entry, ok := s.cacheManager.GetFile(cache.CachePath(caching.mountID, p))
+	if ok && entry.Dirty {
+		// The local contents are newer and will be uploaded
+		return 0, nil
+	}
+	version, hasVersion := remoteVersion(info)
+	if ok && hasVersion && entry.Version == version {
+		s.keepPinned(caching.mountID, p, entry)
+		return 0, nil
+	}
+
+	data, err := caching.Backend.Read(p)
+	if err != nil {
+		return 0, err
+	}
+	if ok && !hasVersion {
+		// Without a remote version, the contents have to be compared to
+		// tell whether the file changed
+		sum := sha256.Sum256(data)
+		if entry.Checksum == hex.EncodeToString(sum[:]) {
+			s.keepPinned(caching.mountID, p, entry)
+			return 0, nil
+		}
+	}
+	if err := s.cacheManager.PutPinned(caching.mountID, p, data, version); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// keepPinned pins the cached contents of a file that are already current
+func (s *PinService) keepPinned(mountID uint32, p string, entry *cache.CacheEntry) {
+	if !entry.Pinned {
+		s.cacheManager.SetPinned(mountID, p, true)
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/disktypes"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// versionlessDiskType is the memory disk type reporting neither ETags nor
+// modification times, like some FTP and WebDAV servers
+type versionlessDiskType struct {
+	disktypes.MemoryDiskType
+}
+
+type versionlessBackend struct {
+	*disktypes.MemoryBackend
+}
+
+func (versionlessDiskType) Name() string {
+	return "versionless"
+}
+
+func (d versionlessDiskType) New(mount *models.Mount) (types.Backend, error) {
+	b, err := d.MemoryDiskType.New(mount)
+	if err != nil {
+		return nil, err
+	}
+	return versionlessBackend{b.(*disktypes.MemoryBackend)}, nil
+}
+
+func (b versionlessBackend) List(p string) ([]types.FileInfo, error) {
+	infos, err := b.MemoryBackend.List(p)
+	for i := range infos {
+		infos[i].ETag, infos[i].ModTime = "", time.Time{}
+	}
+	return infos, err
+}
+
+func (b versionlessBackend) Stat(p string) (types.FileInfo, error) {
+	info, err := b.MemoryBackend.Stat(p)
+	info.ETag, info.ModTime = "", time.Time{}
+	return info, err
+}
+
+type pinTest struct {
+	mounts  *MountService
+	pins    *PinService
+	cache   *cache.CacheManager
+	mountID uint32
+	mount   *types.Mount
+	remote  *disktypes.MemoryBackend
+}
+
+// newPinTest mounts a disk type with an unlimited cache. The pins are
+// refreshed by calling refreshDue instead of in the background.
+func newPinTest(t *testing.T, diskType string) *pinTest {
+	t.Helper()
+	configService, diskTypeService := newTestConfigService(t)
+	diskTypeService.RegisterDiskType(versionlessDiskType{})
+	store := newTestMetadataStore(t)
+	cacheManager := cache.NewCacheManager(t.TempDir(), 0, store)
+	mounts := NewMountService(configService, diskTypeService, store, cacheManager, nil, NewConflictService(store))
+	pins := NewPinService(store, cacheManager)
+	pins.mountService = mounts
+
+	mountID, err := configService.CreateMount("scratch", diskType, map[string]string{"cache": "true"}, diskTypeService)
+	if err != nil {
+		t.Fatalf("CreateMount failed: %v", err)
+	}
+	if err := mounts.Mount(mountID); err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	t.Cleanup(func() { mounts.Unmount(mountID) })
+	mount, err := mounts.GetMount(mountID)
+	if err != nil {
+		t.Fatalf("GetMount failed: %v", err)
+	}
+	remote, ok := rawBackend(mount.Backend).(*disktypes.MemoryBackend)
+	if !ok {
+		remote = rawBackend(mount.Backend).(versionlessBackend).MemoryBackend
+	}
+	return &pinTest{mounts: mounts, pins: pins, cache: cacheManager, mountID: mountID, mount: mount, remote: remote}
+}
+
+// cached returns the cache entry of a path, failing if there is none
+func (p *pinTest) cached(t *testing.T, filePath string) *cache.CacheEntry {
+	t.Helper()
+	entry, ok := p.cache.GetFile(cache.CachePath(p.mountID, filePath))
+	if !ok {
+		t.Fatalf("%s isn't cached", filePath)
+	}
+	return entry
+}
+
+// refresh refreshes every pin and returns the pin of filePath afterwards
+func (p *pinTest) refresh(t *testing.T, filePath string) PinInfo {
+	t.Helper()
+	p.pins.schedule(func(metadata.PinRecord) bool { return true })
+	p.pins.refreshDue()
+	for _, pin := range p.pins.Pins(p.mountID) {
+		if pin.Path == filePath {
+			return pin
+		}
+	}
+	t.Fatalf("%s isn't pinned", filePath)
+	return PinInfo{}
+}
+
+func TestPinnedFilesSurviveEvictionAndOutages(t *testing.T) {
+	p := newPinTest(t, "memory")
+	writeFiles(t, p.remote, map[string]string{
+		"/docs/a.txt":     "aaaaaaaaaa",
+		"/docs/sub/b.txt": "bbbbbbbbbb",
+		"/other.txt":      "oooooooooo",
+	})
+
+	if err := p.pins.Pin(p.mountID, "/docs"); err != nil {
+		t.Fatalf("Pin failed: %v", err)
+	}
+	if pin := p.refresh(t, "/docs"); pin.LastError != "" || pin.Size != 20 || pin.Refreshed.IsZero() {
+		t.Errorf("pin after refresh = %+v, want 20 bytes refreshed without error", pin)
+	}
+	for _, filePath := range []string{"/docs/a.txt", "/docs/sub/b.txt"} {
+		if !p.cached(t, filePath).Pinned {
+			t.Errorf("%s is cached but not pinned", filePath)
+		}
+	}
+	if !p.pins.IsPinned(p.mountID, "/docs/sub/b.txt") || p.pins.IsPinned(p.mountID, "/other.txt") {
+		t.Error("IsPinned doesn't match the pinned tree")
+	}
+
+	// An eviction pass down to nothing only removes what isn't pinned
+	if _, err := p.mount.Backend.Read("/other.txt"); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	p.cached(t, "/other.txt")
+	p.cache.SetMaxSize(1)
+	if _, ok := p.cache.GetFile(cache.CachePath(p.mountID, "/other.txt")); ok {
+		t.Error("unpinned file survived the eviction")
+	}
+	p.cached(t, "/docs/a.txt")
+	p.cached(t, "/docs/sub/b.txt")
+
+	// With the remote unreachable, pinned files are read from the cache
+	p.remote.FailWith("", syscall.ECONNREFUSED)
+	for filePath, want := range map[string]string{"/docs/a.txt": "aaaaaaaaaa", "/docs/sub/b.txt": "bbbbbbbbbb"} {
+		if data, err := p.mount.Backend.Read(filePath); err != nil || string(data) != want {
+			t.Errorf("offline Read(%s) = %q, %v", filePath, data, err)
+		}
+	}
+	if pin := p.refresh(t, "/docs"); pin.LastError == "" {
+		t.Error("refreshing with the remote down recorded no error")
+	}
+	p.cached(t, "/docs/a.txt")
+
+	// Once unpinned, the contents are evicted like any other
+	p.remote.FailWith("", nil)
+	if err := p.pins.Unpin(p.mountID, "/docs"); err != nil {
+		t.Fatalf("Unpin failed: %v", err)
+	}
+	p.cache.EvictLRU()
+	if files := p.cache.Files(p.mountID, "/docs"); len(files) != 0 {
+		t.Errorf("%d unpinned files survived the eviction", len(files))
+	}
+	if err := p.pins.Unpin(p.mountID, "/docs"); err == nil {
+		t.Error("unpinned a path that isn't pinned")
+	}
+}
+
+func TestPinRefreshDownloadsChangedFiles(t *testing.T) {
+	for _, diskType := range []string{"memory", "versionless"} {
+		t.Run(diskType, func(t *testing.T) {
+			p := newPinTest(t, diskType)
+			writeFiles(t, p.remote, map[string]string{"/docs/a.txt": "a1", "/docs/b.txt": "b1"})
+			if err := p.pins.Pin(p.mountID, "/docs"); err != nil {
+				t.Fatalf("Pin failed: %v", err)
+			}
+			caching := p.mounts.cachingBackend(p.mountID)
+			pin := p.pins.Pins(p.mountID)[0].PinRecord
+			if n, err := p.pins.download(caching, pin); err != nil || n != 2 {
+				t.Fatalf("first download = %d, %v, want both files", n, err)
+			}
+
+			// Unchanged files are kept, whether they can be told apart by
+			// their version or only by their contents
+			if n, err := p.pins.download(caching, pin); err != nil || n != 0 {
+				t.Errorf("download of unchanged files = %d, %v, want none", n, err)
+			}
+
+			writeFiles(t, p.remote, map[string]string{"/docs/a.txt": "a2"})
+			if n, err := p.pins.download(caching, pin); err != nil || n != 1 {
+				t.Errorf("download after a change = %d, %v, want the changed file", n, err)
+			}
+			if data, ok := p.cache.Get(p.mountID, "/docs/a.txt"); !ok || string(data) != "a2" {
+				t.Errorf("cache holds %q, %v, want the new contents", data, ok)
+			}
+
+			// Files removed from the remote are removed from the cache
+			if err := p.remote.Delete("/docs/b.txt"); err != nil {
+				t.Fatal(err)
+			}
+			p.refresh(t, "/docs")
+			if files := p.cache.Files(p.mountID, "/docs"); len(files) != 1 {
+				t.Errorf("cache holds %d files after one was removed, want 1", len(files))
+			}
+		})
+	}
+}
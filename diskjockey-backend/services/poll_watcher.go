@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// Defaults for the watch_* mount options of polled mounts
+const (
+	pollDefaultDepth       = 5
+	pollDefaultMaxEntries  = 10000
+	pollDefaultInterval    = 30 * time.Second
+	pollDefaultMaxInterval = 10 * time.Minute
+	// A poll never starts sooner than this many times the last walk took, so
+	// slow servers are walked less often
+	pollWalkTimeFactor = 4
+)
+
+// pollOptions controls what a pollWatcher walks and how often
+type pollOptions struct {
+	paths       []string      // Directories to walk, relative to the mount
+	depth       int           // Directory levels below each path to walk
+	maxEntries  int           // Entries after which a walk stops
+	interval    time.Duration // Interval while changes are being seen
+	maxInterval time.Duration // Interval the watcher backs off to while idle
+}
+
+// pollOptionsFromMount reads the watch_* options of a mount, using defaults
+// for anything missing or invalid.
+func pollOptionsFromMount(mount *models.Mount) pollOptions {
+	opts := pollOptions{
+		paths:       []string{"/"},
+		depth:       pollDefaultDepth,
+		maxEntries:  pollDefaultMaxEntries,
+		interval:    pollDefaultInterval,
+		maxInterval: pollDefaultMaxInterval,
+	}
+
+	if v := mount.Option("watch_paths"); v != "" {
+		opts.paths = nil
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				opts.paths = append(opts.paths, path.Clean("/"+p))
+			}
+		}
+	}
+	if n, err := strconv.Atoi(mount.Option("watch_depth")); err == nil && n >= 0 {
+		opts.depth = n
+	}
+	if n, err := strconv.Atoi(mount.Option("watch_max_entries")); err == nil && n > 0 {
+		opts.maxEntries = n
+	}
+	if n, err := strconv.Atoi(mount.Option("watch_interval")); err == nil && n > 0 {
+		opts.interval = time.Duration(n) * time.Second
+	}
+	if n, err := strconv.Atoi(mount.Option("watch_max_interval")); err == nil && n > 0 {
+		opts.maxInterval = time.Duration(n) * time.Second
+	}
+	if opts.maxInterval < opts.interval {
+		opts.maxInterval = opts.interval
+	}
+	return opts
+}
+
+// pollWatcher detects remote changes on backends without change notification
+// by periodically walking the configured directories and comparing the result
+// with the snapshot of the previous walk, which is kept in the metadata store.
+//
+// The interval doubles after each walk without changes, up to maxInterval,
+// and drops back to interval as soon as something changes.
+type pollWatcher struct {
+	backend types.Backend
+	store   *metadata.MetadataStore
+	mountID uint32
+	opts    pollOptions
+}
+
+func newPollWatcher(backend types.Backend, store *metadata.MetadataStore, mountID uint32, opts pollOptions) *pollWatcher {
+	return &pollWatcher{backend: backend, store: store, mountID: mountID, opts: opts}
+}
+
+// Watch implements types.Watcher. The snapshot is kept in the metadata store
+// rather than in the watch state, as it is too large for a cursor.
+func (w *pollWatcher) Watch(ctx context.Context, state types.WatchState, emit func(types.ChangeEvent)) error {
+	interval := w.opts.interval
+	for {
+		started := time.Now()
+		changed, err := w.poll(emit)
+		if err != nil {
+			return err
+		}
+
+		if changed {
+			interval = w.opts.interval
+		} else if interval *= 2; interval > w.opts.maxInterval {
+			interval = w.opts.maxInterval
+		}
+		delay := interval
+		if walkTime := time.Since(started) * pollWalkTimeFactor; walkTime > delay {
+			delay = walkTime
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// poll walks the backend once, emits the differences to the stored snapshot
+// and stores the new one. It reports whether anything changed. The first
+// walk of a mount only records the snapshot.
+func (w *pollWatcher) poll(emit func(types.ChangeEvent)) (bool, error) {
+	previous, err := w.store.GetSnapshot(w.mountID)
+	if err != nil {
+		return false, err
+	}
+
+	current, listed, err := w.walk()
+	if err != nil {
+		return false, err
+	}
+
+	if previous == nil {
+		return false, w.store.SetSnapshot(w.mountID, current)
+	}
+
+	changed := false
+	for p, record := range current {
+		old, ok := previous[p]
+		switch {
+		case !ok:
+			emit(types.ChangeEvent{Path: p, Kind: types.ChangeCreated, IsDir: record.IsDir})
+		case old.IsDir != record.IsDir:
+			emit(types.ChangeEvent{Path: p, Kind: types.ChangeDeleted, IsDir: old.IsDir})
+			emit(types.ChangeEvent{Path: p, Kind: types.ChangeCreated, IsDir: record.IsDir})
+		case !record.IsDir && recordChanged(old, record):
+			emit(types.ChangeEvent{Path: p, Kind: types.ChangeModified})
+		default:
+			continue
+		}
+		changed = true
+	}
+
+	// Only entries missing from a directory that was listed were removed,
+	// anything else wasn't walked this time, e.g. because of the entry limit
+	removed := make(map[string]bool)
+	for p := range previous {
+		if _, ok := current[p]; !ok && listed[path.Dir(p)] {
+			removed[p] = true
+		}
+	}
+	for p, old := range previous {
+		if _, ok := current[p]; ok {
+			continue
+		}
+		switch {
+		case ancestorRemoved(p, removed):
+			// Reported with the removed directory
+		case removed[p]:
+			emit(types.ChangeEvent{Path: p, Kind: types.ChangeDeleted, IsDir: old.IsDir})
+			changed = true
+		default:
+			current[p] = old
+		}
+	}
+
+	return changed, w.store.SetSnapshot(w.mountID, current)
+}
+
+// walk lists the configured directories breadth first, down to the
+// configured depth and until the entry limit is reached. It returns the
+// entries found and the set of directories that were listed completely.
+//
+// The directory that reaches the limit is truncated to its first entries by
+// name, so the same entries are watched on every walk.
+func (w *pollWatcher) walk() (map[string]metadata.FileRecord, map[string]bool, error) {
+	type dir struct {
+		path  string
+		depth int
+	}
+
+	records := make(map[string]metadata.FileRecord)
+	listed := make(map[string]bool)
+	queue := make([]dir, 0, len(w.opts.paths))
+	for _, p := range w.opts.paths {
+		queue = append(queue, dir{path: p})
+	}
+
+	for len(queue) > 0 && len(records) < w.opts.maxEntries {
+		d := queue[0]
+		queue = queue[1:]
+
+		entries, err := w.backend.List(d.path)
+		if err != nil {
+			if d.depth == 0 {
+				// A configured path failing usually means the remote is unreachable
+				return nil, nil, err
+			}
+			fmt.Fprintf(os.Stderr, "[PollWatcher] Mount %d: failed to list %s: %v\n", w.mountID, d.path, err)
+			continue
+		}
+		if remaining := w.opts.maxEntries - len(records); len(entries) > remaining {
+			fmt.Printf("[PollWatcher] Mount %d: entry limit of %d reached, watching part of %s\n", w.mountID, w.opts.maxEntries, d.path)
+			sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+			entries = entries[:remaining]
+		} else {
+			listed[d.path] = true
+		}
+
+		for _, entry := range entries {
+			p := path.Join(d.path, entry.Name)
+			records[p] = metadata.FileRecord{
+				Size:    entry.Size,
+				IsDir:   entry.IsDir,
+				ModTime: entry.ModTime,
+				ETag:    entry.ETag,
+			}
+			if entry.IsDir && d.depth < w.opts.depth {
+				queue = append(queue, dir{path: p, depth: d.depth + 1})
+			}
+		}
+	}
+
+	return records, listed, nil
+}
+
+// recordChanged reports whether a file's content appears to have changed,
+// comparing only the attributes both records have.
+func recordChanged(old, record metadata.FileRecord) bool {
+	if old.Size != record.Size {
+		return true
+	}
+	if old.ETag != "" && record.ETag != "" {
+		return old.ETag != record.ETag
+	}
+	if !old.ModTime.IsZero() && !record.ModTime.IsZero() {
+		return !old.ModTime.Equal(record.ModTime)
+	}
+	return false
+}
+
+// ancestorRemoved reports whether a directory containing p was removed.
+func ancestorRemoved(p string, removed map[string]bool) bool {
+	for dir := path.Dir(p); dir != "/" && dir != "."; dir = path.Dir(dir) {
+		if removed[dir] {
+			return true
+		}
+	}
+	return false
+}
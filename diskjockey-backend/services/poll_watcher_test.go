@@ -0,0 +1,187 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/disktypes"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+func newTestMetadataStore(t *testing.T) *metadata.MetadataStore {
+	t.Helper()
+	store, err := metadata.OpenMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	if err != nil {
+		t.Fatalf("failed to open metadata store: %v", err)
+	}
+	t.Cleanup(store.Close)
+	return store
+}
+
+func newTestMemoryBackend(t *testing.T, options map[string]string) *disktypes.MemoryBackend {
+	t.Helper()
+	b, err := disktypes.MemoryDiskType{}.New(&models.Mount{Options: options})
+	if err != nil {
+		t.Fatalf("failed to create memory backend: %v", err)
+	}
+	return b.(*disktypes.MemoryBackend)
+}
+
+func writeFiles(t *testing.T, b types.Backend, files map[string]string) {
+	t.Helper()
+	for p, data := range files {
+		if err := b.Write(p, []byte(data)); err != nil {
+			t.Fatalf("failed to write %s: %v", p, err)
+		}
+	}
+}
+
+// pollEvents polls once and returns the events as sorted "kind path" strings
+func pollEvents(t *testing.T, w *pollWatcher) []string {
+	t.Helper()
+	var events []string
+	if _, err := w.poll(func(event types.ChangeEvent) {
+		events = append(events, fmt.Sprintf("%d %s", event.Kind, event.Path))
+	}); err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	sort.Strings(events)
+	return events
+}
+
+func TestPollWatcherReportsChanges(t *testing.T) {
+	backend := newTestMemoryBackend(t, nil)
+	writeFiles(t, backend, map[string]string{"/a.txt": "a", "/b.txt": "b", "/dir/c.txt": "c"})
+	opts := pollOptionsFromMount(&models.Mount{})
+	w := newPollWatcher(backend, newTestMetadataStore(t), 1, opts)
+
+	if events := pollEvents(t, w); len(events) != 0 {
+		t.Fatalf("first poll reported %v, want only a snapshot", events)
+	}
+
+	writeFiles(t, backend, map[string]string{"/a.txt": "changed", "/dir/new.txt": "new"})
+	if err := backend.Delete("/b.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	want := []string{
+		fmt.Sprintf("%d /a.txt", types.ChangeModified),
+		fmt.Sprintf("%d /b.txt", types.ChangeDeleted),
+		fmt.Sprintf("%d /dir/new.txt", types.ChangeCreated),
+	}
+	sort.Strings(want)
+	if events := pollEvents(t, w); fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Fatalf("second poll reported %v, want %v", events, want)
+	}
+
+	if events := pollEvents(t, w); len(events) != 0 {
+		t.Fatalf("poll without changes reported %v", events)
+	}
+}
+
+func TestPollWatcherTruncatesAtEntryLimit(t *testing.T) {
+	backend := newTestMemoryBackend(t, nil)
+	writeFiles(t, backend, map[string]string{"/1": "", "/2": "", "/3": "", "/4": "", "/5": ""})
+	opts := pollOptionsFromMount(&models.Mount{Options: map[string]string{"watch_max_entries": "3"}})
+	w := newPollWatcher(backend, newTestMetadataStore(t), 1, opts)
+
+	// A directory over the limit is watched in part instead of not at all
+	records, listed, err := w.walk()
+	if err != nil {
+		t.Fatalf("walk failed: %v", err)
+	}
+	var paths []string
+	for p := range records {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	if fmt.Sprint(paths) != "[/1 /2 /3]" {
+		t.Fatalf("walk returned %v, want the first 3 entries by name", paths)
+	}
+	if listed["/"] {
+		t.Errorf("truncated directory is marked as listed completely")
+	}
+
+	pollEvents(t, w)
+	writeFiles(t, backend, map[string]string{"/2": "changed"})
+	// Entries beyond the limit are not reported as deleted
+	if err := backend.Delete("/5"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	want := fmt.Sprintf("[%d /2]", types.ChangeModified)
+	if events := pollEvents(t, w); fmt.Sprint(events) != want {
+		t.Fatalf("poll reported %v, want %v", events, want)
+	}
+}
+
+// pollableDiskType is the memory disk type offering the watch options, like
+// the disk types that are polled do
+type pollableDiskType struct {
+	disktypes.MemoryDiskType
+}
+
+func (pollableDiskType) Name() string {
+	return "pollable"
+}
+
+func (d pollableDiskType) ConfigTemplate() types.DiskTypeConfigTemplate {
+	template := d.MemoryDiskType.ConfigTemplate()
+	template["watch"] = types.DiskTypeConfigField{Type: "bool"}
+	return template
+}
+
+func TestWatchServicePollsOnlyDiskTypesWithWatchOptions(t *testing.T) {
+	configService, diskTypeService := newTestConfigService(t)
+	diskTypeService.RegisterDiskType(pollableDiskType{})
+	store := newTestMetadataStore(t)
+	cacheManager := cache.NewCacheManager(t.TempDir(), 0, store)
+	mountService := NewMountService(configService, diskTypeService, store, cacheManager, nil, nil)
+	watchService := NewWatchService(store, NewChangeService(), mountService)
+
+	tests := []struct {
+		name     string
+		diskType string
+		options  map[string]string
+		polled   bool
+	}{
+		{name: "memory", diskType: "memory", polled: false},
+		{name: "pollable", diskType: "pollable", options: map[string]string{"cache": "true"}, polled: true},
+		{name: "disabled", diskType: "pollable", options: map[string]string{"watch": "false"}, polled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := map[string]string{"path": "/" + tt.name}
+			for k, v := range tt.options {
+				options[k] = v
+			}
+			mountID, err := configService.CreateMount(tt.name, tt.diskType, options, diskTypeService)
+			if err != nil {
+				t.Fatalf("CreateMount failed: %v", err)
+			}
+			if err := mountService.Mount(mountID); err != nil {
+				t.Fatalf("Mount failed: %v", err)
+			}
+			t.Cleanup(func() { mountService.Unmount(mountID) })
+			mount, err := mountService.GetMount(mountID)
+			if err != nil {
+				t.Fatalf("GetMount failed: %v", err)
+			}
+
+			watcher, polled := watchService.pollWatcher(mountID, mount)
+			if polled != tt.polled {
+				t.Fatalf("polled = %v, want %v", polled, tt.polled)
+			}
+			if !polled {
+				return
+			}
+			// The walk must not see the cache or local changes
+			if _, ok := watcher.(*pollWatcher).backend.(*disktypes.MemoryBackend); !ok {
+				t.Errorf("polled backend is %T, want the disk type backend", watcher.(*pollWatcher).backend)
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+const (
+	// Delay before the first retry of a failed upload, doubled for each
+	// further attempt up to uploadMaxRetryDelay
+	uploadRetryDelay    = 5 * time.Second
+	uploadMaxRetryDelay = 10 * time.Minute
+	// Attempts after which an upload is marked failed
+	uploadMaxAttempts = 10
+	// How long synced uploads are kept for status reporting
+	uploadSyncedRetention = time.Hour
+)
+
+// UploadService uploads files written to write-back mounts in the background.
+//
+// Writes land in the cache as dirty entries and are queued in the metadata
+// store, so they survive restarts. Each queued path is pending, in_progress,
+// synced or failed. Failed attempts are retried with exponential backoff
+// until uploadMaxAttempts is reached. Uploads go through the offline layer
+// of their mount: while the remote can't be reached they wait for the mount
+// to come back online without using up attempts, and remotes that are read
+// only fail them right away. Retry queues failed uploads again.
+type UploadService struct {
+	store        *metadata.MetadataStore
+	cacheManager *cache.CacheManager
+	mountService *MountService
+	mu           sync.Mutex // Serialises changes to queue records
+	wake         chan struct{}
+	stop         chan struct{}
+}
+
+// NewUploadService creates an UploadService for the queue in store, uploading
+// contents from cacheManager. Call Start once the MountService exists.
+func NewUploadService(store *metadata.MetadataStore, cacheManager *cache.CacheManager) *UploadService {
+	return &UploadService{
+		store:        store,
+		cacheManager: cacheManager,
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start resets uploads interrupted by the last shutdown, queues dirty cache
+// entries that lost their queue record and starts uploading.
+func (s *UploadService) Start(mountService *MountService) error {
+	s.mountService = mountService
+
+	reset, err := s.store.ResetUploads()
+	if err != nil {
+		return err
+	}
+	if reset > 0 {
+		fmt.Printf("[UploadService] Resuming %d interrupted uploads\n", reset)
+	}
+
+	records, err := s.store.GetUploads()
+	if err != nil {
+		return err
+	}
+	for _, entry := range s.cacheManager.DirtyFiles() {
+		if _, ok := records[entry.Path]; ok {
+			continue
+		}
+		if mountID, filePath, ok := cache.SplitCachePath(entry.Path); ok {
+			if err := s.Enqueue(mountID, filePath); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Mounts coming back may have uploads waiting
+	mountService.AddListener(func(mountID uint32, mount *types.Mount) {
+		if mount != nil {
+			s.signal()
+		}
+	})
+
+	go s.run()
+	return nil
+}
+
+// Close stops uploading. Uploads in progress are resumed on the next Start.
+func (s *UploadService) Close() {
+	close(s.stop)
+	if _, err := s.store.ResetUploads(); err != nil {
+		fmt.Fprintf(os.Stderr, "[UploadService] Failed to reset uploads in progress: %v\n", err)
+	}
+}
+
+// Enqueue queues the cached contents of a file for upload, replacing any
+// earlier upload of the same path.
+func (s *UploadService) Enqueue(mountID uint32, filePath string) error {
+	now := time.Now()
+	s.mu.Lock()
+	err := s.store.PutUpload(cache.CachePath(mountID, filePath), metadata.UploadRecord{
+		MountID:     mountID,
+		Path:        filePath,
+		Status:      metadata.UploadPending,
+		NextAttempt: now,
+		Updated:     now,
+	})
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.signal()
+	return nil
+}
+
+// Cancel removes the queued uploads of a path and everything below it and
+// reports whether any were still waiting to be uploaded.
+func (s *UploadService) Cancel(mountID uint32, filePath string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records, err := s.store.GetUploads()
+	if err != nil {
+		return false, err
+	}
+	var keys []string
+	waiting := false
+	for key, record := range records {
+		if record.MountID != mountID || !isSubpath(record.Path, filePath) {
+			continue
+		}
+		keys = append(keys, key)
+		if record.Status != metadata.UploadSynced {
+			waiting = true
+		}
+	}
+	return waiting, s.store.DeleteUploads(keys...)
+}
+
+// Retry queues the failed uploads of a path and everything below it again,
+// with their attempts reset, and makes pending ones waiting for a retry due
+// right away. It returns how many uploads were queued again.
+func (s *UploadService) Retry(mountID uint32, filePath string) (int, error) {
+	clean, err := types.CleanPath(filePath)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	records, err := s.store.GetUploads()
+	if err != nil {
+		s.mu.Unlock()
+		return 0, err
+	}
+	now := time.Now()
+	retried := 0
+	for key, record := range records {
+		if (mountID != 0 && record.MountID != mountID) || !isSubpath(record.Path, clean) {
+			continue
+		}
+		if record.Status != metadata.UploadFailed && record.Status != metadata.UploadPending {
+			continue
+		}
+		record.Status = metadata.UploadPending
+		record.Attempts = 0
+		record.NextAttempt = now
+		record.Updated = now
+		if err := s.store.PutUpload(key, record); err != nil {
+			s.mu.Unlock()
+			return retried, err
+		}
+		retried++
+	}
+	s.mu.Unlock()
+	if retried > 0 {
+		fmt.Printf("[UploadService] Retrying %d uploads of mount %d under %s\n", retried, mountID, clean)
+		s.signal()
+	}
+	return retried, nil
+}
+
+// Queue returns the queued uploads of a mount, or of all mounts if mountID
+// is 0, ordered by mount and path.
+func (s *UploadService) Queue(mountID uint32) ([]metadata.UploadRecord, error) {
+	records, err := s.store.GetUploads()
+	if err != nil {
+		return nil, err
+	}
+	queue := make([]metadata.UploadRecord, 0, len(records))
+	for _, record := range records {
+		if mountID == 0 || record.MountID == mountID {
+			queue = append(queue, record)
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		if queue[i].MountID != queue[j].MountID {
+			return queue[i].MountID < queue[j].MountID
+		}
+		return queue[i].Path < queue[j].Path
+	})
+	return queue, nil
+}
+
+func (s *UploadService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run uploads due items until stopped, sleeping until the next retry is due
+// or new work is queued.
+func (s *UploadService) run() {
+	for {
+		delay := s.processDue()
+		timer := time.NewTimer(delay)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// processDue uploads every pending item that is due, removes synced items
+// past their retention and returns how long until the next retry is due.
+func (s *UploadService) processDue() time.Duration {
+	delay := uploadMaxRetryDelay
+	records, err := s.store.GetUploads()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[UploadService] Failed to read upload queue: %v\n", err)
+		return uploadRetryDelay
+	}
+
+	var expired []string
+	for key, record := range records {
+		select {
+		case <-s.stop:
+			return delay
+		default:
+		}
+
+		switch record.Status {
+		case metadata.UploadSynced:
+			if time.Since(record.Updated) > uploadSyncedRetention {
+				expired = append(expired, key)
+			}
+			continue
+		case metadata.UploadPending:
+		default:
+			continue
+		}
+
+		if wait := time.Until(record.NextAttempt); wait > 0 {
+			if wait < delay {
+				delay = wait
+			}
+			continue
+		}
+		if next := s.upload(key, record); next > 0 && next < delay {
+			delay = next
+		}
+	}
+
+	if len(expired) > 0 {
+		s.removeSynced(expired)
+	}
+	return delay
+}
+
+// removeSynced removes records that are still synced, i.e. weren't queued
+// again meanwhile.
+func (s *UploadService) removeSynced(keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var synced []string
+	for _, key := range keys {
+		if record, ok, err := s.store.GetUpload(key); err == nil && ok && record.Status == metadata.UploadSynced {
+			synced = append(synced, key)
+		}
+	}
+	if err := s.store.DeleteUploads(synced...); err != nil {
+		fmt.Fprintf(os.Stderr, "[UploadService] Failed to remove synced uploads: %v\n", err)
+	}
+}
+
+// upload uploads a single item and updates its record. It returns the delay
+// before the item should be retried, or 0 if it's done or waits for its mount.
+func (s *UploadService) upload(key string, record metadata.UploadRecord) time.Duration {
+	backend := s.mountService.cachingBackend(record.MountID)
+	if backend == nil {
+		// Retried when the mount is mounted again
+		return 0
+	}
+	offline := s.mountService.offlineBackend(record.MountID)
+	if offline != nil && offline.isOffline() {
+		// Retried once the remote can be reached again
+		return uploadRetryDelay
+	}
+
+	record.Status = metadata.UploadInProgress
+	record.Updated = time.Now()
+	if !s.update(key, record, metadata.UploadPending) {
+		return 0
+	}
+
+	data, ok := s.cacheManager.Get(record.MountID, record.Path)
+	if !ok {
+		record.Status = metadata.UploadFailed
+		record.LastError = "cached contents are missing"
+		record.Updated = time.Now()
+		s.update(key, record, metadata.UploadInProgress)
+		fmt.Fprintf(os.Stderr, "[UploadService] Cannot upload %s to mount %d: %s\n", record.Path, record.MountID, record.LastError)
+		return 0
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	base := ""
+	if entry, ok := s.cacheManager.GetFile(key); ok && entry.Dirty {
+		base = entry.Base
+	}
+
+	version, err := backend.push(record.Path, data, base)
+	if err == nil {
+		s.finish(key, record, backend, checksum, version)
+		return 0
+	}
+	var conflict *conflictError
+	if errors.As(err, &conflict) {
+		s.conflicted(key, record, backend, data, conflict)
+		return 0
+	}
+	if offline != nil && offline.fallBack(err) {
+		// The mount went offline, which isn't the upload's fault. It's
+		// retried once the mount is back online.
+		record.Status = metadata.UploadPending
+		record.LastError = err.Error()
+		record.Updated = time.Now()
+		s.update(key, record, metadata.UploadInProgress)
+		fmt.Fprintf(os.Stderr, "[UploadService] Mount %d went offline, waiting to upload %s: %v\n", record.MountID, record.Path, err)
+		return uploadRetryDelay
+	}
+
+	record.Attempts++
+	record.LastError = err.Error()
+	record.Updated = time.Now()
+	retry := uploadRetryDelay << (record.Attempts - 1)
+	if retry > uploadMaxRetryDelay || retry <= 0 {
+		retry = uploadMaxRetryDelay
+	}
+	if record.Attempts >= uploadMaxAttempts || errors.Is(err, types.ErrInvalidPath) || errors.Is(err, types.ErrReadOnly) {
+		record.Status = metadata.UploadFailed
+		retry = 0
+		fmt.Fprintf(os.Stderr, "[UploadService] Giving up uploading %s to mount %d: %v\n", record.Path, record.MountID, err)
+	} else {
+		record.Status = metadata.UploadPending
+		record.NextAttempt = time.Now().Add(retry)
+		fmt.Fprintf(os.Stderr, "[UploadService] Failed to upload %s to mount %d, retrying in %s: %v\n", record.Path, record.MountID, retry, err)
+	}
+	s.update(key, record, metadata.UploadInProgress)
+	return retry
+}
+
+// finish records a successful upload of the given remote version, unless the
+// file was written again or deleted while it was being uploaded.
+func (s *UploadService) finish(key string, record metadata.UploadRecord, backend *cachingBackend, checksum string, version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok, err := s.store.GetUpload(key)
+	if err != nil || !ok || current.Status != metadata.UploadInProgress {
+		// Deleted or queued again meanwhile
+		return
+	}
+	if !s.cacheManager.MarkClean(record.MountID, record.Path, checksum, version) {
+		// Written again since, upload the new contents over this version
+		s.cacheManager.SetBase(record.MountID, record.Path, version)
+		current.Status = metadata.UploadPending
+		current.NextAttempt = time.Now()
+	} else {
+		current.Status = metadata.UploadSynced
+		current.LastError = ""
+		fmt.Printf("[UploadService] Uploaded %s to mount %d\n", record.Path, record.MountID)
+	}
+	current.Updated = time.Now()
+	if err := s.store.PutUpload(key, current); err != nil {
+		fmt.Fprintf(os.Stderr, "[UploadService] Failed to update upload of %s: %v\n", record.Path, err)
+	}
+	backend.dropListings(record.Path)
+}
+
+// conflicted records an upload that conflicted with a remote change. Held
+// conflicts wait for the user, otherwise the local contents were either
+// written to a conflicted copy or discarded, and the upload is done.
+func (s *UploadService) conflicted(key string, record metadata.UploadRecord, backend *cachingBackend, data []byte, conflict *conflictError) {
+	record.LastError = conflict.Error()
+	record.Updated = time.Now()
+	if conflict.record.Resolution == metadata.ConflictUnresolved {
+		record.Status = metadata.UploadConflict
+	} else {
+		record.Status = metadata.UploadSynced
+		backend.dropLocal(record.Path, data)
+	}
+	s.update(key, record, metadata.UploadInProgress)
+}
+
+// update stores a record if the stored one still has the expected status,
+// i.e. it wasn't queued again or cancelled meanwhile.
+func (s *UploadService) update(key string, record metadata.UploadRecord, expected metadata.UploadStatus) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok, err := s.store.GetUpload(key)
+	if err != nil || !ok || current.Status != expected {
+		return false
+	}
+	if err := s.store.PutUpload(key, record); err != nil {
+		fmt.Fprintf(os.Stderr, "[UploadService] Failed to update upload of %s: %v\n", record.Path, err)
+		return false
+	}
+	return true
+}
+
+// isSubpath reports whether p is dir or below it.
+func isSubpath(p, dir string) bool {
+	if dir == "/" || p == dir {
+		return true
+	}
+	return len(p) > len(dir) && p[:len(dir)] == dir && p[len(dir)] == '/'
+}
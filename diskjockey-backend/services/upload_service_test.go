@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/cache"
+	"github.com/christhomas/diskjockey/diskjockey-backend/disktypes"
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+// uploadTest is a write-back memory mount with its upload queue running
+type uploadTest struct {
+	mounts    *MountService
+	uploads   *UploadService
+	conflicts *ConflictService
+	mountID   uint32
+	mount     *types.Mount
+	remote    *disktypes.MemoryBackend
+}
+
+func newUploadTest(t *testing.T, options map[string]string) *uploadTest {
+	t.Helper()
+	configService, diskTypeService := newTestConfigService(t)
+	store := newTestMetadataStore(t)
+	cacheManager := cache.NewCacheManager(t.TempDir(), 0, store)
+	uploads := NewUploadService(store, cacheManager)
+	conflicts := NewConflictService(store)
+	mounts := NewMountService(configService, diskTypeService, store, cacheManager, uploads, conflicts)
+	conflicts.Start(mounts)
+	if err := uploads.Start(mounts); err != nil {
+		t.Fatalf("failed to start uploads: %v", err)
+	}
+	t.Cleanup(uploads.Close)
+
+	mountOptions := map[string]string{"write_back": "true"}
+	for k, v := range options {
+		mountOptions[k] = v
+	}
+	mountID, err := configService.CreateMount("scratch", "memory", mountOptions, diskTypeService)
+	if err != nil {
+		t.Fatalf("CreateMount failed: %v", err)
+	}
+	if err := mounts.Mount(mountID); err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	t.Cleanup(func() { mounts.Unmount(mountID) })
+	mount, err := mounts.GetMount(mountID)
+	if err != nil {
+		t.Fatalf("GetMount failed: %v", err)
+	}
+	return &uploadTest{
+		mounts:    mounts,
+		uploads:   uploads,
+		conflicts: conflicts,
+		mountID:   mountID,
+		mount:     mount,
+		remote:    rawBackend(mount.Backend).(*disktypes.MemoryBackend),
+	}
+}
+
+// record returns the queued upload of a path
+func (u *uploadTest) record(t *testing.T, p string) metadata.UploadRecord {
+	t.Helper()
+	queue, err := u.uploads.Queue(u.mountID)
+	if err != nil {
+		t.Fatalf("Queue failed: %v", err)
+	}
+	for _, record := range queue {
+		if record.Path == p {
+			return record
+		}
+	}
+	return metadata.UploadRecord{}
+}
+
+// waitFor waits until the upload of a path is in the given status
+func (u *uploadTest) waitFor(t *testing.T, p string, status metadata.UploadStatus) metadata.UploadRecord {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		record := u.record(t, p)
+		if record.Status == status {
+			return record
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("upload of %s is %q, want %q", p, record.Status, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (u *uploadTest) remoteHolds(t *testing.T, p string, want string) {
+	t.Helper()
+	if data, err := u.remote.Read(p); err != nil || string(data) != want {
+		t.Errorf("remote %s = %q, %v, want %q", p, data, err, want)
+	}
+}
+
+func TestUploadWaitsWhileOffline(t *testing.T) {
+	u := newUploadTest(t, nil)
+	u.remote.FailWith("", syscall.ECONNREFUSED)
+
+	if err := u.mount.Backend.Write("/a.txt", []byte("local")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for status, _ := u.mounts.Status(u.mountID); status != types.MountStatusOffline; status, _ = u.mounts.Status(u.mountID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("mount status is %d, want it offline after the upload failed", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	record := u.waitFor(t, "/a.txt", metadata.UploadPending)
+	if record.Attempts != 0 {
+		t.Errorf("upload used %d attempts while the remote was unreachable", record.Attempts)
+	}
+
+	u.remote.FailWith("", nil)
+	if err := u.mounts.offlineBackend(u.mountID).reconnect(); err != nil {
+		t.Fatalf("reconnect failed: %v", err)
+	}
+	u.uploads.signal()
+	u.waitFor(t, "/a.txt", metadata.UploadSynced)
+	u.remoteHolds(t, "/a.txt", "local")
+}
+
+func TestUploadFailsRightAwayOnReadOnlyRemote(t *testing.T) {
+	u := newUploadTest(t, nil)
+	u.remote.FailWith(disktypes.MemoryOpWrite, types.ErrReadOnly)
+
+	if err := u.mount.Backend.Write("/a.txt", []byte("local")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	record := u.waitFor(t, "/a.txt", metadata.UploadFailed)
+	if record.Attempts != 1 {
+		t.Errorf("upload failed after %d attempts, want 1", record.Attempts)
+	}
+
+	// Retrying once the remote accepts writes again uploads the contents
+	u.remote.FailWith(disktypes.MemoryOpWrite, nil)
+	retried, err := u.uploads.Retry(u.mountID, "/")
+	if err != nil || retried != 1 {
+		t.Fatalf("Retry = %d, %v, want 1 upload retried", retried, err)
+	}
+	u.waitFor(t, "/a.txt", metadata.UploadSynced)
+	u.remoteHolds(t, "/a.txt", "local")
+}
+
+func TestUploadRetriesWithBackoff(t *testing.T) {
+	u := newUploadTest(t, nil)
+	u.remote.FailWith(disktypes.MemoryOpWrite, errors.New("disk full"))
+
+	if err := u.mount.Backend.Write("/a.txt", []byte("local")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	record := u.record(t, "/a.txt")
+	for record.Attempts == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		record = u.record(t, "/a.txt")
+	}
+	if record.Status != metadata.UploadPending || record.Attempts != 1 {
+		t.Fatalf("upload is %q after %d attempts, want pending after 1", record.Status, record.Attempts)
+	}
+	if wait := time.Until(record.NextAttempt); wait <= 0 || wait > uploadRetryDelay {
+		t.Errorf("next attempt in %s, want within %s", wait, uploadRetryDelay)
+	}
+	if status, _ := u.mounts.Status(u.mountID); status == types.MountStatusOffline {
+		t.Errorf("a rejected upload took the mount offline")
+	}
+
+	// Retry doesn't wait for the backoff
+	u.remote.FailWith(disktypes.MemoryOpWrite, nil)
+	if _, err := u.uploads.Retry(u.mountID, "/a.txt"); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	u.waitFor(t, "/a.txt", metadata.UploadSynced)
+	u.remoteHolds(t, "/a.txt", "local")
+}
+
+func TestUploadOfContentsWrittenDuringUpload(t *testing.T) {
+	u := newUploadTest(t, nil)
+	// Slow enough to write again while the first upload is in progress
+	u.remote.SetLatency(200 * time.Millisecond)
+
+	if err := u.mount.Backend.Write("/a.txt", []byte("first")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	u.waitFor(t, "/a.txt", metadata.UploadInProgress)
+	if err := u.mount.Backend.Write("/a.txt", []byte("second")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	u.waitFor(t, "/a.txt", metadata.UploadSynced)
+	u.remote.SetLatency(0)
+	u.remoteHolds(t, "/a.txt", "second")
+	if data, err := u.mount.Backend.Read("/a.txt"); err != nil || string(data) != "second" {
+		t.Errorf("mount reads %q, %v, want the last write", data, err)
+	}
+}
+
+func TestUploadConflict(t *testing.T) {
+	u := newUploadTest(t, map[string]string{"conflict_policy": "ask"})
+	writeFiles(t, u.remote, map[string]string{"/a.txt": "base"})
+	if _, err := u.mount.Backend.Read("/a.txt"); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	// Someone else changes the file after it was read
+	writeFiles(t, u.remote, map[string]string{"/a.txt": "remote"})
+
+	if err := u.mount.Backend.Write("/a.txt", []byte("local")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	u.waitFor(t, "/a.txt", metadata.UploadConflict)
+	u.remoteHolds(t, "/a.txt", "remote")
+	// Held for the user, the mount keeps showing the local contents
+	if data, err := u.mount.Backend.Read("/a.txt"); err != nil || string(data) != "local" {
+		t.Errorf("mount reads %q, %v, want the local contents", data, err)
+	}
+}
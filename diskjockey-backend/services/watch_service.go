@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/christhomas/diskjockey/diskjockey-backend/metadata"
+	"github.com/christhomas/diskjockey/diskjockey-backend/types"
+)
+
+const (
+	// Delay before restarting a watcher that failed, doubled on each failure
+	watchRetryMin = 5 * time.Second
+	watchRetryMax = 5 * time.Minute
+)
+
+// WatchService runs a change watcher for every mounted backend, and publishes
+// the changes it reports to the ChangeService. Backends that can't be notified
+// of changes are polled if their disk type offers the watch options, unless
+// their mount sets the watch option to false.
+type WatchService struct {
+	mu            sync.Mutex
+	store         *metadata.MetadataStore
+	changeService *ChangeService
+	mountService  *MountService
+	cancels       map[uint32]context.CancelFunc // mount ID -> running watcher
+}
+
+// NewWatchService creates a WatchService and starts following mount changes.
+func NewWatchService(store *metadata.MetadataStore, changes *ChangeService, mounts *MountService) *WatchService {
+	ws := &WatchService{
+		store:         store,
+		changeService: changes,
+		mountService:  mounts,
+		cancels:       make(map[uint32]context.CancelFunc),
+	}
+	mounts.AddListener(ws.mountChanged)
+	return ws
+}
+
+// mountChanged stops the watcher of a mount and starts a new one if the
+// mount is active and its backend can be watched.
+func (ws *WatchService) mountChanged(mountID uint32, mount *types.Mount) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if cancel, ok := ws.cancels[mountID]; ok {
+		cancel()
+		delete(ws.cancels, mountID)
+	}
+
+	if mount == nil {
+		return
+	}
+	watcher, ok := types.FindWatcher(mount.Backend)
+	if !ok {
+		watcher, ok = ws.pollWatcher(mountID, mount)
+		if !ok {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ws.cancels[mountID] = cancel
+	go ws.run(ctx, mountID, watcher)
+}
+
+// pollWatcher returns a watcher polling the backend of a mount, using the
+// watch_* options of the mount. The disk type backend is polled directly, so
+// the walk sees the remote rather than the cache and local changes.
+func (ws *WatchService) pollWatcher(mountID uint32, mount *types.Mount) (types.Watcher, bool) {
+	model, err := ws.mountService.configService.GetMountByID(mountID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WatchService] Failed to load mount %d: %v\n", mountID, err)
+		return nil, false
+	}
+	diskType, ok := ws.mountService.disktypeService.LookupDiskType(model.DiskType)
+	if !ok {
+		return nil, false
+	}
+	// Disk types that aren't worth polling, e.g. read-only or local ones,
+	// don't offer the watch options
+	if _, ok := diskType.ConfigTemplate()["watch"]; !ok {
+		return nil, false
+	}
+	if enabled, err := strconv.ParseBool(model.Option("watch")); err == nil && !enabled {
+		return nil, false
+	}
+	return newPollWatcher(rawBackend(mount.Backend), ws.store, mountID, pollOptionsFromMount(model)), true
+}
+
+// run keeps a watcher running until ctx is cancelled, restarting it with
+// backoff when it fails.
+func (ws *WatchService) run(ctx context.Context, mountID uint32, watcher types.Watcher) {
+	state := &mountWatchState{store: ws.store, mountID: mountID}
+	emit := func(event types.ChangeEvent) {
+		event.MountID = mountID
+		ws.changeService.Publish(event)
+	}
+
+	delay := watchRetryMin
+	for {
+		err := watcher.Watch(ctx, state, emit)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[WatchService] Watcher for mount %d failed: %v\n", mountID, err)
+			ws.mountService.observe(mountID, err)
+			if errors.Is(err, types.ErrReauthRequired) {
+				// Retrying cannot help until the mount is authorized again
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > watchRetryMax {
+			delay = watchRetryMax
+		}
+	}
+}
+
+// mountWatchState stores a watcher's cursor in the metadata store.
+type mountWatchState struct {
+	store   *metadata.MetadataStore
+	mountID uint32
+}
+
+func (s *mountWatchState) Cursor() (string, error) {
+	return s.store.GetCursor(s.mountID)
+}
+
+func (s *mountWatchState) SetCursor(cursor string) error {
+	return s.store.SetCursor(s.mountID, cursor)
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrInvalidPath is returned for paths that are malformed or would resolve
+// outside the root of the mount
+var ErrInvalidPath = errors.New("invalid path")
+
+// CleanPath validates a path requested from a mount and returns it in its
+// canonical form: slash separated, absolute and without "." or ".." elements.
+// ".." elements are allowed as long as they don't climb above the root.
+// Backslashes are treated as separators while checking, as some remotes
+// (e.g. SMB) interpret them that way.
+func CleanPath(p string) (string, error) {
+	if strings.ContainsRune(p, 0) {
+		return "", fmt.Errorf("%w: contains a NUL byte", ErrInvalidPath)
+	}
+
+	depth := 0
+	for _, elem := range strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '\\' }) {
+		switch elem {
+		case ".":
+		case "..":
+			if depth--; depth < 0 {
+				return "", fmt.Errorf("%w: %q escapes the mount root", ErrInvalidPath, p)
+			}
+		default:
+			depth++
+		}
+	}
+
+	return path.Clean("/" + p), nil
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCleanPath(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		invalid bool
+	}{
+		// Canonical and relative paths
+		{in: "", want: "/"},
+		{in: "/", want: "/"},
+		{in: ".", want: "/"},
+		{in: "a/b", want: "/a/b"},
+		{in: "/a/b", want: "/a/b"},
+		{in: "./a/./b", want: "/a/b"},
+
+		// Repeated and trailing separators
+		{in: "//", want: "/"},
+		{in: "//a//b", want: "/a/b"},
+		{in: "/a/b/", want: "/a/b"},
+		{in: "/a/b//", want: "/a/b"},
+
+		// ".." inside the mount is allowed, above it is not
+		{in: "/a/../b", want: "/b"},
+		{in: "/a/b/../../c", want: "/c"},
+		{in: "..", invalid: true},
+		{in: "/..", invalid: true},
+		{in: "../etc/passwd", invalid: true},
+		{in: "/../etc/passwd", invalid: true},
+		{in: "/a/../../etc/passwd", invalid: true},
+		{in: "a/b/../../../c", invalid: true},
+		{in: "//../etc", invalid: true},
+
+		// Backslashes are separators for some remotes
+		{in: `..\etc\passwd`, invalid: true},
+		{in: `/a\..\..\etc`, invalid: true},
+		{in: `/a\b`, want: `/a\b`},
+
+		// NUL terminates paths in C APIs
+		{in: "/a\x00b", invalid: true},
+		{in: "/a.txt\x00.jpg", invalid: true},
+		{in: "\x00", invalid: true},
+
+		// Unicode is passed through unchanged, lookalikes of "." and "/" are
+		// ordinary characters
+		{in: "/caf\u00e9", want: "/caf\u00e9"},
+		{in: "/cafe\u0301", want: "/cafe\u0301"},
+		{in: "/\uff0e\uff0e/etc", want: "/\uff0e\uff0e/etc"},
+		{in: "/\u2024\u2024/etc", want: "/\u2024\u2024/etc"},
+		{in: "/a\u2215..\u2215b", want: "/a\u2215..\u2215b"},
+	}
+
+	for _, tt := range tests {
+		got, err := CleanPath(tt.in)
+		if tt.invalid {
+			if !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("CleanPath(%q) = %q, %v, want ErrInvalidPath", tt.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("CleanPath(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
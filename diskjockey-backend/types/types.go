@@ -1,9 +1,37 @@
 package types
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
 	"github.com/christhomas/diskjockey/diskjockey-backend/models"
+	"golang.org/x/oauth2"
 )
 
+// ErrReauthRequired is returned by backends whose credentials expired or were
+// revoked, so the user has to authorize the mount again
+var ErrReauthRequired = errors.New("re-authorization required")
+
+// ErrOffline is returned for operations that need the remote of a mount that
+// can't be reached
+var ErrOffline = errors.New("remote is unreachable")
+
+// ErrConflict is returned when a file changed on the remote since the version
+// local changes to it were based on
+var ErrConflict = errors.New("file changed on the remote")
+
+// ErrReadOnly is returned for changes to a mount whose disk type can only be
+// read
+var ErrReadOnly = errors.New("mount is read-only")
+
 // AppConfig holds configuration for mountpoints, cache, etc.
 type AppConfig struct {
 	SocketPath   string        `json:"socket_path"`
@@ -15,16 +43,32 @@ type AppConfig struct {
 // Mount describes an active disk type instance
 // (unique name, disk type, config, Backend)
 type Mount struct {
+	ID       uint32
 	Name     string
 	DiskType string
 	Backend  Backend
 }
 
-// FileInfo describes a file or directory returned by disk types
+// MountStatus describes the state of a mount
+type MountStatus int
+
+const (
+	MountStatusUnknown MountStatus = iota
+	MountStatusMounted
+	MountStatusUnmounted
+	MountStatusError
+	MountStatusOffline // Mounted, but the remote can't be reached
+)
+
+// FileInfo describes a file or directory returned by disk types.
+// ModTime and ETag are optional and left empty when the remote doesn't
+// provide them.
 type FileInfo struct {
-	Name  string
-	Size  int64
-	IsDir bool
+	Name    string
+	Size    int64
+	IsDir   bool
+	ModTime time.Time
+	ETag    string // Opaque version of the content, e.g. an HTTP ETag or revision
 }
 
 // Backend defines the disk type instance interface (for a mount)
@@ -36,6 +80,208 @@ type Backend interface {
 	Reconnect() error
 }
 
+// Stater is implemented by backends that can look up a single path without
+// listing its parent directory
+type Stater interface {
+	Stat(path string) (FileInfo, error)
+}
+
+// Stat returns information about a path, using the backend's Stat if it
+// implements Stater and looking the path up in its parent directory otherwise.
+// A missing path returns an error wrapping fs.ErrNotExist.
+func Stat(b Backend, p string) (FileInfo, error) {
+	if s, ok := b.(Stater); ok {
+		return s.Stat(p)
+	}
+
+	clean := path.Clean("/" + p)
+	if clean == "/" {
+		return FileInfo{Name: "/", IsDir: true}, nil
+	}
+	infos, err := b.List(path.Dir(clean))
+	if err != nil {
+		return FileInfo{}, err
+	}
+	name := path.Base(clean)
+	for _, info := range infos {
+		if info.Name == name {
+			return info, nil
+		}
+	}
+	return FileInfo{}, &fs.PathError{Op: "stat", Path: clean, Err: fs.ErrNotExist}
+}
+
+// ConditionalWriter is implemented by backends whose remote can refuse a
+// write atomically when a file no longer has the given ETag. WriteIfMatch
+// returns ErrConflict in that case.
+type ConditionalWriter interface {
+	WriteIfMatch(path string, data []byte, etag string) error
+}
+
+// RangeReader is implemented by backends that can read part of a file
+// without downloading all of it
+type RangeReader interface {
+	ReadRange(path string, offset, length int64) ([]byte, error)
+}
+
+// ReadRange reads up to length bytes of a file starting at offset, using the
+// backend's ReadRange if it implements RangeReader and reading the whole file
+// otherwise. Less data is returned when the range ends past the end of the
+// file.
+func ReadRange(b Backend, p string, offset, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("read %s: invalid range %d+%d", p, offset, length)
+	}
+	if r, ok := b.(RangeReader); ok {
+		return r.ReadRange(p, offset, length)
+	}
+	data, err := b.Read(p)
+	if err != nil {
+		return nil, err
+	}
+	return SliceRange(data, offset, length), nil
+}
+
+// SliceRange returns the part of data a range covers, for backends that had
+// to read more than the range.
+func SliceRange(data []byte, offset, length int64) []byte {
+	if offset >= int64(len(data)) {
+		return []byte{}
+	}
+	end := int64(len(data))
+	if length < end-offset {
+		end = offset + length
+	}
+	return data[offset:end]
+}
+
+// Renamer is implemented by backends that can move a file or directory
+type Renamer interface {
+	Rename(from, to string) error
+}
+
+// Rename moves a file or directory, using the backend's Rename if it
+// implements Renamer. Other backends can only move files, by copying them.
+func Rename(b Backend, from, to string) error {
+	if r, ok := b.(Renamer); ok {
+		return r.Rename(from, to)
+	}
+
+	info, err := Stat(b, from)
+	if err != nil {
+		return err
+	}
+	if info.IsDir {
+		return fmt.Errorf("rename %s: directories can't be moved on this mount", from)
+	}
+	data, err := b.Read(from)
+	if err != nil {
+		return err
+	}
+	if err := b.Write(to, data); err != nil {
+		return err
+	}
+	return b.Delete(from)
+}
+
+// IsUnreachable reports whether err means the remote couldn't be reached, as
+// opposed to the remote rejecting the operation. Failing to dial or to look
+// up the host, timeouts and dropped connections count as unreachable. Other
+// network errors, such as a certificate that doesn't verify, don't: the
+// remote answered, and retrying won't help.
+func IsUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	for _, target := range []error{
+		ErrOffline, net.ErrClosed, os.ErrDeadlineExceeded, io.ErrUnexpectedEOF,
+		syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE,
+		syscall.ETIMEDOUT, syscall.EHOSTUNREACH, syscall.ENETUNREACH, syscall.ENETDOWN,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Wrapper is implemented by backends that decorate another backend
+type Wrapper interface {
+	Unwrap() Backend
+}
+
+// ChangeKind describes what happened to a path
+type ChangeKind int
+
+const (
+	ChangeUnknown ChangeKind = iota
+	ChangeCreated
+	ChangeModified
+	ChangeDeleted
+)
+
+// ChangeEvent describes a change to a path in a mount that was made outside
+// of DiskJockey, e.g. on another machine
+type ChangeEvent struct {
+	MountID uint32
+	Path    string
+	Kind    ChangeKind
+	IsDir   bool
+}
+
+// WatchState persists a watcher's position (e.g. a change cursor) so it can
+// resume after a restart without reporting or missing changes
+type WatchState interface {
+	Cursor() (string, error)
+	SetCursor(cursor string) error
+}
+
+// Watcher is implemented by backends that can be notified of remote changes.
+// Watch blocks until ctx is cancelled or an error occurs, calling emit for
+// every change it sees. Paths are relative to the mount.
+type Watcher interface {
+	Watch(ctx context.Context, state WatchState, emit func(ChangeEvent)) error
+}
+
+// FindWatcher returns the Watcher implemented by b or any backend it wraps
+func FindWatcher(b Backend) (Watcher, bool) {
+	for b != nil {
+		if w, ok := b.(Watcher); ok {
+			return w, true
+		}
+		wrapper, ok := b.(Wrapper)
+		if !ok {
+			break
+		}
+		b = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// MountRegistry looks up active mounts, for disk types that read their data
+// from a file on another mount
+type MountRegistry interface {
+	GetMountByName(name string) (*Mount, error)
+}
+
+// TokenStore saves the OAuth2 token of a mount, for disk types whose backends
+// refresh their access token while mounted
+type TokenStore interface {
+	SaveToken(mountID uint32, token *oauth2.Token) error
+}
+
 // DiskType defines a disk type (template)
 type DiskType interface {
 	New(mount *models.Mount) (Backend, error)
@@ -44,6 +290,27 @@ type DiskType interface {
 	ConfigTemplate() DiskTypeConfigTemplate
 }
 
+// OAuthDiskType is implemented by disk types whose mounts are authorized with
+// an OAuth2 authorization code flow. It returns the client config (without
+// RedirectURL) and any extra parameters for the authorization URL.
+type OAuthDiskType interface {
+	DiskType
+	OAuthConfig(mount *models.Mount) (*oauth2.Config, []oauth2.AuthCodeOption, error)
+}
+
+// ReadOnlyDiskType is implemented by disk types whose mounts can't be
+// changed, or only depending on their options.
+type ReadOnlyDiskType interface {
+	DiskType
+	ReadOnly(mount *models.Mount) bool
+}
+
+// IsReadOnly reports whether a mount of a disk type can't be changed.
+func IsReadOnly(diskType DiskType, mount *models.Mount) bool {
+	r, ok := diskType.(ReadOnlyDiskType)
+	return ok && r.ReadOnly(mount)
+}
+
 // ListDiskTypes returns all registered disk types
 type DiskTypeInfo struct {
 	Name        string
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsUnreachable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"offline", fmt.Errorf("list: %w", ErrOffline), true},
+		{"refused", &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{"dial in a url error", &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route")}}, true},
+		{"dns", &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, true},
+		{"reset", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"timeout", &url.Error{Op: "Get", URL: "https://example.com", Err: os.ErrDeadlineExceeded}, true},
+		{"closed", fmt.Errorf("sftp: %w", net.ErrClosed), true},
+		{"unknown authority", &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}}, false},
+		{"hostname mismatch", &url.Error{Op: "Get", URL: "https://example.com", Err: x509.HostnameError{Host: "example.com", Certificate: &x509.Certificate{}}}, false},
+		{"canceled", &url.Error{Op: "Get", URL: "https://example.com", Err: context.Canceled}, false},
+		{"not found", &fs.PathError{Op: "read", Path: "/a", Err: fs.ErrNotExist}, false},
+		{"read only", ErrReadOnly, false},
+	}
+	for _, tt := range tests {
+		if got := IsUnreachable(tt.err); got != tt.want {
+			t.Errorf("%s: IsUnreachable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIsUnreachableWithServers(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	// A server whose certificate isn't trusted can be reached
+	_, err := client.Get(server.URL)
+	if err == nil {
+		t.Fatal("request with an untrusted certificate succeeded")
+	}
+	if IsUnreachable(err) {
+		t.Errorf("certificate error %v counts as unreachable", err)
+	}
+
+	// A closed port can't
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	_, err = client.Get("http://" + addr)
+	if err == nil {
+		t.Fatal("request to a closed port succeeded")
+	}
+	if !IsUnreachable(err) {
+		t.Errorf("connection error %v doesn't count as unreachable", err)
+	}
+}
@@ -6,7 +6,7 @@ import (
 	"io"
 	"net"
 
-	"github.com/christhomas/diskjockey/diskjockey-backend/proto/api"
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -51,8 +51,30 @@ func main() {
 		subcommand.ListMounts(client)
 	case "ls":
 		subcommand.ListDirCommand(client, newArgs[1:])
+	case "mv":
+		subcommand.MoveCommand(client, newArgs[1:])
 	case "cp":
 		subcommand.CopyCommand(client, newArgs[1:])
+	case "authorize":
+		subcommand.AuthorizeCommand(client, newArgs[1:])
+	case "watch":
+		subcommand.WatchCommand(client, newArgs[1:])
+	case "uploads":
+		subcommand.UploadsCommand(client, newArgs[1:])
+	case "retry":
+		subcommand.RetryCommand(client, newArgs[1:])
+	case "pin":
+		subcommand.PinCommand(client, newArgs[1:])
+	case "unpin":
+		subcommand.UnpinCommand(client, newArgs[1:])
+	case "pins":
+		subcommand.PinsCommand(client, newArgs[1:])
+	case "cache":
+		subcommand.CacheCommand(client, newArgs[1:])
+	case "conflicts":
+		subcommand.ConflictsCommand(client, newArgs[1:])
+	case "resolve":
+		subcommand.ResolveCommand(client, newArgs[1:])
 	default:
 		usage()
 	}
@@ -67,5 +89,16 @@ func usage() {
 	fmt.Println("  djctl --port <port> add-mount ...      # Add a new mount (not implemented)")
 	fmt.Println("  djctl --port <port> remove-mount ...   # Remove a mount (not implemented)")
 	fmt.Println("  djctl --port <port> ls <mount> [path]  # List directory contents")
+	fmt.Println("  djctl --port <port> mv <mount> <from> <to> # Rename a file or directory")
+	fmt.Println("  djctl --port <port> authorize <mount> [code] # Authorize an OAuth mount (e.g. dropbox)")
+	fmt.Println("  djctl --port <port> watch [mount...]   # Print remote changes as they happen")
+	fmt.Println("  djctl --port <port> uploads [mount]    # Show the upload queue of write-back mounts")
+	fmt.Println("  djctl --port <port> retry [mount [path]] # Retry failed uploads")
+	fmt.Println("  djctl --port <port> pin <mount> <path> # Keep a file or directory available offline")
+	fmt.Println("  djctl --port <port> unpin <mount> <path> # Stop keeping a path available offline")
+	fmt.Println("  djctl --port <port> pins [mount]       # List pinned paths")
+	fmt.Println("  djctl --port <port> cache [stats|ls|purge|max-size] ... # Show and manage the local cache")
+	fmt.Println("  djctl --port <port> conflicts [mount]  # List conflicts between local and remote changes")
+	fmt.Println("  djctl --port <port> resolve <mount> <path> <keep-both|local|remote> # Resolve a held conflict")
 	fmt.Println("  --port <port> is now REQUIRED; unix sockets are no longer supported.")
 }
@@ -0,0 +1,111 @@
+package subcommand
+
+import (
+	"fmt"
+	"os"
+
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
+	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
+	"google.golang.org/protobuf/proto"
+)
+
+// AuthorizeCommand implements: djctl authorize <mount> [code]
+// It starts the OAuth flow for a mount, prints the URL to open in a browser
+// and waits for the backend to receive the redirect. If the redirect cannot
+// reach the backend, the code shown by the provider can be passed instead.
+func AuthorizeCommand(client *ipc.Client, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Usage: djctl authorize <mount> [code]")
+		os.Exit(1)
+	}
+	mountID := lookupMountID(client, args[0])
+
+	if len(args) < 2 {
+		if err := client.SendMessage(api.MessageType_OAUTH_START_REQUEST, &api.OAuthStartRequest{MountId: mountID}); err != nil {
+			fmt.Println("Send OAuthStartRequest error:", err)
+			os.Exit(1)
+		}
+		typeReceived, payload, err := client.ReceiveMessage()
+		if err != nil {
+			fmt.Println("Receive OAuthStartResponse error:", err)
+			os.Exit(1)
+		}
+		if typeReceived != api.MessageType_OAUTH_START_RESPONSE {
+			fmt.Printf("Unexpected resp type for OAuthStartResponse: %v\n", typeReceived)
+			os.Exit(1)
+		}
+		startResp := &api.OAuthStartResponse{}
+		if err := proto.Unmarshal(payload, startResp); err != nil {
+			fmt.Println("Unmarshal error:", err)
+			os.Exit(1)
+		}
+		if startResp.Error != "" {
+			fmt.Println("Server error:", startResp.Error)
+			os.Exit(1)
+		}
+		fmt.Printf("Open this URL in a browser to authorize the mount:\n\n  %s\n\n", startResp.AuthUrl)
+		fmt.Printf("Waiting for the redirect to %s ...\n", startResp.RedirectUrl)
+	}
+
+	req := &api.OAuthFinishRequest{MountId: mountID}
+	if len(args) > 1 {
+		req.Code = args[1]
+	}
+	if err := client.SendMessage(api.MessageType_OAUTH_FINISH_REQUEST, req); err != nil {
+		fmt.Println("Send OAuthFinishRequest error:", err)
+		os.Exit(1)
+	}
+	typeReceived, payload, err := client.ReceiveMessage()
+	if err != nil {
+		fmt.Println("Receive OAuthFinishResponse error:", err)
+		os.Exit(1)
+	}
+	if typeReceived != api.MessageType_OAUTH_FINISH_RESPONSE {
+		fmt.Printf("Unexpected resp type for OAuthFinishResponse: %v\n", typeReceived)
+		os.Exit(1)
+	}
+	resp := &api.OAuthFinishResponse{}
+	if err := proto.Unmarshal(payload, resp); err != nil {
+		fmt.Println("Unmarshal error:", err)
+		os.Exit(1)
+	}
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Println("Mount authorized")
+}
+
+// lookupMountID resolves a mount name to its ID using ListMountsRequest.
+func lookupMountID(client *ipc.Client, name string) uint32 {
+	if err := client.SendMessage(api.MessageType_LIST_MOUNTS_REQUEST, &api.ListMountsRequest{}); err != nil {
+		fmt.Println("Send ListMountsRequest error:", err)
+		os.Exit(1)
+	}
+	typeReceived, payload, err := client.ReceiveMessage()
+	if err != nil {
+		fmt.Println("Receive ListMountsResponse error:", err)
+		os.Exit(1)
+	}
+	if typeReceived != api.MessageType_LIST_MOUNTS_RESPONSE {
+		fmt.Printf("Unexpected resp type for ListMountsResponse: %v\n", typeReceived)
+		os.Exit(1)
+	}
+	resp := &api.ListMountsResponse{}
+	if err := proto.Unmarshal(payload, resp); err != nil {
+		fmt.Println("Unmarshal ListMountsResponse error:", err)
+		os.Exit(1)
+	}
+	if resp.Error != "" {
+		fmt.Println("Server error (mounts):", resp.Error)
+		os.Exit(1)
+	}
+	for _, m := range resp.Mounts {
+		if m.Name == name {
+			return m.MountId
+		}
+	}
+	fmt.Printf("Mount '%s' not found\n", name)
+	os.Exit(1)
+	return 0
+}
@@ -0,0 +1,177 @@
+package subcommand
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
+	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
+)
+
+const cacheUsage = `Usage:
+  djctl cache [stats]                       # Show cache size and per-mount statistics
+  djctl cache ls <mount> [path]             # List cached files
+  djctl cache purge [mount [path]] [--pinned] # Remove cached files, pinned ones only with --pinned
+  djctl cache max-size <size>               # Change max_cache_size, e.g. 500M or 2G, 0 for unlimited`
+
+// CacheCommand implements: djctl cache [stats|ls|purge|max-size] ...
+// It shows what the local cache holds and manages it.
+func CacheCommand(client *ipc.Client, args []string) {
+	if len(args) == 0 {
+		cacheStats(client)
+		return
+	}
+	switch args[0] {
+	case "stats":
+		cacheStats(client)
+	case "ls":
+		cacheList(client, args[1:])
+	case "purge":
+		cachePurge(client, args[1:])
+	case "max-size":
+		cacheMaxSize(client, args[1:])
+	default:
+		fmt.Println(cacheUsage)
+		os.Exit(1)
+	}
+}
+
+func cacheStats(client *ipc.Client) {
+	if err := client.SendMessage(api.MessageType_CACHE_STATS_REQUEST, &api.CacheStatsRequest{}); err != nil {
+		fmt.Println("Send CacheStatsRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.CacheStatsResponse{}
+	receive(client, api.MessageType_CACHE_STATS_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+
+	limit := "unlimited"
+	if resp.MaxSize > 0 {
+		limit = fmt.Sprintf("%d bytes", resp.MaxSize)
+	}
+	fmt.Printf("Cache size: %d bytes of %s\n", resp.Size, limit)
+	for _, mount := range resp.Mounts {
+		ratio := "-"
+		if reads := mount.Hits + mount.Misses; reads > 0 {
+			ratio = fmt.Sprintf("%.1f%%", float64(mount.Hits)*100/float64(reads))
+		}
+		fmt.Printf("[%d] %d entries\t%d bytes\t%d pinned\t%d dirty\t%d hits\t%d misses\thit ratio %s\n",
+			mount.MountId, mount.Entries, mount.Size, mount.PinnedBytes, mount.DirtyBytes, mount.Hits, mount.Misses, ratio)
+	}
+}
+
+func cacheList(client *ipc.Client, args []string) {
+	if len(args) < 1 {
+		fmt.Println(cacheUsage)
+		os.Exit(1)
+	}
+	req := &api.ListCacheRequest{MountId: lookupMountID(client, args[0])}
+	if len(args) > 1 {
+		req.Path = args[1]
+	}
+	if err := client.SendMessage(api.MessageType_LIST_CACHE_REQUEST, req); err != nil {
+		fmt.Println("Send ListCacheRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.ListCacheResponse{}
+	receive(client, api.MessageType_LIST_CACHE_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	if len(resp.Entries) == 0 {
+		fmt.Println("Nothing cached")
+		return
+	}
+	for _, entry := range resp.Entries {
+		var flags []string
+		if entry.Pinned {
+			flags = append(flags, "pinned")
+		}
+		if entry.Dirty {
+			flags = append(flags, "dirty")
+		}
+		lastUsed := time.Unix(entry.LastUsed, 0).Format("2006-01-02 15:04")
+		fmt.Printf("%s\t%d bytes\tused %s\t%s\n", entry.Path, entry.Size, lastUsed, strings.Join(flags, ","))
+	}
+}
+
+func cachePurge(client *ipc.Client, args []string) {
+	req := &api.PurgeCacheRequest{}
+	var positional []string
+	for _, arg := range args {
+		if arg == "--pinned" {
+			req.IncludePinned = true
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) > 0 {
+		req.MountId = lookupMountID(client, positional[0])
+	}
+	if len(positional) > 1 {
+		req.Path = positional[1]
+	}
+	if err := client.SendMessage(api.MessageType_PURGE_CACHE_REQUEST, req); err != nil {
+		fmt.Println("Send PurgeCacheRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.PurgeCacheResponse{}
+	receive(client, api.MessageType_PURGE_CACHE_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Printf("Purged %d entries, %d bytes\n", resp.Entries, resp.Bytes)
+}
+
+func cacheMaxSize(client *ipc.Client, args []string) {
+	if len(args) < 1 {
+		fmt.Println(cacheUsage)
+		os.Exit(1)
+	}
+	size, err := parseSize(args[0])
+	if err != nil {
+		fmt.Println("Invalid size:", err)
+		os.Exit(1)
+	}
+	if err := client.SendMessage(api.MessageType_SET_CACHE_SIZE_REQUEST, &api.SetCacheSizeRequest{MaxSize: size}); err != nil {
+		fmt.Println("Send SetCacheSizeRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.SetCacheSizeResponse{}
+	receive(client, api.MessageType_SET_CACHE_SIZE_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Printf("Max cache size set to %d bytes, cache now holds %d bytes\n", size, resp.Size)
+}
+
+// parseSize parses a size in bytes with an optional K, M, G or T suffix
+// (powers of 1024).
+func parseSize(s string) (int64, error) {
+	multiplier := int64(1)
+	upper := strings.TrimSuffix(strings.ToUpper(s), "B")
+	for i, suffix := range []string{"K", "M", "G", "T"} {
+		if strings.HasSuffix(upper, suffix) {
+			multiplier = 1 << (10 * (i + 1))
+			upper = strings.TrimSuffix(upper, suffix)
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%s is negative", s)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,81 @@
+package subcommand
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
+	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
+)
+
+// ConflictsCommand implements: djctl conflicts [mount]
+// It lists files whose local changes conflicted with remote changes.
+func ConflictsCommand(client *ipc.Client, args []string) {
+	req := &api.ListConflictsRequest{}
+	if len(args) > 0 {
+		req.MountId = lookupMountID(client, args[0])
+	}
+	if err := client.SendMessage(api.MessageType_LIST_CONFLICTS_REQUEST, req); err != nil {
+		fmt.Println("Send ListConflictsRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.ListConflictsResponse{}
+	receive(client, api.MessageType_LIST_CONFLICTS_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	if len(resp.Conflicts) == 0 {
+		fmt.Println("No conflicts")
+		return
+	}
+	for _, conflict := range resp.Conflicts {
+		detected := time.Unix(conflict.Detected, 0).Format("2006-01-02 15:04")
+		fmt.Printf("[%d] %s\tdetected %s\t", conflict.MountId, conflict.Path, detected)
+		switch conflict.Resolution {
+		case api.ConflictResolution_CONFLICT_KEEP_BOTH:
+			fmt.Printf("local contents kept in %s", conflict.CopyPath)
+		case api.ConflictResolution_CONFLICT_LOCAL:
+			fmt.Print("remote overwritten")
+		case api.ConflictResolution_CONFLICT_REMOTE:
+			fmt.Print("local changes discarded")
+		default:
+			fmt.Print("unresolved")
+		}
+		if conflict.RemoteDeleted {
+			fmt.Print(" (deleted on the remote)")
+		}
+		fmt.Println()
+	}
+}
+
+// ResolveCommand implements: djctl resolve <mount> <path> <keep-both|local|remote>
+// It resolves a conflict held for the user.
+func ResolveCommand(client *ipc.Client, args []string) {
+	resolutions := map[string]api.ConflictResolution{
+		"keep-both": api.ConflictResolution_CONFLICT_KEEP_BOTH,
+		"local":     api.ConflictResolution_CONFLICT_LOCAL,
+		"remote":    api.ConflictResolution_CONFLICT_REMOTE,
+	}
+	if len(args) < 3 || resolutions[args[2]] == api.ConflictResolution_CONFLICT_UNRESOLVED {
+		fmt.Println("Usage: djctl resolve <mount> <path> <keep-both|local|remote>")
+		os.Exit(1)
+	}
+	req := &api.ResolveConflictRequest{
+		MountId:    lookupMountID(client, args[0]),
+		Path:       args[1],
+		Resolution: resolutions[args[2]],
+	}
+	if err := client.SendMessage(api.MessageType_RESOLVE_CONFLICT_REQUEST, req); err != nil {
+		fmt.Println("Send ResolveConflictRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.ResolveConflictResponse{}
+	receive(client, api.MessageType_RESOLVE_CONFLICT_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Printf("Resolved conflict of %s\n", args[1])
+}
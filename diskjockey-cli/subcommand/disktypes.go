@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/christhomas/diskjockey/diskjockey-backend/proto/api"
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
 	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
 	"google.golang.org/protobuf/proto"
 )
@@ -3,8 +3,9 @@ package subcommand
 import (
 	"fmt"
 	"os"
+	"time"
 
-	"github.com/christhomas/diskjockey/diskjockey-backend/proto/api"
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
 	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
 	"google.golang.org/protobuf/proto"
 )
@@ -20,42 +21,7 @@ func ListDirCommand(client *ipc.Client, args []string) {
 	if len(args) > 1 {
 		path = args[1]
 	}
-	// Query backend for mount list to resolve mount name to mount_id
-	if err := client.SendMessage(api.MessageType_LIST_MOUNTS_REQUEST, &api.ListMountsRequest{}); err != nil {
-		fmt.Println("Send ListMountsRequest error:", err)
-		os.Exit(1)
-	}
-	typeReceived, payload, err := client.ReceiveMessage()
-	if err != nil {
-		fmt.Println("Receive ListMountsResponse error:", err)
-		os.Exit(1)
-	}
-	if typeReceived != api.MessageType_LIST_MOUNTS_REQUEST {
-		fmt.Printf("Unexpected resp type for ListMountsResponse: %v\n", typeReceived)
-		os.Exit(1)
-	}
-	mountsResp := &api.ListMountsResponse{}
-	if err := proto.Unmarshal(payload, mountsResp); err != nil {
-		fmt.Println("Unmarshal ListMountsResponse error:", err)
-		os.Exit(1)
-	}
-	if mountsResp.Error != "" {
-		fmt.Println("Server error (mounts):", mountsResp.Error)
-		os.Exit(1)
-	}
-	var mountID uint32
-	found := false
-	for _, m := range mountsResp.Mounts {
-		if m.Name == mount {
-			mountID = m.MountId
-			found = true
-			break
-		}
-	}
-	if !found {
-		fmt.Printf("Mount '%s' not found\n", mount)
-		os.Exit(1)
-	}
+	mountID := lookupMountID(client, mount)
 	// --- ListDirRequest ---
 	if err := client.SendMessage(api.MessageType_LIST_DIR_REQUEST, &api.ListDirRequest{
 		MountId: mountID,
@@ -64,12 +30,12 @@ func ListDirCommand(client *ipc.Client, args []string) {
 		fmt.Println("Send ListDirRequest error:", err)
 		os.Exit(1)
 	}
-	typeReceived, payload, err = client.ReceiveMessage()
+	typeReceived, payload, err := client.ReceiveMessage()
 	if err != nil {
 		fmt.Println("Receive ListDirResponse error:", err)
 		os.Exit(1)
 	}
-	if typeReceived != api.MessageType_LIST_DIR_REQUEST {
+	if typeReceived != api.MessageType_LIST_DIR_RESPONSE {
 		fmt.Printf("Unexpected resp type for ListDirResponse: %v\n", typeReceived)
 		os.Exit(1)
 	}
@@ -87,6 +53,10 @@ func ListDirCommand(client *ipc.Client, args []string) {
 		if f.IsDir {
 			kind = "dir"
 		}
-		fmt.Printf("%s\t%s\t%d\n", kind, f.Name, f.Size)
+		modified := "-"
+		if f.ModTime != 0 {
+			modified = time.Unix(f.ModTime, 0).Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%s\t%s\t%d\t%s\n", kind, f.Name, f.Size, modified)
 	}
 }
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/christhomas/diskjockey/diskjockey-backend/proto/api"
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
 	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
 	"google.golang.org/protobuf/proto"
 )
@@ -33,6 +33,9 @@ func ListMounts(client *ipc.Client) {
 		os.Exit(1)
 	}
 	for _, m := range resp.Mounts {
-		fmt.Printf("Mount: %s (disk type: %s)\n", m.Name, m.DiskType)
+		fmt.Printf("Mount: %s (disk type: %s, status: %s)\n", m.Name, m.DiskType, m.Status)
+		if m.StatusError != "" {
+			fmt.Printf("  Error: %s\n", m.StatusError)
+		}
 	}
 }
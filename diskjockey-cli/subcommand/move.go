@@ -0,0 +1,30 @@
+package subcommand
+
+import (
+	"fmt"
+	"os"
+
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
+	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
+)
+
+// MoveCommand implements: djctl mv <mount> <from> <to>
+// It renames a file or directory within a mount.
+func MoveCommand(client *ipc.Client, args []string) {
+	if len(args) < 3 {
+		fmt.Println("Usage: djctl mv <mount> <from> <to>")
+		os.Exit(1)
+	}
+	req := &api.RenameRequest{MountId: lookupMountID(client, args[0]), From: args[1], To: args[2]}
+	if err := client.SendMessage(api.MessageType_RENAME_REQUEST, req); err != nil {
+		fmt.Println("Send RenameRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.RenameResponse{}
+	receive(client, api.MessageType_RENAME_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Printf("Moved %s to %s\n", args[1], args[2])
+}
@@ -0,0 +1,105 @@
+package subcommand
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
+	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
+	"google.golang.org/protobuf/proto"
+)
+
+// PinCommand implements: djctl pin <mount> <path>
+// It keeps a file or directory tree available offline.
+func PinCommand(client *ipc.Client, args []string) {
+	if len(args) < 2 {
+		fmt.Println("Usage: djctl pin <mount> <path>")
+		os.Exit(1)
+	}
+	req := &api.PinRequest{MountId: lookupMountID(client, args[0]), Path: args[1]}
+	if err := client.SendMessage(api.MessageType_PIN_REQUEST, req); err != nil {
+		fmt.Println("Send PinRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.PinResponse{}
+	receive(client, api.MessageType_PIN_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Printf("Pinned %s, its contents are downloaded in the background\n", args[1])
+}
+
+// UnpinCommand implements: djctl unpin <mount> <path>
+// It removes the pins of a path and everything below it.
+func UnpinCommand(client *ipc.Client, args []string) {
+	if len(args) < 2 {
+		fmt.Println("Usage: djctl unpin <mount> <path>")
+		os.Exit(1)
+	}
+	req := &api.UnpinRequest{MountId: lookupMountID(client, args[0]), Path: args[1]}
+	if err := client.SendMessage(api.MessageType_UNPIN_REQUEST, req); err != nil {
+		fmt.Println("Send UnpinRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.UnpinResponse{}
+	receive(client, api.MessageType_UNPIN_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Printf("Unpinned %s\n", args[1])
+}
+
+// PinsCommand implements: djctl pins [mount]
+// It lists the pinned paths and how much of them is cached.
+func PinsCommand(client *ipc.Client, args []string) {
+	req := &api.ListPinsRequest{}
+	if len(args) > 0 {
+		req.MountId = lookupMountID(client, args[0])
+	}
+	if err := client.SendMessage(api.MessageType_LIST_PINS_REQUEST, req); err != nil {
+		fmt.Println("Send ListPinsRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.ListPinsResponse{}
+	receive(client, api.MessageType_LIST_PINS_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	if len(resp.Pins) == 0 {
+		fmt.Println("Nothing pinned")
+		return
+	}
+	for _, pin := range resp.Pins {
+		refreshed := "never"
+		if pin.Refreshed != 0 {
+			refreshed = time.Unix(pin.Refreshed, 0).Format("2006-01-02 15:04")
+		}
+		fmt.Printf("[%d] %s\t%d bytes\trefreshed %s", pin.MountId, pin.Path, pin.Size, refreshed)
+		if pin.LastError != "" {
+			fmt.Printf("\terror: %s", pin.LastError)
+		}
+		fmt.Println()
+	}
+}
+
+// receive reads the response to a request and unmarshals it into resp,
+// exiting on errors.
+func receive(client *ipc.Client, expected api.MessageType, resp proto.Message) {
+	typeReceived, payload, err := client.ReceiveMessage()
+	if err != nil {
+		fmt.Printf("Receive %v error: %v\n", expected, err)
+		os.Exit(1)
+	}
+	if typeReceived != expected {
+		fmt.Printf("Unexpected resp type, expected %v: %v\n", expected, typeReceived)
+		os.Exit(1)
+	}
+	if err := proto.Unmarshal(payload, resp); err != nil {
+		fmt.Println("Unmarshal error:", err)
+		os.Exit(1)
+	}
+}
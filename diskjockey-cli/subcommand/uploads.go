@@ -0,0 +1,84 @@
+package subcommand
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
+	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
+	"google.golang.org/protobuf/proto"
+)
+
+// UploadsCommand implements: djctl uploads [mount]
+// It prints the upload queue of write-back mounts.
+func UploadsCommand(client *ipc.Client, args []string) {
+	req := &api.UploadQueueRequest{}
+	if len(args) > 0 {
+		req.MountId = lookupMountID(client, args[0])
+	}
+
+	if err := client.SendMessage(api.MessageType_UPLOAD_QUEUE_REQUEST, req); err != nil {
+		fmt.Println("Send UploadQueueRequest error:", err)
+		os.Exit(1)
+	}
+	typeReceived, payload, err := client.ReceiveMessage()
+	if err != nil {
+		fmt.Println("Receive UploadQueueResponse error:", err)
+		os.Exit(1)
+	}
+	if typeReceived != api.MessageType_UPLOAD_QUEUE_RESPONSE {
+		fmt.Printf("Unexpected resp type for UploadQueueResponse: %v\n", typeReceived)
+		os.Exit(1)
+	}
+	resp := &api.UploadQueueResponse{}
+	if err := proto.Unmarshal(payload, resp); err != nil {
+		fmt.Println("Unmarshal error:", err)
+		os.Exit(1)
+	}
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	if len(resp.Items) == 0 {
+		fmt.Println("No uploads queued")
+		return
+	}
+
+	for _, item := range resp.Items {
+		status := strings.ToLower(strings.TrimPrefix(item.Status.String(), "UPLOAD_"))
+		detail := ""
+		if item.NextAttempt != 0 && item.Attempts > 0 {
+			detail = fmt.Sprintf(" (attempt %d, retry at %s)", item.Attempts+1, time.Unix(item.NextAttempt, 0).Format("15:04:05"))
+		}
+		if item.LastError != "" {
+			detail += ": " + item.LastError
+		}
+		fmt.Printf("[%d] %-11s %s%s\n", item.MountId, status, item.Path, detail)
+	}
+}
+
+// RetryCommand implements: djctl retry [mount [path]]
+// It queues failed uploads of a mount, or of every mount, again.
+func RetryCommand(client *ipc.Client, args []string) {
+	req := &api.RetryUploadsRequest{}
+	if len(args) > 0 {
+		req.MountId = lookupMountID(client, args[0])
+	}
+	if len(args) > 1 {
+		req.Path = args[1]
+	}
+
+	if err := client.SendMessage(api.MessageType_RETRY_UPLOADS_REQUEST, req); err != nil {
+		fmt.Println("Send RetryUploadsRequest error:", err)
+		os.Exit(1)
+	}
+	resp := &api.RetryUploadsResponse{}
+	receive(client, api.MessageType_RETRY_UPLOADS_RESPONSE, resp)
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Printf("Retrying %d uploads\n", resp.Retried)
+}
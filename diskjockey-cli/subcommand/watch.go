@@ -0,0 +1,67 @@
+package subcommand
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	api "github.com/christhomas/diskjockey/diskjockey-backend/proto/backend"
+	"github.com/christhomas/diskjockey/diskjockey-cli/ipc"
+	"google.golang.org/protobuf/proto"
+)
+
+// WatchCommand implements: djctl watch [mount...]
+// It subscribes to change events for the given mounts (all mounts if none
+// are given) and prints them until interrupted.
+func WatchCommand(client *ipc.Client, args []string) {
+	req := &api.SubscribeChangesRequest{}
+	for _, name := range args {
+		req.MountIds = append(req.MountIds, lookupMountID(client, name))
+	}
+
+	if err := client.SendMessage(api.MessageType_SUBSCRIBE_CHANGES_REQUEST, req); err != nil {
+		fmt.Println("Send SubscribeChangesRequest error:", err)
+		os.Exit(1)
+	}
+	typeReceived, payload, err := client.ReceiveMessage()
+	if err != nil {
+		fmt.Println("Receive SubscribeChangesResponse error:", err)
+		os.Exit(1)
+	}
+	if typeReceived != api.MessageType_SUBSCRIBE_CHANGES_RESPONSE {
+		fmt.Printf("Unexpected resp type for SubscribeChangesResponse: %v\n", typeReceived)
+		os.Exit(1)
+	}
+	resp := &api.SubscribeChangesResponse{}
+	if err := proto.Unmarshal(payload, resp); err != nil {
+		fmt.Println("Unmarshal error:", err)
+		os.Exit(1)
+	}
+	if resp.Error != "" {
+		fmt.Println("Server error:", resp.Error)
+		os.Exit(1)
+	}
+	fmt.Println("Watching for changes, press Ctrl-C to stop")
+
+	for {
+		typeReceived, payload, err := client.ReceiveMessage()
+		if err != nil {
+			fmt.Println("Receive ChangeEvent error:", err)
+			os.Exit(1)
+		}
+		if typeReceived != api.MessageType_CHANGE_EVENT {
+			continue
+		}
+		event := &api.ChangeEvent{}
+		if err := proto.Unmarshal(payload, event); err != nil {
+			fmt.Println("Unmarshal error:", err)
+			os.Exit(1)
+		}
+		kind := strings.ToLower(strings.TrimPrefix(event.Kind.String(), "CHANGE_"))
+		path := event.Path
+		if event.IsDir && !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+		fmt.Printf("[%d] %-8s %s\n", event.MountId, kind, path)
+	}
+}